@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kcansari/optix/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run PROFILE [-- extra arguments]",
+	Short: "Run a saved profile",
+	Long: `Run a command line saved as a profile in a configuration file.
+
+Placeholders such as {{old}} in the profile are filled in with --set.
+Arguments after "--" are appended to the saved command line.
+
+Examples:
+  optix run secrets-scan
+  optix run rename-host --set old=db1.internal --set new=db2.internal
+  optix run secrets-scan -- --context 2`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		assignments, _ := cmd.Flags().GetStringArray("set")
+
+		settings, err := config.LoadDefault()
+		if err != nil {
+			return err
+		}
+		profile, ok := settings.Profile(args[0])
+		if !ok {
+			return fmt.Errorf("unknown profile '%s' (see 'optix alias list')", args[0])
+		}
+
+		values := make(map[string]string)
+		for _, assignment := range assignments {
+			name, value, found := strings.Cut(assignment, "=")
+			if !found || name == "" {
+				return fmt.Errorf("invalid --set '%s' (expected NAME=VALUE)", assignment)
+			}
+			values[name] = value
+		}
+
+		words, err := profile.Expand(values)
+		if err != nil {
+			return err
+		}
+		return runCommandLine(profile.Name, append(words, args[1:]...))
+	},
+}
+
+// runCommandLine runs the command line of a profile as if it had been
+// typed, including the defaults from the configuration.
+func runCommandLine(profileName string, words []string) error {
+	target, rest, err := RootCmd.Find(words)
+	if err != nil || target == RootCmd {
+		return fmt.Errorf("profile '%s': unknown command '%s'", profileName, strings.Join(words, " "))
+	}
+	if managesProfiles(target) {
+		return fmt.Errorf("profile '%s' cannot run '%s'", profileName, target.CommandPath())
+	}
+	if !target.Runnable() {
+		return fmt.Errorf("profile '%s': '%s' needs a subcommand", profileName, target.CommandPath())
+	}
+
+	if err := target.ParseFlags(rest); err != nil {
+		return fmt.Errorf("profile '%s': %w", profileName, err)
+	}
+	positional := target.Flags().Args()
+	if err := target.ValidateArgs(positional); err != nil {
+		return fmt.Errorf("profile '%s': %w", profileName, err)
+	}
+	if err := target.ValidateRequiredFlags(); err != nil {
+		return fmt.Errorf("profile '%s': %w", profileName, err)
+	}
+	if err := target.ValidateFlagGroups(); err != nil {
+		return fmt.Errorf("profile '%s': %w", profileName, err)
+	}
+	if err := applyConfig(target); err != nil {
+		return err
+	}
+
+	if target.RunE != nil {
+		return target.RunE(target, positional)
+	}
+	target.Run(target, positional)
+	return nil
+}
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage saved profiles",
+	Long: `Manage profiles: command lines saved under a name and run with 'optix run'.
+
+Profiles are stored in the user configuration file, or in the project file
+with --project.`,
+}
+
+var aliasAddCmd = &cobra.Command{
+	Use:   "add NAME -- COMMAND [FLAGS...]",
+	Short: "Save a command line as a profile",
+	Long: `Save a command line as a profile.
+
+Words may contain {{name}} placeholders, filled in by 'optix run --set'.
+Quote placeholders so that the shell leaves them alone.
+
+Examples:
+  optix alias add secrets-scan -d "Find secrets in configs" -- search -p "(password|secret)\s*[:=]" -r -f "*.yaml"
+  optix alias add rename-host -- replace --file '{{file}}' --find '{{old}}' --replace '{{new}}' --backup`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		description, _ := cmd.Flags().GetString("description")
+		project, _ := cmd.Flags().GetBool("project")
+		force, _ := cmd.Flags().GetBool("force")
+
+		name := args[0]
+		if err := config.ValidateProfileName(name); err != nil {
+			return err
+		}
+
+		// Split the command path from its flags and arguments
+		target, words, err := RootCmd.Find(args[1:])
+		if err != nil || target == RootCmd || !target.Runnable() {
+			return fmt.Errorf("unknown command '%s'", strings.Join(args[1:], " "))
+		}
+		if managesProfiles(target) {
+			return fmt.Errorf("a profile cannot run '%s'", target.CommandPath())
+		}
+		command := strings.Join(commandPath(target), " ")
+
+		path, err := profileFile(project)
+		if err != nil {
+			return err
+		}
+		profile := config.Profile{Name: name, Description: description, Command: command, Args: words}
+		if err := config.SaveProfile(path, profile, force); err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Saved profile '%s' in %s\n", name, path)
+		fmt.Printf("▶️  optix %s\n", formatWords(profile.CommandLine()))
+		if placeholders := profile.Placeholders(); len(placeholders) > 0 {
+			fmt.Printf("🔣 Placeholders: %s\n", strings.Join(placeholders, ", "))
+		}
+		return nil
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved profiles",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := config.LoadDefault()
+		if err != nil {
+			return err
+		}
+
+		profiles := settings.Profiles()
+		if len(profiles) == 0 {
+			fmt.Printf("ℹ️  No profiles saved (add one with 'optix alias add')\n")
+			return nil
+		}
+
+		fmt.Printf("📚 Profiles: %d\n", len(profiles))
+		fmt.Println("─────────────────────────────────────────────────────")
+		for _, profile := range profiles {
+			fmt.Printf("🔖 %s", profile.Name)
+			if profile.Description != "" {
+				fmt.Printf(" - %s", profile.Description)
+			}
+			fmt.Println()
+			fmt.Printf("   ▶️  optix %s\n", formatWords(profile.CommandLine()))
+			if placeholders := profile.Placeholders(); len(placeholders) > 0 {
+				fmt.Printf("   🔣 Placeholders: %s\n", strings.Join(placeholders, ", "))
+			}
+			fmt.Printf("   📄 %s (%s)\n", profile.Origin, profile.Source)
+		}
+		return nil
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:   "remove NAME",
+	Short: "Remove a saved profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, _ := cmd.Flags().GetBool("project")
+
+		path, err := profileFile(project)
+		if err != nil {
+			return err
+		}
+		removed, err := config.RemoveProfile(path, args[0])
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("profile '%s' not found in %s", args[0], path)
+		}
+
+		fmt.Printf("🗑️  Removed profile '%s' from %s\n", args[0], path)
+		return nil
+	},
+}
+
+// profileFile returns the configuration file that profiles are saved in:
+// the user file, or with project set, the nearest project file (a new
+// one is created in the working directory).
+func profileFile(project bool) (string, error) {
+	if !project {
+		path := config.UserFile()
+		if path == "" {
+			return "", fmt.Errorf("cannot find the user configuration directory")
+		}
+		return path, nil
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if path := config.FindProjectFile(workDir); path != "" {
+		return path, nil
+	}
+	return filepath.Join(workDir, config.ProjectFileNames[0]), nil
+}
+
+// managesProfiles reports whether a command runs or edits profiles; a
+// profile cannot run them.
+func managesProfiles(command *cobra.Command) bool {
+	path := commandPath(command)
+	return len(path) > 0 && (path[0] == "run" || path[0] == "alias")
+}
+
+// formatWords joins words into a command line, quoting those that the
+// shell would change.
+func formatWords(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		if word == "" || strings.ContainsAny(word, " \t\"'\\|*?$&;<>()[]") {
+			quoted[i] = "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+		} else {
+			quoted[i] = word
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+func init() {
+	RootCmd.AddCommand(runCmd)
+	runCmd.Flags().StringArray("set", nil, "Value for a placeholder, as NAME=VALUE (repeatable)")
+
+	RootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasAddCmd, aliasListCmd, aliasRemoveCmd)
+	aliasAddCmd.Flags().StringP("description", "d", "", "Description of the profile")
+	aliasAddCmd.Flags().Bool("project", false, "Save in the project file (.optix.yaml) instead of the user file")
+	aliasAddCmd.Flags().Bool("force", false, "Replace an existing profile of the same name")
+	aliasRemoveCmd.Flags().Bool("project", false, "Remove from the project file instead of the user file")
+}
@@ -0,0 +1,126 @@
+// Package common contains helpers shared by the Optix CLI commands.
+// This file implements archive handling for commands that modify files.
+package common
+
+import (
+	"fmt"
+
+	"github.com/kcansari/optix/internal/backup"
+	"github.com/kcansari/optix/internal/discovery"
+	"github.com/kcansari/optix/internal/lineending"
+	"github.com/kcansari/optix/internal/reader"
+	"github.com/kcansari/optix/internal/types"
+	"github.com/kcansari/optix/internal/validator"
+	"github.com/kcansari/optix/internal/writer"
+	"github.com/spf13/cobra"
+)
+
+// AddArchiveFlags registers the flag that allows modifying archive members.
+func AddArchiveFlags(command *cobra.Command) {
+	command.Flags().Bool("rewrite-archive", false, "Allow modifying members of zip and tar archives (the archive is rebuilt)")
+}
+
+// ArchiveRewriteRequested reports whether fileName is an archive that should
+// be rewritten. Archives are read-only unless --rewrite-archive is given.
+func ArchiveRewriteRequested(command *cobra.Command, fileName string) (bool, error) {
+	isArchive, err := discovery.IsArchive(fileName)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect '%s': %w", fileName, err)
+	}
+	if !isArchive {
+		return false, nil
+	}
+
+	rewrite, _ := command.Flags().GetBool("rewrite-archive")
+	if !rewrite {
+		return false, fmt.Errorf("'%s' is an archive and archives are read-only (use --rewrite-archive to modify its members)", fileName)
+	}
+	if output, _ := command.Flags().GetString("output"); output != "" {
+		return false, fmt.Errorf("--output cannot be used with --rewrite-archive")
+	}
+	return true, nil
+}
+
+// ArchiveResult summarises a rewrite of archive members.
+type ArchiveResult struct {
+	Members        int
+	ChangedMembers int
+	MatchesFound   int
+	LinesProcessed int
+	BackupPath     string
+}
+
+// RewriteArchive runs process over every member of the archive and writes
+// the changed members back. process must not write files itself (callers
+// pass DryRun options). A single backup of the whole archive is made when
+// requested, and nothing is written in a dry run. Binary members are left
+// unchanged unless the policy is text or error.
+func RewriteArchive(fileName string, readerStrategy *reader.FileReaderStrategy, policy validator.BinaryPolicy, options types.ProcessOptions, process func(*reader.FileContent, types.ProcessOptions) (*types.ProcessingResult, error)) (*ArchiveResult, error) {
+	summary := &ArchiveResult{}
+	validatorStrategy := validator.NewValidatorStrategy(validator.NewBasicFileValidator())
+	if policy == validator.BinaryReport {
+		policy = validator.BinarySkip
+	}
+
+	rewriteMember := func(entry discovery.Entry) ([]byte, bool, error) {
+		content, _, err := ReadEntry(entry, readerStrategy, validatorStrategy, policy)
+		if err != nil || content == nil {
+			return nil, false, err
+		}
+
+		memberOptions := options
+		memberOptions.FileName = entry.Name
+		memberOptions.DryRun = true
+		memberOptions.CreateBackup = false
+
+		result, err := process(content, memberOptions)
+		if err != nil {
+			return nil, false, fmt.Errorf("'%s': %w", entry.Name, err)
+		}
+
+		summary.Members++
+		summary.MatchesFound += result.MatchesFound
+		summary.LinesProcessed += result.LinesProcessed
+
+		writeOptions := writer.OptionsFor(content, entry.Name, entry.Name)
+		if result.LineEnding != "" {
+			writeOptions.LineEnding = lineending.Style(result.LineEnding)
+		}
+		if result.ModifiedContent == content.Content && writeOptions.LineEnding == lineending.Style(content.LineEnding) {
+			return nil, false, nil
+		}
+		summary.ChangedMembers++
+
+		data, err := writer.Encode(result.ModifiedContent, writeOptions)
+		if err != nil {
+			return nil, false, fmt.Errorf("'%s': %w", entry.Name, err)
+		}
+		return data, true, nil
+	}
+
+	if options.DryRun {
+		// Walk the members without rebuilding the archive; nested archives
+		// are copied unchanged by a rewrite, so they are skipped here too
+		err := discovery.Walk(discovery.Target{Path: fileName}, func(entry discovery.Entry) error {
+			if entry.Nested() {
+				return nil
+			}
+			_, _, err := rewriteMember(entry)
+			return err
+		})
+		return summary, err
+	}
+
+	if options.CreateBackup {
+		backupPath, err := backup.Create(fileName, options.BackupDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create backup: %w", err)
+		}
+		summary.BackupPath = backupPath
+	}
+
+	if _, err := discovery.Rewrite(fileName, rewriteMember); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
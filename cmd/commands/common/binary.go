@@ -0,0 +1,96 @@
+// Package common contains helpers shared by the Optix CLI commands.
+// This file implements the --binary policy for binary input files.
+package common
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kcansari/optix/internal/discovery"
+	"github.com/kcansari/optix/internal/reader"
+	"github.com/kcansari/optix/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+// AddBinaryFlag registers the flag that decides how binary files are
+// treated. Commands that modify files refuse binary files by default.
+func AddBinaryFlag(command *cobra.Command, modifiesFiles bool) {
+	usage := "How to treat binary files: binary (only report matches), skip, text (process as text), error"
+	if modifiesFiles {
+		usage = "How to treat binary files: binary or error (refuse), skip, text (modify as text)"
+	}
+	command.Flags().String("binary", string(validator.BinaryReport), usage)
+}
+
+// BinaryPolicyFromFlags returns the policy selected with --binary.
+func BinaryPolicyFromFlags(command *cobra.Command) (validator.BinaryPolicy, error) {
+	name, _ := command.Flags().GetString("binary")
+	return validator.ParseBinaryPolicy(name)
+}
+
+// ReadEntry reads a file or archive member according to the binary policy.
+// Text is read with the reader strategy. Binary content is returned as raw
+// bytes (binary reports true) under the binary and text policies, as nil
+// content under the skip policy and as a *validator.BinaryFileError under
+// the error policy.
+func ReadEntry(entry discovery.Entry, readerStrategy *reader.FileReaderStrategy, validatorStrategy *validator.ValidatorStrategy, policy validator.BinaryPolicy) (content *reader.FileContent, binary bool, err error) {
+	var reason string
+	if entry.InArchive() || entry.IsStdin() {
+		data, err := entry.Data()
+		if err != nil {
+			return nil, false, err
+		}
+		binary, reason = validator.DetectBinaryData(entry.DataName(), data)
+	} else {
+		binary, reason, err = validatorStrategy.DetectBinary(entry.Path)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if !binary {
+		content, err := entry.Read(readerStrategy)
+		return content, false, err
+	}
+
+	switch policy {
+	case validator.BinarySkip:
+		return nil, true, nil
+	case validator.BinaryError:
+		return nil, true, &validator.BinaryFileError{Filename: entry.Name, Reason: reason}
+	}
+
+	data, format, err := entry.Raw()
+	if err != nil {
+		return nil, true, err
+	}
+	content = reader.RawContent(data)
+	content.Compression = string(format)
+
+	return content, true, nil
+}
+
+// ReadInputFile reads the input of a command that modifies files, or
+// standard input for "-". Binary files are only modified under the text
+// policy; the binary policy refuses them like the error policy. Skipped
+// files yield nil content.
+func ReadInputFile(fileName string, readerStrategy *reader.FileReaderStrategy, validatorStrategy *validator.ValidatorStrategy, policy validator.BinaryPolicy) (*reader.FileContent, error) {
+	if policy == validator.BinaryReport {
+		policy = validator.BinaryError
+	}
+
+	entry, err := InputEntry(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	content, _, err := ReadEntry(entry, readerStrategy, validatorStrategy, policy)
+	if err != nil {
+		var binaryErr *validator.BinaryFileError
+		if errors.As(err, &binaryErr) {
+			return nil, fmt.Errorf("%w; use --binary text to process it anyway or --binary skip to ignore it", err)
+		}
+		return nil, err
+	}
+	return content, nil
+}
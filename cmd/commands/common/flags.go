@@ -0,0 +1,15 @@
+// Package common contains helpers shared by the Optix CLI commands.
+// This file implements helpers for inspecting command flags.
+package common
+
+import (
+	"github.com/kcansari/optix/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// FlagGiven reports whether the flag was given on the command line or set
+// from the configuration, rather than left at its built-in default. Checks
+// for conflicting flags use Changed instead, which ignores the configuration.
+func FlagGiven(command *cobra.Command, name string) bool {
+	return command.Flags().Changed(name) || config.Configured(command.Flags().Lookup(name))
+}
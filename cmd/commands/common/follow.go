@@ -0,0 +1,215 @@
+// Package common contains helpers shared by the Optix CLI commands.
+// This file implements --follow, which processes lines as they are appended.
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kcansari/optix/internal/compression"
+	"github.com/kcansari/optix/internal/discovery"
+	"github.com/kcansari/optix/internal/follow"
+	"github.com/kcansari/optix/internal/logparser"
+	"github.com/kcansari/optix/internal/reader"
+	"github.com/spf13/cobra"
+)
+
+// defaultPollInterval is how often followed files are checked for new lines.
+const defaultPollInterval = 500 * time.Millisecond
+
+// FollowBatch holds the new lines of a followed file.
+type FollowBatch struct {
+	// Name is the path of the file
+	Name string
+
+	// Lines are the new lines, without line breaks
+	Lines []string
+
+	// FirstLine is the line number of the first of the lines
+	FirstLine int
+}
+
+// Content returns the lines of the batch as file content for a processor.
+func (b FollowBatch) Content() *reader.FileContent {
+	var content strings.Builder
+	for _, line := range b.Lines {
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+	return &reader.FileContent{
+		Content:   content.String(),
+		Lines:     b.Lines,
+		FileType:  "txt",
+		LineCount: len(b.Lines),
+	}
+}
+
+// AddFollowFlags registers the flags that keep a command reading new lines.
+func AddFollowFlags(command *cobra.Command) {
+	command.Flags().Bool("follow", false, "Keep running and process lines as they are appended, like tail -f (stop with Ctrl+C)")
+	command.Flags().Duration("poll-interval", defaultPollInterval, "How often followed files are checked for new lines")
+}
+
+// FollowOptionsFromFlags returns whether --follow was given and the poll
+// interval.
+func FollowOptionsFromFlags(command *cobra.Command) (bool, time.Duration, error) {
+	enabled, _ := command.Flags().GetBool("follow")
+	interval, _ := command.Flags().GetDuration("poll-interval")
+	if interval <= 0 {
+		return false, 0, fmt.Errorf("--poll-interval must be a positive duration such as 500ms")
+	}
+	return enabled, interval, nil
+}
+
+// ValidateFollowTarget checks that a file can be followed: a plain file,
+// not standard input, an archive or a compressed file.
+func ValidateFollowTarget(fileName string) error {
+	if IsStdin(fileName) {
+		return fmt.Errorf("--follow needs a file; standard input is read to its end before processing")
+	}
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return fmt.Errorf("cannot follow '%s': %w", fileName, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("cannot follow '%s': not a regular file", fileName)
+	}
+	if isArchive, err := discovery.IsArchive(fileName); err == nil && isArchive {
+		return fmt.Errorf("cannot follow archive '%s'", fileName)
+	}
+	if format, err := compression.DetectFile(fileName); err == nil && format != compression.None {
+		return fmt.Errorf("cannot follow %s compressed file '%s'", format, fileName)
+	}
+	return nil
+}
+
+// FollowFiles passes the lines appended to the files to handle, polling
+// every interval, until SIGINT or SIGTERM arrives. The files are then read
+// one last time, so nothing written before the signal is lost, and
+// FollowFiles returns nil.
+//
+// With a record start (see ProcessOptions.RecordStart) the lines of a
+// record that may still be growing, such as a stack trace being written,
+// are held back until the next record starts or the file has been quiet
+// for one interval, so that records are never split between batches.
+func FollowFiles(paths []string, interval time.Duration, recordStart string, handle func(FollowBatch) error) error {
+	var grouping *logparser.RecordGrouping
+	if recordStart != "" {
+		var err error
+		if grouping, err = logparser.ParseRecordGrouping(recordStart); err != nil {
+			return err
+		}
+	}
+
+	followed := make([]*followedFile, 0, len(paths))
+	defer func() {
+		for _, file := range followed {
+			file.follower.Close()
+		}
+	}()
+	for _, path := range paths {
+		follower, err := follow.Open(path)
+		if err != nil {
+			return err
+		}
+		follower.OnRotate = func(reason string) {
+			fmt.Fprintf(Warnings(), "🔄 %s was %s; reading it from the start\n", path, reason)
+		}
+		followed = append(followed, &followedFile{follower: follower, grouping: grouping})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Read what was written before the signal, then flush everything
+			for _, file := range followed {
+				if err := file.poll(handle); err != nil {
+					return err
+				}
+				lines, firstLine := file.follower.Flush()
+				if err := file.add(lines, firstLine, handle); err != nil {
+					return err
+				}
+				if err := file.flush(len(file.pending), handle); err != nil {
+					return err
+				}
+			}
+			return nil
+		case <-ticker.C:
+			for _, file := range followed {
+				if err := file.poll(handle); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// followedFile holds the lines of a followed file not yet handled.
+type followedFile struct {
+	follower *follow.Follower
+	grouping *logparser.RecordGrouping
+
+	// pending are the lines of a record that may still grow
+	pending      []string
+	pendingFirst int
+}
+
+// poll reads the new lines and handles the ones that are complete.
+func (f *followedFile) poll(handle func(FollowBatch) error) error {
+	lines, firstLine, err := f.follower.Poll()
+	if err != nil {
+		return err
+	}
+	if err := f.add(lines, firstLine, handle); err != nil {
+		return err
+	}
+
+	// A quiet file has finished its last record
+	if len(lines) == 0 || f.grouping == nil {
+		return f.flush(len(f.pending), handle)
+	}
+
+	// Keep the last record, which the next lines may continue
+	starts := f.grouping.Group(f.pending)
+	return f.flush(starts[len(starts)-1], handle)
+}
+
+// add appends lines to the pending ones. Lines that do not follow on from
+// the pending ones, because the file was rotated, flush them first.
+func (f *followedFile) add(lines []string, firstLine int, handle func(FollowBatch) error) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	if len(f.pending) > 0 && firstLine != f.pendingFirst+len(f.pending) {
+		if err := f.flush(len(f.pending), handle); err != nil {
+			return err
+		}
+	}
+	if len(f.pending) == 0 {
+		f.pendingFirst = firstLine
+	}
+	f.pending = append(f.pending, lines...)
+	return nil
+}
+
+// flush handles the first count pending lines.
+func (f *followedFile) flush(count int, handle func(FollowBatch) error) error {
+	if count == 0 {
+		return nil
+	}
+	batch := FollowBatch{Name: f.follower.Path(), Lines: f.pending[:count], FirstLine: f.pendingFirst}
+	f.pending = append([]string(nil), f.pending[count:]...)
+	f.pendingFirst += count
+	return handle(batch)
+}
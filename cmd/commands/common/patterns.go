@@ -0,0 +1,29 @@
+// Package common contains helpers shared by the Optix CLI commands.
+// This file implements the registry of kinds of values for extract and redact.
+package common
+
+import (
+	"fmt"
+
+	"github.com/kcansari/optix/internal/config"
+	"github.com/kcansari/optix/internal/patterns"
+)
+
+// PatternRegistry returns the built-in kinds of values together with the
+// kinds of the patterns section of the configuration files, which replace
+// built-in kinds of the same name.
+func PatternRegistry() (*patterns.Registry, error) {
+	registry := patterns.NewDefaultRegistry()
+	settings, err := config.LoadDefault()
+	if err != nil {
+		return nil, err
+	}
+	for _, pattern := range settings.Patterns() {
+		kind, err := patterns.NewKind(pattern.Name, pattern.Description, pattern.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pattern.Origin, err)
+		}
+		registry.Add(kind)
+	}
+	return registry, nil
+}
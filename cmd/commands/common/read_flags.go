@@ -0,0 +1,129 @@
+// Package common contains helpers shared by the Optix CLI commands.
+// This file implements the flags that control how input files are read.
+package common
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/kcansari/optix/internal/compression"
+	"github.com/kcansari/optix/internal/reader"
+	"github.com/kcansari/optix/internal/reader/strategies"
+	"github.com/kcansari/optix/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// maxReportedInvalidLines limits how many skipped lines are listed individually.
+const maxReportedInvalidLines = 10
+
+// AddReadFlags registers the flags that control how input files are read.
+func AddReadFlags(command *cobra.Command) {
+	command.Flags().Bool("skip-invalid", false, "Skip records that fail to decode (JSON Lines, logs) instead of failing")
+	command.Flags().Int("max-errors", 0, "Fail after this many invalid records (implies --skip-invalid, 0 = no limit)")
+	AddTypeFlags(command)
+}
+
+// AddTypeFlags registers the flags that choose the type of input files,
+// for commands that read files without decoding their records.
+func AddTypeFlags(command *cobra.Command) {
+	command.Flags().String("type", "", "Read input as this type (txt, csv, json, jsonl, yaml, toml, xml, logfmt, access, syslog) instead of detecting it")
+	command.Flags().StringToString("ext-map", nil, "Map extensions to types, e.g. --ext-map .conf=toml,.out=jsonl")
+}
+
+// ReadOptionsFromFlags builds reader options from the flags registered by
+// AddReadFlags or AddTypeFlags.
+func ReadOptionsFromFlags(command *cobra.Command) (types.ReadOptions, error) {
+	skipInvalid, _ := command.Flags().GetBool("skip-invalid")
+	maxErrors, _ := command.Flags().GetInt("max-errors")
+
+	fileType, _ := command.Flags().GetString("type")
+	extensionTypes, _ := command.Flags().GetStringToString("ext-map")
+
+	if maxErrors < 0 {
+		return types.ReadOptions{}, fmt.Errorf("--max-errors cannot be negative")
+	}
+
+	return types.ReadOptions{
+		SkipInvalid:    skipInvalid,
+		MaxErrors:      maxErrors,
+		Type:           fileType,
+		ExtensionTypes: extensionTypes,
+	}, nil
+}
+
+// NewReaderStrategy creates the default reader strategy configured from the
+// command's read flags.
+func NewReaderStrategy(command *cobra.Command) (*reader.FileReaderStrategy, error) {
+	options, err := ReadOptionsFromFlags(command)
+	if err != nil {
+		return nil, err
+	}
+	return NewReaderStrategyWithOptions(options)
+}
+
+// NewTextReaderStrategy creates the reader strategy for commands that work
+// on the lines of any file, such as logs and secret scans: files are read
+// as text unless --type or --ext-map chooses another reader.
+func NewTextReaderStrategy(command *cobra.Command) (*reader.FileReaderStrategy, error) {
+	options, err := ReadOptionsFromFlags(command)
+	if err != nil {
+		return nil, err
+	}
+	options.DefaultType = "txt"
+	return NewReaderStrategyWithOptions(options)
+}
+
+// InputType returns the reader type chosen for fileName with --type or
+// --ext-map, or "" when the reader is chosen by detection. A compression
+// extension is ignored, so app.out.gz is mapped like app.out.
+func InputType(options types.ReadOptions, fileName string) string {
+	if options.Type != "" {
+		return strings.ToLower(options.Type)
+	}
+
+	extension := strings.ToLower(filepath.Ext(compression.TrimExtension(fileName)))
+	for mapped, fileType := range options.ExtensionTypes {
+		if extension != "" && "."+strings.TrimPrefix(strings.ToLower(mapped), ".") == extension {
+			return strings.ToLower(fileType)
+		}
+	}
+	return ""
+}
+
+// NewReaderStrategyWithOptions creates the default reader strategy and
+// checks that the requested reader types exist.
+func NewReaderStrategyWithOptions(options types.ReadOptions) (*reader.FileReaderStrategy, error) {
+	strategy := strategies.NewFileReaderStrategyWithOptions(options)
+
+	// Reject unknown types up front rather than on the first file
+	if options.Type != "" && strategy.GetReaderForType(options.Type) == nil {
+		return nil, fmt.Errorf("unsupported input type '%s'. Supported types: %s",
+			options.Type, strings.Join(strategy.GetSupportedTypeNames(), ", "))
+	}
+	for extension, fileType := range options.ExtensionTypes {
+		if strategy.GetReaderForType(fileType) == nil {
+			return nil, fmt.Errorf("unsupported type '%s' in --ext-map for '%s'. Supported types: %s",
+				fileType, extension, strings.Join(strategy.GetSupportedTypeNames(), ", "))
+		}
+	}
+
+	return strategy, nil
+}
+
+// ReportInvalidLines prints the lines a reader skipped, if any.
+func ReportInvalidLines(w io.Writer, filename string, content *types.FileContent) {
+	if len(content.InvalidLines) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "⚠️  Skipped %d invalid line(s) in %s:\n", len(content.InvalidLines), filename)
+	for i, invalid := range content.InvalidLines {
+		if i == maxReportedInvalidLines {
+			fmt.Fprintf(w, "   ... and %d more\n", len(content.InvalidLines)-maxReportedInvalidLines)
+			break
+		}
+		fmt.Fprintf(w, "   line %d: %s\n", invalid.LineNumber, invalid.Message)
+	}
+}
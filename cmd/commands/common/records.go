@@ -0,0 +1,29 @@
+// Package common contains helpers shared by the Optix CLI commands.
+// This file implements the --record-start flag for multi-line records.
+package common
+
+import (
+	"strings"
+
+	"github.com/kcansari/optix/internal/logparser"
+	"github.com/spf13/cobra"
+)
+
+// AddRecordFlag registers the flag that groups lines into multi-line
+// records, such as a log line and its stack trace.
+func AddRecordFlag(command *cobra.Command) {
+	command.Flags().String("record-start", "", "Group lines into records starting at lines matching this regex, or use a preset: "+strings.Join(logparser.RecordPresets(), ", "))
+}
+
+// RecordStartFromFlags returns the value of --record-start after checking
+// that it is a preset or a valid regular expression.
+func RecordStartFromFlags(command *cobra.Command) (string, error) {
+	recordStart, _ := command.Flags().GetString("record-start")
+	if recordStart == "" {
+		return "", nil
+	}
+	if _, err := logparser.ParseRecordGrouping(recordStart); err != nil {
+		return "", err
+	}
+	return recordStart, nil
+}
@@ -0,0 +1,120 @@
+// Package common contains helpers shared by the Optix CLI commands.
+// This file implements reading standard input and writing standard output.
+package common
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kcansari/optix/internal/discovery"
+	"github.com/kcansari/optix/internal/lineending"
+	"github.com/kcansari/optix/internal/reader"
+	"github.com/kcansari/optix/internal/types"
+	"github.com/kcansari/optix/internal/validator"
+	"github.com/kcansari/optix/internal/writer"
+)
+
+// IsStdin reports whether an input file name stands for standard input:
+// "-", or no file at all.
+func IsStdin(fileName string) bool {
+	return fileName == "" || fileName == discovery.StdinName
+}
+
+// IsStdout reports whether an output file name stands for standard output.
+func IsStdout(fileName string) bool {
+	return fileName == discovery.StdinName
+}
+
+// WritesStdout reports whether a command that modifies its input writes
+// the result to standard output: when the output file is "-", or when it
+// reads standard input and no output file is given.
+func WritesStdout(fileName, outputFile string) bool {
+	return IsStdout(outputFile) || (IsStdin(fileName) && outputFile == "")
+}
+
+// StdinPiped reports whether standard input is a pipe or a file rather
+// than a terminal, i.e. whether reading it will not wait for typing.
+func StdinPiped() bool {
+	return !isTerminal(os.Stdin)
+}
+
+// Interactive reports whether standard output is a terminal.
+func Interactive() bool {
+	return isTerminal(os.Stdout)
+}
+
+// Console returns the writer for banners, progress messages and summaries.
+// They go to standard output on a terminal and are dropped otherwise, so
+// that piped output holds nothing but the results.
+func Console() io.Writer {
+	if Interactive() {
+		return os.Stdout
+	}
+	return io.Discard
+}
+
+// Warnings returns the writer for messages about files that could not be
+// processed: standard output on a terminal, standard error otherwise.
+func Warnings() io.Writer {
+	if Interactive() {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ValidateInput validates an input file. Standard input is valid when
+// something is piped to it; a terminal would wait for typed input.
+func ValidateInput(validatorStrategy *validator.ValidatorStrategy, fileName string) error {
+	if IsStdin(fileName) {
+		if !StdinPiped() {
+			return fmt.Errorf("no input file given and nothing piped to standard input")
+		}
+		return nil
+	}
+	if err := validatorStrategy.ValidateFile(fileName); err != nil {
+		return fmt.Errorf("file validation failed: %v", err)
+	}
+	return nil
+}
+
+// InputEntry returns the entry for an input file. Standard input is read
+// into memory; it has no extension, so its reader is chosen by --type or
+// by content detection.
+func InputEntry(fileName string) (discovery.Entry, error) {
+	if IsStdin(fileName) {
+		return discovery.ReadStdin(os.Stdin)
+	}
+	return discovery.Entry{Name: fileName, Path: fileName}, nil
+}
+
+// ReadFile reads a file, or standard input for "-", with the reader strategy.
+func ReadFile(readerStrategy *reader.FileReaderStrategy, fileName string) (*reader.FileContent, error) {
+	entry, err := InputEntry(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return entry.Read(readerStrategy)
+}
+
+// WriteStdout writes the modified content of a processing result to
+// standard output in the encoding and line endings of its input. The
+// output is not compressed, so that it can be piped to other tools.
+func WriteStdout(content *reader.FileContent, result *types.ProcessingResult) error {
+	options := writer.OptionsFor(content, "", discovery.StdinName)
+	if result.LineEnding != "" {
+		options.LineEnding = lineending.Style(result.LineEnding)
+	}
+
+	data, err := writer.Encode(result.ModifiedContent, options)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
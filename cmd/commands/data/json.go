@@ -0,0 +1,23 @@
+// Package data contains the CLI commands for structured data formats.
+// This file implements the 'json' parent command.
+package data
+
+import (
+	"github.com/kcansari/optix/cmd"
+	"github.com/spf13/cobra"
+)
+
+// jsonCmd groups the JSON specific subcommands.
+var jsonCmd = &cobra.Command{
+	Use:   "json",
+	Short: "Work with JSON and JSON Lines documents",
+	Long: `Work with JSON and JSON Lines documents.
+
+Subcommands operate on a decoded, order-preserving view of the document,
+so number literals are kept exactly as written.`,
+}
+
+// init registers the json command with the root command.
+func init() {
+	cmd.RootCmd.AddCommand(jsonCmd)
+}
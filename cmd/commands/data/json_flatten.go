@@ -0,0 +1,141 @@
+// Package data contains the CLI commands for structured data formats.
+// This file implements the 'json flatten' and 'json unflatten' commands.
+package data
+
+import (
+	"fmt"
+
+	"github.com/kcansari/optix/cmd/commands/common"
+	"github.com/kcansari/optix/internal/jsonutil"
+	"github.com/spf13/cobra"
+)
+
+// jsonFlattenCmd represents the json flatten command.
+// This command turns nested JSON into single-level objects with path keys.
+var jsonFlattenCmd = &cobra.Command{
+	Use:   "flatten [filename]",
+	Short: "Flatten nested JSON into dotted path keys",
+	Long: `Flatten nested objects and arrays into a single-level object.
+
+Object keys are joined with the separator and array elements use [index]
+notation, which makes the output easy to feed into tabular tools:
+
+  {"database": {"host": "localhost"}, "items": [{"id": 1}]}
+  becomes
+  {"database.host": "localhost", "items[0].id": 1}
+
+Empty objects and arrays are kept as values so 'json unflatten' can
+restore the original document.
+
+Examples:
+  optix json flatten config.json
+  optix json flatten config.json --separator /
+  optix json flatten events.jsonl --output flat.jsonl`,
+
+	Args: cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		separator, _ := cmd.Flags().GetString("separator")
+
+		return runJSONRewrite(cmd, args[0], func(value any) (any, error) {
+			return jsonutil.Flatten(value, separator), nil
+		})
+	},
+}
+
+// jsonUnflattenCmd represents the json unflatten command.
+// This command rebuilds nested JSON from objects with path keys.
+var jsonUnflattenCmd = &cobra.Command{
+	Use:   "unflatten [filename]",
+	Short: "Rebuild nested JSON from dotted path keys",
+	Long: `Rebuild nested objects and arrays from a flattened object.
+
+This is the inverse of 'json flatten': keys such as "database.host" and
+"items[0].id" become nested objects and arrays again. Use the same
+--separator that was used for flattening.
+
+Examples:
+  optix json unflatten flat.json
+  optix json unflatten flat.json --separator /
+  optix json unflatten flat.jsonl --output nested.jsonl`,
+
+	Args: cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		separator, _ := cmd.Flags().GetString("separator")
+
+		return runJSONRewrite(cmd, args[0], func(value any) (any, error) {
+			return jsonutil.Unflatten(value, separator)
+		})
+	},
+}
+
+// runJSONRewrite applies rewrite to a JSON document, or to every record of
+// a JSON Lines stream, and writes the result according to the output flags.
+func runJSONRewrite(cmd *cobra.Command, fileName string, rewrite func(value any) (any, error)) error {
+	linesMode, _ := cmd.Flags().GetBool("lines")
+	minify, _ := cmd.Flags().GetBool("minify")
+	separator, _ := cmd.Flags().GetString("separator")
+
+	if separator == "" {
+		return fmt.Errorf("separator cannot be empty")
+	}
+
+	readOptions, err := common.ReadOptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	linesMode = linesMode || isJSONLinesInput(readOptions, fileName)
+
+	options := jsonutil.FormatOptions{Indent: "  "}
+	if minify || linesMode {
+		options.Indent = ""
+	}
+
+	content, err := readJSONSource(readOptions, fileName)
+	if err != nil {
+		return err
+	}
+
+	encode := func(value any) ([]byte, error) {
+		rewritten, err := rewrite(value)
+		if err != nil {
+			return nil, err
+		}
+		return jsonutil.Format(rewritten, options)
+	}
+
+	var output string
+	if linesMode {
+		output, err = jsonutil.TransformLines(content.Content, encode)
+		if err != nil {
+			return fmt.Errorf("failed to process '%s': %w", fileName, err)
+		}
+	} else {
+		value, err := jsonutil.DecodeString(content.Content)
+		if err != nil {
+			return fmt.Errorf("file '%s' contains invalid JSON: %w", fileName, err)
+		}
+		encoded, err := encode(value)
+		if err != nil {
+			return fmt.Errorf("failed to process '%s': %w", fileName, err)
+		}
+		output = string(encoded)
+		if options.Indent == "" {
+			output += "\n"
+		}
+	}
+
+	return writeJSONOutput(cmd, fileName, content, output)
+}
+
+// init registers the flatten commands and their flags.
+func init() {
+	for _, command := range []*cobra.Command{jsonFlattenCmd, jsonUnflattenCmd} {
+		jsonCmd.AddCommand(command)
+
+		command.Flags().StringP("separator", "s", jsonutil.DefaultSeparator, "Separator between object keys")
+		command.Flags().Bool("minify", false, "Write compact output instead of pretty-printing")
+		addJSONOutputFlags(command)
+	}
+}
@@ -0,0 +1,224 @@
+// Package data contains the CLI commands for structured data formats.
+// This file implements the 'json fmt' command for reformatting JSON.
+package data
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kcansari/optix/cmd/commands/common"
+	"github.com/kcansari/optix/internal/backup"
+	"github.com/kcansari/optix/internal/compression"
+	"github.com/kcansari/optix/internal/jsonutil"
+	"github.com/kcansari/optix/internal/reader"
+	"github.com/kcansari/optix/internal/types"
+	"github.com/kcansari/optix/internal/validator"
+	"github.com/kcansari/optix/internal/writer"
+	"github.com/spf13/cobra"
+)
+
+// jsonFmtCmd represents the json fmt command.
+// This command pretty-prints, minifies or canonicalizes JSON documents.
+var jsonFmtCmd = &cobra.Command{
+	Use:   "fmt [filename]",
+	Short: "Pretty-print, minify or canonicalize JSON",
+	Long: `Reformat a JSON document or a JSON Lines stream.
+
+Formatting modes:
+  - indent:    Pretty-print with N spaces or tabs (default: 2 spaces)
+  - minify:    Remove all insignificant whitespace
+  - sort-keys: Order object members by key
+  - canonical: RFC 8785 canonical form, suitable for hashing and signing
+
+Number literals are preserved exactly as written (big integers and
+high-precision decimals are never rounded through float64), except in
+canonical mode where RFC 8785 mandates IEEE 754 number serialization.
+
+Files with a .jsonl or .ndjson extension (or any file with --lines or
+--type jsonl) are processed line by line; each record is written on its
+own line.
+
+Examples:
+  optix json fmt config.json                      # Pretty-print to stdout
+  optix json fmt config.json --indent tab         # Indent with tabs
+  optix json fmt data.json --minify --output data.min.json
+  optix json fmt config.json --sort-keys --in-place --backup
+  optix json fmt payload.json --canonical | sha256sum
+  optix json fmt events.jsonl --sort-keys`,
+
+	Args: cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fileName := args[0]
+
+		// Get flag values
+		indentSpec, _ := cmd.Flags().GetString("indent")
+		minify, _ := cmd.Flags().GetBool("minify")
+		sortKeys, _ := cmd.Flags().GetBool("sort-keys")
+		canonical, _ := cmd.Flags().GetBool("canonical")
+		linesMode, _ := cmd.Flags().GetBool("lines")
+
+		// Validate flag combinations
+		indentChanged := cmd.Flags().Changed("indent")
+		if minify && indentChanged {
+			return fmt.Errorf("cannot use both --minify and --indent flags")
+		}
+		if canonical && (minify || indentChanged || sortKeys) {
+			return fmt.Errorf("--canonical cannot be combined with --indent, --minify or --sort-keys")
+		}
+
+		readOptions, err := common.ReadOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		linesMode = linesMode || isJSONLinesInput(readOptions, fileName)
+		if linesMode && indentChanged {
+			return fmt.Errorf("--indent cannot be used with JSON Lines input; records are always written one per line")
+		}
+
+		indent, err := jsonutil.ParseIndent(indentSpec)
+		if err != nil {
+			return err
+		}
+		if minify || linesMode {
+			indent = ""
+		}
+
+		content, err := readJSONSource(readOptions, fileName)
+		if err != nil {
+			return err
+		}
+
+		// Pick the encoder for a single decoded value
+		encode := func(value any) ([]byte, error) {
+			if canonical {
+				return jsonutil.Canonicalize(value)
+			}
+			return jsonutil.Format(value, jsonutil.FormatOptions{Indent: indent, SortKeys: sortKeys})
+		}
+
+		var output string
+		if linesMode {
+			output, err = jsonutil.TransformLines(content.Content, encode)
+			if err != nil {
+				return fmt.Errorf("failed to format '%s': %w", fileName, err)
+			}
+		} else {
+			value, err := jsonutil.DecodeString(content.Content)
+			if err != nil {
+				return fmt.Errorf("file '%s' contains invalid JSON: %w", fileName, err)
+			}
+			encoded, err := encode(value)
+			if err != nil {
+				return fmt.Errorf("failed to format '%s': %w", fileName, err)
+			}
+			output = string(encoded)
+			// Minified output is a single line; terminate it like any text file.
+			// Canonical output is left untouched so it can be hashed as is.
+			if indent == "" && !canonical {
+				output += "\n"
+			}
+		}
+
+		return writeJSONOutput(cmd, fileName, content, output)
+	},
+}
+
+// isJSONLinesInput reports whether fileName is read as JSON Lines, from its
+// extension or the type chosen with --type or --ext-map.
+func isJSONLinesInput(options types.ReadOptions, fileName string) bool {
+	if fileType := common.InputType(options, fileName); fileType != "" {
+		return fileType == "jsonl" || fileType == "ndjson"
+	}
+	return jsonutil.IsJSONLinesFile(compression.TrimExtension(fileName))
+}
+
+// readJSONSource validates and reads a file through the reader strategy
+// configured by the read flags.
+func readJSONSource(options types.ReadOptions, fileName string) (*reader.FileContent, error) {
+	validatorStrategy := validator.NewValidatorStrategy(validator.NewBasicFileValidator())
+	if err := validatorStrategy.ValidateFile(fileName); err != nil {
+		return nil, fmt.Errorf("file validation failed: %v", err)
+	}
+
+	readerStrategy, err := common.NewReaderStrategyWithOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	content, err := readerStrategy.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	return content, nil
+}
+
+// writeJSONOutput writes the result in place, to --output, or to stdout.
+// It honours the --in-place, --backup, --backup-dir and --output flags.
+// Files are written in the on-disk format of the source (e.g. gzip) when
+// rewritten in place, or as implied by the --output extension.
+func writeJSONOutput(cmd *cobra.Command, fileName string, content *reader.FileContent, output string) error {
+	inPlace, _ := cmd.Flags().GetBool("in-place")
+	createBackup, _ := cmd.Flags().GetBool("backup")
+	backupDir, _ := cmd.Flags().GetString("backup-dir")
+	outputFile, _ := cmd.Flags().GetString("output")
+
+	if inPlace && outputFile != "" {
+		return fmt.Errorf("cannot use both --in-place and --output flags")
+	}
+	if createBackup && !inPlace {
+		return fmt.Errorf("--backup requires --in-place")
+	}
+
+	if !inPlace && outputFile == "" {
+		fmt.Print(output)
+		return nil
+	}
+
+	target := outputFile
+	if inPlace {
+		target = fileName
+	}
+
+	var backupPath string
+	if createBackup {
+		var err error
+		backupPath, err = backup.Create(fileName, backupDir)
+		if err != nil {
+			return fmt.Errorf("failed to create backup: %w", err)
+		}
+	}
+
+	if err := writer.WriteFile(target, output, writer.OptionsFor(content, fileName, target)); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", target, err)
+	}
+
+	// Status goes to stderr so it never mixes with JSON written to stdout
+	fmt.Fprintf(os.Stderr, "✅ Wrote %s\n", target)
+	if backupPath != "" {
+		fmt.Fprintf(os.Stderr, "💾 Backup created: %s\n", backupPath)
+	}
+
+	return nil
+}
+
+// addJSONOutputFlags registers the output flags shared by the json subcommands.
+func addJSONOutputFlags(command *cobra.Command) {
+	command.Flags().BoolP("in-place", "w", false, "Rewrite the input file instead of printing to stdout")
+	command.Flags().BoolP("backup", "b", false, "Create backup before rewriting (requires --in-place)")
+	command.Flags().String("backup-dir", "", "Directory for backup files (default: same as original)")
+	command.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+	command.Flags().Bool("lines", false, "Treat input as JSON Lines (auto-enabled for .jsonl and .ndjson)")
+	common.AddReadFlags(command)
+}
+
+// init registers the json fmt command and its flags.
+func init() {
+	jsonCmd.AddCommand(jsonFmtCmd)
+
+	jsonFmtCmd.Flags().String("indent", "2", "Indentation: number of spaces or 'tab'")
+	jsonFmtCmd.Flags().Bool("minify", false, "Remove all insignificant whitespace")
+	jsonFmtCmd.Flags().Bool("sort-keys", false, "Sort object keys")
+	jsonFmtCmd.Flags().Bool("canonical", false, "Emit RFC 8785 canonical JSON (for hashing/signing)")
+	addJSONOutputFlags(jsonFmtCmd)
+}
@@ -0,0 +1,23 @@
+// Package data contains the CLI commands for structured data formats.
+// This file implements the 'xml' parent command.
+package data
+
+import (
+	"github.com/kcansari/optix/cmd"
+	"github.com/spf13/cobra"
+)
+
+// xmlCmd groups the XML specific subcommands.
+var xmlCmd = &cobra.Command{
+	Use:   "xml",
+	Short: "Work with XML documents",
+	Long: `Work with XML documents such as vendor feeds, RSS and Atom.
+
+Subcommands stream the document token by token, so large files are never
+loaded into memory as a whole.`,
+}
+
+// init registers the xml command with the root command.
+func init() {
+	cmd.RootCmd.AddCommand(xmlCmd)
+}
@@ -0,0 +1,145 @@
+// Package data contains the CLI commands for structured data formats.
+// This file implements the 'xml query' command for XPath-style lookups.
+package data
+
+import (
+	"fmt"
+
+	"github.com/kcansari/optix/cmd/commands/common"
+	"github.com/kcansari/optix/internal/compression"
+	"github.com/kcansari/optix/internal/validator"
+	"github.com/kcansari/optix/internal/xmlquery"
+	"github.com/spf13/cobra"
+)
+
+// xmlQueryCmd represents the xml query command.
+// This command evaluates an XPath subset against one or more XML files.
+var xmlQueryCmd = &cobra.Command{
+	Use:   "query [expression] [filename...]",
+	Short: "Select elements, text or attributes with an XPath subset",
+	Long: `Evaluate an XPath expression against XML files and print the matches.
+
+The document is streamed with encoding/xml, so only matching elements are
+held in memory. Supported syntax:
+
+  /feed/entry            child axis from the document root
+  //entry, /feed//link   descendant axis
+  *                      any element name
+  [@id]                  attribute exists
+  [@type='sale']         attribute equals (also !=)
+  [2]                    position among matching siblings (1-based)
+  /title/text()          text content of the selected elements
+  /link/@href, /@*       attribute values of the selected elements
+
+Element names are matched on their local name; namespace prefixes are
+ignored. Every file is read as XML, whatever its extension; --type and
+--ext-map only accept xml.
+
+Examples:
+  optix xml query '//item/title/text()' feed.rss
+  optix xml query '/catalog/product[@status="active"]' products.xml
+  optix xml query '//entry[1]/link/@href' feed.atom
+  optix xml query '//product' a.xml b.xml --count
+  optix xml query '//price/text()' products.xml --with-path`,
+
+	Args: cobra.MinimumNArgs(2),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		expression, fileNames := args[0], args[1:]
+
+		// Get flag values
+		withPath, _ := cmd.Flags().GetBool("with-path")
+		countOnly, _ := cmd.Flags().GetBool("count")
+
+		query, err := xmlquery.Parse(expression)
+		if err != nil {
+			return err
+		}
+
+		readOptions, err := common.ReadOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		validatorStrategy := validator.NewValidatorStrategy(validator.NewBasicFileValidator())
+		multipleFiles := len(fileNames) > 1
+
+		for _, fileName := range fileNames {
+			if err := validatorStrategy.ValidateFile(fileName); err != nil {
+				return fmt.Errorf("file validation failed: %v", err)
+			}
+			if fileType := common.InputType(readOptions, fileName); fileType != "" && fileType != "xml" {
+				return fmt.Errorf("cannot query '%s' as %s: xml query only reads XML", fileName, fileType)
+			}
+
+			count, err := queryXMLFile(query, fileName, func(match xmlquery.Match) {
+				if countOnly {
+					return
+				}
+				printXMLMatch(match, fileName, multipleFiles, withPath)
+			})
+			if err != nil {
+				return err
+			}
+
+			if countOnly {
+				if multipleFiles {
+					fmt.Printf("%s:%d\n", fileName, count)
+				} else {
+					fmt.Println(count)
+				}
+			}
+		}
+
+		return nil
+	},
+}
+
+// queryXMLFile streams fileName through the query and returns the number of matches.
+func queryXMLFile(query *xmlquery.Query, fileName string, handle func(xmlquery.Match)) (int, error) {
+	// Compressed feeds (feed.xml.gz) are decompressed while streaming
+	file, _, err := compression.Open(fileName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open '%s': %w", fileName, err)
+	}
+	defer file.Close()
+
+	count := 0
+	err = query.Evaluate(file, func(match xmlquery.Match) error {
+		count++
+		handle(match)
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("failed to query '%s': %w", fileName, err)
+	}
+
+	return count, nil
+}
+
+// printXMLMatch prints one match, prefixed like grep output when needed.
+func printXMLMatch(match xmlquery.Match, fileName string, withFileName, withPath bool) {
+	prefix := ""
+	if withFileName {
+		prefix = fmt.Sprintf("%s:%d: ", fileName, match.Line)
+	}
+
+	if withPath {
+		path := match.Path
+		if match.Attribute != "" {
+			path += "/@" + match.Attribute
+		}
+		prefix += path + " = "
+	}
+
+	fmt.Println(prefix + match.Value)
+}
+
+// init registers the xml query command and its flags.
+func init() {
+	xmlCmd.AddCommand(xmlQueryCmd)
+
+	xmlQueryCmd.Flags().Bool("with-path", false, "Prefix each match with its element path")
+	xmlQueryCmd.Flags().BoolP("count", "c", false, "Only print the number of matches")
+	common.AddTypeFlags(xmlQueryCmd)
+}
@@ -0,0 +1,293 @@
+// Package file contains the CLI commands for the Optix file processor.
+// This file implements the 'convert-encoding' command that re-encodes files.
+package file
+
+import (
+	"bytes"         // Package for byte slice helpers
+	"fmt"           // Package for formatted I/O operations
+	"io"            // Package for reading decompressed streams
+	"os"            // Package for file information
+	"path/filepath" // Package for glob patterns
+	"strings"       // Package for string operations
+	"unicode/utf8"  // Package for UTF-8 validation
+
+	"github.com/kcansari/optix/cmd"
+	"github.com/kcansari/optix/cmd/commands/common"  // Shared read flags
+	"github.com/kcansari/optix/internal/backup"      // Backups before modification
+	"github.com/kcansari/optix/internal/charset"     // Character encodings
+	"github.com/kcansari/optix/internal/compression" // Compressed input and output
+	"github.com/kcansari/optix/internal/detect"      // Binary file detection
+	"github.com/kcansari/optix/internal/reader"      // Our file reader package
+	"github.com/kcansari/optix/internal/validator"   // Our file validator package
+	"github.com/kcansari/optix/internal/writer"      // Encoded output
+	"github.com/spf13/cobra"                         // CLI framework
+)
+
+// convertEncodingCmd represents the convert-encoding command.
+// This command rewrites files in another character encoding, e.g. to
+// normalise a folder of UTF-16 and Latin-1 exports to UTF-8.
+var convertEncodingCmd = &cobra.Command{
+	Use:   "convert-encoding [files...]",
+	Short: "Convert files to another character encoding",
+	Long: `Convert files to another character encoding.
+
+The source encoding is detected from the byte order mark and the content
+(UTF-8, UTF-16LE/BE, UTF-32LE/BE, ISO-8859-1, Windows-1252); use --from
+when detection guesses wrong. Files are converted in place unless --output
+is given. Compressed files stay compressed, and binary files are skipped
+unless --type or --ext-map gives their type.
+
+A byte order mark is written for UTF-16 and UTF-32 targets by default; use
+--bom=true or --bom=false to decide explicitly.
+
+Examples:
+  optix convert-encoding --to utf-8 export.csv
+  optix convert-encoding --to utf-8 "data/*.csv" --backup
+  optix convert-encoding --from latin1 --to utf-8 legacy.txt -o legacy-utf8.txt
+  optix convert-encoding --to utf-16le --bom report.txt --dry-run`,
+
+	// Args requires at least one file or pattern
+	Args: cobra.MinimumNArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get flag values
+		to, _ := cmd.Flags().GetString("to")
+		from, _ := cmd.Flags().GetString("from")
+		outputFile, _ := cmd.Flags().GetString("output")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		createBackup, _ := cmd.Flags().GetBool("backup")
+		backupDir, _ := cmd.Flags().GetString("backup-dir")
+
+		// Parse the encodings
+		target, err := charset.Parse(to)
+		if err != nil {
+			return err
+		}
+		var source charset.Encoding
+		if from != "" {
+			if source, err = charset.Parse(from); err != nil {
+				return err
+			}
+		}
+
+		// Wide encodings are hard to detect without a byte order mark
+		bom := target != charset.UTF8 && charset.BOM(target) != nil
+		if common.FlagGiven(cmd, "bom") {
+			bom, _ = cmd.Flags().GetBool("bom")
+		}
+
+		// Expand the file patterns
+		var files []string
+		for _, pattern := range args {
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid file pattern '%s': %w", pattern, err)
+			}
+			for _, match := range matches {
+				if info, err := os.Stat(match); err == nil && info.IsDir() {
+					continue
+				}
+				files = append(files, match)
+			}
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no files found matching %v", args)
+		}
+		if outputFile != "" && len(files) > 1 {
+			return fmt.Errorf("--output can only be used with a single file (got %d files)", len(files))
+		}
+
+		// Files with a type given by --type or --ext-map are never skipped as binary
+		readOptions, err := common.ReadOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		if _, err := common.NewReaderStrategyWithOptions(readOptions); err != nil {
+			return err
+		}
+
+		// Display operation info
+		fmt.Printf("🔠 Encoding Conversion\n")
+		fmt.Printf("🎯 Target: %s", target)
+		if bom {
+			fmt.Printf(" (with BOM)")
+		}
+		fmt.Println()
+		if source != "" {
+			fmt.Printf("📥 Source: %s (forced)\n", source)
+		}
+		fmt.Printf("📁 Files: %d\n", len(files))
+		if dryRun {
+			fmt.Printf("🧪 Dry Run: Enabled (no changes will be made)\n")
+		}
+		fmt.Println("─────────────────────────────────────────────────────")
+
+		validatorStrategy := validator.NewValidatorStrategy(validator.NewBasicFileValidator())
+		var converted, unchanged, skipped, failed int
+
+		for _, file := range files {
+			if err := validatorStrategy.ValidateFile(file); err != nil {
+				fmt.Printf("❌ Skipping '%s': %v\n", file, err)
+				failed++
+				continue
+			}
+
+			output := file
+			if outputFile != "" {
+				output = outputFile
+			}
+
+			asText := common.InputType(readOptions, file) != ""
+			result, err := convertFileEncoding(file, output, source, target, bom, asText, dryRun, createBackup, backupDir)
+			if err != nil {
+				fmt.Printf("❌ %s: %v\n", file, err)
+				failed++
+				continue
+			}
+
+			if result.skipped != "" {
+				fmt.Printf("⏭️  %s: skipped (%s)\n", file, result.skipped)
+				skipped++
+				continue
+			}
+			if result.unchanged {
+				fmt.Printf("✔️  %s: already %s\n", file, result.to)
+				unchanged++
+				continue
+			}
+
+			fmt.Printf("🔄 %s: %s → %s\n", file, result.from, result.to)
+			if result.backupPath != "" {
+				fmt.Printf("   💾 Backup created: %s\n", result.backupPath)
+			}
+			if output != file && !dryRun {
+				fmt.Printf("   📄 Written to: %s\n", output)
+			}
+			converted++
+		}
+
+		// Display summary
+		fmt.Println("─────────────────────────────────────────────────────")
+		fmt.Printf("📊 Conversion Summary:\n")
+		fmt.Printf("   🔄 Converted: %d\n", converted)
+		fmt.Printf("   ✔️  Already %s: %d\n", target, unchanged)
+		if skipped > 0 {
+			fmt.Printf("   ⏭️  Skipped: %d\n", skipped)
+		}
+		if failed > 0 {
+			fmt.Printf("   ❌ Failed: %d\n", failed)
+		}
+		if dryRun && converted > 0 {
+			fmt.Printf("   🧪 Dry run completed - no changes were made\n")
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d files could not be converted", failed, len(files))
+		}
+		return nil
+	},
+}
+
+// conversionResult describes the conversion of a single file.
+type conversionResult struct {
+	from       string
+	to         string
+	unchanged  bool
+	skipped    string
+	backupPath string
+}
+
+// convertFileEncoding decodes file and writes it to output in the target
+// encoding, keeping the file's compression when it is rewritten in place.
+// Binary files are skipped unless asText is set.
+func convertFileEncoding(file, output string, source, target charset.Encoding, bom, asText, dryRun, createBackup bool, backupDir string) (*conversionResult, error) {
+	input, format, err := compression.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(input)
+	input.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read: %w", err)
+	}
+
+	// Detect the source encoding unless it was given
+	sample := data[:min(len(data), detect.SampleSize)]
+	encoding, hasBOM := charset.Detect(sample)
+	if source != "" {
+		encoding = source
+		hasBOM = charset.BOM(source) != nil && bytes.HasPrefix(data, charset.BOM(source))
+	}
+
+	result := &conversionResult{from: describeEncoding(&reader.FileContent{Encoding: string(encoding), BOM: hasBOM})}
+	result.to = describeEncoding(&reader.FileContent{Encoding: string(target), BOM: bom})
+
+	if decoded, err := charset.Decode(sample, encoding); err == nil && !asText && detect.Detect(file, []byte(decoded)).Type == detect.Binary {
+		result.skipped = "binary file"
+		return result, nil
+	}
+
+	if encoding == target && hasBOM == bom && output == file {
+		result.unchanged = true
+		return result, nil
+	}
+
+	text, err := charset.Decode(data, encoding)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.ValidString(text) {
+		return nil, fmt.Errorf("content is not valid %s; use --from to set the source encoding", encoding)
+	}
+
+	// Encode first so that unrepresentable characters fail before anything is written
+	options := writer.OptionsFor(&reader.FileContent{Compression: string(format)}, file, output)
+	options.Encoding = target
+	options.BOM = bom
+	if _, err := writer.Encode(text, options); err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if createBackup {
+		backupPath, err := backup.Create(file, backupDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create backup: %w", err)
+		}
+		result.backupPath = backupPath
+	}
+
+	if err := writer.WriteFile(output, text, options); err != nil {
+		return nil, fmt.Errorf("failed to write '%s': %w", output, err)
+	}
+	return result, nil
+}
+
+// describeEncoding formats the encoding of content for display.
+func describeEncoding(content *reader.FileContent) string {
+	encoding := content.Encoding
+	if encoding == "" {
+		encoding = string(charset.UTF8)
+	}
+	if content.BOM {
+		return encoding + " with BOM"
+	}
+	return encoding
+}
+
+// init registers the convert-encoding command and its flags.
+func init() {
+	cmd.RootCmd.AddCommand(convertEncodingCmd)
+
+	convertEncodingCmd.Flags().String("to", "utf-8", "Target encoding: "+strings.Join(charset.Names(), ", "))
+	convertEncodingCmd.Flags().String("from", "", "Source encoding (default: detect)")
+	convertEncodingCmd.Flags().Bool("bom", false, "Write a byte order mark (default: only for UTF-16 and UTF-32)")
+	convertEncodingCmd.Flags().StringP("output", "o", "", "Output file (single input only, default: convert in place)")
+	convertEncodingCmd.Flags().Bool("dry-run", false, "Show what would be converted without modifying files")
+	convertEncodingCmd.Flags().BoolP("backup", "b", false, "Create backup before modification")
+	convertEncodingCmd.Flags().String("backup-dir", "", "Directory for backup files (default: same as original)")
+	common.AddTypeFlags(convertEncodingCmd)
+}
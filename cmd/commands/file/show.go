@@ -4,11 +4,15 @@ package file
 
 import (
 	"fmt" // Package for formatted I/O operations
-
-	"github.com/kcansari/optix/cmd"                        // Our file reader package
-	"github.com/kcansari/optix/internal/reader/strategies" // Reader strategies
-	"github.com/kcansari/optix/internal/validator"         // Our file validator package
-	"github.com/spf13/cobra"                               // CLI framework
+	"os"  // Package for access to stderr
+
+	"github.com/kcansari/optix/cmd"                 // Our file reader package
+	"github.com/kcansari/optix/cmd/commands/common" // Shared read flags
+	"github.com/kcansari/optix/internal/charset"    // Character encodings
+	"github.com/kcansari/optix/internal/discovery"  // Standard input
+	"github.com/kcansari/optix/internal/lineending" // Line-ending styles
+	"github.com/kcansari/optix/internal/validator"  // Our file validator package
+	"github.com/spf13/cobra"                        // CLI framework
 )
 
 // showCmd represents the show command.
@@ -23,60 +27,117 @@ Supported file types:
   - .txt  (Text files)
   - .csv  (Comma-separated values)
   - .json (JSON files)
+  - .jsonl, .ndjson (JSON Lines, one record per line)
+  - .yaml, .yml (YAML files)
+  - .toml (TOML files)
+  - .xml, .rss, .atom (XML documents)
+
+Without a filename, or with "-", the content is read from standard input.
+When the output is not a terminal only the content is printed.
+
+Binary files, such as images or files with NUL bytes, are not printed;
+use --binary text to print them anyway, --binary skip to ignore them or
+--binary error to fail.
 
 Examples:
   optix show myfile.txt     # Display a text file
   optix show data.csv       # Display a CSV file
-  optix show config.json    # Display a JSON file`,
+  optix show config.json    # Display a JSON file
+  cat data | optix show --type json   # Display JSON read from standard input`,
 
 	// Args validates the number of command line arguments
-	// cobra.ExactArgs(1) means this command requires exactly 1 argument
-	Args: cobra.ExactArgs(1),
+	// cobra.MaximumNArgs(1) means the filename is optional (stdin is read instead)
+	Args: cobra.MaximumNArgs(1),
 
 	// RunE is the function that executes when the command is called
 	// The 'E' suffix means it can return an error
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// args[0] contains the filename passed to the command
-		filename := args[0]
+		// args[0] contains the filename passed to the command, if any
+		filename := discovery.StdinName
+		if len(args) > 0 {
+			filename = args[0]
+		}
 
 		// Step 1: Validate the file exists and is readable
 		// Create a file validator using our strategy pattern
 		fileValidator := validator.NewBasicFileValidator()
 		validatorStrategy := validator.NewValidatorStrategy(fileValidator)
 
-		// Validate the file before trying to read it
-		if err := validatorStrategy.ValidateFile(filename); err != nil {
-			// If validation fails, return a user-friendly error message
-			return fmt.Errorf("file validation failed: %v", err)
+		// Validate the file before trying to read it; standard input only
+		// has to be piped
+		if err := common.ValidateInput(validatorStrategy, filename); err != nil {
+			return err
 		}
 
 		// Step 2: Read the file using our improved reader strategy
 		// Create a reader strategy that can handle multiple file types
-		readerStrategy := strategies.NewDefaultFileReaderStrategy()
+		readerStrategy, err := common.NewReaderStrategy(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Binary files are only printed with --binary text
+		binaryPolicy, err := common.BinaryPolicyFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		entry, err := common.InputEntry(filename)
+		if err != nil {
+			return err
+		}
 
 		// Read the file - the strategy will automatically choose the right reader
-		content, err := readerStrategy.ReadFile(filename)
+		content, binary, err := common.ReadEntry(entry, readerStrategy, validatorStrategy, binaryPolicy)
 		if err != nil {
 			// If reading fails, return an error with context
-			return fmt.Errorf("failed to read file: %v", err)
+			return fmt.Errorf("failed to read file: %w", err)
 		}
 
 		// Step 3: Display the file information and contents
+		// The header and footer are only shown on a terminal
+		console := common.Console()
+		if common.IsStdin(filename) {
+			filename = discovery.StdinDisplayName
+		}
+		if content == nil {
+			fmt.Fprintf(common.Warnings(), "⏭️  Skipping binary file '%s'\n", filename)
+			return nil
+		}
+
 		// Print a header with file information
-		fmt.Printf("📄 File: %s\n", filename)
-		fmt.Printf("📊 Type: %s\n", content.FileType)
-		fmt.Printf("📏 Size: %d bytes\n", content.Size)
-		fmt.Printf("📝 Lines: %d\n", content.LineCount)
-		fmt.Printf("🔤 Words: %d\n", content.WordCount)
-		fmt.Println("📖 Content:")
-		fmt.Println("─────────────────────────────────────────────────────")
+		fmt.Fprintf(console, "📄 File: %s\n", filename)
+		fmt.Fprintf(console, "📊 Type: %s\n", content.FileType)
+		fmt.Fprintf(console, "📏 Size: %d bytes\n", content.Size)
+		if content.Compression != "" {
+			fmt.Fprintf(console, "🗜️  Compression: %s\n", content.Compression)
+		}
+		if binary && binaryPolicy != validator.BinaryText {
+			fmt.Fprintf(common.Warnings(), "🚫 Binary file '%s' not shown (use --binary text to show it anyway)\n", filename)
+			return nil
+		}
+		if content.Encoding != "" && (content.Encoding != string(charset.UTF8) || content.BOM) {
+			fmt.Fprintf(console, "🔠 Encoding: %s\n", describeEncoding(content))
+		}
+		if content.LineEnding != "" && content.LineEnding != string(lineending.LF) || content.NoFinalNewline {
+			fmt.Fprintf(console, "↩️  Line Endings: %s\n", lineending.Describe(lineending.Style(content.LineEnding), !content.NoFinalNewline))
+		}
+		fmt.Fprintf(console, "📝 Lines: %d\n", content.LineCount)
+		fmt.Fprintf(console, "🔤 Words: %d\n", content.WordCount)
+		if content.Records != nil {
+			fmt.Fprintf(console, "🧾 Records: %d\n", len(content.Records))
+		}
+		fmt.Fprintln(console, "📖 Content:")
+		fmt.Fprintln(console, "─────────────────────────────────────────────────────")
 
 		// Print the actual file content
 		fmt.Print(content.Content)
 
 		// Add a separator line at the end for better readability
-		fmt.Println("─────────────────────────────────────────────────────")
-		fmt.Printf("✅ Successfully displayed %s (%s file)\n", filename, content.FileType)
+		fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+		fmt.Fprintf(console, "✅ Successfully displayed %s (%s file)\n", filename, content.FileType)
+
+		// Report records the reader had to skip
+		common.ReportInvalidLines(os.Stderr, filename, content)
 
 		// Return nil to indicate success
 		return nil
@@ -89,4 +150,8 @@ func init() {
 	// Add the show command to the root command
 	// This makes it available as 'optix show'
 	cmd.RootCmd.AddCommand(showCmd)
+
+	// Add flags controlling how the file is read
+	common.AddReadFlags(showCmd)
+	common.AddBinaryFlag(showCmd, false)
 }
@@ -7,10 +7,14 @@ import (
 	"strings" // Package for string operations
 
 	"github.com/kcansari/optix/cmd"
-	"github.com/kcansari/optix/internal/reader"            // Our file reader package
-	"github.com/kcansari/optix/internal/reader/strategies" // Reader strategies
-	"github.com/kcansari/optix/internal/validator"         // Our file validator package
-	"github.com/spf13/cobra"                               // CLI framework
+	"github.com/kcansari/optix/cmd/commands/common" // Shared read flags
+	"github.com/kcansari/optix/internal/discovery"  // Standard input
+	"github.com/kcansari/optix/internal/jsonutil"   // JSON structure analysis
+	"github.com/kcansari/optix/internal/lineending" // Line-ending display
+	"github.com/kcansari/optix/internal/reader"     // Our file reader package
+	"github.com/kcansari/optix/internal/validator"  // Our file validator package
+	"github.com/kcansari/optix/internal/xmlquery"   // XML structure analysis
+	"github.com/spf13/cobra"                        // CLI framework
 )
 
 // statsCmd represents the stats command.
@@ -33,36 +37,88 @@ General Statistics:
 
 File Type Specific:
   - CSV: Number of records and fields
-  - JSON: Validation status and structure info
+  - JSON, YAML, TOML: Depth, value counts by type, key paths and largest array
+  - JSON Lines: Record count, invalid lines and key frequency across records
   - TXT: Line length analysis
 
-Supported file types: .txt, .csv, .json
+Supported file types: .txt, .csv, .json, .jsonl, .yaml, .toml, .xml
+
+Without a filename, or with "-", standard input is analysed.
+
+Only the size of binary files, such as images or files with NUL bytes, is
+reported; use --binary text to analyse them as text, --binary skip to
+ignore them or --binary error to fail.
 
 Examples:
   optix stats document.txt   # Show statistics for a text file
   optix stats data.csv       # Show statistics for a CSV file
-  optix stats config.json    # Show statistics for a JSON file`,
+  optix stats config.json    # Show statistics for a JSON file
+  kubectl logs my-pod | optix stats   # Show statistics for piped input`,
 
-	// Require exactly one argument (the filename)
-	Args: cobra.ExactArgs(1),
+	// The filename is optional; standard input is read without one
+	Args: cobra.MaximumNArgs(1),
 
 	// RunE executes the command and can return an error
 	RunE: func(cmd *cobra.Command, args []string) error {
-		filename := args[0]
+		filename := discovery.StdinName
+		if len(args) > 0 {
+			filename = args[0]
+		}
 
-		// Step 1: Validate the file
+		// Step 1: Validate the file; standard input only has to be piped
 		fileValidator := validator.NewBasicFileValidator()
 		validatorStrategy := validator.NewValidatorStrategy(fileValidator)
 
-		if err := validatorStrategy.ValidateFile(filename); err != nil {
-			return fmt.Errorf("file validation failed: %v", err)
+		if err := common.ValidateInput(validatorStrategy, filename); err != nil {
+			return err
 		}
 
 		// Step 2: Read the file to get content for analysis
-		readerStrategy := strategies.NewDefaultFileReaderStrategy()
-		content, err := readerStrategy.ReadFile(filename)
+		readOptions, err := common.ReadOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		// Statistics report invalid records rather than failing on the first one,
+		// unless the user asked for an explicit error limit
+		if !common.FlagGiven(cmd, "max-errors") {
+			readOptions.SkipInvalid = true
+		}
+
+		readerStrategy, err := common.NewReaderStrategyWithOptions(readOptions)
+		if err != nil {
+			return err
+		}
+		binaryPolicy, err := common.BinaryPolicyFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		entry, err := common.InputEntry(filename)
+		if err != nil {
+			return err
+		}
+		content, binary, err := common.ReadEntry(entry, readerStrategy, validatorStrategy, binaryPolicy)
 		if err != nil {
-			return fmt.Errorf("failed to read file for statistics: %v", err)
+			return fmt.Errorf("failed to read file for statistics: %w", err)
+		}
+		if common.IsStdin(filename) {
+			filename = discovery.StdinDisplayName
+		}
+		if content == nil {
+			fmt.Fprintf(common.Warnings(), "⏭️  Skipping binary file '%s'\n", filename)
+			return nil
+		}
+
+		// Text statistics of binary content are only computed with --binary text
+		if binary {
+			if binaryPolicy != validator.BinaryText {
+				fmt.Printf("📊 File Statistics for: %s\n", filename)
+				fmt.Println("═════════════════════════════════════════════════════")
+				fmt.Printf("📄 File Type:           %s\n", strings.ToUpper(content.FileType))
+				fmt.Printf("📏 File Size:           %d bytes\n", content.Size)
+				fmt.Printf("🚫 Binary file: text statistics not computed (use --binary text to compute them anyway)\n")
+				return nil
+			}
+			content.WordCount = len(strings.Fields(content.Content))
 		}
 
 		// Step 3: Calculate additional statistics
@@ -75,6 +131,12 @@ Examples:
 	},
 }
 
+// maxKeyFrequencyRows limits the key frequency table for JSON Lines files.
+const maxKeyFrequencyRows = 20
+
+// maxInvalidLineRows limits how many invalid JSON Lines records are listed.
+const maxInvalidLineRows = 10
+
 // DetailedStats holds additional calculated statistics.
 // This struct extends the basic FileContent with more detailed analysis.
 type DetailedStats struct {
@@ -154,6 +216,15 @@ func displayStats(filename string, content *reader.FileContent, stats *DetailedS
 	// Basic file information
 	fmt.Printf("📄 File Type:           %s\n", strings.ToUpper(content.FileType))
 	fmt.Printf("📏 File Size:           %d bytes\n", content.Size)
+	if content.Compression != "" {
+		fmt.Printf("🗜️  Compression:         %s (%d bytes uncompressed)\n", content.Compression, len(content.Content))
+	}
+	if content.Encoding != "" {
+		fmt.Printf("🔠 Encoding:            %s\n", describeEncoding(content))
+	}
+	if content.LineEnding != "" || content.NoFinalNewline {
+		fmt.Printf("↩️  Line Endings:        %s\n", lineending.Describe(lineending.Style(content.LineEnding), !content.NoFinalNewline))
+	}
 
 	// Line statistics
 	fmt.Println("\n📝 Line Statistics:")
@@ -203,8 +274,12 @@ func displayFileTypeSpecificStats(content *reader.FileContent) {
 	switch content.FileType {
 	case "csv":
 		displayCSVStats(content)
-	case "json":
-		displayJSONStats(content)
+	case "json", "yaml", "toml":
+		displayStructuredStats(content)
+	case "jsonl":
+		displayJSONLinesStats(content)
+	case "xml":
+		displayXMLStats(content)
 	case "txt":
 		displayTextStats(content)
 	default:
@@ -229,21 +304,82 @@ func displayCSVStats(content *reader.FileContent) {
 	fmt.Printf("   Estimated Cells:     %d\n", content.LineCount*estimatedFields)
 }
 
-// displayJSONStats shows JSON-specific statistics.
-func displayJSONStats(content *reader.FileContent) {
-	// Count braces and brackets for structure analysis
-	openBraces := strings.Count(content.Content, "{")
-	closeBraces := strings.Count(content.Content, "}")
-	openBrackets := strings.Count(content.Content, "[")
-	closeBrackets := strings.Count(content.Content, "]")
-
-	fmt.Printf("   Objects ({}):        %d pairs\n", openBraces)
-	fmt.Printf("   Arrays ([]):         %d pairs\n", openBrackets)
-	fmt.Printf("   Bracket Balance:     %s\n", getBracketBalanceStatus(openBraces, closeBraces, openBrackets, closeBrackets))
-
-	// Count commas as a rough estimate of JSON elements
-	commas := strings.Count(content.Content, ",")
-	fmt.Printf("   Estimated Elements:  %d (based on commas)\n", commas+1)
+// displayStructuredStats shows statistics computed from the decoded document
+// tree of JSON, YAML and TOML files. The readers reject files with syntax
+// errors, so any content that reaches this point is valid.
+func displayStructuredStats(content *reader.FileContent) {
+	fmt.Printf("   Valid %-14s ✅ Yes\n", strings.ToUpper(content.FileType)+":")
+	displayJSONStructure(jsonutil.Analyze(content.Data))
+}
+
+// displayJSONLinesStats shows per-record statistics for JSON Lines files.
+func displayJSONLinesStats(content *reader.FileContent) {
+	lineStats := jsonutil.NewLineStats()
+	for _, record := range content.Records {
+		lineStats.AddRecord(record.Data)
+	}
+
+	fmt.Printf("   Records:             %d\n", lineStats.Records)
+	fmt.Printf("   Invalid Lines:       %d\n", len(content.InvalidLines))
+	for i, invalid := range content.InvalidLines {
+		if i == maxInvalidLineRows {
+			fmt.Printf("   ... and %d more invalid lines\n", len(content.InvalidLines)-maxInvalidLineRows)
+			break
+		}
+		fmt.Printf("     line %-6d %s\n", invalid.LineNumber, invalid.Message)
+	}
+
+	displayJSONStructure(lineStats.Structure)
+
+	if lineStats.Records == 0 {
+		return
+	}
+
+	// Show how many records contain each key path
+	keyCounts := jsonutil.SortedKeyCounts(lineStats.KeyFrequency)
+	limit := min(len(keyCounts), maxKeyFrequencyRows)
+
+	fmt.Println("\n🔑 Key Frequency (records containing key):")
+	for _, keyCount := range keyCounts[:limit] {
+		percentage := float64(keyCount.Count) / float64(lineStats.Records) * 100
+		fmt.Printf("   %-30s %6d (%.1f%%)\n", keyCount.Path, keyCount.Count, percentage)
+	}
+	if len(keyCounts) > limit {
+		fmt.Printf("   ... and %d more key paths\n", len(keyCounts)-limit)
+	}
+}
+
+// displayJSONStructure prints the structural statistics of decoded JSON.
+func displayJSONStructure(stats *jsonutil.Stats) {
+	fmt.Printf("   Max Depth:           %d\n", stats.MaxDepth)
+	fmt.Printf("   Objects:             %d\n", stats.Objects)
+	fmt.Printf("   Arrays:              %d\n", stats.Arrays)
+	fmt.Printf("   Strings:             %d\n", stats.Strings)
+	fmt.Printf("   Numbers:             %d\n", stats.Numbers)
+	fmt.Printf("   Booleans:            %d\n", stats.Booleans)
+	fmt.Printf("   Nulls:               %d\n", stats.Nulls)
+	fmt.Printf("   Distinct Key Paths:  %d\n", stats.DistinctKeyPaths())
+	fmt.Printf("   Largest Array:       %d elements\n", stats.LargestArray)
+}
+
+// displayXMLStats shows element and attribute counts for XML files.
+// The reader already rejects malformed documents, so analysis only fails
+// if the content changed in between.
+func displayXMLStats(content *reader.FileContent) {
+	stats, err := xmlquery.Analyze(strings.NewReader(content.Content))
+	if err != nil {
+		fmt.Printf("   Well-formed:         ❌ No (%v)\n", err)
+		return
+	}
+
+	fmt.Println("   Well-formed:         ✅ Yes")
+	fmt.Printf("   Root Element:        <%s>\n", stats.RootElement)
+	fmt.Printf("   Elements:            %d\n", stats.Elements)
+	fmt.Printf("   Attributes:          %d\n", stats.Attributes)
+	fmt.Printf("   Text Nodes:          %d\n", stats.TextNodes)
+	fmt.Printf("   Comments:            %d\n", stats.Comments)
+	fmt.Printf("   Max Depth:           %d\n", stats.MaxDepth)
+	fmt.Printf("   Distinct Elements:   %d\n", len(stats.DistinctElements))
 }
 
 // displayTextStats shows text-specific statistics.
@@ -265,16 +401,11 @@ func displayTextStats(content *reader.FileContent) {
 	}
 }
 
-// getBracketBalanceStatus checks if JSON brackets are properly balanced.
-// This is a helper function that demonstrates Go's approach to utility functions.
-func getBracketBalanceStatus(openB, closeB, openBr, closeBr int) string {
-	if openB == closeB && openBr == closeBr {
-		return "✅ Balanced"
-	}
-	return "❌ Unbalanced"
-}
-
 // init registers the stats command with the root command.
 func init() {
 	cmd.RootCmd.AddCommand(statsCmd)
+
+	// Add flags controlling how the file is read
+	common.AddReadFlags(statsCmd)
+	common.AddBinaryFlag(statsCmd, false)
 }
@@ -0,0 +1,100 @@
+// Package logs contains the CLI commands for application log files.
+// This file implements the 'logs' parent command and shared helpers.
+package logs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kcansari/optix/cmd"
+	"github.com/kcansari/optix/cmd/commands/common"
+	"github.com/kcansari/optix/internal/discovery"
+	"github.com/kcansari/optix/internal/logparser"
+	"github.com/kcansari/optix/internal/reader"
+	"github.com/kcansari/optix/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+// logsCmd groups the log specific subcommands.
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Work with application log files",
+	Long: `Work with application log files.
+
+Subcommands understand the structure of log lines, such as their severity
+level, rather than matching plain text. Levels are read from the common
+layouts: "LEVEL:" prefixes, "[LEVEL]", logfmt "level=" and JSON "level"
+fields. Timestamps are read in RFC 3339, Go log, Apache, syslog and epoch
+formats, or in a custom Go layout given with --time-format. Lines without
+a level or timestamp, such as stack traces, belong to the entry before
+them.`,
+}
+
+// readLog reads a log file, or standard input for "" and "-", as text
+// whatever its extension, so that JSON and logfmt logs keep their lines,
+// unless --type or --ext-map chooses another reader. It returns the
+// content and the name to display.
+func readLog(command *cobra.Command, fileName string) (*reader.FileContent, string, error) {
+	validatorStrategy := validator.NewValidatorStrategy(validator.NewBasicFileValidator())
+	if err := common.ValidateInput(validatorStrategy, fileName); err != nil {
+		return nil, "", err
+	}
+
+	readerStrategy, err := common.NewTextReaderStrategy(command)
+	if err != nil {
+		return nil, "", err
+	}
+	content, err := common.ReadFile(readerStrategy, fileName)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read log: %w", err)
+	}
+
+	displayName := fileName
+	if common.IsStdin(fileName) {
+		displayName = discovery.StdinDisplayName
+	}
+	return content, displayName, nil
+}
+
+// addTimeFlags registers the flags that control how timestamps are read.
+func addTimeFlags(command *cobra.Command) {
+	command.Flags().String("time-format", "", "Go time layout of the timestamps, e.g. \"02.01.2006 15:04:05\" (default: detect common formats)")
+	command.Flags().Bool("utc", false, "Read times without a time zone as UTC instead of local time")
+}
+
+// timeLocation returns the time zone for timestamps and times given
+// without one: UTC with --utc, local time otherwise.
+func timeLocation(command *cobra.Command) *time.Location {
+	if utc, _ := command.Flags().GetBool("utc"); utc {
+		return time.UTC
+	}
+	return time.Local
+}
+
+// newRecordParser creates the record parser configured by the time flags.
+func newRecordParser(command *cobra.Command) (*logparser.RecordParser, error) {
+	layout, _ := command.Flags().GetString("time-format")
+	location := timeLocation(command)
+
+	times := logparser.NewTimestampParser(location)
+	if layout != "" {
+		var err error
+		if times, err = logparser.NewLayoutTimestampParser(layout, location); err != nil {
+			return nil, fmt.Errorf("invalid --time-format: %w", err)
+		}
+	}
+	parser := logparser.NewRecordParser(logparser.NewDefaultLevelParserStrategy(), times)
+	if recordStart, _ := command.Flags().GetString("record-start"); recordStart != "" {
+		grouping, err := logparser.ParseRecordGrouping(recordStart)
+		if err != nil {
+			return nil, err
+		}
+		parser.SetGrouping(grouping)
+	}
+	return parser, nil
+}
+
+// init registers the logs command with the root command.
+func init() {
+	cmd.RootCmd.AddCommand(logsCmd)
+}
@@ -0,0 +1,229 @@
+// Package logs contains the CLI commands for application log files.
+// This file implements the 'logs patterns' command that summarizes logs by message template.
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kcansari/optix/cmd/commands/common"
+	"github.com/kcansari/optix/internal/formatter"
+	"github.com/kcansari/optix/internal/jsonutil"
+	"github.com/kcansari/optix/internal/logparser"
+	"github.com/kcansari/optix/internal/writer"
+	"github.com/spf13/cobra"
+)
+
+// tablePatternFields are the fields shown in a table; the example line is
+// left out to keep the rows readable.
+var tablePatternFields = []string{"count", "percent", "first_line", "last_line", "template"}
+
+// patternFields are the fields written in the other formats.
+var patternFields = []string{"count", "percent", "first_line", "last_line", "template", "example"}
+
+// logsPatternsCmd represents the logs patterns command.
+// This command groups log lines into message templates.
+var logsPatternsCmd = &cobra.Command{
+	Use:   "patterns",
+	Short: "Summarize a log by its message templates",
+	Long: `Group the lines of a log into the message templates they were written
+from, to see what a large log is made of.
+
+Variable parts of every line are masked first: quoted strings (<STR>),
+UUIDs (<UUID>), timestamps (<TIME>), IP addresses (<IP>), hex values
+(<HEX>) and numbers (<NUM>). Lines with the same number of words are then
+grouped with the Drain algorithm: a line joins the most similar template
+when at least --similarity of its words are equal, and the words in which
+they differ become <*>.
+
+For every template the number of lines, their share of the log, the first
+and last line number and an example line are reported, the most frequent
+template first. Use --format json or jsonl for dashboards.
+
+With --record-start, multi-line records such as stack traces are grouped
+by their first line and counted once.
+
+Examples:
+  optix logs patterns --input app.log
+  optix logs patterns --top 50 --similarity 0.6 --input app.log
+  optix logs patterns --format json --input app.log --output patterns.json
+  optix logs patterns --record-start java --input app.log
+  kubectl logs my-pod | optix logs patterns --top 10`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get flag values
+		top, _ := cmd.Flags().GetInt("top")
+		similarity, _ := cmd.Flags().GetFloat64("similarity")
+		outputFormat, _ := cmd.Flags().GetString("format")
+		inputFile, _ := cmd.Flags().GetString("input")
+		outputFile, _ := cmd.Flags().GetString("output")
+
+		// Validate the options
+		if top < 0 {
+			return fmt.Errorf("--top cannot be negative")
+		}
+		if similarity <= 0 || similarity > 1 {
+			return fmt.Errorf("--similarity must be greater than 0 and at most 1")
+		}
+		recordStart, err := common.RecordStartFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		var grouping *logparser.RecordGrouping
+		if recordStart != "" {
+			if grouping, err = logparser.ParseRecordGrouping(recordStart); err != nil {
+				return err
+			}
+		}
+		if inputFile == "" && !common.StdinPiped() {
+			return fmt.Errorf("input file is required (use --input flag or pipe input to standard input)")
+		}
+		if common.IsStdout(outputFile) {
+			outputFile = ""
+		}
+
+		formatters := formatter.NewDefaultFormatterStrategy()
+		if outputFormat == "" {
+			outputFormat = formatters.FormatForFile(outputFile)
+		}
+		if outputFormat == "" {
+			outputFormat = "table"
+		}
+		rowFormatter := formatters.GetFormatter(outputFormat)
+		if rowFormatter == nil {
+			return fmt.Errorf("unsupported output format '%s'. Supported formats: %s",
+				outputFormat, strings.Join(formatters.GetFormatNames(), ", "))
+		}
+
+		content, displayName, err := readLog(cmd, inputFile)
+		if err != nil {
+			return err
+		}
+
+		// Display operation info; banners are only shown on a terminal
+		console := common.Console()
+		fmt.Fprintf(console, "🧩 Log Patterns Operation\n")
+		fmt.Fprintf(console, "📄 Input: %s\n", displayName)
+		fmt.Fprintf(console, "🎚️  Similarity: %g\n", similarity)
+		if top > 0 {
+			fmt.Fprintf(console, "🔝 Top: %d\n", top)
+		}
+		if recordStart != "" {
+			fmt.Fprintf(console, "🧱 Records: %s\n", recordStart)
+		}
+		fmt.Fprintf(console, "🖨️  Output Format: %s\n", rowFormatter.GetFormat())
+		if outputFile != "" {
+			fmt.Fprintf(console, "📤 Output: %s\n", outputFile)
+		} else {
+			fmt.Fprintf(console, "📤 Output: Console\n")
+		}
+		fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+
+		// Group the lines, or the first lines of the records
+		startTime := time.Now()
+		starts := make([]int, len(content.Lines))
+		for i := range starts {
+			starts[i] = i
+		}
+		if grouping != nil {
+			starts = grouping.Group(content.Lines)
+		}
+
+		miner := logparser.NewPatternMiner(similarity)
+		entries := 0
+		for _, start := range starts {
+			if miner.Add(start+1, content.Lines[start]) != nil {
+				entries++
+			}
+		}
+
+		patterns := miner.Patterns()
+		total := len(patterns)
+		if top > 0 && len(patterns) > top {
+			patterns = patterns[:top]
+		}
+
+		rows := make([]*jsonutil.Object, len(patterns))
+		for i, pattern := range patterns {
+			rows[i] = patternRow(pattern, entries)
+		}
+		fields := patternFields
+		if rowFormatter.GetFormat() == "table" {
+			fields = tablePatternFields
+		}
+
+		var output strings.Builder
+		if len(rows) > 0 {
+			if err := rowFormatter.Format(&output, fields, rows); err != nil {
+				return fmt.Errorf("failed to format patterns: %w", err)
+			}
+		}
+		executionTime := time.Since(startTime)
+
+		// Write or display the patterns
+		if outputFile != "" {
+			if err := writer.WriteFile(outputFile, output.String(), writer.OptionsFor(nil, inputFile, outputFile)); err != nil {
+				return fmt.Errorf("failed to write output file: %w", err)
+			}
+		} else if output.Len() > 0 {
+			fmt.Fprintf(console, "📋 Patterns:\n")
+			fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+			fmt.Print(output.String())
+			fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+		}
+
+		// Display results summary
+		unit := "lines"
+		if grouping != nil {
+			unit = "records"
+		}
+		fmt.Fprintf(console, "✅ Log patterns completed successfully\n")
+		fmt.Fprintf(console, "📊 Results:\n")
+		fmt.Fprintf(console, "   🧩 Patterns: %d", total)
+		if len(patterns) < total {
+			fmt.Fprintf(console, " (top %d shown)", len(patterns))
+		}
+		fmt.Fprintln(console)
+		fmt.Fprintf(console, "   📝 Total %s: %d\n", unit, entries)
+		fmt.Fprintf(console, "   ⏱️  Execution time: %v\n", executionTime)
+		if outputFile != "" {
+			fmt.Fprintf(console, "   📄 Output written to: %s\n", outputFile)
+		}
+
+		return nil
+	},
+}
+
+// patternRow returns the fields of a pattern as a row for the formatters.
+func patternRow(pattern *logparser.Pattern, entries int) *jsonutil.Object {
+	percent := 0.0
+	if entries > 0 {
+		percent = float64(pattern.Count) * 100 / float64(entries)
+	}
+
+	row := &jsonutil.Object{}
+	row.Set("count", json.Number(strconv.Itoa(pattern.Count)))
+	row.Set("percent", json.Number(strconv.FormatFloat(percent, 'f', 2, 64)))
+	row.Set("first_line", json.Number(strconv.Itoa(pattern.FirstLine)))
+	row.Set("last_line", json.Number(strconv.Itoa(pattern.LastLine)))
+	row.Set("template", pattern.Template())
+	row.Set("example", pattern.Example)
+	return row
+}
+
+// init function registers the logs patterns command and its flags.
+func init() {
+	logsCmd.AddCommand(logsPatternsCmd)
+
+	// Add flags for log pattern options
+	logsPatternsCmd.Flags().Int("top", 20, "Number of most frequent patterns to report (0 = all)")
+	logsPatternsCmd.Flags().Float64("similarity", logparser.DefaultSimilarity, "Share of equal words, from 0 to 1, for a line to join a pattern")
+	logsPatternsCmd.Flags().String("format", "", "Output format: table, csv, jsonl or json (default: from --output extension, else table)")
+	logsPatternsCmd.Flags().StringP("input", "i", "", "Log file to summarize (default: standard input)")
+	logsPatternsCmd.Flags().StringP("output", "o", "", "Output file for the patterns (default: console, - for standard output)")
+	common.AddRecordFlag(logsPatternsCmd)
+	common.AddReadFlags(logsPatternsCmd)
+}
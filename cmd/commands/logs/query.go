@@ -0,0 +1,247 @@
+// Package logs contains the CLI commands for application log files.
+// This file implements the 'logs query' command that selects fields of structured logs.
+package logs
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kcansari/optix/cmd/commands/common"
+	"github.com/kcansari/optix/internal/formatter"
+	"github.com/kcansari/optix/internal/jsonutil"
+	"github.com/kcansari/optix/internal/logparser"
+	"github.com/kcansari/optix/internal/types"
+	"github.com/kcansari/optix/internal/writer"
+	"github.com/spf13/cobra"
+)
+
+// logsQueryCmd represents the logs query command.
+// This command parses structured log lines into fields and selects them.
+var logsQueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Query the fields of structured logs",
+	Long: `Parse structured log lines into fields, keep the records matching
+conditions and print the chosen fields as a table, CSV, JSON Lines or JSON.
+
+The log format is detected from the first lines, or given with --log-format:
+
+  json     {"level":"warn","http":{"status":503}}   (nested keys: http.status)
+  access   Apache/Nginx Common and Combined Log Format: remote_addr,
+           remote_user, time, method, path, protocol, status, bytes,
+           referer, user_agent
+  syslog   RFC 5424 and RFC 3164: facility, severity, time, host, app,
+           pid, msgid, structured_data, message
+  logfmt   time=... level=warn msg="..."
+
+--where takes FIELD OPERATOR VALUE with =, !=, >, >=, <, <=, ~ (regex
+match) or !~; values that are numbers are compared as numbers. Repeat
+--where to require several conditions. Times are written in RFC 3339, so
+time>=2024-05-01T14:00 compares as expected within one time zone.
+
+Lines that do not parse stop the query unless --skip-invalid or
+--max-errors is given. The output format defaults to the extension of
+--output, or a table.
+
+Examples:
+  optix logs query --where 'status>=500' --fields time,path,status --input access.log
+  optix logs query --where 'path~^/api/' --where 'method=POST' --input access.log --output api.csv
+  optix logs query --log-format syslog --where 'app=sshd' --fields time,host,message --input /var/log/auth.log
+  optix logs query --where 'level=error' --format jsonl --input app.logfmt
+  kubectl logs my-pod | optix logs query --where 'http.status>=500' --format csv`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get flag values
+		whereExpressions, _ := cmd.Flags().GetStringArray("where")
+		fields, _ := cmd.Flags().GetStringSlice("fields")
+		logFormat, _ := cmd.Flags().GetString("log-format")
+		outputFormat, _ := cmd.Flags().GetString("format")
+		inputFile, _ := cmd.Flags().GetString("input")
+		outputFile, _ := cmd.Flags().GetString("output")
+		skipInvalid, _ := cmd.Flags().GetBool("skip-invalid")
+		maxErrors, _ := cmd.Flags().GetInt("max-errors")
+
+		// Validate the options
+		var conditions []*logparser.Condition
+		for _, expression := range whereExpressions {
+			condition, err := logparser.ParseCondition(expression)
+			if err != nil {
+				return err
+			}
+			conditions = append(conditions, condition)
+		}
+		if maxErrors < 0 {
+			return fmt.Errorf("--max-errors cannot be negative")
+		}
+		if inputFile == "" && !common.StdinPiped() {
+			return fmt.Errorf("input file is required (use --input flag or pipe input to standard input)")
+		}
+		if common.IsStdout(outputFile) {
+			outputFile = ""
+		}
+
+		formatters := formatter.NewDefaultFormatterStrategy()
+		if outputFormat == "" {
+			outputFormat = formatters.FormatForFile(outputFile)
+		}
+		if outputFormat == "" {
+			outputFormat = "table"
+		}
+		rowFormatter := formatters.GetFormatter(outputFormat)
+		if rowFormatter == nil {
+			return fmt.Errorf("unsupported output format '%s'. Supported formats: %s",
+				outputFormat, strings.Join(formatters.GetFormatNames(), ", "))
+		}
+
+		parsers := logparser.NewDefaultFieldParserStrategy(time.Local)
+		if logFormat != "" && parsers.GetParser(logFormat) == nil {
+			return fmt.Errorf("unsupported log format '%s'. Supported formats: %s",
+				logFormat, strings.Join(parsers.GetFormats(), ", "))
+		}
+
+		content, displayName, err := readLog(cmd, inputFile)
+		if err != nil {
+			return err
+		}
+
+		// Detect the format from the first lines unless it was given
+		formatSource := "given"
+		if logFormat == "" {
+			parser := parsers.DetectFormat(content.Lines)
+			if parser == nil {
+				return fmt.Errorf("could not detect the log format of '%s' (use --log-format: %s)",
+					displayName, strings.Join(parsers.GetFormats(), ", "))
+			}
+			logFormat, formatSource = parser.GetFormat(), "detected"
+		}
+
+		// Parse the records with the reader for the format; JSON logs are
+		// read as JSON Lines
+		readerType := strings.ToLower(logFormat)
+		if readerType == "json" {
+			readerType = "jsonl"
+		}
+		readerStrategy, err := common.NewReaderStrategyWithOptions(types.ReadOptions{
+			Type:        readerType,
+			SkipInvalid: skipInvalid,
+			MaxErrors:   maxErrors,
+		})
+		if err != nil {
+			return err
+		}
+		logContent, err := readerStrategy.ReadData(displayName, []byte(content.Content))
+		if err != nil {
+			return fmt.Errorf("failed to parse log: %w", err)
+		}
+		common.ReportInvalidLines(os.Stderr, displayName, logContent)
+
+		// Display operation info; banners are only shown on a terminal
+		console := common.Console()
+		fmt.Fprintf(console, "🔎 Log Query Operation\n")
+		fmt.Fprintf(console, "📄 Input: %s\n", displayName)
+		fmt.Fprintf(console, "🧾 Log Format: %s (%s)\n", logFormat, formatSource)
+		if len(conditions) > 0 {
+			described := make([]string, len(conditions))
+			for i, condition := range conditions {
+				described[i] = condition.String()
+			}
+			fmt.Fprintf(console, "🔍 Where: %s\n", strings.Join(described, " AND "))
+		}
+		if len(fields) > 0 {
+			fmt.Fprintf(console, "📋 Fields: %s\n", strings.Join(fields, ", "))
+		}
+		fmt.Fprintf(console, "🖨️  Output Format: %s\n", rowFormatter.GetFormat())
+		if outputFile != "" {
+			fmt.Fprintf(console, "📤 Output: %s\n", outputFile)
+		} else {
+			fmt.Fprintf(console, "📤 Output: Console\n")
+		}
+		fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+
+		// Select the records
+		startTime := time.Now()
+		var all, selected []*jsonutil.Object
+		for _, record := range logContent.Records {
+			row, ok := jsonutil.Flatten(record.Data, jsonutil.DefaultSeparator).(*jsonutil.Object)
+			if !ok {
+				continue
+			}
+			all = append(all, row)
+			if matchAll(conditions, row) {
+				selected = append(selected, row)
+			}
+		}
+
+		// Without --fields every field found is shown
+		if len(fields) == 0 {
+			fields = formatter.Columns(selected)
+		} else {
+			known := make(map[string]bool)
+			for _, column := range formatter.Columns(all) {
+				known[column] = true
+			}
+			for _, field := range fields {
+				if !known[field] {
+					fmt.Fprintf(common.Warnings(), "⚠️  Field '%s' was not found in any record\n", field)
+				}
+			}
+		}
+
+		var output strings.Builder
+		if len(selected) > 0 {
+			if err := rowFormatter.Format(&output, fields, selected); err != nil {
+				return fmt.Errorf("failed to format records: %w", err)
+			}
+		}
+		executionTime := time.Since(startTime)
+
+		// Write or display the selected records
+		if outputFile != "" {
+			if err := writer.WriteFile(outputFile, output.String(), writer.OptionsFor(nil, inputFile, outputFile)); err != nil {
+				return fmt.Errorf("failed to write output file: %w", err)
+			}
+		} else if output.Len() > 0 {
+			fmt.Fprintf(console, "📋 Matching Records:\n")
+			fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+			fmt.Print(output.String())
+			fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+		}
+
+		// Display results summary
+		fmt.Fprintf(console, "✅ Log query completed successfully\n")
+		fmt.Fprintf(console, "📊 Results:\n")
+		fmt.Fprintf(console, "   🎯 Matching records: %d\n", len(selected))
+		fmt.Fprintf(console, "   📝 Total records: %d (%d lines)\n", len(all), len(logContent.Lines))
+		fmt.Fprintf(console, "   ⏱️  Execution time: %v\n", executionTime)
+		if outputFile != "" {
+			fmt.Fprintf(console, "   📄 Output written to: %s\n", outputFile)
+		}
+
+		return nil
+	},
+}
+
+// matchAll reports whether a row satisfies every condition.
+func matchAll(conditions []*logparser.Condition, row *jsonutil.Object) bool {
+	for _, condition := range conditions {
+		if !condition.Match(row) {
+			return false
+		}
+	}
+	return true
+}
+
+// init function registers the logs query command and its flags.
+func init() {
+	logsCmd.AddCommand(logsQueryCmd)
+
+	// Add flags for log query options
+	logsQueryCmd.Flags().StringArrayP("where", "w", nil, "Keep records matching FIELD OPERATOR VALUE, e.g. status>=500 (repeatable)")
+	logsQueryCmd.Flags().StringSliceP("fields", "f", nil, "Fields to output, in order, e.g. time,path,status (default: all)")
+	logsQueryCmd.Flags().String("log-format", "", "Log format: json, access, syslog or logfmt (default: detect)")
+	logsQueryCmd.Flags().String("format", "", "Output format: table, csv, jsonl or json (default: from --output extension, else table)")
+	logsQueryCmd.Flags().StringP("input", "i", "", "Log file to query (default: standard input)")
+	logsQueryCmd.Flags().StringP("output", "o", "", "Output file for the records (default: console, - for standard output)")
+	common.AddReadFlags(logsQueryCmd)
+}
@@ -0,0 +1,269 @@
+// Package optix contains the CLI commands for the Optix file processor.
+// This file implements the 'extract' command that pulls values such as emails out of files.
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kcansari/optix/cmd"
+	"github.com/kcansari/optix/cmd/commands/common"
+	"github.com/kcansari/optix/internal/discovery"
+	"github.com/kcansari/optix/internal/formatter"
+	"github.com/kcansari/optix/internal/jsonutil"
+	"github.com/kcansari/optix/internal/patterns"
+	"github.com/kcansari/optix/internal/validator"
+	"github.com/kcansari/optix/internal/writer"
+	"github.com/spf13/cobra"
+)
+
+// matchFields are the fields of every value found.
+var matchFields = []string{"line", "column", "end_column", "kind", "value"}
+
+// frequencyFields are the fields written with --count.
+var frequencyFields = []string{"count", "kind", "value", "first_line"}
+
+// extractCmd represents the extract command.
+// This command lists the values of some kinds found in a file.
+var extractCmd = &cobra.Command{
+	Use:   "extract",
+	Short: "Extract emails, URLs, IP addresses and other values from a file",
+	Long: `List every value of the given kinds found in a file, with its line and
+column, e.g. to pull the email addresses out of a log.
+
+Built-in kinds (--kind):
+  url     URLs with an http, https or ftp scheme
+  email   email addresses
+  uuid    UUIDs
+  card    payment card numbers, checked with the Luhn checksum
+  date    dates such as 2024-05-01, 2024-05-01T12:00:00Z or 01/05/2024
+  ipv4    IPv4 addresses
+  ipv6    IPv6 addresses
+  phone   phone numbers with a country code, an area code in parentheses
+          or at least two separators
+  number  integers and decimals; versions such as 1.2.3 are left out
+
+Every value is reported once even when it looks like several kinds; the
+kind listed first wins, so an IP address inside a URL is part of the URL.
+Columns count characters from 1; end_column is the column just after the
+value.
+
+More kinds are defined in the patterns section of a configuration file
+(see 'optix config'), and replace a built-in kind of the same name:
+
+  patterns:
+    order-id: '\bORD-\d{8}\b'
+    ticket:
+      description: Ticket reference
+      pattern: '\b(?P<value>[A-Z]{2,5}-\d+)\b'
+
+When the expression has a group named "value", only that group is
+extracted. Use --list-kinds to see every kind.
+
+With --unique every value is listed once, where it is first found. With
+--count the values are listed with the number of times they were found,
+the most frequent first.
+
+Examples:
+  optix extract --kind email --input app.log
+  optix extract --kind email --unique --format csv --input app.log --output emails.csv
+  optix extract --kind ipv4,ipv6 --count --input access.log
+  optix extract --kind url --format jsonl --input README.md
+  optix extract --kind order-id --count --input orders.log
+  kubectl logs my-pod | optix extract --kind uuid --unique`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get flag values
+		kindNames, _ := cmd.Flags().GetStringSlice("kind")
+		unique, _ := cmd.Flags().GetBool("unique")
+		count, _ := cmd.Flags().GetBool("count")
+		listKinds, _ := cmd.Flags().GetBool("list-kinds")
+		outputFormat, _ := cmd.Flags().GetString("format")
+		inputFile, _ := cmd.Flags().GetString("input")
+		outputFile, _ := cmd.Flags().GetString("output")
+
+		// Register the kinds of the configuration files
+		registry, err := common.PatternRegistry()
+		if err != nil {
+			return err
+		}
+
+		if listKinds {
+			fmt.Printf("🏷️  Kinds: %d\n", len(registry.Kinds()))
+			for _, kind := range registry.Kinds() {
+				fmt.Printf("   %-12s %s\n", kind.Name, kind.Description)
+			}
+			return nil
+		}
+
+		// Validate the options
+		if len(kindNames) == 0 {
+			return fmt.Errorf("kind is required (use --kind, e.g. --kind email; see --list-kinds)")
+		}
+		kinds, err := registry.Select(kindNames)
+		if err != nil {
+			return err
+		}
+		if unique && count {
+			return fmt.Errorf("--unique and --count cannot be used together")
+		}
+		if inputFile == "" && !common.StdinPiped() {
+			return fmt.Errorf("input file is required (use --input flag or pipe input to standard input)")
+		}
+		if common.IsStdout(outputFile) {
+			outputFile = ""
+		}
+
+		formatters := formatter.NewDefaultFormatterStrategy()
+		if outputFormat == "" {
+			outputFormat = formatters.FormatForFile(outputFile)
+		}
+		if outputFormat == "" {
+			outputFormat = "table"
+		}
+		rowFormatter := formatters.GetFormatter(outputFormat)
+		if rowFormatter == nil {
+			return fmt.Errorf("unsupported output format '%s'. Supported formats: %s",
+				outputFormat, strings.Join(formatters.GetFormatNames(), ", "))
+		}
+
+		// Read the input as text unless --type or --ext-map chooses another reader
+		validatorStrategy := validator.NewValidatorStrategy(validator.NewBasicFileValidator())
+		if err := common.ValidateInput(validatorStrategy, inputFile); err != nil {
+			return err
+		}
+		readerStrategy, err := common.NewTextReaderStrategy(cmd)
+		if err != nil {
+			return err
+		}
+		content, err := common.ReadFile(readerStrategy, inputFile)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		displayName := inputFile
+		if common.IsStdin(inputFile) {
+			displayName = discovery.StdinDisplayName
+		}
+
+		// Display operation info; banners are only shown on a terminal
+		kindList := make([]string, len(kinds))
+		for i, kind := range kinds {
+			kindList[i] = kind.Name
+		}
+		console := common.Console()
+		fmt.Fprintf(console, "⛏️  Extract Operation\n")
+		fmt.Fprintf(console, "📄 Input: %s\n", displayName)
+		fmt.Fprintf(console, "🏷️  Kinds: %s\n", strings.Join(kindList, ", "))
+		if unique {
+			fmt.Fprintf(console, "🧹 Unique: Enabled\n")
+		}
+		if count {
+			fmt.Fprintf(console, "🔢 Count: Enabled\n")
+		}
+		fmt.Fprintf(console, "🖨️  Output Format: %s\n", rowFormatter.GetFormat())
+		if outputFile != "" {
+			fmt.Fprintf(console, "📤 Output: %s\n", outputFile)
+		} else {
+			fmt.Fprintf(console, "📤 Output: Console\n")
+		}
+		fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+
+		// Find the values of every line
+		startTime := time.Now()
+		counter := patterns.NewCounter()
+		var rows []*jsonutil.Object
+		matches := 0
+		for i, line := range content.Lines {
+			for _, match := range patterns.FindAll(kinds, line) {
+				matches++
+				if first := counter.Add(i+1, match); (first || !unique) && !count {
+					rows = append(rows, matchRow(i+1, line, match))
+				}
+			}
+		}
+
+		fields := matchFields
+		frequencies := counter.Frequencies()
+		if count {
+			fields = frequencyFields
+			for _, frequency := range frequencies {
+				rows = append(rows, frequencyRow(frequency))
+			}
+		}
+
+		var output strings.Builder
+		if len(rows) > 0 {
+			if err := rowFormatter.Format(&output, fields, rows); err != nil {
+				return fmt.Errorf("failed to format values: %w", err)
+			}
+		}
+		executionTime := time.Since(startTime)
+
+		// Write or display the values
+		if outputFile != "" {
+			if err := writer.WriteFile(outputFile, output.String(), writer.OptionsFor(nil, inputFile, outputFile)); err != nil {
+				return fmt.Errorf("failed to write output file: %w", err)
+			}
+		} else if output.Len() > 0 {
+			fmt.Fprintf(console, "📋 Values:\n")
+			fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+			fmt.Print(output.String())
+			fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+		}
+
+		// Display results summary
+		fmt.Fprintf(console, "✅ Extract operation completed successfully\n")
+		fmt.Fprintf(console, "📊 Results:\n")
+		fmt.Fprintf(console, "   🎯 Values found: %d\n", matches)
+		fmt.Fprintf(console, "   🧹 Distinct values: %d\n", len(frequencies))
+		fmt.Fprintf(console, "   📝 Lines processed: %d\n", content.LineCount)
+		fmt.Fprintf(console, "   ⏱️  Execution time: %v\n", executionTime)
+		if outputFile != "" {
+			fmt.Fprintf(console, "   📄 Output written to: %s\n", outputFile)
+		}
+
+		return nil
+	},
+}
+
+// matchRow returns a value found on a line as a row for the formatters.
+func matchRow(lineNumber int, line string, match patterns.Match) *jsonutil.Object {
+	column := utf8.RuneCountInString(line[:match.Start]) + 1
+
+	row := &jsonutil.Object{}
+	row.Set("line", json.Number(strconv.Itoa(lineNumber)))
+	row.Set("column", json.Number(strconv.Itoa(column)))
+	row.Set("end_column", json.Number(strconv.Itoa(column+utf8.RuneCountInString(match.Text))))
+	row.Set("kind", match.Kind.Name)
+	row.Set("value", match.Text)
+	return row
+}
+
+// frequencyRow returns a counted value as a row for the formatters.
+func frequencyRow(frequency *patterns.Frequency) *jsonutil.Object {
+	row := &jsonutil.Object{}
+	row.Set("count", json.Number(strconv.Itoa(frequency.Count)))
+	row.Set("kind", frequency.Kind.Name)
+	row.Set("value", frequency.Text)
+	row.Set("first_line", json.Number(strconv.Itoa(frequency.FirstLine)))
+	return row
+}
+
+// init function registers the extract command and its flags.
+func init() {
+	cmd.RootCmd.AddCommand(extractCmd)
+
+	// Add flags for extract options
+	extractCmd.Flags().StringSlice("kind", nil, "Kinds of values to extract, e.g. email or ipv4,ipv6 (see --list-kinds)")
+	extractCmd.Flags().Bool("unique", false, "List every value once, where it is first found")
+	extractCmd.Flags().Bool("count", false, "List every value once with the number of times it was found")
+	extractCmd.Flags().Bool("list-kinds", false, "List the built-in and configured kinds and exit")
+	extractCmd.Flags().String("format", "", "Output format: table, csv, jsonl or json (default: from --output extension, else table)")
+	extractCmd.Flags().StringP("input", "i", "", "File to extract values from (default: standard input)")
+	extractCmd.Flags().StringP("output", "o", "", "Output file for the values (default: console, - for standard output)")
+	common.AddReadFlags(extractCmd)
+}
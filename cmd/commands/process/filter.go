@@ -4,11 +4,19 @@ package process
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/kcansari/optix/cmd"
+	"github.com/kcansari/optix/cmd/commands/common"
+	"github.com/kcansari/optix/internal/discovery"
 	"github.com/kcansari/optix/internal/processor"
+	"github.com/kcansari/optix/internal/processor/strategies"
 	"github.com/kcansari/optix/internal/reader"
 	"github.com/kcansari/optix/internal/validator"
+	"github.com/kcansari/optix/internal/writer"
 	"github.com/spf13/cobra"
 )
 
@@ -24,13 +32,25 @@ The filter command supports:
   - Inverted matching (lines that don't match)
   - Extract only matching parts or entire lines
   - Case-sensitive and case-insensitive filtering
+  - Keeping whole multi-line records such as stack traces (--record-start)
   - Output to file or console
+  - Filtering every member of a zip or tar archive
+  - Filtering standard input (no --input, or --input -)
+  - Following a file as it grows, like tail -f (--follow)
+
+When the output is not a terminal only the filtered lines are printed.
 
 Examples:
   optix filter --contains "WARNING" --input app.log --output warnings.log
   optix filter --pattern "error\d+" --regex --input system.log
   optix filter --contains "TODO" --invert --input code.go
-  optix filter --pattern "user" --only-matching --input data.txt`,
+  optix filter --pattern "user" --only-matching --input data.txt
+  optix filter --contains "ERROR" --input logs.tar.gz --output errors.log
+  optix filter --contains "NullPointerException" --record-start java --input app.log
+  optix filter --pattern "^\d{4}-\d\d-\d\d .*Timeout" --record-start "^\d{4}-" --input app.log
+  optix filter --contains "ERROR" --input dump.bin --binary text
+  optix filter --contains "ERROR" --input app.log --follow
+  kubectl logs my-pod | optix filter --contains "WARN" | sort | uniq -c`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get flag values
@@ -42,6 +62,18 @@ Examples:
 		caseSensitive, _ := cmd.Flags().GetBool("case-sensitive")
 		invertMatch, _ := cmd.Flags().GetBool("invert")
 		onlyMatching, _ := cmd.Flags().GetBool("only-matching")
+		binaryPolicy, err := common.BinaryPolicyFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		recordStart, err := common.RecordStartFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		followMode, pollInterval, err := common.FollowOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
 
 		// Determine the search pattern
 		searchPattern := pattern
@@ -56,24 +88,42 @@ Examples:
 		if searchPattern == "" {
 			return fmt.Errorf("search criteria is required (use --pattern or --contains flag)")
 		}
-		if inputFile == "" {
-			return fmt.Errorf("input file is required (use --input flag)")
+		if inputFile == "" && !common.StdinPiped() {
+			return fmt.Errorf("input file is required (use --input flag or pipe input to standard input)")
+		}
+		if common.IsStdout(outputFile) {
+			outputFile = ""
+		}
+		if followMode {
+			if err := common.ValidateFollowTarget(inputFile); err != nil {
+				return err
+			}
 		}
 
 		// Create processor strategy
-		processorStrategy := processor.NewTextProcessorStrategy()
-		readerStrategy := reader.NewFileReaderStrategy()
+		processorStrategy := strategies.NewDefaultTextProcessorStrategy()
+		readerStrategy, err := common.NewReaderStrategy(cmd)
+		if err != nil {
+			return err
+		}
 		validatorStrategy := validator.NewValidatorStrategy(validator.NewBasicFileValidator())
 
 		// Validate file
-		if err := validatorStrategy.ValidateFile(inputFile); err != nil {
-			return fmt.Errorf("file validation failed: %v", err)
+		if err := common.ValidateInput(validatorStrategy, inputFile); err != nil {
+			return err
 		}
 
-		// Read file content
-		content, err := readerStrategy.ReadFile(inputFile)
+		// Archives are filtered member by member
+		isArchive := false
+		if !common.IsStdin(inputFile) {
+			isArchive, err = discovery.IsArchive(inputFile)
+			if err != nil {
+				return fmt.Errorf("failed to inspect '%s': %v", inputFile, err)
+			}
+		}
+		input, err := common.InputEntry(inputFile)
 		if err != nil {
-			return fmt.Errorf("failed to read file: %v", err)
+			return err
 		}
 
 		// Prepare processing options
@@ -83,63 +133,108 @@ Examples:
 			CaseSensitive: caseSensitive,
 			InvertMatch:   invertMatch,
 			OnlyMatching:  onlyMatching,
+			RecordStart:   recordStart,
 			FileName:      inputFile,
 			OutputFile:    outputFile,
 		}
 
-		// Display operation info
-		fmt.Printf("📋 Filter Operation\n")
-		fmt.Printf("📄 Input: %s\n", inputFile)
-		fmt.Printf("🔍 Pattern: %s\n", searchPattern)
+		// Display operation info; banners are only shown on a terminal
+		console := common.Console()
+		fmt.Fprintf(console, "📋 Filter Operation\n")
+		fmt.Fprintf(console, "📄 Input: %s\n", input.Name)
+		fmt.Fprintf(console, "🔍 Pattern: %s\n", searchPattern)
 		if regexMode || (pattern != "") {
-			fmt.Printf("🔧 Mode: Regular Expression\n")
+			fmt.Fprintf(console, "🔧 Mode: Regular Expression\n")
 		} else {
-			fmt.Printf("🔧 Mode: Literal Text (contains)\n")
+			fmt.Fprintf(console, "🔧 Mode: Literal Text (contains)\n")
 		}
-		fmt.Printf("📊 Case Sensitive: %t\n", caseSensitive)
+		fmt.Fprintf(console, "📊 Case Sensitive: %t\n", caseSensitive)
 		if invertMatch {
-			fmt.Printf("🔄 Invert Match: %t (lines that DON'T match)\n", invertMatch)
+			fmt.Fprintf(console, "🔄 Invert Match: %t (lines that DON'T match)\n", invertMatch)
 		}
 		if onlyMatching {
-			fmt.Printf("✂️  Only Matching: %t (extract matching parts only)\n", onlyMatching)
+			fmt.Fprintf(console, "✂️  Only Matching: %t (extract matching parts only)\n", onlyMatching)
+		}
+		if recordStart != "" {
+			fmt.Fprintf(console, "🧱 Records: %s\n", recordStart)
 		}
 		if outputFile != "" {
-			fmt.Printf("📤 Output: %s\n", outputFile)
+			fmt.Fprintf(console, "📤 Output: %s\n", outputFile)
 		} else {
-			fmt.Printf("📤 Output: Console\n")
+			fmt.Fprintf(console, "📤 Output: Console\n")
+		}
+		if followMode {
+			fmt.Fprintf(console, "👀 Following: new lines, press Ctrl+C to stop\n")
+		}
+		fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+
+		if followMode {
+			return followFilter(inputFile, pollInterval, processorStrategy, options)
 		}
-		fmt.Println("─────────────────────────────────────────────────────")
 
 		// Process the file
-		result, err := processorStrategy.ProcessText("filter", content, options)
+		var result *processor.ProcessingResult
+		if isArchive {
+			result, err = filterArchive(inputFile, readerStrategy, validatorStrategy, processorStrategy, binaryPolicy, options)
+		} else {
+			var content *reader.FileContent
+			var binary bool
+			content, binary, err = common.ReadEntry(input, readerStrategy, validatorStrategy, binaryPolicy)
+			if err != nil {
+				return fmt.Errorf("failed to read file: %w", err)
+			}
+			if content == nil {
+				fmt.Fprintf(common.Warnings(), "⏭️  Skipping binary file '%s'\n", input.Name)
+				return nil
+			}
+			common.ReportInvalidLines(os.Stderr, input.Name, content)
+
+			// Lines of a binary file are only counted, never written
+			reportOnly := binary && binaryPolicy == validator.BinaryReport
+			if reportOnly {
+				options.DryRun = true
+			}
+
+			result, err = processorStrategy.ProcessText("filter", content, options)
+			if err == nil && reportOnly {
+				if result.MatchesFound > 0 {
+					reportBinaryMatch(input.Name)
+				}
+				result.ModifiedContent = ""
+			}
+		}
 		if err != nil {
 			return fmt.Errorf("filter operation failed: %v", err)
 		}
 
 		// Display filtered content if no output file specified
 		if outputFile == "" && result.ModifiedContent != "" {
-			fmt.Printf("📋 Filtered Content:\n")
-			fmt.Println("─────────────────────────────────────────────────────")
+			fmt.Fprintf(console, "📋 Filtered Content:\n")
+			fmt.Fprintln(console, "─────────────────────────────────────────────────────")
 			fmt.Print(result.ModifiedContent)
-			fmt.Println("─────────────────────────────────────────────────────")
+			fmt.Fprintln(console, "─────────────────────────────────────────────────────")
 		}
 
 		// Display results summary
-		fmt.Printf("✅ Filter operation completed successfully\n")
-		fmt.Printf("📊 Results:\n")
-		fmt.Printf("   🎯 Matching lines: %d\n", result.MatchesFound)
-		fmt.Printf("   📝 Total lines processed: %d\n", result.LinesProcessed)
-		fmt.Printf("   ⏱️  Execution time: %v\n", result.ExecutionTime)
+		fmt.Fprintf(console, "✅ Filter operation completed successfully\n")
+		fmt.Fprintf(console, "📊 Results:\n")
+		if recordStart != "" {
+			fmt.Fprintf(console, "   🎯 Matching records: %d\n", result.MatchesFound)
+		} else {
+			fmt.Fprintf(console, "   🎯 Matching lines: %d\n", result.MatchesFound)
+		}
+		fmt.Fprintf(console, "   📝 Total lines processed: %d\n", result.LinesProcessed)
+		fmt.Fprintf(console, "   ⏱️  Execution time: %v\n", result.ExecutionTime)
 
 		if outputFile != "" {
-			fmt.Printf("   📄 Output written to: %s\n", outputFile)
+			fmt.Fprintf(console, "   📄 Output written to: %s\n", outputFile)
 		}
 
 		if result.MatchesFound == 0 {
 			if invertMatch {
-				fmt.Printf("   ℹ️  All lines matched the pattern '%s'\n", searchPattern)
+				fmt.Fprintf(console, "   ℹ️  All lines matched the pattern '%s'\n", searchPattern)
 			} else {
-				fmt.Printf("   ℹ️  No lines matched the pattern '%s'\n", searchPattern)
+				fmt.Fprintf(console, "   ℹ️  No lines matched the pattern '%s'\n", searchPattern)
 			}
 		}
 
@@ -147,6 +242,124 @@ Examples:
 	},
 }
 
+// filterArchive filters every member of an archive. The kept lines of all
+// members are combined into one result, each member introduced by a
+// "==> name <==" header, and written once to the output file. Binary
+// members are handled according to the binary policy.
+func filterArchive(fileName string, readerStrategy *reader.FileReaderStrategy, validatorStrategy *validator.ValidatorStrategy, processorStrategy *processor.TextProcessorStrategy, binaryPolicy validator.BinaryPolicy, options processor.ProcessOptions) (*processor.ProcessingResult, error) {
+	startTime := time.Now()
+	combined := &processor.ProcessingResult{FileName: fileName, Operation: "filter", Success: true}
+
+	var output strings.Builder
+	err := discovery.Walk(discovery.Target{Path: fileName}, func(entry discovery.Entry) error {
+		content, binary, err := common.ReadEntry(entry, readerStrategy, validatorStrategy, binaryPolicy)
+		if err != nil {
+			return err
+		}
+		if content == nil {
+			fmt.Fprintf(common.Warnings(), "⏭️  Skipping binary file '%s'\n", entry.Name)
+			return nil
+		}
+		common.ReportInvalidLines(os.Stderr, entry.Name, content)
+
+		memberOptions := options
+		memberOptions.FileName = entry.Name
+		memberOptions.DryRun = true
+
+		result, err := processorStrategy.ProcessText("filter", content, memberOptions)
+		if err != nil {
+			return fmt.Errorf("'%s': %w", entry.Name, err)
+		}
+
+		combined.MatchesFound += result.MatchesFound
+		combined.LinesProcessed += result.LinesProcessed
+		if binary && binaryPolicy == validator.BinaryReport {
+			if result.MatchesFound > 0 {
+				reportBinaryMatch(entry.Name)
+			}
+			return nil
+		}
+		if result.ModifiedContent != "" {
+			fmt.Fprintf(&output, "==> %s <==\n%s", entry.Name, result.ModifiedContent)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	combined.ModifiedContent = output.String()
+	if options.OutputFile != "" && !options.DryRun {
+		if err := writer.WriteFile(options.OutputFile, combined.ModifiedContent, writer.OptionsFor(nil, fileName, options.OutputFile)); err != nil {
+			return nil, fmt.Errorf("failed to write output file: %w", err)
+		}
+	}
+	combined.ExecutionTime = time.Since(startTime)
+	return combined, nil
+}
+
+// followFilter filters the lines appended to a file until Ctrl+C and then
+// prints a summary. Kept lines are written as they arrive, to the output
+// file when one is given.
+func followFilter(fileName string, interval time.Duration, processorStrategy *processor.TextProcessorStrategy, options processor.ProcessOptions) error {
+	output := os.Stdout
+	if options.OutputFile != "" {
+		file, err := os.Create(options.OutputFile)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	// Batches are only filtered; the output is written here
+	options.OutputFile = ""
+	options.DryRun = true
+
+	startTime := time.Now()
+	matches := 0
+	linesProcessed := 0
+	err := common.FollowFiles([]string{fileName}, interval, options.RecordStart, func(batch common.FollowBatch) error {
+		result, err := processorStrategy.ProcessText("filter", batch.Content(), options)
+		if err != nil {
+			return fmt.Errorf("filter operation failed: %w", err)
+		}
+		matches += result.MatchesFound
+		linesProcessed += len(batch.Lines)
+		if _, err := io.WriteString(output, result.ModifiedContent); err != nil {
+			return fmt.Errorf("failed to write filtered lines: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The summary follows Ctrl+C, so it also reaches the terminal when the
+	// filtered lines are piped
+	summary := common.Warnings()
+	fmt.Fprintln(summary, "\n─────────────────────────────────────────────────────")
+	fmt.Fprintf(summary, "📊 Follow Summary:\n")
+	if options.RecordStart != "" {
+		fmt.Fprintf(summary, "   🎯 Matching records: %d\n", matches)
+	} else {
+		fmt.Fprintf(summary, "   🎯 Matching lines: %d\n", matches)
+	}
+	fmt.Fprintf(summary, "   📝 New lines processed: %d\n", linesProcessed)
+	fmt.Fprintf(summary, "   ⏱️  Followed for: %v\n", time.Since(startTime).Round(time.Second))
+	return nil
+}
+
+// reportBinaryMatch reports a binary file with matching lines, which are
+// not printed.
+func reportBinaryMatch(name string) {
+	if common.Interactive() {
+		fmt.Printf("📄 Binary file %s matches\n", name)
+		return
+	}
+	fmt.Printf("Binary file %s matches\n", name)
+}
+
 // init function registers the filter command and its flags.
 func init() {
 	cmd.RootCmd.AddCommand(filterCmd)
@@ -154,13 +367,14 @@ func init() {
 	// Add flags for filter options
 	filterCmd.Flags().StringP("pattern", "p", "", "Regular expression pattern to match")
 	filterCmd.Flags().String("contains", "", "Literal text that lines must contain")
-	filterCmd.Flags().StringP("input", "i", "", "Input file to filter (required)")
-	filterCmd.Flags().StringP("output", "o", "", "Output file for filtered results (optional)")
+	filterCmd.Flags().StringP("input", "i", "", "Input file to filter (default: standard input)")
+	filterCmd.Flags().StringP("output", "o", "", "Output file for filtered results (default: console, - for standard output)")
 	filterCmd.Flags().BoolP("regex", "r", false, "Use regular expression mode (auto-enabled with --pattern)")
 	filterCmd.Flags().BoolP("case-sensitive", "c", false, "Case sensitive filtering")
 	filterCmd.Flags().BoolP("invert", "v", false, "Invert match (select lines that DON'T match)")
 	filterCmd.Flags().Bool("only-matching", false, "Output only the matching parts of lines")
-
-	// Mark required flags
-	filterCmd.MarkFlagRequired("input")
+	common.AddRecordFlag(filterCmd)
+	common.AddFollowFlags(filterCmd)
+	common.AddReadFlags(filterCmd)
+	common.AddBinaryFlag(filterCmd, false)
 }
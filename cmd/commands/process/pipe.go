@@ -0,0 +1,288 @@
+// Package optix contains the CLI commands for the Optix file processor.
+// This file implements the 'pipe' command that chains several processors.
+package process
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kcansari/optix/cmd"
+	"github.com/kcansari/optix/cmd/commands/common"
+	"github.com/kcansari/optix/internal/backup"
+	"github.com/kcansari/optix/internal/discovery"
+	"github.com/kcansari/optix/internal/lineending"
+	"github.com/kcansari/optix/internal/pipeline"
+	"github.com/kcansari/optix/internal/processor"
+	"github.com/kcansari/optix/internal/processor/strategies"
+	"github.com/kcansari/optix/internal/validator"
+	"github.com/kcansari/optix/internal/writer"
+	"github.com/spf13/cobra"
+)
+
+// pipeCmd represents the pipe command.
+// This command runs several processors over a file in one pass.
+var pipeCmd = &cobra.Command{
+	Use:   "pipe [pipeline]",
+	Short: "Run several processing steps over a file in one pass",
+	Long: `Run several processing steps over a file in one pass.
+
+The file is read once, each stage processes the output of the stage before
+it, and the result is written once at the end (with a single backup).
+Stages are separated by "|":
+
+  filter [-v] [-c] [-o] [-r] PATTERN   keep matching lines; /PATTERN/ is a regex
+  replace [-c] [-w] [-r] FIND REPLACE  replace text, like the replace command
+  replace s/PATTERN/REPLACE/[gi]       replace a regex ($1 refers to a group)
+  transform TYPE                       upper, lower, title, trim, eol-lf, eol-crlf
+  dedupe                               drop repeated lines, keeping the first
+
+Flags mirror the filter and replace commands: -v invert, -c case-sensitive,
+-o only matching, -r regex, -w whole word. Quote words that contain spaces
+or "|". Stages can also be read from a YAML file with --pipeline.
+
+The result is written to standard output unless --output is given, or
+--in-place to rewrite the file itself. Without --file, or with --file -,
+standard input is processed.
+
+Examples:
+  optix pipe --file app.log 'filter ERROR | replace s/host\d+/HOST/ | transform lower | dedupe' --output errors.log
+  optix pipe --file app.log 'filter -v DEBUG | dedupe' > clean.log
+  optix pipe --file app.log --pipeline cleanup.yaml --in-place --backup
+  kubectl logs my-pod | optix pipe 'filter /timeout|refused/ | dedupe'`,
+
+	// The pipeline is given as an argument unless --pipeline is used
+	Args: cobra.MaximumNArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get flag values
+		fileName, _ := cmd.Flags().GetString("file")
+		pipelineFile, _ := cmd.Flags().GetString("pipeline")
+		outputFile, _ := cmd.Flags().GetString("output")
+		inPlace, _ := cmd.Flags().GetBool("in-place")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		createBackup, _ := cmd.Flags().GetBool("backup")
+		backupDir, _ := cmd.Flags().GetString("backup-dir")
+
+		// Parse the stages
+		var stages []pipeline.Stage
+		var err error
+		switch {
+		case pipelineFile != "" && len(args) > 0:
+			return fmt.Errorf("cannot use both a pipeline argument and --pipeline")
+		case pipelineFile != "":
+			stages, err = pipeline.Load(pipelineFile)
+		case len(args) == 1:
+			stages, err = pipeline.Parse(args[0])
+		default:
+			return fmt.Errorf("pipeline is required (e.g. 'filter ERROR | dedupe', or use --pipeline)")
+		}
+		if err != nil {
+			return err
+		}
+
+		processorStrategy := strategies.NewDefaultTextProcessorStrategy()
+		if err := pipeline.Validate(processorStrategy, stages); err != nil {
+			return err
+		}
+
+		// Validate the input
+		if fileName == "" && !common.StdinPiped() {
+			return fmt.Errorf("file is required (use --file flag or pipe input to standard input)")
+		}
+		if inPlace && outputFile != "" {
+			return fmt.Errorf("cannot use both --in-place and --output flags")
+		}
+		if inPlace && common.IsStdin(fileName) {
+			return fmt.Errorf("--in-place cannot be used with standard input")
+		}
+		if createBackup && !inPlace {
+			return fmt.Errorf("--backup requires --in-place")
+		}
+
+		// The result goes to standard output unless --output or --in-place is given
+		toStdout := !inPlace && (outputFile == "" || common.IsStdout(outputFile))
+		if toStdout {
+			outputFile = ""
+		}
+
+		readerStrategy, err := common.NewReaderStrategy(cmd)
+		if err != nil {
+			return err
+		}
+		validatorStrategy := validator.NewValidatorStrategy(validator.NewBasicFileValidator())
+		if err := common.ValidateInput(validatorStrategy, fileName); err != nil {
+			return err
+		}
+
+		// Binary files are only processed with --binary text
+		binaryPolicy, err := common.BinaryPolicyFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Display operation info; banners are only shown on a terminal and
+		// never mixed into content written to standard output
+		console := common.Console()
+		if toStdout {
+			console = io.Discard
+		}
+		displayName := fileName
+		if common.IsStdin(fileName) {
+			displayName = discovery.StdinDisplayName
+		}
+
+		fmt.Fprintf(console, "🔗 Pipeline Operation\n")
+		fmt.Fprintf(console, "📄 File: %s\n", displayName)
+		fmt.Fprintf(console, "🔗 Stages: %d\n", len(stages))
+		for i, stage := range stages {
+			fmt.Fprintf(console, "   %d. %s\n", i+1, stage.Source)
+		}
+		if createBackup {
+			fmt.Fprintf(console, "💾 Backup: Enabled\n")
+		}
+		if dryRun {
+			fmt.Fprintf(console, "🧪 Dry Run: Enabled (no changes will be made)\n")
+		}
+		if outputFile != "" {
+			fmt.Fprintf(console, "📤 Output File: %s\n", outputFile)
+		} else if toStdout {
+			fmt.Fprintf(console, "📤 Output: Standard output\n")
+		} else {
+			fmt.Fprintf(console, "📤 Output: Overwrite original file\n")
+		}
+		fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+
+		// Read the file once
+		content, err := common.ReadInputFile(fileName, readerStrategy, validatorStrategy, binaryPolicy)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %v", err)
+		}
+		if content == nil {
+			fmt.Fprintf(common.Warnings(), "⏭️  Skipping binary file '%s'\n", displayName)
+			return nil
+		}
+		common.ReportInvalidLines(common.Warnings(), displayName, content)
+
+		// Run every stage in memory
+		result, err := pipeline.Run(processorStrategy, fileName, content, stages)
+		if err != nil {
+			return fmt.Errorf("pipeline failed: %v", err)
+		}
+
+		// Write the result once
+		var backupPath string
+		outputTarget := fileName
+		switch {
+		case dryRun:
+		case toStdout:
+			outputTarget = "(standard output)"
+			final := &processor.ProcessingResult{ModifiedContent: result.Content, LineEnding: result.LineEnding}
+			if err := common.WriteStdout(content, final); err != nil {
+				return fmt.Errorf("failed to write standard output: %w", err)
+			}
+		default:
+			if outputFile != "" {
+				outputTarget = outputFile
+			}
+
+			writeOptions := writer.OptionsFor(content, fileName, outputTarget)
+			if result.LineEnding != "" {
+				writeOptions.LineEnding = lineending.Style(result.LineEnding)
+			}
+			// Encode first so that nothing is backed up or written on failure
+			if _, err := writer.Encode(result.Content, writeOptions); err != nil {
+				return fmt.Errorf("failed to encode output: %w", err)
+			}
+
+			if createBackup {
+				backupPath, err = backup.Create(fileName, backupDir)
+				if err != nil {
+					return fmt.Errorf("failed to create backup: %w", err)
+				}
+			}
+			if err := writer.WriteFile(outputTarget, result.Content, writeOptions); err != nil {
+				return fmt.Errorf("failed to write '%s': %w", outputTarget, err)
+			}
+		}
+
+		// Display preview for dry run
+		if dryRun {
+			fmt.Fprintf(console, "🧪 Dry Run Preview:\n")
+			fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+
+			var lines []string
+			if result.Content != "" {
+				lines = strings.Split(strings.TrimSuffix(result.Content, "\n"), "\n")
+			}
+			previewLines := min(len(lines), 10)
+			for i := 0; i < previewLines; i++ {
+				fmt.Printf("%3d: %s\n", i+1, lines[i])
+			}
+			if len(lines) > previewLines {
+				fmt.Printf("... and %d more lines\n", len(lines)-previewLines)
+			}
+
+			fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+		}
+
+		// Display results
+		fmt.Fprintf(console, "✅ Pipeline completed successfully\n")
+		fmt.Fprintf(console, "📊 Stage Results:\n")
+		for i, stageResult := range result.Stages {
+			fmt.Fprintf(console, "   %d. %-30s %s (%d lines in, %v)\n", i+1, stages[i].Source,
+				describeStageResult(stages[i].Operation, stageResult), stageResult.LinesProcessed, stageResult.ExecutionTime)
+		}
+		fmt.Fprintf(console, "📊 Results:\n")
+		fmt.Fprintf(console, "   📝 Lines: %d → %d\n", content.LineCount, countLines(result.Content))
+		fmt.Fprintf(console, "   ⏱️  Execution time: %v\n", result.ExecutionTime)
+		if backupPath != "" {
+			fmt.Fprintf(console, "   💾 Backup created: %s\n", backupPath)
+		}
+		if dryRun {
+			fmt.Fprintf(console, "   🧪 Dry run completed - no changes were made\n")
+			fmt.Fprintf(console, "   ℹ️  Run without --dry-run to write the result\n")
+		} else {
+			fmt.Fprintf(console, "   📄 Modified file: %s\n", outputTarget)
+		}
+
+		return nil
+	},
+}
+
+// describeStageResult summarises what a stage did.
+func describeStageResult(operation string, result *processor.ProcessingResult) string {
+	switch operation {
+	case "filter":
+		return fmt.Sprintf("🎯 %d lines kept", result.MatchesFound)
+	case "replace":
+		return fmt.Sprintf("🔄 %d replacements", result.MatchesFound)
+	case "dedupe":
+		return fmt.Sprintf("🧹 %d duplicates removed", result.MatchesFound)
+	}
+	return "🔧 applied"
+}
+
+// countLines counts the lines of text, with or without a final newline.
+func countLines(text string) int {
+	if text == "" {
+		return 0
+	}
+	return strings.Count(strings.TrimSuffix(text, "\n"), "\n") + 1
+}
+
+// init function registers the pipe command and its flags.
+func init() {
+	cmd.RootCmd.AddCommand(pipeCmd)
+
+	// Add flags for pipe options
+	pipeCmd.Flags().String("file", "", "File to process (default: standard input)")
+	pipeCmd.Flags().String("pipeline", "", "YAML file with the pipeline stages")
+	pipeCmd.Flags().StringP("output", "o", "", "Output file, - for standard output (default: standard output)")
+	pipeCmd.Flags().BoolP("in-place", "w", false, "Rewrite the input file instead of writing to standard output")
+	pipeCmd.Flags().Bool("dry-run", false, "Preview the result without modifying files")
+	pipeCmd.Flags().BoolP("backup", "b", false, "Create backup before rewriting (requires --in-place)")
+	pipeCmd.Flags().String("backup-dir", "", "Directory for backup files (default: same as original)")
+	common.AddReadFlags(pipeCmd)
+	common.AddBinaryFlag(pipeCmd, true)
+}
@@ -0,0 +1,390 @@
+// Package optix contains the CLI commands for the Optix file processor.
+// This file implements the 'redact' command that masks personal data.
+package process
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kcansari/optix/cmd"
+	"github.com/kcansari/optix/cmd/commands/common"
+	"github.com/kcansari/optix/internal/discovery"
+	"github.com/kcansari/optix/internal/patterns"
+	"github.com/kcansari/optix/internal/processor"
+	"github.com/kcansari/optix/internal/processor/strategies"
+	"github.com/kcansari/optix/internal/reader"
+	"github.com/kcansari/optix/internal/validator"
+	"github.com/kcansari/optix/internal/writer"
+	"github.com/spf13/cobra"
+)
+
+// redactKeyVariable is the environment variable holding the default key of
+// the hash and fake modes.
+const redactKeyVariable = "OPTIX_REDACT_KEY"
+
+// redactionReport is the layout of the file written with --report.
+type redactionReport struct {
+	File   string         `json:"file"`
+	Mode   string         `json:"mode"`
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts"`
+}
+
+// redactCmd represents the redact command.
+// This command replaces personal data such as email addresses in a file.
+var redactCmd = &cobra.Command{
+	Use:   "redact",
+	Short: "Mask personal data such as emails, IPs, phone and card numbers",
+	Long: `Replace personal data in a file before sharing it, e.g. logs sent to
+a vendor.
+
+Kinds of personal data, redacted by default (--kinds):
+  email   email addresses
+  card    payment card numbers, checked with the Luhn checksum
+  ipv4    IPv4 addresses
+  ipv6    IPv6 addresses
+  phone   phone numbers with a country code, an area code in parentheses
+          or at least two separators, e.g. +1-555-0123, (555) 456-7890
+
+The other kinds of 'optix extract' (url, uuid, date, number), and the
+kinds of the patterns section of a configuration file (see 'optix
+config'), are only redacted when named in --kinds.
+
+Modes (--mode):
+  mask    hide letters and digits but keep the shape: *******@*******.***;
+          card numbers keep their last four digits
+  hash    replace with a token from a keyed HMAC: [email:3f9a0c1d]
+  fake    replace with a made-up value of the same kind from ranges
+          reserved for examples: user123456@example.com, 198.18.4.7
+
+In the hash and fake modes the same value always gets the same
+replacement, so records can still be correlated. The key comes from --key
+or the ` + redactKeyVariable + ` environment variable; use the same key for
+every file to keep replacements consistent across files. Without a key a
+random one is used and replacements are only consistent within one run.
+
+The number of values replaced per kind is shown after the run and written
+as JSON with --report.
+
+The result is written to standard output, so the input is kept unless
+--output names another file or --in-place rewrites the file itself (with
+--backup to keep a copy). Without --file, or with --file -, standard input
+is redacted.
+
+Examples:
+  optix redact --file app.log > app.redacted.log
+  optix redact --file app.log --kinds email,ipv4 --dry-run
+  optix redact --file app.log --mode hash --key "$TEAM_KEY" --output shared.log
+  optix redact --file app.log --in-place --backup
+  optix redact --file support.zip --rewrite-archive --in-place --mode fake
+  optix redact --file users.csv --output users.safe.csv --report redaction.json
+  kubectl logs my-pod | optix redact --mode hash > pod.log`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get flag values
+		fileName, _ := cmd.Flags().GetString("file")
+		outputFile, _ := cmd.Flags().GetString("output")
+		inPlace, _ := cmd.Flags().GetBool("in-place")
+		createBackup, _ := cmd.Flags().GetBool("backup")
+		backupDir, _ := cmd.Flags().GetString("backup-dir")
+		mode, _ := cmd.Flags().GetString("mode")
+		kindNames, _ := cmd.Flags().GetStringSlice("kinds")
+		key, _ := cmd.Flags().GetString("key")
+		reportFile, _ := cmd.Flags().GetString("report")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		// Validate required flags
+		if fileName == "" && !common.StdinPiped() {
+			return fmt.Errorf("file is required (use --file flag or pipe input to standard input)")
+		}
+		if inPlace && outputFile != "" {
+			return fmt.Errorf("cannot use both --in-place and --output flags")
+		}
+		if inPlace && common.IsStdin(fileName) {
+			return fmt.Errorf("--in-place cannot be used with standard input")
+		}
+		if createBackup && !inPlace {
+			return fmt.Errorf("--backup requires --in-place")
+		}
+		mode = strings.ToLower(mode)
+		if len(kindNames) == 0 {
+			kindNames = patterns.PersonalKinds
+		}
+		registry, err := common.PatternRegistry()
+		if err != nil {
+			return err
+		}
+		kinds, err := registry.Select(kindNames)
+		if err != nil {
+			return err
+		}
+
+		// The result goes to standard output unless --output or --in-place is given
+		toStdout := !inPlace && (outputFile == "" || common.IsStdout(outputFile))
+		if toStdout {
+			outputFile = ""
+		}
+
+		// Banners are only shown on a terminal and never mixed into content
+		// written to standard output
+		console := common.Console()
+		notices := console
+		if toStdout {
+			console = io.Discard
+			notices = os.Stderr
+		}
+
+		// The hash and fake modes need a key
+		randomKey := false
+		if key == "" {
+			key = os.Getenv(redactKeyVariable)
+		}
+		if key == "" && mode != patterns.ModeMask {
+			random := make([]byte, 32)
+			if _, err := rand.Read(random); err != nil {
+				return fmt.Errorf("failed to create a key: %w", err)
+			}
+			key, randomKey = hex.EncodeToString(random), true
+		}
+
+		// Create processor strategy
+		processorStrategy := strategies.NewDefaultTextProcessorStrategy()
+		readerStrategy, err := common.NewReaderStrategy(cmd)
+		if err != nil {
+			return err
+		}
+		validatorStrategy := validator.NewValidatorStrategy(validator.NewBasicFileValidator())
+
+		// Validate file
+		if err := common.ValidateInput(validatorStrategy, fileName); err != nil {
+			return err
+		}
+
+		// Binary files are only modified with --binary text
+		binaryPolicy, err := common.BinaryPolicyFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Archives are read-only unless --rewrite-archive is given
+		rewriteArchive := false
+		if !common.IsStdin(fileName) {
+			rewriteArchive, err = common.ArchiveRewriteRequested(cmd, fileName)
+			if err != nil {
+				return err
+			}
+		}
+		if rewriteArchive && !inPlace && !dryRun {
+			return fmt.Errorf("--rewrite-archive rewrites the archive itself (use it with --in-place)")
+		}
+
+		// Prepare processing options
+		options := processor.ProcessOptions{
+			RedactKinds:    kindNames,
+			RedactMode:     mode,
+			RedactKey:      key,
+			RedactRegistry: registry,
+			FileName:       fileName,
+			OutputFile:     outputFile,
+			DryRun:         dryRun || toStdout, // Standard output is written below
+			CreateBackup:   createBackup,
+			BackupDir:      backupDir,
+		}
+		if err := processorStrategy.GetProcessor("redact").ValidateOptions(options); err != nil {
+			return err
+		}
+
+		// Display operation info
+		displayName := fileName
+		if common.IsStdin(fileName) {
+			displayName = discovery.StdinDisplayName
+		}
+		kindList := make([]string, len(kinds))
+		for i, kind := range kinds {
+			kindList[i] = kind.Name
+		}
+
+		fmt.Fprintf(console, "🕶️  Redact Operation\n")
+		fmt.Fprintf(console, "📄 File: %s\n", displayName)
+		fmt.Fprintf(console, "🏷️  Kinds: %s\n", strings.Join(kindList, ", "))
+		fmt.Fprintf(console, "🔧 Mode: %s\n", mode)
+		if createBackup {
+			fmt.Fprintf(console, "💾 Backup: Enabled\n")
+		}
+		if dryRun {
+			fmt.Fprintf(console, "🧪 Dry Run: Enabled (no changes will be made)\n")
+		}
+		if outputFile != "" {
+			fmt.Fprintf(console, "📤 Output File: %s\n", outputFile)
+		} else if rewriteArchive {
+			fmt.Fprintf(console, "📦 Output: Rewrite archive members\n")
+		} else if toStdout {
+			fmt.Fprintf(console, "📤 Output: Standard output\n")
+		} else {
+			fmt.Fprintf(console, "📤 Output: Overwrite original file\n")
+		}
+		if randomKey {
+			fmt.Fprintf(notices, "🔑 No --key or %s given: a random key is used, so replacements are only consistent within this run\n", redactKeyVariable)
+		}
+		fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+
+		// Redact the members of an archive, adding up the counts
+		counts := make(map[string]int)
+		if rewriteArchive {
+			result, err := common.RewriteArchive(fileName, readerStrategy, binaryPolicy, options, func(content *reader.FileContent, options processor.ProcessOptions) (*processor.ProcessingResult, error) {
+				result, err := processorStrategy.ProcessText("redact", content, options)
+				if err == nil {
+					for kind, count := range result.RedactionCounts {
+						counts[kind] += count
+					}
+				}
+				return result, err
+			})
+			if err != nil {
+				return fmt.Errorf("redact operation failed: %v", err)
+			}
+
+			fmt.Fprintf(console, "✅ Redact operation completed successfully\n")
+			fmt.Fprintf(console, "📊 Results:\n")
+			fmt.Fprintf(console, "   📦 Members processed: %d\n", result.Members)
+			fmt.Fprintf(console, "   ✏️  Members changed: %d\n", result.ChangedMembers)
+			fmt.Fprintf(console, "   📝 Lines processed: %d\n", result.LinesProcessed)
+			printRedactionCounts(console, kinds, counts)
+			if dryRun {
+				fmt.Fprintf(console, "   🧪 Dry run completed - no changes were made\n")
+			} else if result.ChangedMembers > 0 {
+				fmt.Fprintf(console, "   📄 Redacted archive: %s\n", fileName)
+			}
+			if result.BackupPath != "" {
+				fmt.Fprintf(console, "   💾 Backup created: %s\n", result.BackupPath)
+			}
+			return writeRedactionReport(reportFile, displayName, mode, counts)
+		}
+
+		// Read file content
+		content, err := common.ReadInputFile(fileName, readerStrategy, validatorStrategy, binaryPolicy)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %v", err)
+		}
+		if content == nil {
+			fmt.Fprintf(common.Warnings(), "⏭️  Skipping binary file '%s'\n", displayName)
+			return nil
+		}
+
+		// Process the file
+		result, err := processorStrategy.ProcessText("redact", content, options)
+		if err != nil {
+			return fmt.Errorf("redact operation failed: %v", err)
+		}
+		if toStdout && !dryRun {
+			if err := common.WriteStdout(content, result); err != nil {
+				return fmt.Errorf("failed to write standard output: %w", err)
+			}
+		}
+
+		// Display preview for dry run
+		if dryRun {
+			fmt.Fprintf(console, "🧪 Dry Run Preview:\n")
+			fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+
+			lines := strings.Split(strings.TrimSuffix(result.ModifiedContent, "\n"), "\n")
+			previewLines := min(len(lines), 10)
+			for i := 0; i < previewLines; i++ {
+				fmt.Printf("%3d: %s\n", i+1, lines[i])
+			}
+			if len(lines) > previewLines {
+				fmt.Printf("... and %d more lines\n", len(lines)-previewLines)
+			}
+
+			fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+		}
+
+		// Display results
+		fmt.Fprintf(console, "✅ Redact operation completed successfully\n")
+		fmt.Fprintf(console, "📊 Results:\n")
+		fmt.Fprintf(console, "   📝 Lines processed: %d\n", result.LinesProcessed)
+		printRedactionCounts(console, kinds, result.RedactionCounts)
+		fmt.Fprintf(console, "   ⏱️  Execution time: %v\n", result.ExecutionTime)
+
+		if dryRun {
+			fmt.Fprintf(console, "   🧪 Dry run completed - no changes were made\n")
+			fmt.Fprintf(console, "   ℹ️  Run without --dry-run to apply redaction\n")
+		} else {
+			outputTarget := fileName
+			if outputFile != "" {
+				outputTarget = outputFile
+			} else if toStdout {
+				outputTarget = "(standard output)"
+			}
+			fmt.Fprintf(console, "   📄 Redacted file: %s\n", outputTarget)
+			if result.BackupPath != "" {
+				fmt.Fprintf(console, "   💾 Backup created: %s\n", result.BackupPath)
+			}
+		}
+
+		return writeRedactionReport(reportFile, displayName, mode, result.RedactionCounts)
+	},
+}
+
+// printRedactionCounts shows the number of values replaced per kind.
+func printRedactionCounts(console io.Writer, kinds []*patterns.Kind, counts map[string]int) {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	fmt.Fprintf(console, "   🕶️  Values redacted: %d\n", total)
+	for _, kind := range kinds {
+		if counts[kind.Name] > 0 {
+			fmt.Fprintf(console, "      %-6s %d\n", kind.Name, counts[kind.Name])
+		}
+	}
+}
+
+// writeRedactionReport writes the counts per kind as JSON, when a report
+// file was requested.
+func writeRedactionReport(reportFile, fileName, mode string, counts map[string]int) error {
+	if reportFile == "" {
+		return nil
+	}
+
+	report := redactionReport{File: fileName, Mode: mode, Counts: counts}
+	for _, count := range counts {
+		report.Total += count
+	}
+	if report.Counts == nil {
+		report.Counts = map[string]int{}
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode redaction report: %w", err)
+	}
+	if err := writer.WriteFile(reportFile, string(data)+"\n", writer.OptionsFor(nil, "", reportFile)); err != nil {
+		return fmt.Errorf("failed to write redaction report: %w", err)
+	}
+	return nil
+}
+
+// init function registers the redact command and its flags.
+func init() {
+	cmd.RootCmd.AddCommand(redactCmd)
+
+	// Add flags for redact options
+	redactCmd.Flags().String("file", "", "File to redact (default: standard input)")
+	redactCmd.Flags().StringP("output", "o", "", "Output file, - for standard output (default: standard output)")
+	redactCmd.Flags().BoolP("in-place", "w", false, "Rewrite the input file instead of writing to standard output")
+	redactCmd.Flags().String("mode", patterns.ModeMask, "Redaction mode: mask, hash or fake")
+	redactCmd.Flags().StringSlice("kinds", nil, "Kinds of values to redact (default: email, card, ipv4, ipv6, phone)")
+	redactCmd.Flags().String("key", "", "Key for the hash and fake modes (default: $"+redactKeyVariable+", else random)")
+	redactCmd.Flags().String("report", "", "Write the number of values redacted per kind to this JSON file")
+	redactCmd.Flags().Bool("dry-run", false, "Preview redaction without modifying files")
+	redactCmd.Flags().BoolP("backup", "b", false, "Create backup before rewriting (requires --in-place)")
+	redactCmd.Flags().String("backup-dir", "", "Directory for backup files (default: same as original)")
+	common.AddReadFlags(redactCmd)
+	common.AddArchiveFlags(redactCmd)
+	common.AddBinaryFlag(redactCmd, true)
+}
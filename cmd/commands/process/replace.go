@@ -4,9 +4,13 @@ package process
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/kcansari/optix/cmd"
+	"github.com/kcansari/optix/cmd/commands/common"
+	"github.com/kcansari/optix/internal/discovery"
 	"github.com/kcansari/optix/internal/processor"
+	"github.com/kcansari/optix/internal/processor/strategies"
 	"github.com/kcansari/optix/internal/reader"
 	"github.com/kcansari/optix/internal/validator"
 	"github.com/spf13/cobra"
@@ -25,12 +29,17 @@ The replace command supports:
   - Dry run mode to preview changes
   - Case-sensitive and case-insensitive replacement
   - Whole word matching
+  - Rewriting members of zip and tar archives (--rewrite-archive)
+  - Reading standard input (no --file, or --file -) and writing the
+    result to standard output (also with --output -)
 
 Examples:
   optix replace --find "old_url" --replace "new_url" --file config.txt
   optix replace --find "user\d+" --replace "customer$0" --regex --file data.txt
   optix replace --find "TODO" --replace "DONE" --file notes.txt --backup
-  optix replace --find "debug" --replace "info" --file app.log --dry-run`,
+  optix replace --find "debug" --replace "info" --file app.log --dry-run
+  optix replace --find "staging" --replace "prod" --file configs.tar.gz --rewrite-archive --backup
+  cat app.log | optix replace --find "secret=\S+" --replace "secret=***" --regex > clean.log`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get flag values
@@ -52,24 +61,45 @@ Examples:
 		if replaceWith == "" {
 			return fmt.Errorf("replacement text is required (use --replace flag)")
 		}
-		if fileName == "" {
-			return fmt.Errorf("file is required (use --file flag)")
+		if fileName == "" && !common.StdinPiped() {
+			return fmt.Errorf("file is required (use --file flag or pipe input to standard input)")
+		}
+
+		// Results for standard input go to standard output unless --output is given
+		toStdout := common.WritesStdout(fileName, outputFile)
+		if toStdout {
+			outputFile = ""
+		}
+		if createBackup && common.IsStdin(fileName) {
+			return fmt.Errorf("--backup cannot be used with standard input")
 		}
 
 		// Create processor strategy
-		processorStrategy := processor.NewTextProcessorStrategy()
-		readerStrategy := reader.NewFileReaderStrategy()
+		processorStrategy := strategies.NewDefaultTextProcessorStrategy()
+		readerStrategy, err := common.NewReaderStrategy(cmd)
+		if err != nil {
+			return err
+		}
 		validatorStrategy := validator.NewValidatorStrategy(validator.NewBasicFileValidator())
 
 		// Validate file
-		if err := validatorStrategy.ValidateFile(fileName); err != nil {
-			return fmt.Errorf("file validation failed: %v", err)
+		if err := common.ValidateInput(validatorStrategy, fileName); err != nil {
+			return err
 		}
 
-		// Read file content
-		content, err := readerStrategy.ReadFile(fileName)
+		// Binary files are only modified with --binary text
+		binaryPolicy, err := common.BinaryPolicyFromFlags(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to read file: %v", err)
+			return err
+		}
+
+		// Archives are read-only unless --rewrite-archive is given
+		rewriteArchive := false
+		if !common.IsStdin(fileName) {
+			rewriteArchive, err = common.ArchiveRewriteRequested(cmd, fileName)
+			if err != nil {
+				return err
+			}
 		}
 
 		// Prepare processing options
@@ -81,77 +111,150 @@ Examples:
 			WholeWord:     wholeWord,
 			CreateBackup:  createBackup,
 			BackupDir:     backupDir,
-			DryRun:        dryRun,
+			DryRun:        dryRun || toStdout, // Standard output is written below
 			FileName:      fileName,
 			OutputFile:    outputFile,
 		}
 
-		// Display operation info
-		fmt.Printf("🔄 Replace Operation\n")
-		fmt.Printf("📄 File: %s\n", fileName)
-		fmt.Printf("🔍 Find: %s\n", findPattern)
-		fmt.Printf("🔄 Replace: %s\n", replaceWith)
+		// Display operation info; banners are only shown on a terminal and
+		// never mixed into content written to standard output
+		console := common.Console()
+		if toStdout {
+			console = io.Discard
+		}
+		displayName := fileName
+		if common.IsStdin(fileName) {
+			displayName = discovery.StdinDisplayName
+		}
+
+		fmt.Fprintf(console, "🔄 Replace Operation\n")
+		fmt.Fprintf(console, "📄 File: %s\n", displayName)
+		fmt.Fprintf(console, "🔍 Find: %s\n", findPattern)
+		fmt.Fprintf(console, "🔄 Replace: %s\n", replaceWith)
 		if regexMode {
-			fmt.Printf("🔧 Mode: Regular Expression\n")
+			fmt.Fprintf(console, "🔧 Mode: Regular Expression\n")
 		} else {
-			fmt.Printf("🔧 Mode: Literal Text\n")
+			fmt.Fprintf(console, "🔧 Mode: Literal Text\n")
 		}
-		fmt.Printf("📊 Case Sensitive: %t\n", caseSensitive)
+		fmt.Fprintf(console, "📊 Case Sensitive: %t\n", caseSensitive)
 		if wholeWord {
-			fmt.Printf("🔤 Whole Word: %t\n", wholeWord)
+			fmt.Fprintf(console, "🔤 Whole Word: %t\n", wholeWord)
 		}
 		if createBackup {
-			fmt.Printf("💾 Backup: Enabled\n")
+			fmt.Fprintf(console, "💾 Backup: Enabled\n")
 			if backupDir != "" {
-				fmt.Printf("📁 Backup Directory: %s\n", backupDir)
+				fmt.Fprintf(console, "📁 Backup Directory: %s\n", backupDir)
 			}
 		}
 		if dryRun {
-			fmt.Printf("🧪 Dry Run: Enabled (no changes will be made)\n")
+			fmt.Fprintf(console, "🧪 Dry Run: Enabled (no changes will be made)\n")
 		}
 		if outputFile != "" {
-			fmt.Printf("📤 Output File: %s\n", outputFile)
+			fmt.Fprintf(console, "📤 Output File: %s\n", outputFile)
+		}
+		if rewriteArchive {
+			fmt.Fprintf(console, "📦 Archive: members will be rewritten\n")
+		}
+		fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+
+		if rewriteArchive {
+			return replaceInArchive(fileName, findPattern, readerStrategy, processorStrategy, binaryPolicy, options)
+		}
+
+		// Read file content
+		content, err := common.ReadInputFile(fileName, readerStrategy, validatorStrategy, binaryPolicy)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %v", err)
+		}
+		if content == nil {
+			fmt.Fprintf(common.Warnings(), "⏭️  Skipping binary file '%s'\n", displayName)
+			return nil
 		}
-		fmt.Println("─────────────────────────────────────────────────────")
 
 		// Process the file
 		result, err := processorStrategy.ProcessText("replace", content, options)
 		if err != nil {
 			return fmt.Errorf("replace operation failed: %v", err)
 		}
+		if toStdout && !dryRun {
+			if err := common.WriteStdout(content, result); err != nil {
+				return fmt.Errorf("failed to write standard output: %w", err)
+			}
+		}
 
 		// Display results
-		fmt.Printf("✅ Replace operation completed successfully\n")
-		fmt.Printf("📊 Results:\n")
-		fmt.Printf("   🎯 Matches found: %d\n", result.MatchesFound)
-		fmt.Printf("   📝 Lines processed: %d\n", result.LinesProcessed)
-		fmt.Printf("   ⏱️  Execution time: %v\n", result.ExecutionTime)
+		fmt.Fprintf(console, "✅ Replace operation completed successfully\n")
+		fmt.Fprintf(console, "📊 Results:\n")
+		fmt.Fprintf(console, "   🎯 Matches found: %d\n", result.MatchesFound)
+		fmt.Fprintf(console, "   📝 Lines processed: %d\n", result.LinesProcessed)
+		fmt.Fprintf(console, "   ⏱️  Execution time: %v\n", result.ExecutionTime)
 
 		if result.BackupPath != "" {
-			fmt.Printf("   💾 Backup created: %s\n", result.BackupPath)
+			fmt.Fprintf(console, "   💾 Backup created: %s\n", result.BackupPath)
 		}
 
 		if dryRun {
-			fmt.Printf("   🧪 Dry run completed - no changes were made\n")
+			fmt.Fprintf(console, "   🧪 Dry run completed - no changes were made\n")
 			if result.MatchesFound > 0 {
-				fmt.Printf("   ℹ️  Run without --dry-run to apply changes\n")
+				fmt.Fprintf(console, "   ℹ️  Run without --dry-run to apply changes\n")
 			}
 		} else {
 			outputTarget := fileName
 			if outputFile != "" {
 				outputTarget = outputFile
+			} else if toStdout {
+				outputTarget = "(standard output)"
 			}
-			fmt.Printf("   📄 Modified file: %s\n", outputTarget)
+			fmt.Fprintf(console, "   📄 Modified file: %s\n", outputTarget)
 		}
 
 		if result.MatchesFound == 0 {
-			fmt.Printf("   ℹ️  No matches found for pattern '%s'\n", findPattern)
+			fmt.Fprintf(console, "   ℹ️  No matches found for pattern '%s'\n", findPattern)
 		}
 
 		return nil
 	},
 }
 
+// replaceInArchive applies the replacement to every member of an archive
+// and rebuilds the archive with the changed members.
+func replaceInArchive(fileName, findPattern string, readerStrategy *reader.FileReaderStrategy, processorStrategy *processor.TextProcessorStrategy, binaryPolicy validator.BinaryPolicy, options processor.ProcessOptions) error {
+	result, err := common.RewriteArchive(fileName, readerStrategy, binaryPolicy, options, func(content *reader.FileContent, options processor.ProcessOptions) (*processor.ProcessingResult, error) {
+		return processorStrategy.ProcessText("replace", content, options)
+	})
+	if err != nil {
+		return fmt.Errorf("replace operation failed: %v", err)
+	}
+
+	// Display results
+	console := common.Console()
+	fmt.Fprintf(console, "✅ Replace operation completed successfully\n")
+	fmt.Fprintf(console, "📊 Results:\n")
+	fmt.Fprintf(console, "   📦 Members processed: %d\n", result.Members)
+	fmt.Fprintf(console, "   ✏️  Members changed: %d\n", result.ChangedMembers)
+	fmt.Fprintf(console, "   🎯 Matches found: %d\n", result.MatchesFound)
+	fmt.Fprintf(console, "   📝 Lines processed: %d\n", result.LinesProcessed)
+
+	if result.BackupPath != "" {
+		fmt.Fprintf(console, "   💾 Backup created: %s\n", result.BackupPath)
+	}
+
+	if options.DryRun {
+		fmt.Fprintf(console, "   🧪 Dry run completed - no changes were made\n")
+		if result.ChangedMembers > 0 {
+			fmt.Fprintf(console, "   ℹ️  Run without --dry-run to apply changes\n")
+		}
+	} else if result.ChangedMembers > 0 {
+		fmt.Fprintf(console, "   📄 Modified archive: %s\n", fileName)
+	}
+
+	if result.MatchesFound == 0 {
+		fmt.Fprintf(console, "   ℹ️  No matches found for pattern '%s'\n", findPattern)
+	}
+
+	return nil
+}
+
 // init function registers the replace command and its flags.
 func init() {
 	cmd.RootCmd.AddCommand(replaceCmd)
@@ -159,17 +262,19 @@ func init() {
 	// Add flags for replace options
 	replaceCmd.Flags().StringP("find", "f", "", "Text pattern to find (required)")
 	replaceCmd.Flags().StringP("replace", "r", "", "Replacement text (required)")
-	replaceCmd.Flags().String("file", "", "File to process (required)")
+	replaceCmd.Flags().String("file", "", "File to process (default: standard input)")
 	replaceCmd.Flags().Bool("regex", false, "Use regular expression mode")
 	replaceCmd.Flags().BoolP("case-sensitive", "c", false, "Case sensitive replacement")
 	replaceCmd.Flags().BoolP("whole-word", "w", false, "Match whole words only")
 	replaceCmd.Flags().BoolP("backup", "b", false, "Create backup before modification")
 	replaceCmd.Flags().String("backup-dir", "", "Directory for backup files (default: same as original)")
 	replaceCmd.Flags().Bool("dry-run", false, "Preview changes without modifying files")
-	replaceCmd.Flags().StringP("output", "o", "", "Output file (default: overwrite input file)")
+	replaceCmd.Flags().StringP("output", "o", "", "Output file, - for standard output (default: overwrite input file)")
+	common.AddReadFlags(replaceCmd)
+	common.AddArchiveFlags(replaceCmd)
+	common.AddBinaryFlag(replaceCmd, true)
 
 	// Mark required flags
 	replaceCmd.MarkFlagRequired("find")
 	replaceCmd.MarkFlagRequired("replace")
-	replaceCmd.MarkFlagRequired("file")
 }
@@ -3,12 +3,18 @@
 package process
 
 import (
+	"errors"
 	"fmt"
-	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/kcansari/optix/cmd"
+	"github.com/kcansari/optix/cmd/commands/common"
+	"github.com/kcansari/optix/internal/discovery"
 	"github.com/kcansari/optix/internal/processor"
-	"github.com/kcansari/optix/internal/reader"
+	"github.com/kcansari/optix/internal/processor/strategies"
+	"github.com/kcansari/optix/internal/types"
 	"github.com/kcansari/optix/internal/validator"
 	"github.com/spf13/cobra"
 )
@@ -25,13 +31,24 @@ The search command supports:
   - Case-sensitive and case-insensitive searches
   - Whole word matching
   - Context lines around matches
+  - Multi-line records such as stack traces (--record-start)
   - Multiple file processing with glob patterns
+  - Searching inside zip and tar archives (bundle.tar.gz!/path:line)
+  - Searching standard input (no --files, or --files -)
+  - Following files as they grow, like tail -f (--follow)
+
+When the output is not a terminal only the matching lines are printed.
 
 Examples:
   optix search --pattern "error" --files "*.log"
   optix search --pattern "user\d+" --regex --files "data.txt"
   optix search --pattern "TODO" --context 2 --files "*.go"
-  optix search --pattern "config" --whole-word --files "*.json"`,
+  optix search --pattern "config" --whole-word --files "*.json"
+  optix search --pattern "panic" --files "bundle.tar.gz"
+  optix search --pattern "NullPointerException" --record-start java --files "*.log"
+  optix search --pattern "timeout" --files "support.zip!/var/log/*.log"
+  optix search --pattern "ERROR" --files "/var/log/app/*.log" --follow
+  kubectl logs my-pod | optix search --pattern "error"`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get flag values
@@ -41,63 +58,131 @@ Examples:
 		caseSensitive, _ := cmd.Flags().GetBool("case-sensitive")
 		wholeWord, _ := cmd.Flags().GetBool("whole-word")
 		contextLines, _ := cmd.Flags().GetInt("context")
+		recordStart, err := common.RecordStartFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		binaryPolicy, err := common.BinaryPolicyFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		followMode, pollInterval, err := common.FollowOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
 
 		// Validate required flags
 		if pattern == "" {
 			return fmt.Errorf("pattern is required (use --pattern flag)")
 		}
-		if files == "" {
-			return fmt.Errorf("files pattern is required (use --files flag)")
+		if files == "" && !common.StdinPiped() {
+			return fmt.Errorf("files pattern is required (use --files flag or pipe input to standard input)")
 		}
 
-		// Find matching files
-		matchingFiles, err := filepath.Glob(files)
-		if err != nil {
-			return fmt.Errorf("invalid file pattern '%s': %w", files, err)
+		// Find matching files; archives are expanded into their members
+		var targets []discovery.Target
+		fromStdin := common.IsStdin(files)
+		if fromStdin {
+			files = discovery.StdinDisplayName
+		} else {
+			targets, err = discovery.Expand(files)
+			if err != nil {
+				return err
+			}
+
+			if len(targets) == 0 {
+				return fmt.Errorf("no files found matching pattern '%s'", files)
+			}
 		}
 
-		if len(matchingFiles) == 0 {
-			return fmt.Errorf("no files found matching pattern '%s'", files)
+		// Only plain files can be followed
+		var followPaths []string
+		if followMode {
+			if fromStdin {
+				return common.ValidateFollowTarget(discovery.StdinName)
+			}
+			for _, target := range targets {
+				if target.MemberPattern != "" {
+					return fmt.Errorf("cannot follow archive members ('%s')", files)
+				}
+				if err := common.ValidateFollowTarget(target.Path); err != nil {
+					return err
+				}
+				followPaths = append(followPaths, target.Path)
+			}
 		}
 
 		// Create processor strategy
-		processorStrategy := processor.NewTextProcessorStrategy()
-		readerStrategy := reader.NewFileReaderStrategy()
+		processorStrategy := strategies.NewDefaultTextProcessorStrategy()
+		readerStrategy, err := common.NewReaderStrategy(cmd)
+		if err != nil {
+			return err
+		}
 		validatorStrategy := validator.NewValidatorStrategy(validator.NewBasicFileValidator())
 
 		totalMatches := 0
 		totalFiles := 0
 
-		fmt.Printf("🔍 Searching for pattern: %s\n", pattern)
-		fmt.Printf("📁 Files: %s\n", files)
+		// Banners and the summary are only shown on a terminal
+		console := common.Console()
+		warnings := common.Warnings()
+		interactive := common.Interactive()
+
+		fmt.Fprintf(console, "🔍 Searching for pattern: %s\n", pattern)
+		fmt.Fprintf(console, "📁 Files: %s\n", files)
 		if regexMode {
-			fmt.Printf("🔧 Mode: Regular Expression\n")
+			fmt.Fprintf(console, "🔧 Mode: Regular Expression\n")
 		} else {
-			fmt.Printf("🔧 Mode: Literal Text\n")
+			fmt.Fprintf(console, "🔧 Mode: Literal Text\n")
 		}
-		fmt.Printf("📊 Case Sensitive: %t\n", caseSensitive)
+		fmt.Fprintf(console, "📊 Case Sensitive: %t\n", caseSensitive)
 		if wholeWord {
-			fmt.Printf("🔤 Whole Word: %t\n", wholeWord)
+			fmt.Fprintf(console, "🔤 Whole Word: %t\n", wholeWord)
 		}
 		if contextLines > 0 {
-			fmt.Printf("📄 Context Lines: %d\n", contextLines)
+			fmt.Fprintf(console, "📄 Context Lines: %d\n", contextLines)
+		}
+		if recordStart != "" {
+			fmt.Fprintf(console, "🧱 Records: %s\n", recordStart)
 		}
-		fmt.Println("─────────────────────────────────────────────────────")
+		if followMode {
+			fmt.Fprintf(console, "👀 Following: new lines of %d file(s), press Ctrl+C to stop\n", len(followPaths))
+		}
+		fmt.Fprintln(console, "─────────────────────────────────────────────────────")
 
-		// Process each file
-		for _, fileName := range matchingFiles {
-			// Validate file
-			if err := validatorStrategy.ValidateFile(fileName); err != nil {
-				fmt.Printf("❌ Skipping '%s': %v\n", fileName, err)
-				continue
+		if followMode {
+			options := processor.ProcessOptions{
+				Pattern:       pattern,
+				RegexMode:     regexMode,
+				CaseSensitive: caseSensitive,
+				WholeWord:     wholeWord,
+				ContextLines:  contextLines,
+				RecordStart:   recordStart,
 			}
+			return followSearch(followPaths, pollInterval, options, processorStrategy)
+		}
 
-			// Read file content
-			content, err := readerStrategy.ReadFile(fileName)
+		// Process each file, or each member of an archive
+		filesProcessed := 0
+		binarySkipped := 0
+		searchEntry := func(entry discovery.Entry) error {
+			filesProcessed++
+
+			// Read file content, applying the binary file policy
+			content, binary, err := common.ReadEntry(entry, readerStrategy, validatorStrategy, binaryPolicy)
 			if err != nil {
-				fmt.Printf("❌ Failed to read '%s': %v\n", fileName, err)
-				continue
+				var binaryErr *validator.BinaryFileError
+				if errors.As(err, &binaryErr) {
+					return err
+				}
+				fmt.Fprintf(warnings, "❌ Failed to read '%s': %v\n", entry.Name, err)
+				return nil
 			}
+			if content == nil {
+				binarySkipped++
+				return nil
+			}
+			common.ReportInvalidLines(warnings, entry.Name, content)
 
 			// Prepare processing options
 			options := processor.ProcessOptions{
@@ -106,56 +191,175 @@ Examples:
 				CaseSensitive: caseSensitive,
 				WholeWord:     wholeWord,
 				ContextLines:  contextLines,
-				FileName:      fileName,
+				RecordStart:   recordStart,
+				FileName:      entry.Name,
 			}
 
 			// Process the file
 			result, err := processorStrategy.ProcessText("search", content, options)
 			if err != nil {
-				fmt.Printf("❌ Search failed for '%s': %v\n", fileName, err)
-				continue
+				fmt.Fprintf(warnings, "❌ Search failed for '%s': %v\n", entry.Name, err)
+				return nil
 			}
 
-			// Display results
-			if result.MatchesFound > 0 {
-				fmt.Printf("\n📄 %s (%d matches)\n", fileName, result.MatchesFound)
-				totalMatches += result.MatchesFound
-				totalFiles++
+			if result.MatchesFound == 0 {
+				return nil
+			}
+			totalMatches += result.MatchesFound
+			totalFiles++
+
+			// Binary content is not printed, like grep
+			if binary && binaryPolicy == validator.BinaryReport {
+				if interactive {
+					fmt.Printf("\n📄 Binary file %s matches\n", entry.Name)
+				} else {
+					fmt.Printf("Binary file %s matches\n", entry.Name)
+				}
+				return nil
+			}
+
+			// Display results as name:line: text; piped output has no
+			// headers and leaves out the name for standard input
+			prefix := entry.Name + ":"
+			indent := ""
+			if interactive {
+				fmt.Printf("\n📄 %s (%d matches)\n", entry.Name, result.MatchesFound)
+				indent = "   "
+			} else if entry.IsStdin() {
+				prefix = ""
+			}
 
-				// For now, we'll display a summary. In a full implementation,
-				// we'd want to return the actual SearchResult objects and display them
-				fmt.Printf("   ✅ Found %d matches in %d lines\n", result.MatchesFound, result.LinesProcessed)
+			printSearchMatches(result.SearchResults, prefix, indent, interactive)
+			return nil
+		}
+
+		// Standard input is searched when no files are given
+		if fromStdin {
+			entry, err := common.InputEntry(discovery.StdinName)
+			if err != nil {
+				return err
+			}
+			if err := searchEntry(entry); err != nil {
+				return err
+			}
+		}
+
+		for _, target := range targets {
+			// Validate file
+			if err := validatorStrategy.ValidateFile(target.Path); err != nil {
+				fmt.Fprintf(warnings, "❌ Skipping '%s': %v\n", target.Path, err)
+				continue
+			}
+
+			if err := discovery.Walk(target, searchEntry); err != nil {
+				var binaryErr *validator.BinaryFileError
+				if errors.As(err, &binaryErr) {
+					return err
+				}
+				fmt.Fprintf(warnings, "❌ Failed to read '%s': %v\n", target.Path, err)
 			}
 		}
 
 		// Display summary
-		fmt.Println("\n─────────────────────────────────────────────────────")
-		fmt.Printf("📊 Search Summary:\n")
-		fmt.Printf("   🎯 Total matches: %d\n", totalMatches)
-		fmt.Printf("   📁 Files with matches: %d\n", totalFiles)
-		fmt.Printf("   📝 Files processed: %d\n", len(matchingFiles))
+		fmt.Fprintln(console, "\n─────────────────────────────────────────────────────")
+		fmt.Fprintf(console, "📊 Search Summary:\n")
+		fmt.Fprintf(console, "   🎯 Total matches: %d\n", totalMatches)
+		fmt.Fprintf(console, "   📁 Files with matches: %d\n", totalFiles)
+		fmt.Fprintf(console, "   📝 Files processed: %d\n", filesProcessed)
+		if binarySkipped > 0 {
+			fmt.Fprintf(console, "   ⏭️  Binary files skipped: %d\n", binarySkipped)
+		}
 
 		if totalMatches == 0 {
-			fmt.Printf("   ℹ️  No matches found for pattern '%s'\n", pattern)
+			fmt.Fprintf(console, "   ℹ️  No matches found for pattern '%s'\n", pattern)
 		}
 
 		return nil
 	},
 }
 
+// printSearchMatches prints matches as name:line: text after the prefix
+// and indent. Later lines of a multi-line record are aligned under the
+// first on a terminal and numbered name:line- text when piped.
+func printSearchMatches(matches []types.SearchResult, prefix, indent string, interactive bool) {
+	for _, match := range matches {
+		for i, line := range strings.Split(match.Line, "\n") {
+			if i == 0 {
+				fmt.Printf("%s%s%d: %s\n", indent, prefix, match.LineNumber, line)
+			} else if interactive {
+				fmt.Printf("%s%*s  %s\n", indent, len(prefix)+len(strconv.Itoa(match.LineNumber)), "", line)
+			} else {
+				fmt.Printf("%s%d- %s\n", prefix, match.LineNumber+i, line)
+			}
+		}
+		for _, contextLine := range match.Context {
+			for _, line := range strings.Split(contextLine, "\n") {
+				fmt.Printf("%s   │ %s\n", indent, line)
+			}
+		}
+	}
+}
+
+// followSearch searches the lines appended to the files until Ctrl+C and
+// then prints a summary. Context lines come from the same batch of new
+// lines only.
+func followSearch(paths []string, interval time.Duration, options processor.ProcessOptions, processorStrategy *processor.TextProcessorStrategy) error {
+	startTime := time.Now()
+	totalMatches := 0
+	linesSearched := 0
+
+	err := common.FollowFiles(paths, interval, options.RecordStart, func(batch common.FollowBatch) error {
+		batchOptions := options
+		batchOptions.FileName = batch.Name
+		result, err := processorStrategy.ProcessText("search", batch.Content(), batchOptions)
+		if err != nil {
+			return fmt.Errorf("search failed for '%s': %w", batch.Name, err)
+		}
+		linesSearched += len(batch.Lines)
+		totalMatches += result.MatchesFound
+
+		// Numbers within the batch become line numbers of the file
+		for i := range result.SearchResults {
+			result.SearchResults[i].LineNumber += batch.FirstLine - 1
+		}
+		prefix := ""
+		if len(paths) > 1 {
+			prefix = batch.Name + ":"
+		}
+		printSearchMatches(result.SearchResults, prefix, "", false)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The summary follows Ctrl+C, so it also reaches the terminal when the
+	// matches are piped
+	summary := common.Warnings()
+	fmt.Fprintln(summary, "\n─────────────────────────────────────────────────────")
+	fmt.Fprintf(summary, "📊 Follow Summary:\n")
+	fmt.Fprintf(summary, "   🎯 Total matches: %d\n", totalMatches)
+	fmt.Fprintf(summary, "   📝 New lines searched: %d\n", linesSearched)
+	fmt.Fprintf(summary, "   ⏱️  Followed for: %v\n", time.Since(startTime).Round(time.Second))
+	return nil
+}
+
 // init function registers the search command and its flags.
 func init() {
 	cmd.RootCmd.AddCommand(searchCmd)
 
 	// Add flags for search options
 	searchCmd.Flags().StringP("pattern", "p", "", "Search pattern (required)")
-	searchCmd.Flags().StringP("files", "f", "", "File pattern to search (required, supports glob)")
+	searchCmd.Flags().StringP("files", "f", "", "File pattern to search (supports glob, default: standard input)")
 	searchCmd.Flags().BoolP("regex", "r", false, "Use regular expression mode")
 	searchCmd.Flags().BoolP("case-sensitive", "c", false, "Case sensitive search")
 	searchCmd.Flags().BoolP("whole-word", "w", false, "Match whole words only")
 	searchCmd.Flags().IntP("context", "C", 0, "Number of context lines to show around matches")
+	common.AddRecordFlag(searchCmd)
+	common.AddFollowFlags(searchCmd)
+	common.AddReadFlags(searchCmd)
+	common.AddBinaryFlag(searchCmd, false)
 
 	// Mark required flags
 	searchCmd.MarkFlagRequired("pattern")
-	searchCmd.MarkFlagRequired("files")
 }
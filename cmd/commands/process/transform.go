@@ -4,10 +4,14 @@ package process
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/kcansari/optix/cmd"
+	"github.com/kcansari/optix/cmd/commands/common"
+	"github.com/kcansari/optix/internal/discovery"
 	"github.com/kcansari/optix/internal/processor"
+	"github.com/kcansari/optix/internal/processor/strategies"
 	"github.com/kcansari/optix/internal/reader"
 	"github.com/kcansari/optix/internal/validator"
 	"github.com/spf13/cobra"
@@ -23,64 +27,102 @@ var transformCmd = &cobra.Command{
 The transform command supports:
   - Case conversion (upper, lower, title)
   - Whitespace cleanup (trim)
+  - Line-ending conversion (eol-lf, eol-crlf)
   - Output to file or overwrite original
   - Dry run mode to preview changes
+  - Rewriting members of zip and tar archives (--rewrite-archive)
 
 Available transformations:
   - upper: Convert all text to uppercase
   - lower: Convert all text to lowercase
   - title: Convert text to title case
   - trim:  Remove leading/trailing whitespace from each line
+  - eol-lf:   Convert line endings to LF (Unix)
+  - eol-crlf: Convert line endings to CRLF (Windows)
+
+Line endings, a missing final newline, the character encoding and the
+byte order mark of the file are otherwise preserved; in a file with mixed
+line endings, every line keeps its own.
+
+Without --file, or with --file -, standard input is transformed and the
+result is written to standard output (also with --output -).
+
+The transformation is chosen with --mode; --type chooses the input type,
+like in the other commands. Transformations given with --type are still
+accepted for compatibility.
 
 Examples:
-  optix transform --type upper --file document.txt
-  optix transform --type lower --file README.md --output readme.md
-  optix transform --type trim --file data.csv --dry-run
-  optix transform --type title --file notes.txt`,
+  optix transform --mode upper --file document.txt
+  optix transform --mode lower --file README.md --output readme.md
+  optix transform --mode trim --file data.csv --dry-run
+  optix transform --mode title --file notes.txt
+  optix transform --mode eol-lf --file windows-export.csv
+  cat payload | optix transform --mode upper --type json
+  cat names.txt | optix transform --mode upper | sort`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get flag values
-		transformType, _ := cmd.Flags().GetString("type")
+		transformType, _ := cmd.Flags().GetString("mode")
 		fileName, _ := cmd.Flags().GetString("file")
 		outputFile, _ := cmd.Flags().GetString("output")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 
+		// --type used to select the transformation; accept those values
+		// rather than reading them as an input type
+		if inputType, _ := cmd.Flags().GetString("type"); transformType == "" && isTransformation(inputType) {
+			fmt.Fprintf(common.Warnings(), "⚠️  --type %s is deprecated for transformations, use --mode %s\n", inputType, inputType)
+			transformType = inputType
+			if err := cmd.Flags().Set("type", ""); err != nil {
+				return err
+			}
+		}
+
 		// Validate required flags
 		if transformType == "" {
-			return fmt.Errorf("transformation type is required (use --type flag)")
+			return fmt.Errorf("transformation type is required (use --mode flag)")
 		}
-		if fileName == "" {
-			return fmt.Errorf("file is required (use --file flag)")
+		if fileName == "" && !common.StdinPiped() {
+			return fmt.Errorf("file is required (use --file flag or pipe input to standard input)")
 		}
 
-		// Validate transformation type
-		validTypes := []string{"upper", "lower", "title", "trim"}
-		isValid := false
-		for _, validType := range validTypes {
-			if strings.ToLower(transformType) == validType {
-				isValid = true
-				break
-			}
+		// Results for standard input go to standard output unless --output is given
+		toStdout := common.WritesStdout(fileName, outputFile)
+		if toStdout {
+			outputFile = ""
 		}
-		if !isValid {
+
+		// Validate transformation type
+		if !isTransformation(transformType) {
 			return fmt.Errorf("invalid transformation type '%s'. Valid types: %s",
-				transformType, strings.Join(validTypes, ", "))
+				transformType, strings.Join(transformations, ", "))
 		}
 
 		// Create processor strategy
-		processorStrategy := processor.NewTextProcessorStrategy()
-		readerStrategy := reader.NewFileReaderStrategy()
+		processorStrategy := strategies.NewDefaultTextProcessorStrategy()
+		readerStrategy, err := common.NewReaderStrategy(cmd)
+		if err != nil {
+			return err
+		}
 		validatorStrategy := validator.NewValidatorStrategy(validator.NewBasicFileValidator())
 
 		// Validate file
-		if err := validatorStrategy.ValidateFile(fileName); err != nil {
-			return fmt.Errorf("file validation failed: %v", err)
+		if err := common.ValidateInput(validatorStrategy, fileName); err != nil {
+			return err
 		}
 
-		// Read file content
-		content, err := readerStrategy.ReadFile(fileName)
+		// Binary files are only modified with --binary text
+		binaryPolicy, err := common.BinaryPolicyFromFlags(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to read file: %v", err)
+			return err
+		}
+
+		// Archives are read-only unless --rewrite-archive is given
+		rewriteArchive := false
+		if !common.IsStdin(fileName) {
+			rewriteArchive, err = common.ArchiveRewriteRequested(cmd, fileName)
+			if err != nil {
+				return err
+			}
 		}
 
 		// Prepare processing options
@@ -88,33 +130,66 @@ Examples:
 			TransformType: strings.ToLower(transformType),
 			FileName:      fileName,
 			OutputFile:    outputFile,
-			DryRun:        dryRun,
+			DryRun:        dryRun || toStdout, // Standard output is written below
+		}
+
+		// Display operation info; banners are only shown on a terminal and
+		// never mixed into content written to standard output
+		console := common.Console()
+		if toStdout {
+			console = io.Discard
+		}
+		displayName := fileName
+		if common.IsStdin(fileName) {
+			displayName = discovery.StdinDisplayName
 		}
 
-		// Display operation info
-		fmt.Printf("🔄 Transform Operation\n")
-		fmt.Printf("📄 File: %s\n", fileName)
-		fmt.Printf("🔧 Transform Type: %s\n", transformType)
+		fmt.Fprintf(console, "🔄 Transform Operation\n")
+		fmt.Fprintf(console, "📄 File: %s\n", displayName)
+		fmt.Fprintf(console, "🔧 Transform Type: %s\n", transformType)
 		if dryRun {
-			fmt.Printf("🧪 Dry Run: Enabled (no changes will be made)\n")
+			fmt.Fprintf(console, "🧪 Dry Run: Enabled (no changes will be made)\n")
 		}
 		if outputFile != "" {
-			fmt.Printf("📤 Output File: %s\n", outputFile)
+			fmt.Fprintf(console, "📤 Output File: %s\n", outputFile)
+		} else if rewriteArchive {
+			fmt.Fprintf(console, "📦 Output: Rewrite archive members\n")
+		} else if toStdout {
+			fmt.Fprintf(console, "📤 Output: Standard output\n")
 		} else {
-			fmt.Printf("📤 Output: Overwrite original file\n")
+			fmt.Fprintf(console, "📤 Output: Overwrite original file\n")
+		}
+		fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+
+		if rewriteArchive {
+			return transformArchive(fileName, readerStrategy, processorStrategy, binaryPolicy, options)
+		}
+
+		// Read file content
+		content, err := common.ReadInputFile(fileName, readerStrategy, validatorStrategy, binaryPolicy)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %v", err)
+		}
+		if content == nil {
+			fmt.Fprintf(common.Warnings(), "⏭️  Skipping binary file '%s'\n", displayName)
+			return nil
 		}
-		fmt.Println("─────────────────────────────────────────────────────")
 
 		// Process the file
 		result, err := processorStrategy.ProcessText("transform", content, options)
 		if err != nil {
 			return fmt.Errorf("transform operation failed: %v", err)
 		}
+		if toStdout && !dryRun {
+			if err := common.WriteStdout(content, result); err != nil {
+				return fmt.Errorf("failed to write standard output: %w", err)
+			}
+		}
 
 		// Display preview for dry run
 		if dryRun {
-			fmt.Printf("🧪 Dry Run Preview:\n")
-			fmt.Println("─────────────────────────────────────────────────────")
+			fmt.Fprintf(console, "🧪 Dry Run Preview:\n")
+			fmt.Fprintln(console, "─────────────────────────────────────────────────────")
 
 			// Show first few lines of transformed content
 			lines := strings.Split(result.ModifiedContent, "\n")
@@ -133,35 +208,41 @@ Examples:
 				fmt.Printf("... and %d more lines\n", len(lines)-previewLines)
 			}
 
-			fmt.Println("─────────────────────────────────────────────────────")
+			fmt.Fprintln(console, "─────────────────────────────────────────────────────")
 		}
 
 		// Display results
-		fmt.Printf("✅ Transform operation completed successfully\n")
-		fmt.Printf("📊 Results:\n")
-		fmt.Printf("   📝 Lines processed: %d\n", result.LinesProcessed)
-		fmt.Printf("   ⏱️  Execution time: %v\n", result.ExecutionTime)
+		fmt.Fprintf(console, "✅ Transform operation completed successfully\n")
+		fmt.Fprintf(console, "📊 Results:\n")
+		fmt.Fprintf(console, "   📝 Lines processed: %d\n", result.LinesProcessed)
+		fmt.Fprintf(console, "   ⏱️  Execution time: %v\n", result.ExecutionTime)
 
 		if dryRun {
-			fmt.Printf("   🧪 Dry run completed - no changes were made\n")
-			fmt.Printf("   ℹ️  Run without --dry-run to apply transformation\n")
+			fmt.Fprintf(console, "   🧪 Dry run completed - no changes were made\n")
+			fmt.Fprintf(console, "   ℹ️  Run without --dry-run to apply transformation\n")
 		} else {
 			outputTarget := fileName
 			if outputFile != "" {
 				outputTarget = outputFile
+			} else if toStdout {
+				outputTarget = "(standard output)"
 			}
-			fmt.Printf("   📄 Transformed file: %s\n", outputTarget)
+			fmt.Fprintf(console, "   📄 Transformed file: %s\n", outputTarget)
 
 			// Show transformation summary
 			switch strings.ToLower(transformType) {
 			case "upper":
-				fmt.Printf("   🔤 All text converted to UPPERCASE\n")
+				fmt.Fprintf(console, "   🔤 All text converted to UPPERCASE\n")
 			case "lower":
-				fmt.Printf("   🔤 All text converted to lowercase\n")
+				fmt.Fprintf(console, "   🔤 All text converted to lowercase\n")
 			case "title":
-				fmt.Printf("   🔤 All text converted to Title Case\n")
+				fmt.Fprintf(console, "   🔤 All text converted to Title Case\n")
 			case "trim":
-				fmt.Printf("   ✂️  Whitespace trimmed from all lines\n")
+				fmt.Fprintf(console, "   ✂️  Whitespace trimmed from all lines\n")
+			case "eol-lf":
+				fmt.Fprintf(console, "   ↩️  Line endings converted to LF\n")
+			case "eol-crlf":
+				fmt.Fprintf(console, "   ↩️  Line endings converted to CRLF\n")
 			}
 		}
 
@@ -169,17 +250,57 @@ Examples:
 	},
 }
 
+// transformations lists the values accepted by --mode.
+var transformations = []string{"upper", "lower", "title", "trim", "eol-lf", "eol-crlf"}
+
+// isTransformation reports whether name is one of the transformations.
+func isTransformation(name string) bool {
+	for _, transformation := range transformations {
+		if strings.ToLower(name) == transformation {
+			return true
+		}
+	}
+	return false
+}
+
+// transformArchive applies the transformation to every member of an
+// archive and rebuilds the archive with the changed members.
+func transformArchive(fileName string, readerStrategy *reader.FileReaderStrategy, processorStrategy *processor.TextProcessorStrategy, binaryPolicy validator.BinaryPolicy, options processor.ProcessOptions) error {
+	result, err := common.RewriteArchive(fileName, readerStrategy, binaryPolicy, options, func(content *reader.FileContent, options processor.ProcessOptions) (*processor.ProcessingResult, error) {
+		return processorStrategy.ProcessText("transform", content, options)
+	})
+	if err != nil {
+		return fmt.Errorf("transform operation failed: %v", err)
+	}
+
+	// Display results
+	console := common.Console()
+	fmt.Fprintf(console, "✅ Transform operation completed successfully\n")
+	fmt.Fprintf(console, "📊 Results:\n")
+	fmt.Fprintf(console, "   📦 Members processed: %d\n", result.Members)
+	fmt.Fprintf(console, "   ✏️  Members changed: %d\n", result.ChangedMembers)
+	fmt.Fprintf(console, "   📝 Lines processed: %d\n", result.LinesProcessed)
+
+	if options.DryRun {
+		fmt.Fprintf(console, "   🧪 Dry run completed - no changes were made\n")
+		fmt.Fprintf(console, "   ℹ️  Run without --dry-run to apply transformation\n")
+	} else if result.ChangedMembers > 0 {
+		fmt.Fprintf(console, "   📄 Transformed archive: %s\n", fileName)
+	}
+
+	return nil
+}
+
 // init function registers the transform command and its flags.
 func init() {
 	cmd.RootCmd.AddCommand(transformCmd)
 
 	// Add flags for transform options
-	transformCmd.Flags().StringP("type", "t", "", "Transformation type: upper, lower, title, trim (required)")
-	transformCmd.Flags().String("file", "", "File to transform (required)")
-	transformCmd.Flags().StringP("output", "o", "", "Output file (default: overwrite input file)")
+	transformCmd.Flags().StringP("mode", "t", "", "Transformation type: upper, lower, title, trim, eol-lf, eol-crlf (required)")
+	transformCmd.Flags().String("file", "", "File to transform (default: standard input)")
+	transformCmd.Flags().StringP("output", "o", "", "Output file, - for standard output (default: overwrite input file)")
 	transformCmd.Flags().Bool("dry-run", false, "Preview transformation without modifying files")
-
-	// Mark required flags
-	transformCmd.MarkFlagRequired("type")
-	transformCmd.MarkFlagRequired("file")
+	common.AddReadFlags(transformCmd)
+	common.AddArchiveFlags(transformCmd)
+	common.AddBinaryFlag(transformCmd, true)
 }
@@ -0,0 +1,22 @@
+// Package scan contains the CLI commands that scan files for problems.
+// This file implements the 'scan' parent command.
+package scan
+
+import (
+	"github.com/kcansari/optix/cmd"
+	"github.com/spf13/cobra"
+)
+
+// scanCmd groups the scanning subcommands.
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Scan files for problems such as leaked secrets",
+	Long: `Scan files, directories and archives for problems such as leaked
+secrets. Directories are scanned recursively, leaving out version control
+directories, and binary files are skipped.`,
+}
+
+// init registers the scan command with the root command.
+func init() {
+	cmd.RootCmd.AddCommand(scanCmd)
+}
@@ -0,0 +1,307 @@
+// Package scan contains the CLI commands that scan files for problems.
+// This file implements the 'scan secrets' command that finds leaked credentials.
+package scan
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kcansari/optix/cmd/commands/common"
+	"github.com/kcansari/optix/internal/discovery"
+	"github.com/kcansari/optix/internal/secrets"
+	"github.com/kcansari/optix/internal/validator"
+	"github.com/kcansari/optix/internal/version"
+	"github.com/kcansari/optix/internal/writer"
+	"github.com/spf13/cobra"
+)
+
+// skippedDirectories are the directories left out when scanning a tree.
+var skippedDirectories = map[string]bool{".git": true, ".hg": true, ".svn": true}
+
+// scanSecretsCmd represents the scan secrets command.
+// This command finds credentials such as API keys in files.
+var scanSecretsCmd = &cobra.Command{
+	Use:   "secrets [PATH...]",
+	Short: "Find leaked credentials such as API keys and private keys",
+	Long: `Find credentials in files: AWS keys, private key headers, GitHub,
+Slack, Stripe and Google tokens, JSON Web Tokens, and secrets assigned in
+code or configuration such as password = "...".
+
+Paths may be files, directories (scanned recursively), glob patterns,
+archives and archive members (bundle.zip!/config/*), or - for standard
+input. Without paths the current directory is scanned.
+
+Every finding has an entropy score, in bits per character; assignment
+rules only report values random enough to be real secrets, so that
+placeholders such as "changeme" are left out. Secrets are always redacted
+in the output.
+
+Findings are suppressed by:
+  - an "optix:ignore" comment on the line
+  - an allowlist file (--allowlist) with one entry per line:
+      fingerprint:3f9a0c1d2e4b5a69   a finding, as printed with it
+      path:testdata/                 files under a directory
+      path:*.md                      files matching a glob
+      rule:jwt                       every finding of a rule
+      secret:EXAMPLE$                secrets matching a regular expression
+
+More rules are added with --rules, a YAML file:
+  rules:
+    - id: internal-api-key
+      description: Internal API key
+      severity: error
+      pattern: '\bik_(?P<secret>[a-z0-9]{32})\b'
+      min_entropy: 3
+
+Added rules run before the generic rule for secret-like assignments.
+
+The report is text, JSON or SARIF for code scanning dashboards (--format,
+or the --output extension). With --fail the command exits with an error
+when secrets are found, for CI.
+
+Examples:
+  optix scan secrets
+  optix scan secrets src/ config/ --allowlist .secrets-allowlist
+  optix scan secrets --format sarif --output secrets.sarif --fail
+  optix scan secrets "deploy/*.yaml" --rules company-rules.yaml
+  optix scan secrets --list-rules
+  git diff | optix scan secrets -`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get flag values
+		outputFormat, _ := cmd.Flags().GetString("format")
+		outputFile, _ := cmd.Flags().GetString("output")
+		rulesFile, _ := cmd.Flags().GetString("rules")
+		allowlistFile, _ := cmd.Flags().GetString("allowlist")
+		listRules, _ := cmd.Flags().GetBool("list-rules")
+		failOnFindings, _ := cmd.Flags().GetBool("fail")
+
+		// Set up the scanner
+		scanner := secrets.NewDefaultScanner()
+		if rulesFile != "" {
+			rules, err := secrets.LoadRules(rulesFile)
+			if err != nil {
+				return err
+			}
+			for _, rule := range rules {
+				scanner.AddRule(rule)
+			}
+		}
+		if allowlistFile != "" {
+			allowlist, err := secrets.LoadAllowlist(allowlistFile)
+			if err != nil {
+				return err
+			}
+			scanner.SetAllowlist(allowlist)
+		}
+
+		if listRules {
+			return printRules(scanner.Rules())
+		}
+
+		if common.IsStdout(outputFile) {
+			outputFile = ""
+		}
+		reporters := secrets.NewDefaultReporterStrategy(version.Version)
+		if outputFormat == "" {
+			outputFormat = reporters.FormatForFile(outputFile)
+		}
+		if outputFormat == "" {
+			outputFormat = "text"
+		}
+		reporter := reporters.GetReporter(outputFormat)
+		if reporter == nil {
+			return fmt.Errorf("unsupported output format '%s'. Supported formats: %s",
+				outputFormat, strings.Join(reporters.GetFormatNames(), ", "))
+		}
+
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+
+		// Every file is read as text, unless --type or --ext-map chooses another reader
+		readerStrategy, err := common.NewTextReaderStrategy(cmd)
+		if err != nil {
+			return err
+		}
+		validatorStrategy := validator.NewValidatorStrategy(validator.NewBasicFileValidator())
+
+		// Display operation info; banners are only shown on a terminal
+		console := common.Console()
+		warnings := common.Warnings()
+		fmt.Fprintf(console, "🔐 Secret Scan Operation\n")
+		fmt.Fprintf(console, "📁 Paths: %s\n", strings.Join(args, ", "))
+		fmt.Fprintf(console, "📜 Rules: %d\n", len(scanner.Rules()))
+		if allowlistFile != "" {
+			fmt.Fprintf(console, "✅ Allowlist: %s\n", allowlistFile)
+		}
+		fmt.Fprintf(console, "🖨️  Output Format: %s\n", reporter.GetFormat())
+		if outputFile != "" {
+			fmt.Fprintf(console, "📤 Output: %s\n", outputFile)
+		} else {
+			fmt.Fprintf(console, "📤 Output: Console\n")
+		}
+		fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+
+		// Scan every file, or every member of an archive
+		startTime := time.Now()
+		var findings []secrets.Finding
+		filesScanned := 0
+		binarySkipped := 0
+		scanEntry := func(entry discovery.Entry) error {
+			if scanner.AllowsFile(entry.Name) {
+				return nil
+			}
+			content, _, err := common.ReadEntry(entry, readerStrategy, validatorStrategy, validator.BinarySkip)
+			if err != nil {
+				fmt.Fprintf(warnings, "❌ Failed to read '%s': %v\n", entry.Name, err)
+				return nil
+			}
+			if content == nil {
+				binarySkipped++
+				return nil
+			}
+			filesScanned++
+			findings = append(findings, scanner.ScanLines(entry.Name, content.Lines)...)
+			return nil
+		}
+
+		for _, path := range args {
+			if path == discovery.StdinName {
+				entry, err := common.InputEntry(discovery.StdinName)
+				if err != nil {
+					return err
+				}
+				if err := scanEntry(entry); err != nil {
+					return err
+				}
+				continue
+			}
+
+			targets, err := discovery.Expand(path)
+			if err != nil {
+				return err
+			}
+			if len(targets) == 0 {
+				return fmt.Errorf("no files found matching '%s'", path)
+			}
+			for _, target := range targets {
+				if err := scanTarget(target, scanner, scanEntry); err != nil {
+					fmt.Fprintf(warnings, "❌ Failed to scan '%s': %v\n", target.Path, err)
+				}
+			}
+		}
+
+		var report strings.Builder
+		if err := reporter.Report(&report, findings, scanner.Rules()); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		executionTime := time.Since(startTime)
+
+		// Write or display the report; an empty text report prints nothing
+		if outputFile != "" {
+			if err := writer.WriteFile(outputFile, report.String(), writer.OptionsFor(nil, "", outputFile)); err != nil {
+				return fmt.Errorf("failed to write output file: %w", err)
+			}
+		} else if report.Len() > 0 {
+			fmt.Print(report.String())
+		}
+
+		// Display results summary
+		severities := make(map[string]int)
+		for _, finding := range findings {
+			severities[finding.Rule.Severity]++
+		}
+		fmt.Fprintln(console, "─────────────────────────────────────────────────────")
+		fmt.Fprintf(console, "📊 Scan Summary:\n")
+		fmt.Fprintf(console, "   🔑 Secrets found: %d", len(findings))
+		if len(findings) > 0 {
+			fmt.Fprintf(console, " (%d error, %d warning, %d note)",
+				severities[secrets.SeverityError], severities[secrets.SeverityWarning], severities[secrets.SeverityNote])
+		}
+		fmt.Fprintln(console)
+		fmt.Fprintf(console, "   📝 Files scanned: %d\n", filesScanned)
+		if binarySkipped > 0 {
+			fmt.Fprintf(console, "   ⏭️  Binary files skipped: %d\n", binarySkipped)
+		}
+		fmt.Fprintf(console, "   ⏱️  Execution time: %v\n", executionTime)
+		if outputFile != "" {
+			fmt.Fprintf(console, "   📄 Report written to: %s\n", outputFile)
+		}
+
+		if failOnFindings && len(findings) > 0 {
+			// Finding secrets is not a usage mistake
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d secret(s) found", len(findings))
+		}
+		return nil
+	},
+}
+
+// scanTarget scans a file or archive, or every file in a directory tree,
+// leaving out version control directories and allowlisted paths.
+func scanTarget(target discovery.Target, scanner *secrets.Scanner, scanEntry func(discovery.Entry) error) error {
+	info, err := os.Stat(target.Path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return discovery.Walk(target, scanEntry)
+	}
+	if target.MemberPattern != "" {
+		return fmt.Errorf("'%s' is not an archive", target.Path)
+	}
+
+	return filepath.WalkDir(target.Path, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrPermission) {
+				fmt.Fprintf(common.Warnings(), "❌ Skipping '%s': %v\n", path, err)
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if path != target.Path && (skippedDirectories[entry.Name()] || scanner.AllowsFile(path)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		if err := discovery.Walk(discovery.Target{Path: path}, scanEntry); err != nil {
+			fmt.Fprintf(common.Warnings(), "❌ Failed to scan '%s': %v\n", path, err)
+		}
+		return nil
+	})
+}
+
+// printRules lists the rules of the scanner.
+func printRules(rules []*secrets.Rule) error {
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tSEVERITY\tMIN ENTROPY\tDESCRIPTION")
+	for _, rule := range rules {
+		fmt.Fprintf(table, "%s\t%s\t%g\t%s\n", rule.ID, rule.Severity, rule.MinEntropy, rule.Description)
+	}
+	return table.Flush()
+}
+
+// init function registers the scan secrets command and its flags.
+func init() {
+	scanCmd.AddCommand(scanSecretsCmd)
+
+	// Add flags for secret scan options
+	scanSecretsCmd.Flags().String("format", "", "Report format: text, json or sarif (default: from --output extension, else text)")
+	scanSecretsCmd.Flags().StringP("output", "o", "", "Output file for the report (default: console, - for standard output)")
+	scanSecretsCmd.Flags().String("rules", "", "YAML file with more rules; a rule with the ID of a built-in one replaces it")
+	scanSecretsCmd.Flags().String("allowlist", "", "File of accepted findings: fingerprint:, path:, rule: or secret: entries")
+	scanSecretsCmd.Flags().Bool("list-rules", false, "List the rules and exit")
+	scanSecretsCmd.Flags().Bool("fail", false, "Exit with an error when secrets are found, e.g. to fail a CI job")
+	common.AddReadFlags(scanSecretsCmd)
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kcansari/optix/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect configuration defaults",
+	Long: `Inspect the defaults loaded from configuration files and environment variables.
+
+Defaults for any command flag are read from, in order of precedence:
+
+  OPTIX_* environment variables  OPTIX_BACKUP_DIR, OPTIX_SEARCH_CONTEXT
+  the project file               .optix.yaml in the working directory or a parent
+  the user file                  $XDG_CONFIG_HOME/optix/config.yaml
+
+Flags given on the command line take precedence over all of them.`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show [command]",
+	Short: "Show the effective configuration and where each value comes from",
+	Long: `Show the effective configuration and where each value comes from.
+
+Without a command, every configured value is listed for each command that
+uses it. With a command (e.g. "search" or "json fmt"), all of its flags are
+listed, including those left at their defaults.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := config.LoadDefault()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("⚙️  Configuration\n")
+		files := settings.Files()
+		if len(files) == 0 {
+			fmt.Printf("📄 Files: none (user file: %s)\n", config.UserFile())
+		}
+		for _, file := range files {
+			fmt.Printf("📄 File: %s\n", file)
+		}
+		fmt.Println("─────────────────────────────────────────────────────")
+
+		if len(args) > 0 {
+			target, remaining, err := RootCmd.Find(args)
+			if err != nil || target == RootCmd || len(remaining) > 0 {
+				return fmt.Errorf("unknown command '%s'", strings.Join(args, " "))
+			}
+			showCommandConfig(settings, target, true)
+			return nil
+		}
+
+		shown := 0
+		for _, command := range allCommands(RootCmd) {
+			shown += showCommandConfig(settings, command, false)
+		}
+		if shown == 0 {
+			fmt.Printf("ℹ️  No configured values; all flags use their defaults\n")
+		}
+		return nil
+	},
+}
+
+// showCommandConfig prints the effective flag values of a command and
+// returns how many of them are configured. Defaults are only listed when
+// withDefaults is set.
+func showCommandConfig(settings *config.Config, command *cobra.Command, withDefaults bool) int {
+	type row struct{ flag, value, source string }
+	var rows []row
+
+	path := commandPath(command)
+	command.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Name == "help" {
+			return
+		}
+		if setting, ok := settings.Lookup(path, flag.Name); ok {
+			rows = append(rows, row{flag.Name, setting.Value(), setting.Source + " (" + setting.Origin + ")"})
+		} else if withDefaults {
+			rows = append(rows, row{flag.Name, flag.DefValue, "default"})
+		}
+	})
+	if len(rows) == 0 {
+		return 0
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].flag < rows[j].flag })
+	fmt.Printf("🔧 %s\n", strings.Join(path, " "))
+	configured := 0
+	for _, r := range rows {
+		fmt.Printf("   --%-18s %-20s %s\n", r.flag, r.value, r.source)
+		if r.source != "default" {
+			configured++
+		}
+	}
+	return configured
+}
+
+// applyConfig sets the flags of a command that were not given on the
+// command line from the configuration. Configured values replace the
+// defaults of the flags; they are not marked as changed.
+func applyConfig(command *cobra.Command) error {
+	settings, err := config.LoadDefault()
+	if err != nil {
+		return err
+	}
+	return settings.Apply(commandPath(command), command.Flags())
+}
+
+// commandPath returns the names of a command and its parents below the
+// root command, e.g. ["json", "fmt"].
+func commandPath(command *cobra.Command) []string {
+	var path []string
+	for c := command; c != nil && c != RootCmd; c = c.Parent() {
+		path = append([]string{c.Name()}, path...)
+	}
+	return path
+}
+
+// allCommands returns every runnable command below root, sorted by path.
+func allCommands(root *cobra.Command) []*cobra.Command {
+	var commands []*cobra.Command
+	for _, child := range root.Commands() {
+		if child.Runnable() {
+			commands = append(commands, child)
+		}
+		commands = append(commands, allCommands(child)...)
+	}
+	return commands
+}
+
+func init() {
+	// Flags that are not given on the command line take their defaults
+	// from the configuration files and OPTIX_* environment variables
+	RootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return applyConfig(cmd)
+	}
+
+	RootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+}
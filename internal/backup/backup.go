@@ -0,0 +1,40 @@
+// Package backup creates timestamped copies of files before they are modified.
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Create copies fileName to a timestamped backup and returns the backup path.
+// When backupDir is empty the backup is written next to the original file.
+func Create(fileName, backupDir string) (string, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return "", fmt.Errorf("failed to read original file: %w", err)
+	}
+
+	timestamp := time.Now().Format("20060102_150405")
+	baseName := filepath.Base(fileName)
+	backupName := fmt.Sprintf("%s.backup_%s", baseName, timestamp)
+
+	var backupPath string
+	if backupDir != "" {
+		err = os.MkdirAll(backupDir, 0755)
+		if err != nil {
+			return "", fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		backupPath = filepath.Join(backupDir, backupName)
+	} else {
+		backupPath = fileName + ".backup_" + timestamp
+	}
+
+	err = os.WriteFile(backupPath, content, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to write backup file: %w", err)
+	}
+
+	return backupPath, nil
+}
@@ -0,0 +1,344 @@
+// Package charset detects the character encoding of text files and converts
+// between that encoding and the UTF-8 strings the rest of optix works with.
+// It covers the encodings that show up in practice: UTF-8 (with or without
+// a byte order mark), UTF-16 and UTF-32 in both byte orders, ISO-8859-1 and
+// Windows-1252.
+package charset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding names a character encoding.
+type Encoding string
+
+const (
+	UTF8        Encoding = "utf-8"
+	UTF16LE     Encoding = "utf-16le"
+	UTF16BE     Encoding = "utf-16be"
+	UTF32LE     Encoding = "utf-32le"
+	UTF32BE     Encoding = "utf-32be"
+	Latin1      Encoding = "iso-8859-1"
+	Windows1252 Encoding = "windows-1252"
+)
+
+// aliases maps accepted spellings to encodings.
+var aliases = map[string]Encoding{
+	"utf-8":        UTF8,
+	"utf8":         UTF8,
+	"utf-16le":     UTF16LE,
+	"utf16le":      UTF16LE,
+	"utf-16":       UTF16LE,
+	"utf-16be":     UTF16BE,
+	"utf16be":      UTF16BE,
+	"utf-32le":     UTF32LE,
+	"utf32le":      UTF32LE,
+	"utf-32":       UTF32LE,
+	"utf-32be":     UTF32BE,
+	"utf32be":      UTF32BE,
+	"iso-8859-1":   Latin1,
+	"iso8859-1":    Latin1,
+	"latin1":       Latin1,
+	"latin-1":      Latin1,
+	"windows-1252": Windows1252,
+	"cp1252":       Windows1252,
+}
+
+// byteOrderMarks lists the BOM of every encoding that has one. UTF-32LE
+// comes before UTF-16LE because its BOM starts with the UTF-16LE BOM.
+var byteOrderMarks = []struct {
+	encoding Encoding
+	bom      []byte
+}{
+	{UTF32LE, []byte{0xff, 0xfe, 0x00, 0x00}},
+	{UTF32BE, []byte{0x00, 0x00, 0xfe, 0xff}},
+	{UTF8, []byte{0xef, 0xbb, 0xbf}},
+	{UTF16LE, []byte{0xff, 0xfe}},
+	{UTF16BE, []byte{0xfe, 0xff}},
+}
+
+// windows1252 maps the bytes 0x80-0x9f to runes. The five bytes the code
+// page leaves undefined map to the C1 controls, like ISO-8859-1, so that
+// every byte round-trips.
+var windows1252 = [32]rune{
+	0x20ac, 0x0081, 0x201a, 0x0192, 0x201e, 0x2026, 0x2020, 0x2021,
+	0x02c6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008d, 0x017d, 0x008f,
+	0x0090, 0x2018, 0x2019, 0x201c, 0x201d, 0x2022, 0x2013, 0x2014,
+	0x02dc, 0x2122, 0x0161, 0x203a, 0x0153, 0x009d, 0x017e, 0x0178,
+}
+
+// wideTextRatio is the share of UTF-16/UTF-32 code units that must be
+// ASCII text before a file without a BOM is taken to be in that encoding.
+const wideTextRatio = 0.9
+
+// maxControlRatio is the share of control bytes above which content that
+// is not valid UTF-8 is left alone (it is most likely binary).
+const maxControlRatio = 0.1
+
+// Parse returns the encoding for a name such as "utf-8", "latin1" or "cp1252".
+func Parse(name string) (Encoding, error) {
+	encoding, ok := aliases[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return "", fmt.Errorf("unsupported encoding '%s'. Supported encodings: %s", name, strings.Join(Names(), ", "))
+	}
+	return encoding, nil
+}
+
+// Names returns the canonical names of the supported encodings.
+func Names() []string {
+	seen := map[Encoding]bool{}
+	var names []string
+	for _, encoding := range aliases {
+		if !seen[encoding] {
+			seen[encoding] = true
+			names = append(names, string(encoding))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BOM returns the byte order mark of an encoding, or nil for the
+// single-byte encodings.
+func BOM(encoding Encoding) []byte {
+	for _, mark := range byteOrderMarks {
+		if mark.encoding == encoding {
+			return mark.bom
+		}
+	}
+	return nil
+}
+
+// Detect guesses the encoding of a sample of leading bytes and reports
+// whether it starts with a byte order mark. Content that is neither valid
+// UTF-8 nor plausible text in another encoding is reported as UTF-8 and
+// left for binary detection to reject.
+func Detect(sample []byte) (Encoding, bool) {
+	for _, mark := range byteOrderMarks {
+		if bytes.HasPrefix(sample, mark.bom) {
+			return mark.encoding, true
+		}
+	}
+
+	if bytes.IndexByte(sample, 0) != -1 {
+		switch {
+		case isWideText(sample, 4, 0):
+			return UTF32LE, false
+		case isWideText(sample, 4, 3):
+			return UTF32BE, false
+		case isWideText(sample, 2, 0):
+			return UTF16LE, false
+		case isWideText(sample, 2, 1):
+			return UTF16BE, false
+		}
+		return UTF8, false
+	}
+
+	if validUTF8(sample) || singleByteControlRatio(sample) > maxControlRatio {
+		return UTF8, false
+	}
+
+	for _, b := range sample {
+		if b >= 0x80 && b <= 0x9f {
+			return Windows1252, false
+		}
+	}
+	return Latin1, false
+}
+
+// Decode converts data in the given encoding to a UTF-8 string, dropping
+// a leading byte order mark.
+func Decode(data []byte, encoding Encoding) (string, error) {
+	data = bytes.TrimPrefix(data, BOM(encoding))
+
+	switch encoding {
+	case UTF8, "":
+		return string(data), nil
+	case UTF16LE, UTF16BE:
+		return decodeUTF16(data, byteOrder(encoding)), nil
+	case UTF32LE, UTF32BE:
+		return decodeUTF32(data, byteOrder(encoding)), nil
+	case Latin1:
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes), nil
+	case Windows1252:
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+			if b >= 0x80 && b <= 0x9f {
+				runes[i] = windows1252[b-0x80]
+			}
+		}
+		return string(runes), nil
+	}
+	return "", fmt.Errorf("unsupported encoding '%s'", encoding)
+}
+
+// Encode converts a UTF-8 string to the given encoding, optionally
+// prefixed with its byte order mark. It fails on characters the encoding
+// cannot represent.
+func Encode(text string, encoding Encoding, bom bool) ([]byte, error) {
+	var output bytes.Buffer
+	if bom {
+		output.Write(BOM(encoding))
+	}
+
+	switch encoding {
+	case UTF8, "":
+		output.WriteString(text)
+	case UTF16LE, UTF16BE:
+		order := byteOrder(encoding)
+		unit := make([]byte, 2)
+		for _, code := range utf16.Encode([]rune(text)) {
+			order.PutUint16(unit, code)
+			output.Write(unit)
+		}
+	case UTF32LE, UTF32BE:
+		order := byteOrder(encoding)
+		unit := make([]byte, 4)
+		for _, r := range text {
+			order.PutUint32(unit, uint32(r))
+			output.Write(unit)
+		}
+	case Latin1, Windows1252:
+		line := 1
+		for _, r := range text {
+			b, ok := encodeSingleByte(r, encoding)
+			if !ok {
+				return nil, fmt.Errorf("line %d: character %q (%U) cannot be encoded in %s", line, r, r, encoding)
+			}
+			if r == '\n' {
+				line++
+			}
+			output.WriteByte(b)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encoding '%s'", encoding)
+	}
+
+	return output.Bytes(), nil
+}
+
+func encodeSingleByte(r rune, encoding Encoding) (byte, bool) {
+	if encoding == Windows1252 {
+		for i, mapped := range windows1252 {
+			if mapped == r {
+				return byte(0x80 + i), true
+			}
+		}
+		if r >= 0x80 && r <= 0x9f {
+			return 0, false
+		}
+	}
+	if r > 0xff {
+		return 0, false
+	}
+	return byte(r), true
+}
+
+func byteOrder(encoding Encoding) binary.ByteOrder {
+	if encoding == UTF16BE || encoding == UTF32BE {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+func decodeUTF16(data []byte, order binary.ByteOrder) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, order.Uint16(data[i:]))
+	}
+
+	decoded := string(utf16.Decode(units))
+	if len(data)%2 != 0 {
+		decoded += string(utf8.RuneError)
+	}
+	return decoded
+}
+
+func decodeUTF32(data []byte, order binary.ByteOrder) string {
+	var builder strings.Builder
+	for i := 0; i+3 < len(data); i += 4 {
+		r := rune(order.Uint32(data[i:]))
+		if !utf8.ValidRune(r) {
+			r = utf8.RuneError
+		}
+		builder.WriteRune(r)
+	}
+	if len(data)%4 != 0 {
+		builder.WriteRune(utf8.RuneError)
+	}
+	return builder.String()
+}
+
+// isWideText reports whether sample looks like ASCII text stored in units
+// of width bytes, with the character in the byte at textOffset and zeros
+// elsewhere.
+func isWideText(sample []byte, width, textOffset int) bool {
+	units := len(sample) / width
+	if units < 2 {
+		return false
+	}
+
+	text := 0
+	for i := 0; i+width <= len(sample); i += width {
+		unit := sample[i : i+width]
+		if !isTextByte(unit[textOffset]) {
+			continue
+		}
+		zeros := true
+		for j, b := range unit {
+			if j != textOffset && b != 0 {
+				zeros = false
+				break
+			}
+		}
+		if zeros {
+			text++
+		}
+	}
+	return float64(text)/float64(units) >= wideTextRatio
+}
+
+func isTextByte(b byte) bool {
+	return (b >= 0x20 && b < 0x7f) || b == '\n' || b == '\r' || b == '\t'
+}
+
+// validUTF8 reports whether sample is valid UTF-8, ignoring a multi-byte
+// sequence cut off at the end of the sample.
+func validUTF8(sample []byte) bool {
+	for i := len(sample) - 1; i >= 0 && i >= len(sample)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(sample[i]) {
+			if !utf8.FullRune(sample[i:]) {
+				sample = sample[:i]
+			}
+			break
+		}
+	}
+	return utf8.Valid(sample)
+}
+
+// singleByteControlRatio returns the share of bytes that are control
+// characters other than common whitespace.
+func singleByteControlRatio(sample []byte) float64 {
+	if len(sample) == 0 {
+		return 0
+	}
+
+	control := 0
+	for _, b := range sample {
+		if (b < 0x20 && b != '\n' && b != '\r' && b != '\t' && b != '\f' && b != '\v' && b != 0x1b) || b == 0x7f {
+			control++
+		}
+	}
+	return float64(control) / float64(len(sample))
+}
@@ -0,0 +1,140 @@
+package charset_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kcansari/optix/internal/charset"
+)
+
+// TestDetect tests encoding detection from byte order marks and content.
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name        string
+		sample      []byte
+		expected    charset.Encoding
+		expectedBOM bool
+	}{
+		{"ascii", []byte("hello world\n"), charset.UTF8, false},
+		{"utf-8", []byte("naïve café\n"), charset.UTF8, false},
+		{"utf-8 cut at sample end", []byte("caf\xc3"), charset.UTF8, false},
+		{"utf-8 bom", []byte("\xef\xbb\xbfid,name\n"), charset.UTF8, true},
+		{"utf-16le bom", []byte("\xff\xfei\x00d\x00"), charset.UTF16LE, true},
+		{"utf-16be bom", []byte("\xfe\xff\x00i\x00d"), charset.UTF16BE, true},
+		{"utf-32le bom", []byte("\xff\xfe\x00\x00i\x00\x00\x00"), charset.UTF32LE, true},
+		{"utf-32be bom", []byte("\x00\x00\xfe\xff\x00\x00\x00i"), charset.UTF32BE, true},
+		{"utf-16le without bom", []byte("i\x00d\x00,\x00n\x00\n\x00"), charset.UTF16LE, false},
+		{"utf-16be without bom", []byte("\x00i\x00d\x00,\x00n\x00\n"), charset.UTF16BE, false},
+		{"utf-32le without bom", []byte("i\x00\x00\x00d\x00\x00\x00"), charset.UTF32LE, false},
+		{"latin-1", []byte("caf\xe9 cr\xe8me\n"), charset.Latin1, false},
+		{"windows-1252", []byte("\x93quoted\x94 \x80 5\n"), charset.Windows1252, false},
+		{"binary with nul bytes", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00"), charset.UTF8, false},
+		{"binary control bytes", []byte("\x01\x02\x03\x04\xff\xfa\x05\x06"), charset.UTF8, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoding, bom := charset.Detect(test.sample)
+			if encoding != test.expected || bom != test.expectedBOM {
+				t.Errorf("Expected %s (bom %t), got %s (bom %t)", test.expected, test.expectedBOM, encoding, bom)
+			}
+		})
+	}
+}
+
+// TestRoundTrip tests that text survives encoding and decoding.
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		encoding charset.Encoding
+		text     string
+	}{
+		{charset.UTF8, "naïve café 😀\n"},
+		{charset.UTF16LE, "naïve café 😀\n"},
+		{charset.UTF16BE, "naïve café 😀\n"},
+		{charset.UTF32LE, "naïve café 😀\n"},
+		{charset.UTF32BE, "naïve café 😀\n"},
+		{charset.Latin1, "naïve café\n"},
+		{charset.Windows1252, "“naïve” café – 5 €\n"},
+	}
+
+	for _, test := range tests {
+		for _, bom := range []bool{false, true} {
+			encoded, err := charset.Encode(test.text, test.encoding, bom)
+			if err != nil {
+				t.Fatalf("Failed to encode %s: %v", test.encoding, err)
+			}
+			mark := charset.BOM(test.encoding)
+			if (bom && mark != nil) != (mark != nil && bytes.HasPrefix(encoded, mark)) {
+				t.Errorf("Unexpected BOM for %s (bom %t): %q", test.encoding, bom, encoded)
+			}
+
+			decoded, err := charset.Decode(encoded, test.encoding)
+			if err != nil {
+				t.Fatalf("Failed to decode %s: %v", test.encoding, err)
+			}
+			if decoded != test.text {
+				t.Errorf("Round trip through %s (bom %t): expected %q, got %q", test.encoding, bom, test.text, decoded)
+			}
+		}
+	}
+}
+
+// TestDecodeWindows1252 tests the characters that differ from ISO-8859-1.
+func TestDecodeWindows1252(t *testing.T) {
+	decoded, err := charset.Decode([]byte("\x80 \x93hi\x94 \x85 \x81"), charset.Windows1252)
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if expected := "€ “hi” … \u0081"; decoded != expected {
+		t.Errorf("Expected %q, got %q", expected, decoded)
+	}
+
+	latin1, _ := charset.Decode([]byte("\x80"), charset.Latin1)
+	if latin1 != "\u0080" {
+		t.Errorf("Expected ISO-8859-1 to keep 0x80 as U+0080, got %q", latin1)
+	}
+}
+
+// TestEncodeUnrepresentable tests that characters outside a single-byte
+// encoding are rejected with their line number.
+func TestEncodeUnrepresentable(t *testing.T) {
+	_, err := charset.Encode("ok\nprice: 5 €\n", charset.Latin1, false)
+	if err == nil {
+		t.Fatal("Expected an error for '€' in ISO-8859-1")
+	}
+	if !bytes.Contains([]byte(err.Error()), []byte("line 2")) {
+		t.Errorf("Expected the error to name line 2, got: %v", err)
+	}
+
+	if _, err := charset.Encode("日本", charset.Windows1252, false); err == nil {
+		t.Error("Expected an error for CJK text in Windows-1252")
+	}
+}
+
+// TestParse tests encoding names and aliases.
+func TestParse(t *testing.T) {
+	tests := map[string]charset.Encoding{
+		"UTF-8":        charset.UTF8,
+		"utf8":         charset.UTF8,
+		"utf-16":       charset.UTF16LE,
+		"UTF-16BE":     charset.UTF16BE,
+		"latin1":       charset.Latin1,
+		"cp1252":       charset.Windows1252,
+		"Windows-1252": charset.Windows1252,
+	}
+
+	for name, expected := range tests {
+		encoding, err := charset.Parse(name)
+		if err != nil {
+			t.Errorf("Failed to parse '%s': %v", name, err)
+			continue
+		}
+		if encoding != expected {
+			t.Errorf("Expected '%s' to parse as %s, got %s", name, expected, encoding)
+		}
+	}
+
+	if _, err := charset.Parse("ebcdic"); err == nil {
+		t.Error("Expected an error for an unsupported encoding")
+	}
+}
@@ -0,0 +1,223 @@
+// Package compression detects compressed files by their magic bytes and
+// wraps them in decompressing readers and compressing writers, so that the
+// rest of Optix can treat app.log.1.gz like any other log file.
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dsnetbzip2 "github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Format identifies a compression format.
+type Format string
+
+const (
+	// None means the content is not compressed
+	None  Format = ""
+	Gzip  Format = "gzip"
+	Bzip2 Format = "bzip2"
+	Zlib  Format = "zlib"
+	Zstd  Format = "zstd"
+)
+
+// HeaderSize is the number of leading bytes Detect inspects.
+const HeaderSize = 4
+
+// extensions maps file extensions to the format they conventionally use.
+var extensions = map[string]Format{
+	".gz":   Gzip,
+	".tgz":  Gzip,
+	".bz2":  Bzip2,
+	".zz":   Zlib,
+	".zlib": Zlib,
+	".zst":  Zstd,
+}
+
+// Detect identifies the compression format from the first bytes of a file.
+func Detect(header []byte) Format {
+	switch {
+	case len(header) >= 2 && header[0] == 0x1f && header[1] == 0x8b:
+		return Gzip
+	case len(header) >= 4 && bytes.Equal(header[:4], []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return Zstd
+	case len(header) >= 4 && bytes.HasPrefix(header, []byte("BZh")) && header[3] >= '1' && header[3] <= '9':
+		return Bzip2
+	case len(header) >= 2 && isZlibHeader(header[0], header[1], false):
+		return Zlib
+	}
+	return None
+}
+
+// DetectNamed is Detect with the file name as a hint: zlib streams whose
+// header is printable ASCII ("x^") are only recognised with a zlib extension.
+func DetectNamed(filename string, header []byte) Format {
+	format := Detect(header)
+	if format == None && FromExtension(filename) == Zlib && len(header) >= 2 && isZlibHeader(header[0], header[1], true) {
+		return Zlib
+	}
+	return format
+}
+
+// isZlibHeader checks the zlib CMF/FLG bytes of a 32K-window deflate
+// stream. Unless lenient is set, the "x^" header of levels 2-5 is rejected
+// so that text starting with those characters is not mistaken for zlib.
+func isZlibHeader(cmf, flg byte, lenient bool) bool {
+	if cmf != 0x78 {
+		return false
+	}
+	switch flg {
+	case 0x01, 0x9c, 0xda:
+	case 0x5e:
+		if !lenient {
+			return false
+		}
+	default:
+		return false
+	}
+	return (uint16(cmf)<<8|uint16(flg))%31 == 0
+}
+
+// FromExtension returns the format implied by the extension of filename.
+func FromExtension(filename string) Format {
+	return extensions[strings.ToLower(filepath.Ext(filename))]
+}
+
+// TrimExtension strips a compression extension, so that "app.log.gz"
+// becomes "app.log" and the inner file type can be determined.
+// A ".tgz" file becomes ".tar".
+func TrimExtension(filename string) string {
+	extension := filepath.Ext(filename)
+	switch strings.ToLower(extension) {
+	case ".tgz":
+		return strings.TrimSuffix(filename, extension) + ".tar"
+	}
+	if FromExtension(filename) == None {
+		return filename
+	}
+	return strings.TrimSuffix(filename, extension)
+}
+
+// NewReader returns a reader that decompresses input in the given format.
+func NewReader(format Format, input io.Reader) (io.ReadCloser, error) {
+	switch format {
+	case None:
+		return io.NopCloser(input), nil
+	case Gzip:
+		return gzip.NewReader(input)
+	case Bzip2:
+		return io.NopCloser(bzip2.NewReader(input)), nil
+	case Zlib:
+		return zlib.NewReader(input)
+	case Zstd:
+		decoder, err := zstd.NewReader(input)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+	return nil, fmt.Errorf("unsupported compression format '%s'", format)
+}
+
+// NewWriter returns a writer that compresses into output in the given
+// format. Close must be called to flush the compressed stream.
+func NewWriter(format Format, output io.Writer) (io.WriteCloser, error) {
+	switch format {
+	case None:
+		return nopWriteCloser{output}, nil
+	case Gzip:
+		return gzip.NewWriter(output), nil
+	case Bzip2:
+		return dsnetbzip2.NewWriter(output, nil)
+	case Zlib:
+		return zlib.NewWriter(output), nil
+	case Zstd:
+		return zstd.NewWriter(output)
+	}
+	return nil, fmt.Errorf("unsupported compression format '%s'", format)
+}
+
+// Compress returns data compressed in the given format.
+func Compress(format Format, data []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer, err := NewWriter(format, &buffer)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to compress %s data: %w", format, err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress %s data: %w", format, err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// Open opens filename and transparently decompresses it if its content
+// starts with a known magic number. The detected format is returned.
+func Open(filename string) (io.ReadCloser, Format, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, None, err
+	}
+
+	buffered := bufio.NewReader(file)
+	header, _ := buffered.Peek(HeaderSize)
+	format := DetectNamed(filename, header)
+
+	reader, err := NewReader(format, buffered)
+	if err != nil {
+		file.Close()
+		return nil, None, fmt.Errorf("failed to decompress %s file '%s': %w", format, filename, err)
+	}
+
+	return &fileReadCloser{Reader: reader, reader: reader, file: file}, format, nil
+}
+
+// DetectFile reports the compression format of filename.
+func DetectFile(filename string) (Format, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return None, err
+	}
+	defer file.Close()
+
+	header := make([]byte, HeaderSize)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return None, err
+	}
+	return DetectNamed(filename, header[:n]), nil
+}
+
+// fileReadCloser closes both the decompressor and the underlying file.
+type fileReadCloser struct {
+	io.Reader
+	reader io.ReadCloser
+	file   *os.File
+}
+
+func (f *fileReadCloser) Close() error {
+	readerErr := f.reader.Close()
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	return readerErr
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,123 @@
+package compression_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kcansari/optix/internal/compression"
+)
+
+// TestRoundTrip tests that every format decompresses what it compressed
+// and is recognised by its magic bytes.
+func TestRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("2024-01-01 INFO request served in 12ms\n"), 100)
+
+	for _, format := range []compression.Format{compression.Gzip, compression.Bzip2, compression.Zlib, compression.Zstd} {
+		t.Run(string(format), func(t *testing.T) {
+			compressed, err := compression.Compress(format, data)
+			if err != nil {
+				t.Fatalf("Failed to compress: %v", err)
+			}
+
+			if detected := compression.Detect(compressed); detected != format {
+				t.Errorf("Expected to detect %s, got '%s'", format, detected)
+			}
+
+			reader, err := compression.NewReader(format, bytes.NewReader(compressed))
+			if err != nil {
+				t.Fatalf("Failed to create reader: %v", err)
+			}
+			defer reader.Close()
+
+			decompressed, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("Failed to decompress: %v", err)
+			}
+			if !bytes.Equal(decompressed, data) {
+				t.Error("Decompressed data does not match the original")
+			}
+		})
+	}
+}
+
+// TestDetectPlainText tests that ordinary text is not mistaken for compressed data.
+func TestDetectPlainText(t *testing.T) {
+	tests := []string{"", "x", "xml version", "x^2 + y^2", "BZh!", "{\"a\": 1}"}
+
+	for _, input := range tests {
+		if format := compression.Detect([]byte(input)); format != compression.None {
+			t.Errorf("Expected no compression for %q, got %s", input, format)
+		}
+	}
+}
+
+// TestDetectNamed tests that weak zlib headers need a zlib extension.
+func TestDetectNamed(t *testing.T) {
+	header := []byte("x^2 + y^2")
+
+	if format := compression.DetectNamed("notes.txt", header); format != compression.None {
+		t.Errorf("Expected no compression for notes.txt, got %s", format)
+	}
+	if format := compression.DetectNamed("data.zz", header); format != compression.Zlib {
+		t.Errorf("Expected zlib for data.zz, got '%s'", format)
+	}
+}
+
+// TestTrimExtension tests how inner file names are derived.
+func TestTrimExtension(t *testing.T) {
+	tests := map[string]string{
+		"app.log.1.gz":    "app.log.1",
+		"events.jsonl.GZ": "events.jsonl",
+		"data.csv.bz2":    "data.csv",
+		"dump.json.zst":   "dump.json",
+		"backup.tgz":      "backup.tar",
+		"notes.txt":       "notes.txt",
+		"archive":         "archive",
+	}
+
+	for input, expected := range tests {
+		if actual := compression.TrimExtension(input); actual != expected {
+			t.Errorf("TrimExtension(%q): expected %q, got %q", input, expected, actual)
+		}
+	}
+}
+
+// TestOpen tests transparent decompression of files regardless of their name.
+func TestOpen(t *testing.T) {
+	compressed, err := compression.Compress(compression.Gzip, []byte("hello\n"))
+	if err != nil {
+		t.Fatalf("Failed to compress: %v", err)
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"rotated.log.1", "plain.txt"} {
+		content := compressed
+		if name == "plain.txt" {
+			content = []byte("hello\n")
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+
+		input, _, err := compression.Open(path)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", name, err)
+		}
+		data, err := io.ReadAll(input)
+		input.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if string(data) != "hello\n" {
+			t.Errorf("Expected decompressed content for %s, got %q", name, data)
+		}
+	}
+
+	if _, _, err := compression.Open(filepath.Join(dir, "missing.gz")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
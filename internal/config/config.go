@@ -0,0 +1,434 @@
+// Package config loads default flag values from configuration files and
+// environment variables.
+//
+// Settings are read from three places, in order of precedence:
+//
+//	OPTIX_* environment variables   OPTIX_BACKUP_DIR, OPTIX_SEARCH_CONTEXT
+//	the project file                .optix.yaml in the working directory or a parent
+//	the user file                   $XDG_CONFIG_HOME/optix/config.yaml
+//
+// Flags given on the command line take precedence over all of them.
+//
+// A configuration file maps flag names to values. A mapping named after a
+// command applies to that command only. Top-level values apply to every
+// command that has the flag, and are only allowed for the GlobalFlags,
+// which mean the same on every command; command values take precedence
+// over top-level values from the same file:
+//
+//	backup-dir: .backups
+//	case-sensitive: true
+//	search:
+//	  context: 2
+//	json:
+//	  fmt:
+//	    indent: 4
+//
+// Environment variables are named after the flag, optionally prefixed
+// with the command: OPTIX_CONTEXT applies to every command, and
+// OPTIX_SEARCH_CONTEXT to search only. Like top-level values, variables
+// without a command are only allowed for the GlobalFlags.
+//
+// Configuration files also hold profiles, saved command lines that are
+// run by name, and patterns, kinds of values for extract; see profile.go
+// and patterns.go.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of the environment variables read as settings.
+const EnvPrefix = "OPTIX_"
+
+// ProjectFileNames are the names of project configuration files, in the
+// order they are looked for in each directory.
+var ProjectFileNames = []string{".optix.yaml", ".optix.yml"}
+
+// GlobalFlags are the flags that can be set for every command at once, at
+// the top level of a file or in a variable without a command, because
+// they mean the same on every command. Other flags, such as output (a
+// file on most commands) or format (with different formats per command),
+// must be set for a command.
+var GlobalFlags = map[string]bool{
+	"backup-dir":     true,
+	"binary":         true,
+	"case-sensitive": true,
+	"context":        true,
+	"ext-map":        true,
+	"max-errors":     true,
+	"record-start":   true,
+	"regex":          true,
+	"skip-invalid":   true,
+	"time-format":    true,
+	"utc":            true,
+	"whole-word":     true,
+}
+
+// maxEnvWords limits the words of a variable name that is considered;
+// longer names are not settings.
+const maxEnvWords = 8
+
+// Source kinds, from the lowest to the highest precedence.
+const (
+	SourceUser    = "user"
+	SourceProject = "project"
+	SourceEnv     = "env"
+)
+
+// Setting is a configured value for a flag.
+type Setting struct {
+	// Flag is the flag name, e.g. "backup-dir"
+	Flag string
+
+	// Values holds the configured value; lists have one entry per item
+	Values []string
+
+	// Source is the kind of source: SourceUser, SourceProject or SourceEnv
+	Source string
+
+	// Origin describes where the value was set, e.g. "/home/me/.optix.yaml:3"
+	// or "OPTIX_BACKUP_DIR"
+	Origin string
+}
+
+// Value returns the value as a single string; list items are joined with
+// commas, as they are written on the command line.
+func (s Setting) Value() string {
+	return strings.Join(s.Values, ",")
+}
+
+// layer holds the settings of one source. Keys are the command path and
+// the flag name joined with dots, e.g. "search.context"; top-level
+// settings have no command part.
+type layer struct {
+	source   string
+	path     string
+	settings map[string]Setting
+	profiles map[string]Profile
+	patterns map[string]Pattern
+}
+
+// Config holds the settings of every source that was found.
+type Config struct {
+	// layers are ordered from the highest to the lowest precedence
+	layers []layer
+}
+
+// Options describes where settings are loaded from.
+type Options struct {
+	// UserFile is the user configuration file; empty skips it
+	UserFile string
+
+	// WorkDir is where the search for a project file starts; empty skips it
+	WorkDir string
+
+	// Environ holds the environment in "KEY=value" form
+	Environ []string
+}
+
+// Load reads the settings from the files and environment in options.
+// Missing files are not an error.
+func Load(options Options) (*Config, error) {
+	config := &Config{}
+
+	config.layers = append(config.layers, envLayer(options.Environ))
+
+	if options.WorkDir != "" {
+		if projectFile := FindProjectFile(options.WorkDir); projectFile != "" {
+			projectLayer, err := loadFile(SourceProject, projectFile)
+			if err != nil {
+				return nil, err
+			}
+			config.layers = append(config.layers, projectLayer)
+		}
+	}
+
+	if options.UserFile != "" {
+		userLayer, err := loadFile(SourceUser, options.UserFile)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err == nil {
+			config.layers = append(config.layers, userLayer)
+		}
+	}
+
+	return config, nil
+}
+
+// LoadDefault loads the user file, the project file for the working
+// directory and the process environment.
+func LoadDefault() (*Config, error) {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	return Load(Options{
+		UserFile: UserFile(),
+		WorkDir:  workDir,
+		Environ:  os.Environ(),
+	})
+}
+
+// UserFile returns the path of the user configuration file,
+// $XDG_CONFIG_HOME/optix/config.yaml or ~/.config/optix/config.yaml.
+func UserFile() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "optix", "config.yaml")
+}
+
+// FindProjectFile looks for a project configuration file in dir and its
+// parents, and returns the first one found, or "".
+func FindProjectFile(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		for _, name := range ProjectFileNames {
+			candidate := filepath.Join(dir, name)
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Files returns the configuration files that were loaded, from the
+// highest to the lowest precedence.
+func (c *Config) Files() []string {
+	var files []string
+	for _, l := range c.layers {
+		if l.path != "" {
+			files = append(files, l.path)
+		}
+	}
+	return files
+}
+
+// Lookup returns the setting for a flag of the command with the given
+// path (e.g. ["json", "fmt"]). Sources are tried in order of precedence;
+// within a source, the setting for the command wins over a top-level one.
+// Top-level settings are only used for the GlobalFlags.
+func (c *Config) Lookup(commandPath []string, flag string) (Setting, bool) {
+	for _, l := range c.layers {
+		for _, key := range lookupKeys(commandPath, flag) {
+			if setting, ok := l.settings[key]; ok {
+				return setting, true
+			}
+		}
+	}
+	return Setting{}, false
+}
+
+// unscoped returns the top-level setting for a flag that is not one of
+// the GlobalFlags, which Lookup ignores.
+func (c *Config) unscoped(flag string) (Setting, bool) {
+	if GlobalFlags[flag] {
+		return Setting{}, false
+	}
+	for _, l := range c.layers {
+		if setting, ok := l.settings[flag]; ok {
+			return setting, true
+		}
+	}
+	return Setting{}, false
+}
+
+// lookupKeys returns the keys for a flag from the most to the least
+// specific: "json.fmt.indent", "json.indent", and "indent" for the
+// GlobalFlags.
+func lookupKeys(commandPath []string, flag string) []string {
+	keys := make([]string, 0, len(commandPath)+1)
+	for i := len(commandPath); i > 0; i-- {
+		keys = append(keys, strings.Join(commandPath[:i], ".")+"."+flag)
+	}
+	if GlobalFlags[flag] {
+		keys = append(keys, flag)
+	}
+	return keys
+}
+
+// envLayer collects the OPTIX_* variables. Their names cannot be split
+// into a command and a flag unambiguously, so they are stored under
+// every key they could stand for, and Lookup picks the ones that exist.
+func envLayer(environ []string) layer {
+	l := layer{source: SourceEnv, settings: make(map[string]Setting)}
+	for _, entry := range environ {
+		name, value, found := strings.Cut(entry, "=")
+		if !found || !strings.HasPrefix(name, EnvPrefix) || len(name) == len(EnvPrefix) {
+			continue
+		}
+
+		// OPTIX_JSON_FMT_INDENT may be json.fmt.indent, json.fmt-indent,
+		// json-fmt-indent, ... ; Lookup only asks for real commands and flags
+		words := strings.Split(strings.ToLower(strings.TrimPrefix(name, EnvPrefix)), "_")
+		if len(words) > maxEnvWords {
+			continue
+		}
+		for _, key := range envKeys(words) {
+			l.settings[key] = Setting{
+				Flag:   key[strings.LastIndex(key, ".")+1:],
+				Values: []string{value},
+				Source: SourceEnv,
+				Origin: name,
+			}
+		}
+	}
+	return l
+}
+
+// envKeys returns every key the words of a variable name could stand
+// for: each word either starts a new part or joins the previous one
+// with a dash. The number of words in a variable name is small.
+func envKeys(words []string) []string {
+	keys := []string{words[0]}
+	for _, word := range words[1:] {
+		next := make([]string, 0, len(keys)*2)
+		for _, key := range keys {
+			next = append(next, key+"-"+word, key+"."+word)
+		}
+		keys = next
+	}
+	return keys
+}
+
+// loadFile reads a configuration file.
+func loadFile(source, path string) (layer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return layer{}, err
+	}
+
+	settings, err := Parse(data)
+	if err != nil {
+		return layer{}, fmt.Errorf("config file '%s': %w", path, err)
+	}
+
+	profiles, err := ParseProfiles(data)
+	if err != nil {
+		return layer{}, fmt.Errorf("config file '%s': %w", path, err)
+	}
+
+	patterns, err := ParsePatterns(data)
+	if err != nil {
+		return layer{}, fmt.Errorf("config file '%s': %w", path, err)
+	}
+
+	l := layer{source: source, path: path, settings: make(map[string]Setting), profiles: make(map[string]Profile), patterns: make(map[string]Pattern)}
+	for key, setting := range settings {
+		setting.Source = source
+		setting.Origin = path + ":" + setting.Origin
+		l.settings[key] = setting
+	}
+	for name, profile := range profiles {
+		profile.Source = source
+		profile.Origin = path + ":" + profile.Origin
+		l.profiles[name] = profile
+	}
+	for name, pattern := range patterns {
+		pattern.Source = source
+		pattern.Origin = path + ":" + pattern.Origin
+		l.patterns[name] = pattern
+	}
+	return l, nil
+}
+
+// Parse parses the content of a configuration file. The returned
+// settings are keyed by command path and flag, e.g. "search.context", and
+// their Origin holds the line number. The profiles and patterns sections
+// are left to ParseProfiles and ParsePatterns.
+func Parse(data []byte) (map[string]Setting, error) {
+	settings := make(map[string]Setting)
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		return settings, nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping of flag names to values", root.Line)
+	}
+	if err := parseMapping(root, nil, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// parseMapping adds the settings of a mapping for the command at path.
+// Nested mappings are sections for subcommands.
+func parseMapping(node *yaml.Node, path []string, settings map[string]Setting) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		name := normalizeName(keyNode.Value)
+		if name == "" {
+			return fmt.Errorf("line %d: empty key", keyNode.Line)
+		}
+		if len(path) == 0 && (name == profilesKey || name == patternsKey) {
+			continue
+		}
+
+		switch valueNode.Kind {
+		case yaml.MappingNode:
+			if err := parseMapping(valueNode, append(path[:len(path):len(path)], name), settings); err != nil {
+				return err
+			}
+
+		case yaml.SequenceNode:
+			values := make([]string, 0, len(valueNode.Content))
+			for _, item := range valueNode.Content {
+				if item.Kind != yaml.ScalarNode {
+					return fmt.Errorf("line %d: '%s' must be a list of values", item.Line, keyNode.Value)
+				}
+				values = append(values, item.Value)
+			}
+			addSetting(settings, path, name, values, keyNode.Line)
+
+		case yaml.ScalarNode:
+			if valueNode.Tag == "!!null" {
+				return fmt.Errorf("line %d: '%s' has no value", keyNode.Line, keyNode.Value)
+			}
+			addSetting(settings, path, name, []string{valueNode.Value}, keyNode.Line)
+
+		default:
+			return fmt.Errorf("line %d: unsupported value for '%s'", keyNode.Line, keyNode.Value)
+		}
+	}
+	return nil
+}
+
+// addSetting stores a setting under its command path and flag name.
+func addSetting(settings map[string]Setting, path []string, flag string, values []string, line int) {
+	key := strings.Join(append(path[:len(path):len(path)], flag), ".")
+	settings[key] = Setting{
+		Flag:   flag,
+		Values: values,
+		Origin: fmt.Sprintf("%d", line),
+	}
+}
+
+// normalizeName accepts backup_dir and Backup-Dir for backup-dir.
+func normalizeName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-")
+}
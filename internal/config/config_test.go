@@ -0,0 +1,447 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kcansari/optix/internal/config"
+	"github.com/spf13/pflag"
+)
+
+// TestParse tests parsing configuration files into settings.
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    map[string][]string
+		expectError bool
+	}{
+		{"empty file", "", map[string][]string{}, false},
+		{"top-level values", "backup-dir: .backups\ncase-sensitive: true\n",
+			map[string][]string{"backup-dir": {".backups"}, "case-sensitive": {"true"}}, false},
+		{"underscores and case", "Backup_Dir: x\n", map[string][]string{"backup-dir": {"x"}}, false},
+		{"command sections", "search:\n  context: 2\njson:\n  fmt:\n    indent: 4\n",
+			map[string][]string{"search.context": {"2"}, "json.fmt.indent": {"4"}}, false},
+		{"lists", "ext-map: [.conf=toml, .out=jsonl]\n",
+			map[string][]string{"ext-map": {".conf=toml", ".out=jsonl"}}, false},
+		{"not a mapping", "- a\n- b\n", nil, true},
+		{"missing value", "backup-dir:\n", nil, true},
+		{"nested list", "ext-map: [[a]]\n", nil, true},
+		{"invalid yaml", "context: [\n", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings, err := config.Parse([]byte(test.input))
+			if test.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			values := make(map[string][]string)
+			for key, setting := range settings {
+				values[key] = setting.Values
+			}
+			if !reflect.DeepEqual(values, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, values)
+			}
+		})
+	}
+}
+
+// TestLookup tests the precedence of environment variables, project and
+// user files, and of command sections over top-level values.
+func TestLookup(t *testing.T) {
+	root := t.TempDir()
+	userFile := filepath.Join(root, "user.yaml")
+	workDir := filepath.Join(root, "project", "sub")
+	projectFile := filepath.Join(root, "project", ".optix.yaml")
+
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+	writeFile(t, userFile, "backup-dir: /user\ncontext: 5\ncase-sensitive: true\nformat: json\njson:\n  indent: 8\n")
+	writeFile(t, projectFile, "backup-dir: /project\nsearch:\n  context: 1\n")
+
+	settings, err := config.Load(config.Options{
+		UserFile: userFile,
+		WorkDir:  workDir,
+		Environ:  []string{"OPTIX_BACKUP_DIR=/env", "OPTIX_JSON_FMT_SORT_KEYS=true", "PATH=/bin", "OPTIX_=x"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		command        []string
+		flag           string
+		expectedValue  string
+		expectedSource string
+		expectFound    bool
+	}{
+		{"env over files", []string{"replace"}, "backup-dir", "/env", config.SourceEnv, true},
+		{"command env variable", []string{"json", "fmt"}, "sort-keys", "true", config.SourceEnv, true},
+		{"project command section over user", []string{"search"}, "context", "1", config.SourceProject, true},
+		{"user top-level", []string{"filter"}, "context", "5", config.SourceUser, true},
+		{"user only", []string{"search"}, "case-sensitive", "true", config.SourceUser, true},
+		{"parent command section", []string{"json", "fmt"}, "indent", "8", config.SourceUser, true},
+		{"not configured", []string{"search"}, "regex", "", "", false},
+		{"top-level command flag", []string{"search"}, "format", "", "", false},
+		{"other command section", []string{"filter"}, "sort-keys", "", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setting, found := settings.Lookup(test.command, test.flag)
+			if found != test.expectFound {
+				t.Fatalf("Expected found %v, got %v", test.expectFound, found)
+			}
+			if setting.Value() != test.expectedValue {
+				t.Errorf("Expected value %q, got %q", test.expectedValue, setting.Value())
+			}
+			if setting.Source != test.expectedSource {
+				t.Errorf("Expected source %q, got %q", test.expectedSource, setting.Source)
+			}
+		})
+	}
+
+	expectedFiles := []string{projectFile, userFile}
+	if files := settings.Files(); !reflect.DeepEqual(files, expectedFiles) {
+		t.Errorf("Expected files %v, got %v", expectedFiles, files)
+	}
+}
+
+// TestLoadErrors tests that missing files are skipped and broken ones reported.
+func TestLoadErrors(t *testing.T) {
+	root := t.TempDir()
+
+	if _, err := config.Load(config.Options{UserFile: filepath.Join(root, "missing.yaml"), WorkDir: root}); err != nil {
+		t.Errorf("Expected missing files to be skipped, got %v", err)
+	}
+
+	writeFile(t, filepath.Join(root, ".optix.yaml"), "context: [\n")
+	if _, err := config.Load(config.Options{WorkDir: root}); err == nil {
+		t.Errorf("Expected error for an invalid project file")
+	}
+}
+
+// TestApply tests that configured values fill in the flags that were not
+// given, without counting as given on the command line.
+func TestApply(t *testing.T) {
+	userFile := filepath.Join(t.TempDir(), "user.yaml")
+	writeFile(t, userFile, "context: 1\njson:\n  fmt:\n    indent: 4\n")
+	settings, err := config.Load(config.Options{UserFile: userFile})
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+
+	flags := pflag.NewFlagSet("fmt", pflag.ContinueOnError)
+	flags.String("indent", "2", "")
+	flags.Bool("minify", false, "")
+	flags.Int("context", 0, "")
+	flags.Bool("sort-keys", false, "")
+	if err := flags.Parse([]string{"--minify", "--context", "3"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	if err := settings.Apply([]string{"json", "fmt"}, flags); err != nil {
+		t.Fatalf("Failed to apply: %v", err)
+	}
+
+	tests := []struct {
+		flag               string
+		expectedValue      string
+		expectedChanged    bool
+		expectedConfigured bool
+	}{
+		{"indent", "4", false, true},
+		{"minify", "true", true, false},
+		{"context", "3", true, false},
+		{"sort-keys", "false", false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.flag, func(t *testing.T) {
+			flag := flags.Lookup(test.flag)
+			if flag.Value.String() != test.expectedValue {
+				t.Errorf("Expected value %q, got %q", test.expectedValue, flag.Value.String())
+			}
+			if flags.Changed(test.flag) != test.expectedChanged {
+				t.Errorf("Expected changed %v, got %v", test.expectedChanged, flags.Changed(test.flag))
+			}
+			if config.Configured(flag) != test.expectedConfigured {
+				t.Errorf("Expected configured %v, got %v", test.expectedConfigured, config.Configured(flag))
+			}
+		})
+	}
+
+	// Invalid configured values are reported with their origin
+	writeFile(t, userFile, "context: many\n")
+	settings, err = config.Load(config.Options{UserFile: userFile})
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	flags = pflag.NewFlagSet("search", pflag.ContinueOnError)
+	flags.Int("context", 0, "")
+	if err := settings.Apply([]string{"search"}, flags); err == nil || !strings.Contains(err.Error(), userFile+":1") {
+		t.Errorf("Expected an invalid value error naming %s:1, got %v", userFile, err)
+	}
+
+	// Top-level values only reach flags that mean the same on every command
+	writeFile(t, userFile, "output: json\ncase-sensitive: true\nsearch:\n  output: found.txt\n")
+	settings, err = config.Load(config.Options{UserFile: userFile})
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	flags = pflag.NewFlagSet("search", pflag.ContinueOnError)
+	flags.String("output", "", "")
+	flags.Bool("case-sensitive", false, "")
+	if err := settings.Apply([]string{"search"}, flags); err != nil {
+		t.Fatalf("Failed to apply: %v", err)
+	}
+	if output := flags.Lookup("output").Value.String(); output != "found.txt" {
+		t.Errorf("Expected the search output found.txt, got %q", output)
+	}
+	if caseSensitive := flags.Lookup("case-sensitive").Value.String(); caseSensitive != "true" {
+		t.Errorf("Expected case-sensitive from the top level, got %q", caseSensitive)
+	}
+
+	flags = pflag.NewFlagSet("replace", pflag.ContinueOnError)
+	flags.String("output", "", "")
+	err = settings.Apply([]string{"replace"}, flags)
+	if err == nil || !strings.Contains(err.Error(), userFile+":1") {
+		t.Errorf("Expected a top-level output error naming %s:1, got %v", userFile, err)
+	}
+	if output := flags.Lookup("output").Value.String(); output != "" {
+		t.Errorf("Expected no output for replace, got %q", output)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+// TestParseProfiles tests reading profiles from a configuration file.
+func TestParseProfiles(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    map[string][]string
+		expectError bool
+	}{
+		{"no profiles", "context: 2\n", map[string][]string{}, false},
+		{"args", "profiles:\n  rename:\n    command: replace\n    args: [--find, '{{old}}']\n",
+			map[string][]string{"rename": {"replace", "--find", "{{old}}"}}, false},
+		{"flags before args", "profiles:\n  scan:\n    command: json  fmt\n    flags:\n      indent: 4\n      sort_keys: true\n    args: ['*.json']\n",
+			map[string][]string{"scan": {"json", "fmt", "--indent=4", "--sort-keys=true", "*.json"}}, false},
+		{"no command", "profiles:\n  scan:\n    args: [x]\n", nil, true},
+		{"unknown field", "profiles:\n  scan:\n    command: search\n    flag: {}\n", nil, true},
+		{"invalid name", "profiles:\n  -scan:\n    command: search\n", nil, true},
+		{"not a mapping", "profiles: [scan]\n", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			profiles, err := config.ParseProfiles([]byte(test.input))
+			if test.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			commandLines := make(map[string][]string)
+			for name, profile := range profiles {
+				commandLines[name] = profile.CommandLine()
+			}
+			if !reflect.DeepEqual(commandLines, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, commandLines)
+			}
+		})
+	}
+
+	// Profiles do not count as settings
+	settings, err := config.Parse([]byte("profiles:\n  scan:\n    command: search\n"))
+	if err != nil || len(settings) != 0 {
+		t.Errorf("Expected no settings, got %v (%v)", settings, err)
+	}
+}
+
+// TestParsePatterns tests reading kinds of values from configuration files.
+func TestParsePatterns(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    map[string]string
+		expectError bool
+	}{
+		{"no patterns", "context: 2\n", map[string]string{}, false},
+		{"expression only", "patterns:\n  order-id: 'ORD-\\d{8}'\n", map[string]string{"order-id": `ORD-\d{8}`}, false},
+		{"underscore name kept", "patterns:\n  ' order_id ': 'ORD-\\d{8}'\n", map[string]string{"order_id": `ORD-\d{8}`}, false},
+		{"with description", "patterns:\n  ticket:\n    description: Ticket\n    pattern: '[A-Z]+-\\d+'\n", map[string]string{"ticket": `[A-Z]+-\d+`}, false},
+		{"invalid expression", "patterns:\n  ticket: '('\n", nil, true},
+		{"no expression", "patterns:\n  ticket:\n    description: Ticket\n", nil, true},
+		{"unknown field", "patterns:\n  ticket:\n    regex: x\n", nil, true},
+		{"not a mapping", "patterns: [ticket]\n", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patterns, err := config.ParsePatterns([]byte(test.input))
+			if test.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			expressions := make(map[string]string)
+			for name, pattern := range patterns {
+				expressions[name] = pattern.Expression
+			}
+			if !reflect.DeepEqual(expressions, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, expressions)
+			}
+		})
+	}
+
+	// A project pattern hides a user pattern of the same name
+	root := t.TempDir()
+	userFile := filepath.Join(root, "user.yaml")
+	writeFile(t, userFile, "patterns:\n  ticket: 'USER-\\d+'\n  build: 'b\\d+'\n")
+	writeFile(t, filepath.Join(root, ".optix.yaml"), "patterns:\n  ticket: 'PROJ-\\d+'\n")
+
+	settings, err := config.Load(config.Options{UserFile: userFile, WorkDir: root})
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	patterns := settings.Patterns()
+	if len(patterns) != 2 || patterns[0].Name != "build" || patterns[1].Expression != `PROJ-\d+` || patterns[1].Source != config.SourceProject {
+		t.Errorf("Unexpected patterns %+v", patterns)
+	}
+	if _, found := settings.Lookup(nil, "ticket"); found {
+		t.Errorf("Expected patterns not to count as settings")
+	}
+}
+
+// TestExpand tests filling in profile placeholders.
+func TestExpand(t *testing.T) {
+	profile := config.Profile{
+		Name:    "rename-host",
+		Command: "replace",
+		Args:    []string{"--file", "{{file}}", "--find", "{{ old }}", "--replace", "{{new}}.{{old}}"},
+	}
+
+	if placeholders := profile.Placeholders(); !reflect.DeepEqual(placeholders, []string{"file", "new", "old"}) {
+		t.Errorf("Unexpected placeholders %v", placeholders)
+	}
+
+	tests := []struct {
+		name        string
+		values      map[string]string
+		expected    []string
+		expectError bool
+	}{
+		{"all values", map[string]string{"file": "hosts.txt", "old": "a", "new": "b"},
+			[]string{"replace", "--file", "hosts.txt", "--find", "a", "--replace", "b.a"}, false},
+		{"missing value", map[string]string{"file": "hosts.txt", "old": "a"}, nil, true},
+		{"unknown placeholder", map[string]string{"file": "x", "old": "a", "new": "b", "typo": "c"}, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			words, err := profile.Expand(test.values)
+			if test.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(words, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, words)
+			}
+		})
+	}
+}
+
+// TestSaveProfile tests adding and removing profiles in a configuration file.
+func TestSaveProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "optix", "config.yaml")
+	profile := config.Profile{Name: "scan", Description: "Find secrets", Command: "search", Args: []string{"-p", "{{word}}", "-f", "*.yaml"}}
+
+	if err := config.SaveProfile(path, profile, false); err != nil {
+		t.Fatalf("Failed to save profile: %v", err)
+	}
+	if err := config.SaveProfile(path, profile, false); err == nil {
+		t.Errorf("Expected error when saving an existing profile")
+	}
+	profile.Args = []string{"-p", "secret"}
+	if err := config.SaveProfile(path, profile, true); err != nil {
+		t.Fatalf("Failed to replace profile: %v", err)
+	}
+
+	// Other settings and comments are kept
+	writeFile(t, path, "# defaults\ncontext: 2\n"+readFile(t, path))
+	if err := config.SaveProfile(path, config.Profile{Name: "other", Command: "stats"}, false); err != nil {
+		t.Fatalf("Failed to save profile: %v", err)
+	}
+
+	settings, err := config.Load(config.Options{UserFile: path})
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	saved, ok := settings.Profile("scan")
+	if !ok {
+		t.Fatalf("Profile not found")
+	}
+	if saved.Description != "Find secrets" || !reflect.DeepEqual(saved.CommandLine(), []string{"search", "-p", "secret"}) {
+		t.Errorf("Unexpected profile %+v", saved)
+	}
+	if names := len(settings.Profiles()); names != 2 {
+		t.Errorf("Expected 2 profiles, got %d", names)
+	}
+	if setting, ok := settings.Lookup([]string{"search"}, "context"); !ok || setting.Value() != "2" {
+		t.Errorf("Expected the context setting to be kept")
+	}
+	if content := readFile(t, path); !strings.Contains(content, "# defaults") {
+		t.Errorf("Expected comments to be kept:\n%s", content)
+	}
+
+	removed, err := config.RemoveProfile(path, "scan")
+	if err != nil || !removed {
+		t.Fatalf("Failed to remove profile: %v", err)
+	}
+	if removed, _ := config.RemoveProfile(path, "scan"); removed {
+		t.Errorf("Expected the profile to be gone")
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
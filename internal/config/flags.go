@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// OriginAnnotation is the flag annotation that records where the value of
+// a flag set by Apply comes from.
+const OriginAnnotation = "optix-config-origin"
+
+// Apply sets the flags of the command with the given path that were not
+// given on the command line from the settings. Configured values are
+// validated by the flag itself and become its default: the flag is not
+// marked as changed, so checks on the flags given on the command line,
+// such as conflicting flags, ignore them. Use Configured to tell them from
+// built-in defaults.
+//
+// A top-level setting for a flag that is not one of the GlobalFlags is an
+// error, rather than a value the flag may not expect; e.g. "output: json"
+// would make replace write to a file named json.
+func (c *Config) Apply(commandPath []string, flags *pflag.FlagSet) error {
+	var applyErr error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if applyErr != nil || flag.Changed || flag.Name == "help" {
+			return
+		}
+		setting, ok := c.Lookup(commandPath, flag.Name)
+		if !ok {
+			if unscoped, found := c.unscoped(flag.Name); found && len(commandPath) > 0 {
+				applyErr = fmt.Errorf("%s sets '%s' for every command, but --%s means different things on different commands; set it for the command instead, e.g. under '%s:'",
+					unscoped.Origin, flag.Name, flag.Name, commandPath[0])
+			}
+			return
+		}
+		if err := flag.Value.Set(setting.Value()); err != nil {
+			applyErr = fmt.Errorf("invalid value '%s' for --%s from %s: %w", setting.Value(), flag.Name, setting.Origin, err)
+			return
+		}
+		flag.DefValue = flag.Value.String()
+		if flag.Annotations == nil {
+			flag.Annotations = make(map[string][]string)
+		}
+		flag.Annotations[OriginAnnotation] = []string{setting.Origin}
+	})
+	return applyErr
+}
+
+// Configured reports whether the value of flag was set by Apply.
+func Configured(flag *pflag.Flag) bool {
+	return flag != nil && len(flag.Annotations[OriginAnnotation]) > 0
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// patternsKey is the section of a configuration file that defines kinds
+// of values for 'optix extract', next to the built-in ones. A kind is a
+// regular expression, written on its own or with a description:
+//
+//	patterns:
+//	  order-id: '\bORD-\d{8}\b'
+//	  ticket:
+//	    description: Ticket reference
+//	    pattern: '\b[A-Z]{2,5}-\d+\b'
+//
+// When the expression has a group named "value", only that group is
+// extracted.
+const patternsKey = "patterns"
+
+// Pattern is a kind of value defined in a configuration file.
+type Pattern struct {
+	// Name is the name the kind is selected by
+	Name string
+
+	// Description says what the kind matches
+	Description string
+
+	// Expression is the regular expression that finds the values
+	Expression string
+
+	// Source is the kind of file the pattern was read from
+	Source string
+
+	// Origin is the file and line the pattern was read from
+	Origin string
+}
+
+// Patterns returns the patterns of every loaded file, sorted by name. A
+// pattern in the project file hides a user pattern of the same name.
+func (c *Config) Patterns() []Pattern {
+	seen := make(map[string]bool)
+	var patterns []Pattern
+	for _, l := range c.layers {
+		for name, pattern := range l.patterns {
+			if !seen[name] {
+				seen[name] = true
+				patterns = append(patterns, pattern)
+			}
+		}
+	}
+	sort.Slice(patterns, func(i, j int) bool { return patterns[i].Name < patterns[j].Name })
+	return patterns
+}
+
+// ParsePatterns parses the patterns section of a configuration file.
+// The Origin of each pattern holds its line number.
+func ParsePatterns(data []byte) (map[string]Pattern, error) {
+	patterns := make(map[string]Pattern)
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	section := sectionNode(&document, patternsKey)
+	if section == nil {
+		return patterns, nil
+	}
+	if section.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: '%s' must be a mapping of names to patterns", section.Line, patternsKey)
+	}
+
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		pattern, err := parsePattern(section.Content[i], section.Content[i+1])
+		if err != nil {
+			return nil, err
+		}
+		patterns[pattern.Name] = pattern
+	}
+	return patterns, nil
+}
+
+// parsePattern parses one entry of the patterns section.
+func parsePattern(keyNode, node *yaml.Node) (Pattern, error) {
+	// Unlike flag names, kind names are kept as written (e.g. order_id)
+	pattern := Pattern{Name: strings.TrimSpace(keyNode.Value), Origin: fmt.Sprintf("%d", keyNode.Line)}
+	if pattern.Name == "" {
+		return Pattern{}, fmt.Errorf("line %d: empty pattern name", keyNode.Line)
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		pattern.Expression = node.Value
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.Kind != yaml.ScalarNode {
+				return Pattern{}, fmt.Errorf("line %d: pattern '%s': '%s' must be a string", value.Line, pattern.Name, key.Value)
+			}
+			switch key.Value {
+			case "description":
+				pattern.Description = value.Value
+			case "pattern":
+				pattern.Expression = value.Value
+			default:
+				return Pattern{}, fmt.Errorf("line %d: pattern '%s': unknown field '%s'", key.Line, pattern.Name, key.Value)
+			}
+		}
+	default:
+		return Pattern{}, fmt.Errorf("line %d: pattern '%s' must be a regular expression or a mapping", node.Line, pattern.Name)
+	}
+
+	if pattern.Expression == "" {
+		return Pattern{}, fmt.Errorf("line %d: pattern '%s' has no regular expression", keyNode.Line, pattern.Name)
+	}
+	if _, err := regexp.Compile(pattern.Expression); err != nil {
+		return Pattern{}, fmt.Errorf("line %d: pattern '%s': %w", keyNode.Line, pattern.Name, err)
+	}
+	return pattern, nil
+}
@@ -0,0 +1,379 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profilesKey is the section of a configuration file that holds profiles.
+// A profile is a saved command line that can be run by name:
+//
+//	profiles:
+//	  secrets-scan:
+//	    description: Find secrets in configs
+//	    command: search
+//	    flags:
+//	      pattern: '(password|secret)\s*[:=]'
+//	      regex: true
+//	      files: '*.yaml'
+//	  rename-host:
+//	    command: replace
+//	    args: [--file, '{{file}}', --find, '{{old}}', --replace, '{{new}}', --backup]
+//
+// Words may contain {{name}} placeholders that are filled in when the
+// profile is run.
+const profilesKey = "profiles"
+
+// placeholderPattern matches a {{name}} placeholder.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// Profile is a named command line saved in a configuration file.
+type Profile struct {
+	// Name is the name the profile is run by
+	Name string
+
+	// Description explains what the profile does
+	Description string
+
+	// Command is the command to run, e.g. "search" or "json fmt"
+	Command string
+
+	// Args holds the flags and arguments passed to the command
+	Args []string
+
+	// Source is the kind of file the profile was read from
+	Source string
+
+	// Origin is the file and line the profile was read from
+	Origin string
+}
+
+// CommandLine returns the words of the command line, before placeholders
+// are filled in.
+func (p Profile) CommandLine() []string {
+	return append(strings.Fields(p.Command), p.Args...)
+}
+
+// Placeholders returns the names of the placeholders in the profile,
+// sorted and without duplicates.
+func (p Profile) Placeholders() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, word := range p.CommandLine() {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(word, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Expand returns the command line with the placeholders replaced by
+// values. Every placeholder needs a value, and every value a placeholder,
+// so a typo in a name is not silently ignored.
+func (p Profile) Expand(values map[string]string) ([]string, error) {
+	placeholders := p.Placeholders()
+
+	var missing []string
+	known := make(map[string]bool)
+	for _, name := range placeholders {
+		known[name] = true
+		if _, ok := values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("profile '%s' needs a value for %s (use --set NAME=VALUE)", p.Name, strings.Join(missing, ", "))
+	}
+
+	var unknown []string
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("profile '%s' has no placeholder %s", p.Name, strings.Join(unknown, ", "))
+	}
+
+	var words []string
+	for _, word := range p.CommandLine() {
+		words = append(words, placeholderPattern.ReplaceAllStringFunc(word, func(placeholder string) string {
+			return values[placeholderPattern.FindStringSubmatch(placeholder)[1]]
+		}))
+	}
+	return words, nil
+}
+
+// Profiles returns the profiles of every loaded file, sorted by name. A
+// profile in the project file hides a user profile of the same name.
+func (c *Config) Profiles() []Profile {
+	seen := make(map[string]bool)
+	var profiles []Profile
+	for _, l := range c.layers {
+		for name, profile := range l.profiles {
+			if !seen[name] {
+				seen[name] = true
+				profiles = append(profiles, profile)
+			}
+		}
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles
+}
+
+// Profile returns the profile with the given name.
+func (c *Config) Profile(name string) (Profile, bool) {
+	for _, l := range c.layers {
+		if profile, ok := l.profiles[name]; ok {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
+
+// ParseProfiles parses the profiles section of a configuration file.
+// The Origin of each profile holds its line number.
+func ParseProfiles(data []byte) (map[string]Profile, error) {
+	profiles := make(map[string]Profile)
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	section := profilesNode(&document)
+	if section == nil {
+		return profiles, nil
+	}
+	if section.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: '%s' must be a mapping of names to profiles", section.Line, profilesKey)
+	}
+
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		profile, err := parseProfile(section.Content[i], section.Content[i+1])
+		if err != nil {
+			return nil, err
+		}
+		profiles[profile.Name] = profile
+	}
+	return profiles, nil
+}
+
+// parseProfile parses one entry of the profiles section.
+func parseProfile(keyNode, node *yaml.Node) (Profile, error) {
+	profile := Profile{Name: keyNode.Value, Origin: fmt.Sprintf("%d", keyNode.Line)}
+	if err := ValidateProfileName(profile.Name); err != nil {
+		return Profile{}, fmt.Errorf("line %d: %w", keyNode.Line, err)
+	}
+	if node.Kind != yaml.MappingNode {
+		return Profile{}, fmt.Errorf("line %d: profile '%s' must be a mapping", node.Line, profile.Name)
+	}
+
+	var flags []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "description":
+			profile.Description = value.Value
+		case "command":
+			profile.Command = strings.Join(strings.Fields(value.Value), " ")
+		case "args":
+			if err := value.Decode(&profile.Args); err != nil {
+				return Profile{}, fmt.Errorf("line %d: profile '%s': 'args' must be a list of words", value.Line, profile.Name)
+			}
+		case "flags":
+			if value.Kind != yaml.MappingNode {
+				return Profile{}, fmt.Errorf("line %d: profile '%s': 'flags' must be a mapping", value.Line, profile.Name)
+			}
+			settings := make(map[string]Setting)
+			if err := parseMapping(value, nil, settings); err != nil {
+				return Profile{}, fmt.Errorf("profile '%s': %w", profile.Name, err)
+			}
+			// Keep the order of the file
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				setting := settings[normalizeName(value.Content[j].Value)]
+				if len(setting.Values) == 0 {
+					return Profile{}, fmt.Errorf("line %d: profile '%s': flag '%s' must have a value", value.Content[j].Line, profile.Name, value.Content[j].Value)
+				}
+				flags = append(flags, "--"+setting.Flag+"="+setting.Value())
+			}
+		default:
+			return Profile{}, fmt.Errorf("line %d: profile '%s': unknown field '%s'", key.Line, profile.Name, key.Value)
+		}
+	}
+
+	if profile.Command == "" {
+		return Profile{}, fmt.Errorf("line %d: profile '%s' has no command", keyNode.Line, profile.Name)
+	}
+	profile.Args = append(flags, profile.Args...)
+	return profile, nil
+}
+
+// ValidateProfileName checks that a profile name can be typed as a
+// single word.
+func ValidateProfileName(name string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\n") {
+		return fmt.Errorf("invalid profile name '%s'", name)
+	}
+	return nil
+}
+
+// SaveProfile adds a profile to a configuration file, creating the file
+// if needed. The rest of the file, including comments, is kept. An
+// existing profile of the same name is only replaced when replace is set.
+func SaveProfile(path string, profile Profile, replace bool) error {
+	if err := ValidateProfileName(profile.Name); err != nil {
+		return err
+	}
+
+	document, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+
+	root := document.Content[0]
+	section := profilesNode(document)
+	if section == nil {
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: profilesKey},
+			&yaml.Node{Kind: yaml.MappingNode})
+		section = root.Content[len(root.Content)-1]
+	}
+	if section.Kind != yaml.MappingNode {
+		return fmt.Errorf("config file '%s': '%s' must be a mapping", path, profilesKey)
+	}
+
+	node := profileNode(profile)
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		if section.Content[i].Value == profile.Name {
+			if !replace {
+				return fmt.Errorf("profile '%s' already exists in '%s'", profile.Name, path)
+			}
+			section.Content[i+1] = node
+			return writeDocument(path, document)
+		}
+	}
+
+	section.Content = append(section.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: profile.Name}, node)
+	return writeDocument(path, document)
+}
+
+// RemoveProfile removes a profile from a configuration file and reports
+// whether it was there.
+func RemoveProfile(path, name string) (bool, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return false, nil
+	}
+
+	document, err := readDocument(path)
+	if err != nil {
+		return false, err
+	}
+	section := profilesNode(document)
+	if section == nil || section.Kind != yaml.MappingNode {
+		return false, nil
+	}
+
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		if section.Content[i].Value == name {
+			section.Content = append(section.Content[:i], section.Content[i+2:]...)
+			return true, writeDocument(path, document)
+		}
+	}
+	return false, nil
+}
+
+// profileNode builds the YAML for a profile. Arguments are written as a
+// flow sequence so that a profile fits on a few lines.
+func profileNode(profile Profile) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	add := func(key string, value *yaml.Node) {
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+
+	if profile.Description != "" {
+		add("description", &yaml.Node{Kind: yaml.ScalarNode, Value: profile.Description})
+	}
+	add("command", &yaml.Node{Kind: yaml.ScalarNode, Value: profile.Command})
+	if len(profile.Args) > 0 {
+		args := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, arg := range profile.Args {
+			args.Content = append(args.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: arg})
+		}
+		add("args", args)
+	}
+	return node
+}
+
+// profilesNode returns the value of the profiles key of a document, or nil.
+func profilesNode(document *yaml.Node) *yaml.Node {
+	return sectionNode(document, profilesKey)
+}
+
+// sectionNode returns the value of a top-level key of a document, or nil.
+func sectionNode(document *yaml.Node, key string) *yaml.Node {
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	root := document.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if normalizeName(root.Content[i].Value) == key {
+			return root.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// readDocument reads a configuration file for editing. A missing or empty
+// file gives an empty mapping.
+func readDocument(path string) (*yaml.Node, error) {
+	document := &yaml.Node{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, document); err != nil {
+		return nil, fmt.Errorf("config file '%s': %w", path, err)
+	}
+
+	if len(document.Content) == 0 {
+		document.Kind = yaml.DocumentNode
+		document.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	if document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("config file '%s': expected a mapping", path)
+	}
+	return document, nil
+}
+
+// writeDocument writes an edited configuration file.
+func writeDocument(path string, document *yaml.Node) error {
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, buffer.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
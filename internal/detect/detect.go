@@ -0,0 +1,356 @@
+// Package detect guesses the format of a file from its name and the first
+// bytes of its content. It lets the reader layer handle files without a
+// recognised extension (Dockerfile, .env, extensionless logs) and files
+// whose extension does not match their content (data.txt holding JSON).
+package detect
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// SampleSize is the number of leading bytes inspected by Detect.
+const SampleSize = 8 * 1024
+
+// Confidence levels used by the detectors.
+const (
+	// StrongConfidence is high enough to override a generic extension such as .txt
+	StrongConfidence = 0.9
+	// FallbackConfidence is reported when nothing more specific matched
+	FallbackConfidence = 0.5
+)
+
+// Binary is the type reported for content that is not text.
+const Binary = "binary"
+
+// Result is the outcome of content detection.
+type Result struct {
+	// Type is a reader type name such as "json" or "csv", or Binary
+	Type string
+	// Confidence ranges from 0 to 1
+	Confidence float64
+	// Reason briefly explains the decision, e.g. "shebang (#!/bin/sh)"
+	Reason string
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%s (%.0f%% confidence: %s)", r.Type, r.Confidence*100, r.Reason)
+}
+
+// DefaultExtensionTypes maps extensions that have no dedicated reader to the
+// reader type that handles them best.
+var DefaultExtensionTypes = map[string]string{
+	".md":          "txt",
+	".markdown":    "txt",
+	".rst":         "txt",
+	".ini":         "txt",
+	".cfg":         "txt",
+	".conf":        "txt",
+	".env":         "txt",
+	".properties":  "txt",
+	".sh":          "txt",
+	".out":         "txt",
+	".err":         "txt",
+	".geojson":     "json",
+	".har":         "json",
+	".webmanifest": "json",
+	".svg":         "xml",
+	".xsd":         "xml",
+	".xsl":         "xml",
+	".plist":       "xml",
+}
+
+// wellKnownNames are extensionless files that are always plain text.
+var wellKnownNames = map[string]bool{
+	"dockerfile":    true,
+	"containerfile": true,
+	"makefile":      true,
+	"gnumakefile":   true,
+	"jenkinsfile":   true,
+	"vagrantfile":   true,
+	"gemfile":       true,
+	"procfile":      true,
+	"license":       true,
+	"readme":        true,
+	"changelog":     true,
+	"codeowners":    true,
+}
+
+// magicSignature identifies a binary format by its leading bytes.
+type magicSignature struct {
+	prefix []byte
+	name   string
+}
+
+var magicSignatures = []magicSignature{
+	{[]byte{0x1f, 0x8b}, "gzip"},
+	{[]byte("BZh"), "bzip2"},
+	{[]byte{0x28, 0xb5, 0x2f, 0xfd}, "zstd"},
+	{[]byte("PK\x03\x04"), "zip"},
+	{[]byte("%PDF-"), "PDF"},
+	{[]byte("\x89PNG\r\n\x1a\n"), "PNG"},
+	{[]byte{0xff, 0xd8, 0xff}, "JPEG"},
+	{[]byte("GIF8"), "GIF"},
+	{[]byte("\x7fELF"), "ELF"},
+	{[]byte("SQLite format 3\x00"), "SQLite"},
+}
+
+var (
+	tomlTablePattern    = regexp.MustCompile(`^\[\[?[A-Za-z0-9_.\-"' ]+\]\]?$`)
+	tomlKeyValuePattern = regexp.MustCompile(`^[A-Za-z0-9_\-."]+\s*=\s*("|'|\[|\{|true|false|[+-]?[0-9]|inf|nan)`)
+)
+
+// Sample reads up to SampleSize bytes from input.
+func Sample(input io.Reader) ([]byte, error) {
+	sample := make([]byte, SampleSize)
+	n, err := io.ReadFull(input, sample)
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		err = nil
+	}
+	return sample[:n], err
+}
+
+// Detect guesses the type of a file from its name and a sample of its
+// leading bytes. A sample shorter than SampleSize is taken to be the whole
+// file. Detect always returns a result; plain text is the fallback.
+func Detect(filename string, sample []byte) Result {
+	complete := len(sample) < SampleSize
+	sample = bytes.TrimPrefix(sample, []byte("\xef\xbb\xbf"))
+
+	if len(bytes.TrimSpace(sample)) == 0 {
+		return Result{Type: "txt", Confidence: FallbackConfidence, Reason: "empty file"}
+	}
+
+	for _, signature := range magicSignatures {
+		if bytes.HasPrefix(sample, signature.prefix) {
+			return Result{Type: Binary, Confidence: 1, Reason: signature.name + " magic bytes"}
+		}
+	}
+
+	if offset := bytes.IndexByte(sample, 0); offset != -1 {
+		return Result{Type: Binary, Confidence: 0.95, Reason: fmt.Sprintf("NUL byte at offset %d", offset)}
+	}
+	if ratio := controlRatio(sample); ratio > 0.1 {
+		return Result{Type: Binary, Confidence: 0.8, Reason: fmt.Sprintf("%.0f%% control characters", ratio*100)}
+	}
+
+	base := strings.ToLower(filepath.Base(filename))
+	if wellKnownNames[base] || strings.HasPrefix(base, ".env") {
+		return Result{Type: "txt", Confidence: 1, Reason: "well-known file name"}
+	}
+
+	if bytes.HasPrefix(sample, []byte("#!")) {
+		line, _, _ := bytes.Cut(sample, []byte("\n"))
+		return Result{Type: "txt", Confidence: StrongConfidence, Reason: fmt.Sprintf("shebang (%s)", strings.TrimSpace(string(line)))}
+	}
+
+	// Drop a line cut off by the end of the sample so it cannot fail the checks below
+	if !complete {
+		if newline := bytes.LastIndexByte(sample, '\n'); newline != -1 {
+			sample = sample[:newline+1]
+		}
+	}
+
+	lines := nonBlankLines(sample)
+	for _, detector := range []func([]byte, []string, bool) (Result, bool){detectJSON, detectXML, detectYAML, detectTOML, detectCSV} {
+		if result, ok := detector(sample, lines, complete); ok {
+			return result
+		}
+	}
+
+	return Result{Type: "txt", Confidence: FallbackConfidence, Reason: "plain text"}
+}
+
+func detectJSON(sample []byte, lines []string, complete bool) (Result, bool) {
+	trimmed := bytes.TrimSpace(sample)
+	if trimmed[0] != '{' && trimmed[0] != '[' {
+		return Result{}, false
+	}
+
+	if complete && json.Valid(trimmed) {
+		return Result{Type: "json", Confidence: 0.95, Reason: "valid JSON document"}, true
+	}
+
+	// Several lines that are each a JSON value form a JSON Lines stream
+	if len(lines) > 1 {
+		valid := 0
+		for _, line := range lines {
+			if json.Valid([]byte(line)) {
+				valid++
+			}
+		}
+		if valid == len(lines) {
+			return Result{Type: "jsonl", Confidence: StrongConfidence, Reason: fmt.Sprintf("%d lines of JSON", valid)}, true
+		}
+	}
+
+	// A truncated sample cannot be validated; a JSON decoder getting past
+	// the first tokens is still a good sign
+	if !complete && jsonPrefixValid(trimmed) {
+		return Result{Type: "json", Confidence: 0.7, Reason: "starts like a JSON document"}, true
+	}
+
+	return Result{}, false
+}
+
+// jsonPrefixValid reports whether data decodes as JSON up to the end of the sample.
+func jsonPrefixValid(data []byte) bool {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return true
+		}
+		if err != nil {
+			// Running out of input mid-value is expected for a sample
+			return err == io.ErrUnexpectedEOF
+		}
+	}
+}
+
+func detectXML(sample []byte, lines []string, complete bool) (Result, bool) {
+	trimmed := bytes.TrimSpace(sample)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<?xml")):
+		return Result{Type: "xml", Confidence: 0.95, Reason: "XML declaration"}, true
+	case bytes.HasPrefix(bytes.ToLower(trimmed), []byte("<!doctype html")), bytes.HasPrefix(bytes.ToLower(trimmed), []byte("<html")):
+		return Result{Type: "txt", Confidence: 0.7, Reason: "HTML document"}, true
+	case len(trimmed) > 1 && trimmed[0] == '<' && isNameStart(trimmed[1]) && bytes.Contains(trimmed, []byte("</")):
+		return Result{Type: "xml", Confidence: 0.7, Reason: "starts with an XML element"}, true
+	}
+	return Result{}, false
+}
+
+func detectYAML(sample []byte, lines []string, complete bool) (Result, bool) {
+	if len(lines) > 0 && strings.TrimRight(lines[0], " \t\r") == "---" {
+		return Result{Type: "yaml", Confidence: 0.7, Reason: "YAML document marker"}, true
+	}
+	return Result{}, false
+}
+
+func detectTOML(sample []byte, lines []string, complete bool) (Result, bool) {
+	tables := 0
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "#"):
+		case tomlTablePattern.MatchString(line):
+			tables++
+		case tomlKeyValuePattern.MatchString(line):
+		default:
+			return Result{}, false
+		}
+	}
+
+	// Without a [table] header, "key = value" lines are just as likely .env or INI
+	if tables == 0 {
+		return Result{}, false
+	}
+	return Result{Type: "toml", Confidence: 0.75, Reason: "TOML tables and key/value pairs"}, true
+}
+
+func detectCSV(sample []byte, lines []string, complete bool) (Result, bool) {
+	if len(lines) < 2 {
+		return Result{}, false
+	}
+
+	best := Result{}
+	for _, delimiter := range []rune{',', '\t', ';'} {
+		reader := csv.NewReader(bytes.NewReader(sample))
+		reader.Comma = delimiter
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+
+		counts := make(map[int]int)
+		records := 0
+		for {
+			record, err := reader.Read()
+			if err != nil {
+				break
+			}
+			counts[len(record)]++
+			records++
+		}
+		if records < 2 {
+			continue
+		}
+
+		// The most common field count must be >1 and shared by most records
+		fields, consistent := 0, 0
+		for count, occurrences := range counts {
+			if occurrences > consistent || (occurrences == consistent && count > fields) {
+				fields, consistent = count, occurrences
+			}
+		}
+		ratio := float64(consistent) / float64(records)
+		if fields < 2 || ratio < 0.9 {
+			continue
+		}
+
+		confidence := 0.5 + 0.3*ratio
+		if records >= 5 {
+			confidence += 0.1
+		}
+		if confidence > best.Confidence {
+			best = Result{
+				Type:       "csv",
+				Confidence: confidence,
+				Reason:     fmt.Sprintf("%d of %d rows have %d %s-separated fields", consistent, records, fields, delimiterName(delimiter)),
+			}
+		}
+	}
+
+	return best, best.Type != ""
+}
+
+func delimiterName(delimiter rune) string {
+	switch delimiter {
+	case '\t':
+		return "tab"
+	case ';':
+		return "semicolon"
+	default:
+		return "comma"
+	}
+}
+
+func nonBlankLines(sample []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(sample), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// controlRatio returns the share of bytes that are neither printable text
+// nor common whitespace. Invalid UTF-8 sequences count as control bytes.
+func controlRatio(sample []byte) float64 {
+	control := 0
+	for i := 0; i < len(sample); {
+		r, size := utf8.DecodeRune(sample[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			// A multi-byte sequence cut off by the sample boundary is not binary
+			if len(sample)-i >= utf8.UTFMax {
+				control++
+			}
+		case r < 0x20 && r != '\n' && r != '\r' && r != '\t' && r != '\f' && r != '\v' && r != 0x1b:
+			control++
+		}
+		i += size
+	}
+	return float64(control) / float64(len(sample))
+}
+
+func isNameStart(b byte) bool {
+	return b == '_' || b == ':' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
@@ -0,0 +1,86 @@
+package detect_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kcansari/optix/internal/detect"
+)
+
+// TestDetect tests content detection for the supported formats.
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name          string
+		filename      string
+		content       string
+		expectedType  string
+		minConfidence float64
+	}{
+		{"json object", "data.txt", `{"name": "optix", "tags": ["a", "b"]}`, "json", detect.StrongConfidence},
+		{"json array with BOM", "export", "\xef\xbb\xbf[1, 2, 3]\n", "json", detect.StrongConfidence},
+		{"json lines", "events", "{\"id\": 1}\n{\"id\": 2}\n\n{\"id\": 3}\n", "jsonl", detect.StrongConfidence},
+		{"invalid json", "notes", "{ this is not json }\n", "txt", 0},
+		{"log with brackets", "app", "[INFO] started\n[WARN] slow request\n", "txt", 0},
+		{"xml declaration", "feed", "<?xml version=\"1.0\"?>\n<feed/>\n", "xml", detect.StrongConfidence},
+		{"xml element", "feed", "<feed><entry>x</entry></feed>\n", "xml", 0.7},
+		{"html", "page", "<!DOCTYPE html>\n<html></html>\n", "txt", 0},
+		{"yaml", "deploy", "---\nname: optix\n", "yaml", 0.7},
+		{"toml", "settings", "# settings\ntitle = \"x\"\n\n[server]\nport = 8080\n", "toml", 0.7},
+		{"env file", ".env.local", "KEY=value\nOTHER=x\n", "txt", 1},
+		{"csv", "export", "name,age\nAlice,30\nBob,25\n\"Smith, J\",40\n", "csv", 0.8},
+		{"tsv", "export", "name\tage\nAlice\t30\nBob\t25\n", "csv", 0.8},
+		{"prose with commas", "notes", "Hello, world.\nThis line, has, many, commas.\nNone here\n", "txt", 0},
+		{"shebang", "deploy", "#!/bin/sh\necho hi\n", "txt", detect.StrongConfidence},
+		{"dockerfile", "Dockerfile", "FROM alpine\nRUN echo {}\n", "txt", 1},
+		{"nul bytes", "blob", "abc\x00def", detect.Binary, detect.StrongConfidence},
+		{"gzip", "app.log.1", "\x1f\x8b\x08\x00rest", detect.Binary, 1},
+		{"empty", "empty", "", "txt", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := detect.Detect(test.filename, []byte(test.content))
+
+			if result.Type != test.expectedType {
+				t.Errorf("Expected type %s, got %s", test.expectedType, result)
+			}
+			if result.Confidence < test.minConfidence {
+				t.Errorf("Expected confidence >= %.2f, got %s", test.minConfidence, result)
+			}
+			if result.Reason == "" {
+				t.Error("Expected a reason")
+			}
+		})
+	}
+}
+
+// TestDetectTruncatedSample tests detection on samples cut off at SampleSize.
+func TestDetectTruncatedSample(t *testing.T) {
+	var builder strings.Builder
+	builder.WriteString(`{"items": [`)
+	for builder.Len() < detect.SampleSize+100 {
+		builder.WriteString(`{"id": 1, "name": "item"}, `)
+	}
+
+	sample, err := detect.Sample(strings.NewReader(builder.String()))
+	if err != nil {
+		t.Fatalf("Failed to sample: %v", err)
+	}
+	if len(sample) != detect.SampleSize {
+		t.Fatalf("Expected sample of %d bytes, got %d", detect.SampleSize, len(sample))
+	}
+
+	result := detect.Detect("big", sample)
+	if result.Type != "json" {
+		t.Errorf("Expected json for truncated document, got %s", result)
+	}
+	if result.Confidence >= detect.StrongConfidence {
+		t.Errorf("Expected reduced confidence for an unverified document, got %s", result)
+	}
+
+	// A multi-byte character cut by the sample boundary is still text
+	text := strings.Repeat("é", detect.SampleSize)
+	if result := detect.Detect("notes", []byte(text)[:detect.SampleSize-1]); result.Type == detect.Binary {
+		t.Errorf("Expected text for UTF-8 content, got %s", result)
+	}
+}
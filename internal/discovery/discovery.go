@@ -0,0 +1,384 @@
+// Package discovery turns the file patterns given on the command line into
+// the inputs a command processes. Plain files are returned as they are;
+// zip and tar archives (optionally gzip, bzip2, zlib or zstd compressed)
+// are expanded into their members, which are named like
+// "bundle.tar.gz!/var/log/app.log".
+package discovery
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kcansari/optix/internal/compression"
+	"github.com/kcansari/optix/internal/reader"
+)
+
+// MemberSeparator separates an archive path from a member path.
+const MemberSeparator = "!/"
+
+// StdinName is the file name that stands for standard input.
+const StdinName = "-"
+
+// StdinDisplayName names standard input in output.
+const StdinDisplayName = "(standard input)"
+
+// MaxMemberSize is the largest archive member that is loaded into memory.
+const MaxMemberSize = 256 * 1024 * 1024
+
+// maxNestingDepth limits how deep archives inside archives are expanded.
+const maxNestingDepth = 3
+
+// ArchiveFormat identifies a supported archive container.
+type ArchiveFormat string
+
+const (
+	NotArchive ArchiveFormat = ""
+	Zip        ArchiveFormat = "zip"
+	Tar        ArchiveFormat = "tar"
+)
+
+// Target is a file on disk matched by a pattern. MemberPattern restricts
+// which archive members are visited (empty means all of them).
+type Target struct {
+	Path          string
+	MemberPattern string
+}
+
+// Entry is a single input: a plain file or an archive member.
+type Entry struct {
+	// Name identifies the entry in output, e.g. "logs.zip!/app.log"
+	Name string
+
+	// Path is the file on disk; for members it is the outermost archive
+	Path string
+
+	// Member is the path inside the archive, empty for plain files
+	Member string
+
+	data []byte
+	err  error
+}
+
+// InArchive reports whether the entry is an archive member.
+func (e Entry) InArchive() bool {
+	return e.Member != ""
+}
+
+// IsStdin reports whether the entry holds standard input.
+func (e Entry) IsStdin() bool {
+	return e.Path == StdinName
+}
+
+// ReadStdin reads input, normally os.Stdin, into an entry. The content is
+// kept in memory because standard input can only be read once.
+func ReadStdin(input io.Reader) (Entry, error) {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to read standard input: %w", err)
+	}
+	return Entry{Name: StdinDisplayName, Path: StdinName, data: data}, nil
+}
+
+// Nested reports whether the entry is a member of an archive that is itself
+// stored in another archive.
+func (e Entry) Nested() bool {
+	return strings.Count(strings.TrimPrefix(e.Name, e.Path), MemberSeparator) > 1
+}
+
+// Data returns the raw bytes of an archive member or of standard input.
+func (e Entry) Data() ([]byte, error) {
+	return e.data, e.err
+}
+
+// Raw returns the decompressed bytes of the entry without choosing a
+// reader, together with the compression they were stored with.
+func (e Entry) Raw() ([]byte, compression.Format, error) {
+	if e.err != nil {
+		return nil, compression.None, e.err
+	}
+
+	var input io.ReadCloser
+	var format compression.Format
+	if e.InArchive() || e.IsStdin() {
+		format = compression.DetectNamed(e.DataName(), e.data[:min(len(e.data), compression.HeaderSize)])
+		if format == compression.None {
+			return e.data, format, nil
+		}
+		decompressor, err := compression.NewReader(format, bytes.NewReader(e.data))
+		if err != nil {
+			return nil, format, fmt.Errorf("failed to decompress '%s': %w", e.Name, err)
+		}
+		input = decompressor
+	} else {
+		file, fileFormat, err := compression.Open(e.Path)
+		if err != nil {
+			return nil, fileFormat, err
+		}
+		input, format = file, fileFormat
+	}
+	defer input.Close()
+
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, format, fmt.Errorf("failed to read '%s': %w", e.Name, err)
+	}
+	return data, format, nil
+}
+
+// Read reads the entry with the reader selected for its name and content.
+func (e Entry) Read(strategy *reader.FileReaderStrategy) (*reader.FileContent, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	if !e.InArchive() && !e.IsStdin() {
+		return strategy.ReadFile(e.Path)
+	}
+	return strategy.ReadData(e.DataName(), e.data)
+}
+
+// DataName returns the name that selects a reader for in-memory content:
+// the member path of an archive member, the display name of standard input.
+func (e Entry) DataName() string {
+	if e.InArchive() {
+		return e.Member
+	}
+	return e.Name
+}
+
+// Expand resolves a glob pattern into targets. A pattern may select archive
+// members after the separator: "bundle.zip!/var/log/*.log".
+func Expand(pattern string) ([]Target, error) {
+	filePattern, memberPattern, _ := strings.Cut(pattern, MemberSeparator)
+
+	if memberPattern != "" {
+		if _, err := path.Match(memberPattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid member pattern '%s': %w", memberPattern, err)
+		}
+	}
+
+	paths, err := filepath.Glob(filePattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file pattern '%s': %w", filePattern, err)
+	}
+
+	targets := make([]Target, 0, len(paths))
+	for _, p := range paths {
+		targets = append(targets, Target{Path: p, MemberPattern: memberPattern})
+	}
+	return targets, nil
+}
+
+// Walk calls fn for the target itself or, for archives, for every regular
+// member that matches the target's member pattern. Nested archives are
+// expanded as well. Returning an error from fn stops the walk.
+func Walk(target Target, fn func(Entry) error) error {
+	format, err := DetectArchive(target.Path)
+	if err != nil {
+		return err
+	}
+
+	if format == NotArchive {
+		if target.MemberPattern != "" {
+			return fmt.Errorf("'%s' is not an archive", target.Path)
+		}
+		return fn(Entry{Name: target.Path, Path: target.Path})
+	}
+
+	walker := &walker{root: target.Path, memberPattern: target.MemberPattern, fn: fn}
+
+	if format == Zip {
+		file, err := os.Open(target.Path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		return walker.walkZip(target.Path, "", file, info.Size(), 0)
+	}
+
+	input, _, err := compression.Open(target.Path)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	return walker.walkTar(target.Path, "", input, 0)
+}
+
+// IsArchive reports whether filename is a zip or tar archive.
+func IsArchive(filename string) (bool, error) {
+	format, err := DetectArchive(filename)
+	return format != NotArchive, err
+}
+
+// DetectArchive identifies zip and tar archives from their content. Tar
+// archives may be compressed in any format the compression package reads.
+func DetectArchive(filename string) (ArchiveFormat, error) {
+	input, _, err := compression.Open(filename)
+	if err != nil {
+		return NotArchive, err
+	}
+	defer input.Close()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(input, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return NotArchive, err
+	}
+
+	return detectArchiveFormat(compression.TrimExtension(filename), header[:n]), nil
+}
+
+// detectArchiveFormat identifies an archive from its (decompressed) header.
+func detectArchiveFormat(name string, header []byte) ArchiveFormat {
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return Zip
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return Tar
+	case strings.EqualFold(filepath.Ext(name), ".tar") && len(header) >= 512:
+		// Pre-POSIX tar archives have no magic; trust the extension
+		return Tar
+	}
+	return NotArchive
+}
+
+// walker carries the state shared by a recursive archive walk.
+type walker struct {
+	root          string
+	memberPattern string
+	fn            func(Entry) error
+}
+
+func (w *walker) walkZip(displayName, prefix string, input io.ReaderAt, size int64, depth int) error {
+	archive, err := zip.NewReader(input, size)
+	if err != nil {
+		return fmt.Errorf("failed to open zip archive '%s': %w", displayName, err)
+	}
+
+	for _, file := range archive.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		var data []byte
+		rc, err := file.Open()
+		if err == nil {
+			data, err = readMember(rc, int64(file.UncompressedSize64))
+			rc.Close()
+		}
+		if err := w.visit(displayName, prefix+file.Name, data, err, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) walkTar(displayName, prefix string, input io.Reader, depth int) error {
+	archive := tar.NewReader(bufio.NewReader(input))
+
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive '%s': %w", displayName, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := readMember(archive, header.Size)
+		if err := w.visit(displayName, prefix+strings.TrimPrefix(header.Name, "./"), data, err, depth); err != nil {
+			return err
+		}
+	}
+}
+
+// visit reports a member, or expands it when it is an archive itself.
+func (w *walker) visit(archiveName, member string, data []byte, readErr error, depth int) error {
+	name := archiveName + MemberSeparator + strings.TrimPrefix(member, "/")
+
+	if readErr == nil && depth < maxNestingDepth {
+		if format, inner := nestedArchive(member, data); format != NotArchive {
+			if format == Zip {
+				return w.walkZip(name, "", bytes.NewReader(inner), int64(len(inner)), depth+1)
+			}
+			return w.walkTar(name, "", bytes.NewReader(inner), depth+1)
+		}
+	}
+
+	if w.memberPattern != "" && !matchMember(w.memberPattern, strings.TrimPrefix(name, w.root+MemberSeparator)) {
+		return nil
+	}
+
+	entry := Entry{Name: name, Path: w.root, Member: member, data: data, err: readErr}
+	if readErr != nil {
+		entry.err = fmt.Errorf("failed to read '%s': %w", name, readErr)
+	}
+	return w.fn(entry)
+}
+
+// nestedArchive returns the archive format and decompressed bytes of a
+// member that is itself an archive.
+func nestedArchive(member string, data []byte) (ArchiveFormat, []byte) {
+	format := compression.DetectNamed(member, data[:min(len(data), compression.HeaderSize)])
+	if format != compression.None {
+		input, err := compression.NewReader(format, bytes.NewReader(data))
+		if err != nil {
+			return NotArchive, nil
+		}
+		defer input.Close()
+
+		decompressed, err := readMember(input, -1)
+		if err != nil {
+			return NotArchive, nil
+		}
+		data = decompressed
+		member = compression.TrimExtension(member)
+	}
+
+	return detectArchiveFormat(member, data[:min(len(data), 512)]), data
+}
+
+// matchMember matches a member path against a pattern; a pattern without a
+// slash also matches the base name, so "*.log" finds logs in any directory.
+func matchMember(pattern, member string) bool {
+	if matched, _ := path.Match(pattern, member); matched {
+		return true
+	}
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(member))
+		return matched
+	}
+	return false
+}
+
+// readMember reads a member, refusing members larger than MaxMemberSize.
+func readMember(input io.Reader, size int64) ([]byte, error) {
+	if size > MaxMemberSize {
+		return nil, fmt.Errorf("member is larger than %d bytes", MaxMemberSize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(input, MaxMemberSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxMemberSize {
+		return nil, fmt.Errorf("member is larger than %d bytes", MaxMemberSize)
+	}
+	return data, nil
+}
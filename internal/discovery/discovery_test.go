@@ -0,0 +1,393 @@
+package discovery_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kcansari/optix/internal/compression"
+	"github.com/kcansari/optix/internal/discovery"
+	"github.com/kcansari/optix/internal/reader/strategies"
+)
+
+// member is a file stored in a test archive.
+type member struct {
+	name string
+	data string
+}
+
+// buildZip returns a zip archive holding the given members.
+func buildZip(t *testing.T, members []member) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for _, m := range members {
+		w, err := archive.Create(m.name)
+		if err != nil {
+			t.Fatalf("Failed to create zip member: %v", err)
+		}
+		w.Write([]byte(m.data))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to close zip archive: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+// buildTar returns a tar archive holding the given members.
+func buildTar(t *testing.T, members []member) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	archive := tar.NewWriter(&buffer)
+	for _, m := range members {
+		header := &tar.Header{Name: m.name, Mode: 0644, Size: int64(len(m.data)), Typeflag: tar.TypeReg}
+		if err := archive.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		archive.Write([]byte(m.data))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to close tar archive: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+// writeFile writes data to name in dir and returns the path.
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// collect walks a target and returns the entry names and their content.
+func collect(t *testing.T, target discovery.Target) map[string]string {
+	t.Helper()
+
+	entries := map[string]string{}
+	err := discovery.Walk(target, func(entry discovery.Entry) error {
+		data, err := entry.Data()
+		if err != nil {
+			return err
+		}
+		if !entry.InArchive() {
+			data, err = os.ReadFile(entry.Path)
+			if err != nil {
+				return err
+			}
+		}
+		entries[strings.TrimPrefix(entry.Name, filepath.Dir(target.Path)+string(filepath.Separator))] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	return entries
+}
+
+// TestWalk tests that archives are expanded into named members.
+func TestWalk(t *testing.T) {
+	dir := t.TempDir()
+	members := []member{
+		{name: "var/log/app.log", data: "INFO started\nERROR failed\n"},
+		{name: "README.txt", data: "hello\n"},
+	}
+
+	tarball, err := compression.Compress(compression.Gzip, buildTar(t, members))
+	if err != nil {
+		t.Fatalf("Failed to compress: %v", err)
+	}
+	nested := buildZip(t, []member{{name: "inner.log", data: "nested\n"}})
+
+	tests := []struct {
+		name     string
+		file     string
+		data     []byte
+		pattern  string
+		expected map[string]string
+	}{
+		{
+			name: "zip archive",
+			file: "bundle.zip",
+			data: buildZip(t, members),
+			expected: map[string]string{
+				"bundle.zip!/var/log/app.log": "INFO started\nERROR failed\n",
+				"bundle.zip!/README.txt":      "hello\n",
+			},
+		},
+		{
+			name: "compressed tar archive",
+			file: "bundle.tar.gz",
+			data: tarball,
+			expected: map[string]string{
+				"bundle.tar.gz!/var/log/app.log": "INFO started\nERROR failed\n",
+				"bundle.tar.gz!/README.txt":      "hello\n",
+			},
+		},
+		{
+			name:    "member pattern matches base name",
+			file:    "bundle.tar.gz",
+			data:    tarball,
+			pattern: "*.log",
+			expected: map[string]string{
+				"bundle.tar.gz!/var/log/app.log": "INFO started\nERROR failed\n",
+			},
+		},
+		{
+			name:    "member pattern with directory",
+			file:    "bundle.zip",
+			data:    buildZip(t, members),
+			pattern: "var/*/*.log",
+			expected: map[string]string{
+				"bundle.zip!/var/log/app.log": "INFO started\nERROR failed\n",
+			},
+		},
+		{
+			name: "nested archive",
+			file: "outer.tar",
+			data: buildTar(t, []member{{name: "logs.zip", data: string(nested)}}),
+			expected: map[string]string{
+				"outer.tar!/logs.zip!/inner.log": "nested\n",
+			},
+		},
+		{
+			name: "plain file",
+			file: "notes.txt",
+			data: []byte("just text\n"),
+			expected: map[string]string{
+				"notes.txt": "just text\n",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeFile(t, dir, test.file, test.data)
+
+			entries := collect(t, discovery.Target{Path: path, MemberPattern: test.pattern})
+			if !reflect.DeepEqual(entries, test.expected) {
+				t.Errorf("Expected entries %v, got %v", test.expected, entries)
+			}
+		})
+	}
+}
+
+// TestExpand tests splitting patterns into files and member patterns.
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "bundle.zip", buildZip(t, []member{{name: "a.log", data: "a\n"}}))
+
+	targets, err := discovery.Expand(filepath.Join(dir, "*.zip") + "!/*.log")
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+
+	expected := []discovery.Target{{Path: path, MemberPattern: "*.log"}}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("Expected %v, got %v", expected, targets)
+	}
+
+	if _, err := discovery.Expand(path + "!/[invalid"); err == nil {
+		t.Error("Expected an error for an invalid member pattern")
+	}
+
+	err = discovery.Walk(discovery.Target{Path: writeFile(t, dir, "plain.txt", []byte("x\n")), MemberPattern: "*.log"}, func(discovery.Entry) error {
+		return nil
+	})
+	if err == nil {
+		t.Error("Expected an error for a member pattern on a plain file")
+	}
+}
+
+// TestIsArchive tests archive detection from content.
+func TestIsArchive(t *testing.T) {
+	dir := t.TempDir()
+	gzipped, _ := compression.Compress(compression.Gzip, []byte("not an archive\n"))
+
+	tests := []struct {
+		file     string
+		data     []byte
+		expected bool
+	}{
+		{file: "bundle.zip", data: buildZip(t, []member{{name: "a", data: "a"}}), expected: true},
+		{file: "bundle.tar", data: buildTar(t, []member{{name: "a", data: "a"}}), expected: true},
+		{file: "renamed.bin", data: buildTar(t, []member{{name: "a", data: "a"}}), expected: true},
+		{file: "app.log.gz", data: gzipped, expected: false},
+		{file: "notes.txt", data: []byte("PK is not enough\n"), expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			isArchive, err := discovery.IsArchive(writeFile(t, dir, test.file, test.data))
+			if err != nil {
+				t.Fatalf("IsArchive failed: %v", err)
+			}
+			if isArchive != test.expected {
+				t.Errorf("Expected IsArchive %t, got %t", test.expected, isArchive)
+			}
+		})
+	}
+}
+
+// TestEntryRead tests that members are read with the reader for their name.
+func TestEntryRead(t *testing.T) {
+	dir := t.TempDir()
+	gzipped, _ := compression.Compress(compression.Gzip, []byte("line one\nline two\n"))
+	path := writeFile(t, dir, "bundle.zip", buildZip(t, []member{
+		{name: "data.json", data: `{"name": "optix"}`},
+		{name: "app.log.gz", data: string(gzipped)},
+	}))
+
+	strategy := strategies.NewDefaultFileReaderStrategy()
+	types := map[string]string{}
+	compressed := map[string]string{}
+
+	err := discovery.Walk(discovery.Target{Path: path}, func(entry discovery.Entry) error {
+		content, err := entry.Read(strategy)
+		if err != nil {
+			return err
+		}
+		types[entry.Member] = content.FileType
+		compressed[entry.Member] = content.Compression
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	if types["data.json"] != "json" || types["app.log.gz"] != "txt" {
+		t.Errorf("Unexpected member types: %v", types)
+	}
+	if compressed["app.log.gz"] != "gzip" {
+		t.Errorf("Expected app.log.gz to be read as gzip, got '%s'", compressed["app.log.gz"])
+	}
+}
+
+// TestReadStdin tests reading standard input into an entry.
+func TestReadStdin(t *testing.T) {
+	strategy := strategies.NewDefaultFileReaderStrategy()
+
+	entry, err := discovery.ReadStdin(strings.NewReader(`{"name": "optix"}`))
+	if err != nil {
+		t.Fatalf("ReadStdin failed: %v", err)
+	}
+	if !entry.IsStdin() || entry.InArchive() || entry.Name != discovery.StdinDisplayName {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+
+	// Without an extension the reader is chosen by content
+	content, err := entry.Read(strategy)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if content.FileType != "json" {
+		t.Errorf("Expected json, got '%s'", content.FileType)
+	}
+
+	gzipped, _ := compression.Compress(compression.Gzip, []byte("line one\n"))
+	entry, err = discovery.ReadStdin(bytes.NewReader(gzipped))
+	if err != nil {
+		t.Fatalf("ReadStdin failed: %v", err)
+	}
+	content, err = entry.Read(strategy)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if content.Content != "line one\n" || content.Compression != "gzip" {
+		t.Errorf("Expected decompressed text, got %q (%s)", content.Content, content.Compression)
+	}
+
+	raw, format, err := entry.Raw()
+	if err != nil || string(raw) != "line one\n" || format != compression.Gzip {
+		t.Errorf("Expected raw decompressed data, got %q (%s, %v)", raw, format, err)
+	}
+}
+
+// TestRewrite tests that changed members are replaced and the rest are kept.
+func TestRewrite(t *testing.T) {
+	members := []member{
+		{name: "var/log/app.log", data: "ERROR failed\n"},
+		{name: "README.txt", data: "hello\n"},
+	}
+	tarball, _ := compression.Compress(compression.Gzip, buildTar(t, members))
+
+	tests := []struct {
+		file string
+		data []byte
+	}{
+		{file: "bundle.zip", data: buildZip(t, members)},
+		{file: "bundle.tar.gz", data: tarball},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			dir := t.TempDir()
+			path := writeFile(t, dir, test.file, test.data)
+
+			changed, err := discovery.Rewrite(path, func(entry discovery.Entry) ([]byte, bool, error) {
+				data, _ := entry.Data()
+				if !strings.Contains(string(data), "ERROR") {
+					return nil, false, nil
+				}
+				return []byte(strings.ReplaceAll(string(data), "ERROR", "WARN")), true, nil
+			})
+			if err != nil {
+				t.Fatalf("Rewrite failed: %v", err)
+			}
+			if changed != 1 {
+				t.Errorf("Expected 1 changed member, got %d", changed)
+			}
+
+			entries := collect(t, discovery.Target{Path: path})
+			expected := map[string]string{
+				test.file + "!/var/log/app.log": "WARN failed\n",
+				test.file + "!/README.txt":      "hello\n",
+			}
+			if !reflect.DeepEqual(entries, expected) {
+				t.Errorf("Expected entries %v, got %v", expected, entries)
+			}
+
+			header, _ := os.ReadFile(path)
+			if format := compression.Detect(header[:compression.HeaderSize]); string(format) != string(compression.FromExtension(test.file)) {
+				t.Errorf("Expected compression '%s' to be kept, got '%s'", compression.FromExtension(test.file), format)
+			}
+		})
+	}
+}
+
+// TestRewriteUnchanged tests that an archive without changes is left alone.
+func TestRewriteUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	original := buildZip(t, []member{{name: "a.txt", data: "a\n"}})
+	path := writeFile(t, dir, "bundle.zip", original)
+
+	changed, err := discovery.Rewrite(path, func(discovery.Entry) ([]byte, bool, error) {
+		return nil, false, nil
+	})
+	if err != nil {
+		t.Fatalf("Rewrite failed: %v", err)
+	}
+	if changed != 0 {
+		t.Errorf("Expected no changed members, got %d", changed)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !bytes.Equal(data, original) {
+		t.Error("Expected the archive to be left unchanged")
+	}
+
+	leftovers, _ := filepath.Glob(filepath.Join(dir, ".*.tmp"))
+	if len(leftovers) != 0 {
+		t.Errorf("Expected temporary files to be removed, found %v", leftovers)
+	}
+}
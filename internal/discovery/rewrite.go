@@ -0,0 +1,201 @@
+package discovery
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kcansari/optix/internal/compression"
+)
+
+// RewriteFunc returns the new content of a member and whether it changed.
+// Members that are left unchanged are copied byte for byte.
+type RewriteFunc func(entry Entry) (data []byte, changed bool, err error)
+
+// Rewrite rebuilds the archive at filename, replacing the regular members
+// for which fn reports a change, and returns the number of changed members.
+// Nested archives are copied unchanged. The archive is written to a
+// temporary file and only replaces the original once it is complete.
+func Rewrite(filename string, fn RewriteFunc) (int, error) {
+	format, err := DetectArchive(filename)
+	if err != nil {
+		return 0, err
+	}
+	if format == NotArchive {
+		return 0, fmt.Errorf("'%s' is not an archive", filename)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary archive: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	var changed int
+	if format == Zip {
+		changed, err = rewriteZip(filename, temp, fn)
+	} else {
+		changed, err = rewriteTar(filename, temp, fn)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := temp.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+	if err := os.Chmod(temp.Name(), info.Mode().Perm()); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(temp.Name(), filename); err != nil {
+		return 0, fmt.Errorf("failed to replace archive '%s': %w", filename, err)
+	}
+
+	return changed, nil
+}
+
+func rewriteZip(filename string, output io.Writer, fn RewriteFunc) (int, error) {
+	archive, err := zip.OpenReader(filename)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open zip archive '%s': %w", filename, err)
+	}
+	defer archive.Close()
+
+	writer := zip.NewWriter(output)
+	changed := 0
+
+	for _, file := range archive.File {
+		data, replace, err := rewriteMember(filename, file.Name, file.Mode().IsRegular(), func() ([]byte, error) {
+			rc, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return readMember(rc, int64(file.UncompressedSize64))
+		}, fn)
+		if err != nil {
+			return 0, err
+		}
+
+		if !replace {
+			if err := writer.Copy(file); err != nil {
+				return 0, fmt.Errorf("failed to copy '%s': %w", file.Name, err)
+			}
+			continue
+		}
+
+		header := file.FileHeader
+		header.CompressedSize64 = 0
+		header.UncompressedSize64 = 0
+		header.CRC32 = 0
+		memberWriter, err := writer.CreateHeader(&header)
+		if err != nil {
+			return 0, fmt.Errorf("failed to write '%s': %w", file.Name, err)
+		}
+		if _, err := memberWriter.Write(data); err != nil {
+			return 0, fmt.Errorf("failed to write '%s': %w", file.Name, err)
+		}
+		changed++
+	}
+
+	if err := writer.Close(); err != nil {
+		return 0, fmt.Errorf("failed to finish zip archive: %w", err)
+	}
+	return changed, nil
+}
+
+func rewriteTar(filename string, output io.Writer, fn RewriteFunc) (int, error) {
+	input, format, err := compression.Open(filename)
+	if err != nil {
+		return 0, err
+	}
+	defer input.Close()
+
+	compressor, err := compression.NewWriter(format, output)
+	if err != nil {
+		return 0, err
+	}
+
+	archive := tar.NewReader(input)
+	writer := tar.NewWriter(compressor)
+	changed := 0
+
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read tar archive '%s': %w", filename, err)
+		}
+
+		var original []byte
+		data, replace, err := rewriteMember(filename, header.Name, header.Typeflag == tar.TypeReg, func() ([]byte, error) {
+			original, err = readMember(archive, header.Size)
+			return original, err
+		}, fn)
+		if err != nil {
+			return 0, err
+		}
+
+		if replace {
+			header.Size = int64(len(data))
+			changed++
+		} else if original != nil {
+			data = original
+		}
+
+		if err := writer.WriteHeader(header); err != nil {
+			return 0, fmt.Errorf("failed to write '%s': %w", header.Name, err)
+		}
+		if data != nil {
+			_, err = writer.Write(data)
+		} else {
+			_, err = io.Copy(writer, archive)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to write '%s': %w", header.Name, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return 0, fmt.Errorf("failed to finish tar archive: %w", err)
+	}
+	if err := compressor.Close(); err != nil {
+		return 0, fmt.Errorf("failed to finish %s stream: %w", format, err)
+	}
+	return changed, nil
+}
+
+// rewriteMember loads a regular member and asks fn for its new content.
+// Non-regular members and nested archives are never rewritten.
+func rewriteMember(archiveName, member string, regular bool, load func() ([]byte, error), fn RewriteFunc) ([]byte, bool, error) {
+	if !regular {
+		return nil, false, nil
+	}
+
+	data, err := load()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read '%s%s%s': %w", archiveName, MemberSeparator, member, err)
+	}
+	if format, _ := nestedArchive(member, data); format != NotArchive {
+		return nil, false, nil
+	}
+
+	member = strings.TrimPrefix(member, "./")
+	entry := Entry{Name: archiveName + MemberSeparator + strings.TrimPrefix(member, "/"), Path: archiveName, Member: member, data: data}
+	return fn(entry)
+}
@@ -0,0 +1,191 @@
+// Package follow reads the lines appended to a file while it grows, like
+// tail -f. Files are polled rather than watched, so it works the same on
+// every platform and file system. Rotation is handled both when the file
+// is replaced (renamed and recreated, detected by its identity) and when
+// it is truncated in place (copytruncate).
+package follow
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Rotation reasons passed to OnRotate.
+const (
+	// Replaced means a new file was created under the path
+	Replaced = "replaced"
+	// Truncated means the file shrank, e.g. after copytruncate
+	Truncated = "truncated"
+)
+
+// Follower reads the complete lines appended to one file.
+type Follower struct {
+	path string
+	file *os.File
+	info os.FileInfo
+
+	// offset is the position up to which the file has been read
+	offset int64
+
+	// partial holds the start of a line whose end has not been written yet
+	partial []byte
+
+	// lineNumber is the number of lines read from the current file,
+	// including the ones that were there when it was opened
+	lineNumber int
+
+	// OnRotate, when set, is called after the file was replaced or
+	// truncated; reading continues from the start of the new content
+	OnRotate func(reason string)
+}
+
+// Open opens a file for following. Reading starts at its current end, so
+// only lines written from now on are returned; the existing lines are
+// counted so that line numbers match the file.
+func Open(path string) (*Follower, error) {
+	f := &Follower{path: path}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	// An unfinished last line is completed by the next write
+	reader := bufio.NewReaderSize(f.file, 64*1024)
+	for {
+		line, err := reader.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			f.partial = append(f.partial, line...)
+			continue
+		}
+		if err == io.EOF {
+			f.partial = append(f.partial, line...)
+			break
+		}
+		if err != nil {
+			f.file.Close()
+			return nil, fmt.Errorf("failed to read '%s': %w", path, err)
+		}
+		f.partial = f.partial[:0]
+		f.lineNumber++
+	}
+
+	f.offset = f.info.Size()
+	return f, nil
+}
+
+// open opens the file at the path and starts at its beginning.
+func (f *Follower) open() error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to open '%s': %w", f.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to inspect '%s': %w", f.path, err)
+	}
+
+	f.file, f.info = file, info
+	f.offset, f.partial, f.lineNumber = 0, nil, 0
+	return nil
+}
+
+// Path returns the path of the followed file.
+func (f *Follower) Path() string {
+	return f.path
+}
+
+// Poll returns the complete lines written since the last call and the
+// line number of the first one. When the file was rotated, the remaining
+// lines of the old file are returned and the next call reads the new one,
+// whose lines are numbered from 1 again. While the path does not exist,
+// e.g. between a rename and the creation of the new file, the old file is
+// still read.
+func (f *Follower) Poll() ([]string, int, error) {
+	firstLine := f.lineNumber + 1
+
+	info, err := os.Stat(f.path)
+	if err == nil && !os.SameFile(info, f.info) {
+		lines, err := f.read()
+		if err != nil {
+			return lines, firstLine, err
+		}
+		lines = append(lines, f.takePartial()...)
+
+		f.file.Close()
+		if err := f.open(); err != nil {
+			return lines, firstLine, err
+		}
+		f.rotated(Replaced)
+		return lines, firstLine, nil
+	}
+
+	if err == nil && info.Size() < f.offset {
+		// Writes made before the truncation that we have not seen are lost
+		lines := f.takePartial()
+		f.offset, f.lineNumber = 0, 0
+		f.rotated(Truncated)
+		return lines, firstLine, nil
+	}
+
+	lines, err := f.read()
+	return lines, firstLine, err
+}
+
+// Flush returns the unfinished last line, if any, as a line of its own.
+// It is meant for stopping, when no more writes are awaited.
+func (f *Follower) Flush() ([]string, int) {
+	firstLine := f.lineNumber + 1
+	return f.takePartial(), firstLine
+}
+
+// Close closes the file.
+func (f *Follower) Close() error {
+	return f.file.Close()
+}
+
+// read reads the file from the offset to its end and returns the complete
+// lines, keeping an unfinished last line for later.
+func (f *Follower) read() ([]string, error) {
+	data, err := io.ReadAll(io.NewSectionReader(f.file, f.offset, 1<<62))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", f.path, err)
+	}
+	f.offset += int64(len(data))
+
+	data = append(f.partial, data...)
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		f.partial = data
+		return nil, nil
+	}
+	f.partial = append([]byte(nil), data[end+1:]...)
+
+	lines := strings.Split(string(data[:end]), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	f.lineNumber += len(lines)
+	return lines, nil
+}
+
+// takePartial returns the unfinished last line as a line and forgets it.
+func (f *Follower) takePartial() []string {
+	if len(f.partial) == 0 {
+		return nil
+	}
+	line := strings.TrimSuffix(string(f.partial), "\r")
+	f.partial = nil
+	f.lineNumber++
+	return []string{line}
+}
+
+// rotated reports a rotation to OnRotate.
+func (f *Follower) rotated(reason string) {
+	if f.OnRotate != nil {
+		f.OnRotate(reason)
+	}
+}
@@ -0,0 +1,111 @@
+package follow_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kcansari/optix/internal/follow"
+)
+
+// appendFile appends text to a file.
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(text); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+// expectPoll polls and compares the lines and first line number.
+func expectPoll(t *testing.T, follower *follow.Follower, expected []string, expectedFirst int) {
+	t.Helper()
+	lines, first, err := follower.Poll()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected lines %q, got %q", expected, lines)
+	}
+	if len(expected) > 0 && first != expectedFirst {
+		t.Errorf("Expected first line %d, got %d", expectedFirst, first)
+	}
+}
+
+// TestFollower tests reading appended lines, including unfinished ones.
+func TestFollower(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "old 1\nold 2\nunfin")
+
+	follower, err := follow.Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer follower.Close()
+
+	// Existing lines are skipped but counted
+	expectPoll(t, follower, nil, 0)
+
+	appendFile(t, path, "ished\r\nnew 4\npart")
+	expectPoll(t, follower, []string{"unfinished", "new 4"}, 3)
+
+	appendFile(t, path, "ial")
+	expectPoll(t, follower, nil, 0)
+
+	lines, first := follower.Flush()
+	if !reflect.DeepEqual(lines, []string{"partial"}) || first != 5 {
+		t.Errorf("Expected flushed line 5 'partial', got %d %q", first, lines)
+	}
+
+	if _, err := follow.Open(filepath.Join(t.TempDir(), "missing.log")); err == nil {
+		t.Errorf("Expected error for a missing file")
+	}
+}
+
+// TestFollowerRotation tests following a file across copytruncate and
+// rename-and-recreate rotations.
+func TestFollowerRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "first\n")
+
+	follower, err := follow.Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer follower.Close()
+
+	var rotations []string
+	follower.OnRotate = func(reason string) {
+		rotations = append(rotations, reason)
+	}
+
+	// copytruncate: the file shrinks and is written from the start
+	appendFile(t, path, "second\n")
+	expectPoll(t, follower, []string{"second"}, 2)
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatalf("Failed to truncate: %v", err)
+	}
+	expectPoll(t, follower, nil, 0)
+	appendFile(t, path, "after truncate\n")
+	expectPoll(t, follower, []string{"after truncate"}, 1)
+
+	// Rename and recreate: the rest of the old file comes first
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("Failed to rename: %v", err)
+	}
+	appendFile(t, path+".1", "late old line\nunfinished old")
+	expectPoll(t, follower, []string{"late old line"}, 2)
+	appendFile(t, path, "new file\n")
+	expectPoll(t, follower, []string{"unfinished old"}, 3)
+	expectPoll(t, follower, []string{"new file"}, 1)
+
+	expected := []string{follow.Truncated, follow.Replaced}
+	if !reflect.DeepEqual(rotations, expected) {
+		t.Errorf("Expected rotations %v, got %v", expected, rotations)
+	}
+}
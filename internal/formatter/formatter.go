@@ -0,0 +1,209 @@
+// Package formatter writes rows of named fields, such as parsed log
+// records, as a table, CSV, JSON Lines or a JSON array. Formatters follow
+// the Strategy pattern used for readers and processors.
+package formatter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kcansari/optix/internal/compression"
+	"github.com/kcansari/optix/internal/jsonutil"
+)
+
+// Formatter writes rows in one output format.
+type Formatter interface {
+	// Format writes the given fields of every row, in order. Rows that
+	// lack a field get an empty value.
+	Format(w io.Writer, fields []string, rows []*jsonutil.Object) error
+
+	// GetFormat returns the name of the format, e.g. "csv"
+	GetFormat() string
+}
+
+// FormatterStrategy holds the available formatters.
+type FormatterStrategy struct {
+	formatters []Formatter
+}
+
+// NewFormatterStrategy creates a strategy without formatters.
+func NewFormatterStrategy() *FormatterStrategy {
+	return &FormatterStrategy{}
+}
+
+// NewDefaultFormatterStrategy creates a strategy with the table, CSV, JSON
+// Lines and JSON formatters.
+func NewDefaultFormatterStrategy() *FormatterStrategy {
+	strategy := NewFormatterStrategy()
+	strategy.AddFormatter(&TableFormatter{})
+	strategy.AddFormatter(&CSVFormatter{})
+	strategy.AddFormatter(&JSONLinesFormatter{})
+	strategy.AddFormatter(&JSONFormatter{})
+	return strategy
+}
+
+// AddFormatter adds a formatter to the strategy.
+func (s *FormatterStrategy) AddFormatter(formatter Formatter) {
+	s.formatters = append(s.formatters, formatter)
+}
+
+// GetFormatter returns the formatter for a format name, or nil.
+func (s *FormatterStrategy) GetFormatter(format string) Formatter {
+	for _, formatter := range s.formatters {
+		if strings.EqualFold(formatter.GetFormat(), format) {
+			return formatter
+		}
+	}
+	return nil
+}
+
+// GetFormatNames returns the names of the formats, sorted.
+func (s *FormatterStrategy) GetFormatNames() []string {
+	names := make([]string, 0, len(s.formatters))
+	for _, formatter := range s.formatters {
+		names = append(names, formatter.GetFormat())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FormatForFile returns the format implied by the extension of an output
+// file (report.csv, events.jsonl.gz, ...), or "" when there is none.
+func (s *FormatterStrategy) FormatForFile(fileName string) string {
+	extension := strings.TrimPrefix(strings.ToLower(filepath.Ext(compression.TrimExtension(fileName))), ".")
+	switch extension {
+	case "ndjson":
+		extension = "jsonl"
+	case "txt", "":
+		return ""
+	}
+	if s.GetFormatter(extension) == nil {
+		return ""
+	}
+	return extension
+}
+
+// Columns returns the fields found in the rows, in the order they first
+// appear.
+func Columns(rows []*jsonutil.Object) []string {
+	var columns []string
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for _, member := range row.Members {
+			if !seen[member.Key] {
+				seen[member.Key] = true
+				columns = append(columns, member.Key)
+			}
+		}
+	}
+	return columns
+}
+
+// cellText returns a field of a row as text for the table and CSV formats.
+func cellText(row *jsonutil.Object, field string) string {
+	value, _ := row.Get(field)
+	return jsonutil.Text(value)
+}
+
+// selectFields returns the requested fields of a row as a new object,
+// leaving out the ones the row does not have.
+func selectFields(row *jsonutil.Object, fields []string) *jsonutil.Object {
+	selected := &jsonutil.Object{}
+	for _, field := range fields {
+		if value, ok := row.Get(field); ok {
+			selected.Members = append(selected.Members, jsonutil.Member{Key: field, Value: value})
+		}
+	}
+	return selected
+}
+
+// TableFormatter writes rows as columns aligned with spaces under a header.
+type TableFormatter struct{}
+
+func (f *TableFormatter) Format(w io.Writer, fields []string, rows []*jsonutil.Object) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, strings.ToUpper(strings.Join(fields, "\t")))
+	for _, row := range rows {
+		cells := make([]string, len(fields))
+		for i, field := range fields {
+			// Tabs and line breaks inside a value would break the columns
+			cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(cellText(row, field))
+		}
+		fmt.Fprintln(table, strings.Join(cells, "\t"))
+	}
+	return table.Flush()
+}
+
+func (f *TableFormatter) GetFormat() string {
+	return "table"
+}
+
+// CSVFormatter writes rows as CSV with a header row.
+type CSVFormatter struct{}
+
+func (f *CSVFormatter) Format(w io.Writer, fields []string, rows []*jsonutil.Object) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(fields); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, len(fields))
+		for i, field := range fields {
+			record[i] = cellText(row, field)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (f *CSVFormatter) GetFormat() string {
+	return "csv"
+}
+
+// JSONLinesFormatter writes every row as a JSON object on its own line.
+type JSONLinesFormatter struct{}
+
+func (f *JSONLinesFormatter) Format(w io.Writer, fields []string, rows []*jsonutil.Object) error {
+	for _, row := range rows {
+		encoded, err := jsonutil.Format(selectFields(row, fields), jsonutil.FormatOptions{})
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", encoded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *JSONLinesFormatter) GetFormat() string {
+	return "jsonl"
+}
+
+// JSONFormatter writes the rows as an indented JSON array of objects.
+type JSONFormatter struct{}
+
+func (f *JSONFormatter) Format(w io.Writer, fields []string, rows []*jsonutil.Object) error {
+	items := make([]any, len(rows))
+	for i, row := range rows {
+		items[i] = selectFields(row, fields)
+	}
+	encoded, err := jsonutil.Format(items, jsonutil.FormatOptions{Indent: "  "})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(encoded)
+	return err
+}
+
+func (f *JSONFormatter) GetFormat() string {
+	return "json"
+}
@@ -0,0 +1,87 @@
+package formatter_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kcansari/optix/internal/formatter"
+	"github.com/kcansari/optix/internal/jsonutil"
+)
+
+// testRows returns two rows with different fields.
+func testRows() []*jsonutil.Object {
+	first := &jsonutil.Object{}
+	first.Set("path", "/a")
+	first.Set("status", json.Number("200"))
+
+	second := &jsonutil.Object{}
+	second.Set("path", "/b, /c")
+	second.Set("agent", `say "hi"`)
+	return []*jsonutil.Object{first, second}
+}
+
+// TestFormatters tests the output of every formatter.
+func TestFormatters(t *testing.T) {
+	fields := []string{"path", "status"}
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"csv", "path,status\n/a,200\n\"/b, /c\",\n"},
+		{"jsonl", "{\"path\":\"/a\",\"status\":200}\n{\"path\":\"/b, /c\"}\n"},
+		{"json", "[\n  {\n    \"path\": \"/a\",\n    \"status\": 200\n  },\n  {\n    \"path\": \"/b, /c\"\n  }\n]\n"},
+		{"table", "PATH    STATUS\n/a      200\n/b, /c  \n"},
+	}
+
+	strategy := formatter.NewDefaultFormatterStrategy()
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			f := strategy.GetFormatter(test.format)
+			if f == nil {
+				t.Fatalf("No formatter for %s", test.format)
+			}
+
+			var output strings.Builder
+			if err := f.Format(&output, fields, testRows()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if output.String() != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, output.String())
+			}
+		})
+	}
+
+	if strategy.GetFormatter("xlsx") != nil {
+		t.Errorf("Expected no formatter for xlsx")
+	}
+}
+
+// TestColumns tests collecting the fields of rows in first-seen order.
+func TestColumns(t *testing.T) {
+	expected := []string{"path", "status", "agent"}
+	if columns := formatter.Columns(testRows()); !reflect.DeepEqual(columns, expected) {
+		t.Errorf("Expected columns %v, got %v", expected, columns)
+	}
+}
+
+// TestFormatForFile tests choosing the format from an output file name.
+func TestFormatForFile(t *testing.T) {
+	tests := map[string]string{
+		"report.csv":      "csv",
+		"events.jsonl.gz": "jsonl",
+		"events.ndjson":   "jsonl",
+		"records.json":    "json",
+		"notes.txt":       "",
+		"output":          "",
+		"archive.tar":     "",
+	}
+
+	strategy := formatter.NewDefaultFormatterStrategy()
+	for fileName, expected := range tests {
+		if format := strategy.FormatForFile(fileName); format != expected {
+			t.Errorf("FormatForFile(%q) = %q, expected %q", fileName, format, expected)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Canonicalize encodes a value using the JSON Canonicalization Scheme
+// (RFC 8785) so that equivalent documents produce identical bytes,
+// which makes the output suitable for hashing and signing.
+//
+// RFC 8785 requires numbers to be serialized as IEEE 754 doubles, so unlike
+// Format this is the one mode where number literals are normalized: 1.0
+// becomes 1 and integers beyond 2^53 lose precision. Numbers that do not fit
+// in a double at all are reported as errors.
+func Canonicalize(value any) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := writeCanonical(&buffer, value); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func writeCanonical(buffer *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case *Object:
+		return writeCanonicalObject(buffer, v.Members)
+	case map[string]any:
+		members := make([]Member, 0, len(v))
+		for key, item := range v {
+			members = append(members, Member{Key: key, Value: item})
+		}
+		return writeCanonicalObject(buffer, members)
+	case []any:
+		buffer.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := writeCanonical(buffer, item); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+		return nil
+	case json.Number:
+		number, err := canonicalNumber(v.String())
+		if err != nil {
+			return err
+		}
+		buffer.WriteString(number)
+		return nil
+	case float64:
+		number, err := formatES6Number(v)
+		if err != nil {
+			return err
+		}
+		buffer.WriteString(number)
+		return nil
+	default:
+		return writeValue(buffer, value, FormatOptions{}, 0)
+	}
+}
+
+func writeCanonicalObject(buffer *bytes.Buffer, members []Member) error {
+	sorted := make([]Member, len(members))
+	copy(sorted, members)
+	// RFC 8785 section 3.2.3: keys are ordered by their UTF-16 code units
+	sortMembers(sorted, lessUTF16)
+
+	buffer.WriteByte('{')
+	for i, member := range sorted {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		writeString(buffer, member.Key)
+		buffer.WriteByte(':')
+		if err := writeCanonical(buffer, member.Value); err != nil {
+			return err
+		}
+	}
+	buffer.WriteByte('}')
+	return nil
+}
+
+func lessUTF16(a, b string) bool {
+	unitsA := utf16.Encode([]rune(a))
+	unitsB := utf16.Encode([]rune(b))
+	for i := 0; i < len(unitsA) && i < len(unitsB); i++ {
+		if unitsA[i] != unitsB[i] {
+			return unitsA[i] < unitsB[i]
+		}
+	}
+	return len(unitsA) < len(unitsB)
+}
+
+func canonicalNumber(literal string) (string, error) {
+	value, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return "", fmt.Errorf("number %s cannot be represented in canonical form: %w", literal, err)
+	}
+	return formatES6Number(value)
+}
+
+// formatES6Number serializes a double the way ECMAScript's Number.toString
+// does, as mandated by RFC 8785 section 3.2.2.3.
+func formatES6Number(value float64) (string, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "", fmt.Errorf("number %v is not valid JSON", value)
+	}
+	if value == 0 {
+		return "0", nil
+	}
+
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	// Shortest round-trip digits in the form d.ddde±XX
+	scientific := strconv.FormatFloat(value, 'e', -1, 64)
+	mantissa, exponentText, _ := strings.Cut(scientific, "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	exponent, err := strconv.Atoi(exponentText)
+	if err != nil {
+		return "", fmt.Errorf("failed to format number %v: %w", value, err)
+	}
+
+	// n is the position of the decimal point relative to the digits
+	k := len(digits)
+	n := exponent + 1
+
+	var result string
+	switch {
+	case k <= n && n <= 21:
+		result = digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		result = digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		result = "0." + strings.Repeat("0", -n) + digits
+	default:
+		exponentSign := "+"
+		if n-1 < 0 {
+			exponentSign = "-"
+		}
+		exponentValue := strconv.Itoa(abs(n - 1))
+		if k == 1 {
+			result = digits + "e" + exponentSign + exponentValue
+		} else {
+			result = digits[:1] + "." + digits[1:] + "e" + exponentSign + exponentValue
+		}
+	}
+
+	return sign + result, nil
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
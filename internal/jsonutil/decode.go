@@ -0,0 +1,129 @@
+// Package jsonutil provides order-preserving JSON decoding and encoding.
+// Numbers are kept as json.Number so that their literal text survives a
+// decode/encode round trip exactly, without passing through float64.
+package jsonutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Member is a single key/value pair of a JSON object.
+type Member struct {
+	Key   string
+	Value any
+}
+
+// Object is a JSON object that remembers the order of its members.
+type Object struct {
+	Members []Member
+}
+
+// Get returns the value stored under key and whether the key exists.
+func (o *Object) Get(key string) (any, bool) {
+	for _, member := range o.Members {
+		if member.Key == key {
+			return member.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Set replaces the value stored under key, or appends a new member
+// when the key is not present yet.
+func (o *Object) Set(key string, value any) {
+	for i := range o.Members {
+		if o.Members[i].Key == key {
+			o.Members[i].Value = value
+			return
+		}
+	}
+	o.Members = append(o.Members, Member{Key: key, Value: value})
+}
+
+// Decode reads exactly one JSON value from r.
+// Objects are returned as *Object, arrays as []any, numbers as json.Number,
+// and strings, booleans and null as string, bool and nil respectively.
+func Decode(r io.Reader) (any, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	value, err := decodeValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	// A document must contain a single value; anything after it is an error
+	if _, err := decoder.Token(); err != io.EOF {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unexpected data after top-level value at offset %d", decoder.InputOffset())
+	}
+
+	return value, nil
+}
+
+// DecodeString is a convenience wrapper around Decode for in-memory documents.
+func DecodeString(data string) (any, error) {
+	return Decode(strings.NewReader(data))
+}
+
+// decodeValue consumes the tokens of one value from the decoder.
+func decodeValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	delim, isDelim := token.(json.Delim)
+	if !isDelim {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		object := &Object{}
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyToken.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected object key at offset %d", decoder.InputOffset())
+			}
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.Members = append(object.Members, Member{Key: key, Value: value})
+		}
+		// Consume the closing brace
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return object, nil
+	case '[':
+		array := []any{}
+		for decoder.More() {
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		// Consume the closing bracket
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return array, nil
+	default:
+		return nil, fmt.Errorf("unexpected delimiter '%s' at offset %d", delim, decoder.InputOffset())
+	}
+}
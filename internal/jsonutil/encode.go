@@ -0,0 +1,210 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FormatOptions controls how a decoded value is written back as JSON.
+type FormatOptions struct {
+	// Indent is the string used for one level of indentation.
+	// An empty Indent produces compact (minified) output.
+	Indent string
+
+	// SortKeys orders object members by key instead of keeping input order.
+	SortKeys bool
+}
+
+// Format encodes a value produced by Decode according to the options.
+// Indented output ends with a newline; compact output does not.
+func Format(value any, options FormatOptions) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := writeValue(&buffer, value, options, 0); err != nil {
+		return nil, err
+	}
+	if options.Indent != "" {
+		buffer.WriteByte('\n')
+	}
+	return buffer.Bytes(), nil
+}
+
+// ParseIndent converts an --indent argument ("tab" or a number of spaces)
+// into the indentation string used by FormatOptions.
+func ParseIndent(spec string) (string, error) {
+	if strings.EqualFold(spec, "tab") {
+		return "\t", nil
+	}
+
+	width, err := strconv.Atoi(spec)
+	if err != nil || width < 0 || width > 16 {
+		return "", fmt.Errorf("invalid indent '%s': use a number between 0 and 16 or 'tab'", spec)
+	}
+	return strings.Repeat(" ", width), nil
+}
+
+func writeValue(buffer *bytes.Buffer, value any, options FormatOptions, depth int) error {
+	switch v := value.(type) {
+	case nil:
+		buffer.WriteString("null")
+	case bool:
+		buffer.WriteString(strconv.FormatBool(v))
+	case string:
+		writeString(buffer, v)
+	case json.Number:
+		buffer.WriteString(v.String())
+	case *Object:
+		return writeObject(buffer, v.Members, options, depth)
+	case map[string]any:
+		members := make([]Member, 0, len(v))
+		for key, item := range v {
+			members = append(members, Member{Key: key, Value: item})
+		}
+		// Go maps have no order, so keep the output stable
+		sortMembers(members, lessCodePoints)
+		return writeObject(buffer, members, options, depth)
+	case []any:
+		return writeArray(buffer, v, options, depth)
+	default:
+		// Fall back to encoding/json for other scalar types (float64, int, ...)
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("cannot encode value of type %T: %w", value, err)
+		}
+		buffer.Write(encoded)
+	}
+	return nil
+}
+
+func writeObject(buffer *bytes.Buffer, members []Member, options FormatOptions, depth int) error {
+	if len(members) == 0 {
+		buffer.WriteString("{}")
+		return nil
+	}
+
+	if options.SortKeys {
+		sorted := make([]Member, len(members))
+		copy(sorted, members)
+		sortMembers(sorted, lessCodePoints)
+		members = sorted
+	}
+
+	buffer.WriteByte('{')
+	for i, member := range members {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		writeNewline(buffer, options, depth+1)
+		writeString(buffer, member.Key)
+		buffer.WriteByte(':')
+		if options.Indent != "" {
+			buffer.WriteByte(' ')
+		}
+		if err := writeValue(buffer, member.Value, options, depth+1); err != nil {
+			return err
+		}
+	}
+	writeNewline(buffer, options, depth)
+	buffer.WriteByte('}')
+	return nil
+}
+
+func writeArray(buffer *bytes.Buffer, items []any, options FormatOptions, depth int) error {
+	if len(items) == 0 {
+		buffer.WriteString("[]")
+		return nil
+	}
+
+	buffer.WriteByte('[')
+	for i, item := range items {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		writeNewline(buffer, options, depth+1)
+		if err := writeValue(buffer, item, options, depth+1); err != nil {
+			return err
+		}
+	}
+	writeNewline(buffer, options, depth)
+	buffer.WriteByte(']')
+	return nil
+}
+
+func writeNewline(buffer *bytes.Buffer, options FormatOptions, depth int) {
+	if options.Indent == "" {
+		return
+	}
+	buffer.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		buffer.WriteString(options.Indent)
+	}
+}
+
+// writeString writes s as a JSON string literal using the minimal escaping
+// required by RFC 8259 (and RFC 8785): quotes, backslashes and control
+// characters only. Unlike encoding/json, HTML characters are left alone.
+func writeString(buffer *bytes.Buffer, s string) {
+	buffer.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '"':
+			buffer.WriteString(`\"`)
+		case r == '\\':
+			buffer.WriteString(`\\`)
+		case r == '\b':
+			buffer.WriteString(`\b`)
+		case r == '\f':
+			buffer.WriteString(`\f`)
+		case r == '\n':
+			buffer.WriteString(`\n`)
+		case r == '\r':
+			buffer.WriteString(`\r`)
+		case r == '\t':
+			buffer.WriteString(`\t`)
+		case r < 0x20:
+			fmt.Fprintf(buffer, `\u%04x`, r)
+		case r == utf8.RuneError && size == 1:
+			// Invalid UTF-8 is replaced, matching encoding/json
+			buffer.WriteString(`\ufffd`)
+		default:
+			buffer.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	buffer.WriteByte('"')
+}
+
+func sortMembers(members []Member, less func(a, b string) bool) {
+	sort.SliceStable(members, func(i, j int) bool {
+		return less(members[i].Key, members[j].Key)
+	})
+}
+
+func lessCodePoints(a, b string) bool {
+	return a < b
+}
+
+// Text returns a decoded value as plain text: strings as they are, null as
+// "" and other values in their compact JSON form.
+func Text(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	encoded, err := Format(value, FormatOptions{})
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,254 @@
+package jsonutil
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultSeparator joins object keys in flattened paths.
+const DefaultSeparator = "."
+
+// indexSuffixPattern matches the trailing array indices of a path segment, e.g. "items[0][1]".
+var indexSuffixPattern = regexp.MustCompile(`^(.*?)((?:\[\d+\])+)$`)
+
+// Flatten turns nested objects and arrays into a single-level object whose
+// keys are paths such as "database.host" or "items[0].id". Object keys are
+// joined with separator and array elements use [index] notation. Empty
+// objects and arrays are kept as values so that Unflatten can restore them.
+// Scalars are returned unchanged.
+//
+// Keys that themselves contain the separator or look like "[n]" cannot be
+// told apart from nesting; choose a separator that does not occur in keys.
+func Flatten(value any, separator string) any {
+	if separator == "" {
+		separator = DefaultSeparator
+	}
+
+	switch value.(type) {
+	case *Object, map[string]any, []any:
+		flat := &Object{}
+		flattenInto(flat, value, "", separator)
+		return flat
+	default:
+		return value
+	}
+}
+
+func flattenInto(flat *Object, value any, path, separator string) {
+	switch v := value.(type) {
+	case *Object:
+		if len(v.Members) == 0 && path != "" {
+			flat.Members = append(flat.Members, Member{Key: path, Value: &Object{}})
+			return
+		}
+		for _, member := range v.Members {
+			flattenInto(flat, member.Value, joinPath(path, member.Key, separator), separator)
+		}
+	case map[string]any:
+		// Reuse the ordered representation so map input is flattened deterministically
+		object := &Object{}
+		for _, key := range sortedMapKeys(v) {
+			object.Members = append(object.Members, Member{Key: key, Value: v[key]})
+		}
+		flattenInto(flat, object, path, separator)
+	case []any:
+		if len(v) == 0 && path != "" {
+			flat.Members = append(flat.Members, Member{Key: path, Value: []any{}})
+			return
+		}
+		for i, item := range v {
+			flattenInto(flat, item, path+"["+strconv.Itoa(i)+"]", separator)
+		}
+	default:
+		flat.Members = append(flat.Members, Member{Key: path, Value: value})
+	}
+}
+
+func joinPath(path, key, separator string) string {
+	if path == "" {
+		return key
+	}
+	return path + separator + key
+}
+
+func sortedMapKeys(m map[string]any) []string {
+	members := make([]Member, 0, len(m))
+	for key := range m {
+		members = append(members, Member{Key: key})
+	}
+	sortMembers(members, lessCodePoints)
+
+	keys := make([]string, len(members))
+	for i, member := range members {
+		keys[i] = member.Key
+	}
+	return keys
+}
+
+// pathStep is one step of a flattened key: either an object key or an array index.
+type pathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// Unflatten rebuilds the nested structure described by a flattened object.
+// It is the inverse of Flatten for the same separator.
+func Unflatten(value any, separator string) (any, error) {
+	if separator == "" {
+		separator = DefaultSeparator
+	}
+
+	var members []Member
+	switch v := value.(type) {
+	case *Object:
+		members = v.Members
+	case map[string]any:
+		for _, key := range sortedMapKeys(v) {
+			members = append(members, Member{Key: key, Value: v[key]})
+		}
+	default:
+		return nil, fmt.Errorf("unflatten expects a JSON object with path keys, got %s", describeType(value))
+	}
+
+	var root any
+	for _, member := range members {
+		steps, err := parsePath(member.Key, separator)
+		if err != nil {
+			return nil, err
+		}
+
+		// Every array element has at least one key, so a larger index
+		// cannot come from a flattened document; refusing it also keeps
+		// a key like "a.999999999" from allocating a huge array
+		for _, step := range steps {
+			if step.isIndex && step.index >= len(members) {
+				return nil, fmt.Errorf("array index %d in key '%s' is out of range (the object has %d keys)",
+					step.index, member.Key, len(members))
+			}
+		}
+
+		root, err = setPath(root, steps, member.Value, member.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if root == nil {
+		return &Object{}, nil
+	}
+	return root, nil
+}
+
+// parsePath splits a flattened key such as "items[0].id" into steps.
+func parsePath(path, separator string) ([]pathStep, error) {
+	var steps []pathStep
+
+	for _, segment := range strings.Split(path, separator) {
+		key := segment
+		var indices string
+		if match := indexSuffixPattern.FindStringSubmatch(segment); match != nil {
+			key, indices = match[1], match[2]
+		}
+
+		// A leading index (e.g. "[0].id") addresses a top-level array
+		if key != "" || indices == "" {
+			steps = append(steps, pathStep{key: key})
+		}
+
+		for _, index := range strings.Split(strings.Trim(indices, "[]"), "][") {
+			if index == "" {
+				continue
+			}
+			position, err := strconv.Atoi(index)
+			if err != nil {
+				return nil, fmt.Errorf("invalid array index in key '%s': %w", path, err)
+			}
+			steps = append(steps, pathStep{index: position, isIndex: true})
+		}
+	}
+
+	return steps, nil
+}
+
+// setPath stores value at the position described by steps, creating
+// intermediate objects and arrays as needed, and returns the updated node.
+func setPath(node any, steps []pathStep, value any, fullKey string) (any, error) {
+	if len(steps) == 0 {
+		if node != nil && !isEmptyContainer(value) {
+			return nil, fmt.Errorf("key '%s' conflicts with another key", fullKey)
+		}
+		if node != nil {
+			return node, nil
+		}
+		return value, nil
+	}
+
+	step := steps[0]
+	if step.isIndex {
+		var array []any
+		switch v := node.(type) {
+		case nil:
+			array = []any{}
+		case []any:
+			array = v
+		default:
+			return nil, fmt.Errorf("key '%s' uses an index on a value that is not an array", fullKey)
+		}
+
+		for len(array) <= step.index {
+			array = append(array, nil)
+		}
+		child, err := setPath(array[step.index], steps[1:], value, fullKey)
+		if err != nil {
+			return nil, err
+		}
+		array[step.index] = child
+		return array, nil
+	}
+
+	var object *Object
+	switch v := node.(type) {
+	case nil:
+		object = &Object{}
+	case *Object:
+		object = v
+	default:
+		return nil, fmt.Errorf("key '%s' uses a field on a value that is not an object", fullKey)
+	}
+
+	existing, _ := object.Get(step.key)
+	child, err := setPath(existing, steps[1:], value, fullKey)
+	if err != nil {
+		return nil, err
+	}
+	object.Set(step.key, child)
+	return object, nil
+}
+
+func isEmptyContainer(value any) bool {
+	switch v := value.(type) {
+	case *Object:
+		return len(v.Members) == 0
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+func describeType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "number"
+	}
+}
@@ -0,0 +1,327 @@
+package jsonutil_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kcansari/optix/internal/jsonutil"
+)
+
+// TestDecodePreservesOrderAndNumbers tests that key order and number literals survive a round trip.
+func TestDecodePreservesOrderAndNumbers(t *testing.T) {
+	input := `{"zeta": 12345678901234567890123, "alpha": [1.10, 2e3], "mid": {"b": true, "a": null}}`
+
+	value, err := jsonutil.DecodeString(input)
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+
+	output, err := jsonutil.Format(value, jsonutil.FormatOptions{})
+	if err != nil {
+		t.Fatalf("Failed to format: %v", err)
+	}
+
+	expected := `{"zeta":12345678901234567890123,"alpha":[1.10,2e3],"mid":{"b":true,"a":null}}`
+	if string(output) != expected {
+		t.Errorf("Expected %s, got %s", expected, output)
+	}
+}
+
+// TestDecodeRejectsTrailingData tests that only a single top-level value is accepted.
+func TestDecodeRejectsTrailingData(t *testing.T) {
+	tests := []string{
+		`{"a": 1} {"b": 2}`,
+		`{"a": 1,}`,
+		`[1, 2`,
+		``,
+	}
+
+	for _, input := range tests {
+		if _, err := jsonutil.DecodeString(input); err == nil {
+			t.Errorf("Expected error for input %q", input)
+		}
+	}
+}
+
+// TestFormatIndentAndSortKeys tests pretty-printing options.
+func TestFormatIndentAndSortKeys(t *testing.T) {
+	value, err := jsonutil.DecodeString(`{"b": [1, {}], "a": "<x> & y"}`)
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+
+	output, err := jsonutil.Format(value, jsonutil.FormatOptions{Indent: "  ", SortKeys: true})
+	if err != nil {
+		t.Fatalf("Failed to format: %v", err)
+	}
+
+	expected := "{\n  \"a\": \"<x> & y\",\n  \"b\": [\n    1,\n    {}\n  ]\n}\n"
+	if string(output) != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestParseIndent tests conversion of --indent values.
+func TestParseIndent(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expected    string
+		expectError bool
+	}{
+		{"2", "  ", false},
+		{"0", "", false},
+		{"tab", "\t", false},
+		{"TAB", "\t", false},
+		{"-1", "", true},
+		{"wide", "", true},
+	}
+
+	for _, tt := range tests {
+		indent, err := jsonutil.ParseIndent(tt.spec)
+		if tt.expectError {
+			if err == nil {
+				t.Errorf("Expected error for indent %q", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for indent %q: %v", tt.spec, err)
+			continue
+		}
+		if indent != tt.expected {
+			t.Errorf("Indent %q: expected %q, got %q", tt.spec, tt.expected, indent)
+		}
+	}
+}
+
+// TestCanonicalize tests RFC 8785 key ordering, escaping and number serialization.
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Sorted keys and no whitespace",
+			input:    `{ "b": 2, "a": { "d": [true, null], "c": "x" } }`,
+			expected: `{"a":{"c":"x","d":[true,null]},"b":2}`,
+		},
+		{
+			name:     "Number normalization",
+			input:    `[1.0, 1e21, 1e20, 0.000001, 1e-7, -0.0, 333333333.33333329, 4.50]`,
+			expected: `[1,1e+21,100000000000000000000,0.000001,1e-7,0,333333333.3333333,4.5]`,
+		},
+		{
+			name:     "UTF-16 key order",
+			input:    `{"\ufb33": 1, "\ud83d\ude00": 2, "\r": 3, "1": 4, "\u20ac": 5}`,
+			expected: "{\"\\r\":3,\"1\":4,\"\u20ac\":5,\"\U0001F600\":2,\"\ufb33\":1}",
+		},
+		{
+			name:     "Minimal string escaping",
+			input:    `"<\u0001\/>"`,
+			expected: `"<\u0001/>"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := jsonutil.DecodeString(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+
+			output, err := jsonutil.Canonicalize(value)
+			if err != nil {
+				t.Fatalf("Failed to canonicalize: %v", err)
+			}
+
+			if string(output) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, output)
+			}
+		})
+	}
+}
+
+// TestCanonicalizeRejectsOutOfRangeNumbers tests that numbers beyond float64 are reported.
+func TestCanonicalizeRejectsOutOfRangeNumbers(t *testing.T) {
+	value, err := jsonutil.DecodeString(`[1e400]`)
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+
+	if _, err := jsonutil.Canonicalize(value); err == nil {
+		t.Error("Expected error for number out of float64 range")
+	}
+}
+
+// TestTransformLines tests line-by-line processing of JSON Lines input.
+func TestTransformLines(t *testing.T) {
+	input := "{\"b\": 1, \"a\": 2}\n\n{\"n\": 10000000000000000000001}\r\n"
+	sortKeys := func(value any) ([]byte, error) {
+		return jsonutil.Format(value, jsonutil.FormatOptions{SortKeys: true})
+	}
+
+	output, err := jsonutil.TransformLines(input, sortKeys)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "{\"a\":2,\"b\":1}\n{\"n\":10000000000000000000001}\n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	// Invalid records are reported with their line number
+	_, err = jsonutil.TransformLines("{}\n{}\n{oops}\n", sortKeys)
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected error mentioning line 3, got: %v", err)
+	}
+}
+
+// TestAnalyze tests structural statistics computed from the decoded tree.
+func TestAnalyze(t *testing.T) {
+	// Braces and commas inside strings must not affect the counts
+	value, err := jsonutil.DecodeString(`{"name": "{a, b}", "items": [{"id": 1}, {"id": 2, "tags": []}], "ok": true, "none": null}`)
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+
+	stats := jsonutil.Analyze(value)
+
+	checks := []struct {
+		name     string
+		got      int
+		expected int
+	}{
+		{"MaxDepth", stats.MaxDepth, 4},
+		{"Objects", stats.Objects, 3},
+		{"Arrays", stats.Arrays, 2},
+		{"Strings", stats.Strings, 1},
+		{"Numbers", stats.Numbers, 2},
+		{"Booleans", stats.Booleans, 1},
+		{"Nulls", stats.Nulls, 1},
+		{"LargestArray", stats.LargestArray, 2},
+		{"DistinctKeyPaths", stats.DistinctKeyPaths(), 6},
+	}
+
+	for _, check := range checks {
+		if check.got != check.expected {
+			t.Errorf("%s: expected %d, got %d", check.name, check.expected, check.got)
+		}
+	}
+
+	if stats.KeyPaths["items[].id"] != 2 {
+		t.Errorf("Expected key path 'items[].id' to occur twice, got %d", stats.KeyPaths["items[].id"])
+	}
+}
+
+// TestAnalyzeLines tests per-record statistics for JSON Lines documents.
+func TestAnalyzeLines(t *testing.T) {
+	input := "{\"a\": 1, \"b\": {\"c\": 2}}\n{\"a\": 2}\n\nnot json\n{\"a\": 3, \"a2\": [1, 2, 3]}\n"
+
+	lineStats := jsonutil.AnalyzeLines(input)
+
+	if lineStats.Records != 3 {
+		t.Errorf("Expected 3 records, got %d", lineStats.Records)
+	}
+	if len(lineStats.InvalidLines) != 1 || lineStats.InvalidLines[0] != 4 {
+		t.Errorf("Expected invalid line 4, got %v", lineStats.InvalidLines)
+	}
+	if lineStats.KeyFrequency["a"] != 3 {
+		t.Errorf("Expected key 'a' in 3 records, got %d", lineStats.KeyFrequency["a"])
+	}
+	if lineStats.Structure.LargestArray != 3 {
+		t.Errorf("Expected largest array 3, got %d", lineStats.Structure.LargestArray)
+	}
+
+	keyCounts := jsonutil.SortedKeyCounts(lineStats.KeyFrequency)
+	if len(keyCounts) == 0 || keyCounts[0].Path != "a" {
+		t.Errorf("Expected most frequent key 'a', got %v", keyCounts)
+	}
+}
+
+// TestFlattenAndUnflatten tests that flattening produces path keys and unflattening restores the document.
+func TestFlattenAndUnflatten(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		separator string
+		flattened string
+	}{
+		{
+			name:      "Nested objects",
+			input:     `{"database":{"host":"localhost","port":5432},"debug":true}`,
+			separator: ".",
+			flattened: `{"database.host":"localhost","database.port":5432,"debug":true}`,
+		},
+		{
+			name:      "Arrays and empty containers",
+			input:     `{"items":[{"id":1},{"id":2,"tags":["a","b"]}],"empty":{},"none":[]}`,
+			separator: ".",
+			flattened: `{"items[0].id":1,"items[1].id":2,"items[1].tags[0]":"a","items[1].tags[1]":"b","empty":{},"none":[]}`,
+		},
+		{
+			name:      "Top-level array with custom separator",
+			input:     `[{"a":{"b":1}},[2,3]]`,
+			separator: "/",
+			flattened: `{"[0]/a/b":1,"[1][0]":2,"[1][1]":3}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := jsonutil.DecodeString(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+
+			flat, err := jsonutil.Format(jsonutil.Flatten(value, tt.separator), jsonutil.FormatOptions{})
+			if err != nil {
+				t.Fatalf("Failed to format: %v", err)
+			}
+			if string(flat) != tt.flattened {
+				t.Errorf("Expected flattened %s, got %s", tt.flattened, flat)
+			}
+
+			flatValue, err := jsonutil.DecodeString(string(flat))
+			if err != nil {
+				t.Fatalf("Failed to decode flattened output: %v", err)
+			}
+			restored, err := jsonutil.Unflatten(flatValue, tt.separator)
+			if err != nil {
+				t.Fatalf("Failed to unflatten: %v", err)
+			}
+
+			output, err := jsonutil.Format(restored, jsonutil.FormatOptions{})
+			if err != nil {
+				t.Fatalf("Failed to format: %v", err)
+			}
+			if string(output) != tt.input {
+				t.Errorf("Expected round trip %s, got %s", tt.input, output)
+			}
+		})
+	}
+}
+
+// TestUnflattenConflicts tests that contradictory keys, and indexes no
+// flattened document can contain, are rejected.
+func TestUnflattenConflicts(t *testing.T) {
+	inputs := []string{
+		`{"a":1,"a.b":2}`,
+		`{"a.b":2,"a":1}`,
+		`{"a[0]":1,"a.b":2}`,
+		`[1,2]`,
+		`{"a[999999999]":1}`,
+		`{"a[0]":1,"b[2]":2}`,
+	}
+
+	for _, input := range inputs {
+		value, err := jsonutil.DecodeString(input)
+		if err != nil {
+			t.Fatalf("Failed to decode %s: %v", input, err)
+		}
+		if _, err := jsonutil.Unflatten(value, "."); err == nil {
+			t.Errorf("Expected error for %s", input)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package jsonutil
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// IsJSONLinesFile reports whether the filename uses a JSON Lines extension.
+func IsJSONLinesFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".jsonl", ".ndjson":
+		return true
+	}
+	return false
+}
+
+// TransformLines decodes every non-blank line of a JSON Lines document,
+// passes the value to transform and joins the results, one per line.
+// Blank lines are dropped; errors report the 1-based line number.
+func TransformLines(data string, transform func(value any) ([]byte, error)) (string, error) {
+	var builder strings.Builder
+
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		value, err := DecodeString(line)
+		if err != nil {
+			return "", fmt.Errorf("line %d: invalid JSON: %w", i+1, err)
+		}
+
+		encoded, err := transform(value)
+		if err != nil {
+			return "", fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		builder.Write(encoded)
+		builder.WriteByte('\n')
+	}
+
+	return builder.String(), nil
+}
@@ -0,0 +1,170 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Stats describes the structure of one or more decoded JSON values.
+type Stats struct {
+	// MaxDepth is the deepest nesting of objects and arrays (a scalar has depth 0)
+	MaxDepth int
+
+	// Value counts by JSON type
+	Objects  int
+	Arrays   int
+	Strings  int
+	Numbers  int
+	Booleans int
+	Nulls    int
+
+	// LargestArray is the length of the longest array encountered
+	LargestArray int
+
+	// KeyPaths counts how often each key path occurs, e.g. "database.host"
+	// or "items[].id" (array indices are collapsed to [])
+	KeyPaths map[string]int
+}
+
+// LineStats describes a JSON Lines document record by record.
+type LineStats struct {
+	// Records is the number of lines that decoded successfully
+	Records int
+
+	// InvalidLines holds the 1-based line numbers that failed to decode
+	InvalidLines []int
+
+	// KeyFrequency counts the number of records containing each key path
+	KeyFrequency map[string]int
+
+	// Structure merges the structural statistics of all valid records
+	Structure *Stats
+}
+
+// KeyCount is a key path together with its number of occurrences.
+type KeyCount struct {
+	Path  string
+	Count int
+}
+
+// NewStats creates an empty Stats ready to accumulate values.
+func NewStats() *Stats {
+	return &Stats{KeyPaths: make(map[string]int)}
+}
+
+// Analyze computes structural statistics for a single decoded value.
+func Analyze(value any) *Stats {
+	stats := NewStats()
+	stats.Add(value)
+	return stats
+}
+
+// Add accumulates the statistics of another decoded value.
+func (s *Stats) Add(value any) {
+	s.walk(value, "", 0)
+}
+
+// DistinctKeyPaths returns the number of different key paths seen.
+func (s *Stats) DistinctKeyPaths() int {
+	return len(s.KeyPaths)
+}
+
+func (s *Stats) walk(value any, path string, depth int) {
+	switch v := value.(type) {
+	case *Object:
+		s.Objects++
+		s.MaxDepth = max(s.MaxDepth, depth+1)
+		for _, member := range v.Members {
+			childPath := joinKeyPath(path, member.Key)
+			s.KeyPaths[childPath]++
+			s.walk(member.Value, childPath, depth+1)
+		}
+	case map[string]any:
+		s.Objects++
+		s.MaxDepth = max(s.MaxDepth, depth+1)
+		for key, item := range v {
+			childPath := joinKeyPath(path, key)
+			s.KeyPaths[childPath]++
+			s.walk(item, childPath, depth+1)
+		}
+	case []any:
+		s.Arrays++
+		s.MaxDepth = max(s.MaxDepth, depth+1)
+		s.LargestArray = max(s.LargestArray, len(v))
+		for _, item := range v {
+			s.walk(item, path+"[]", depth+1)
+		}
+	case nil:
+		s.Nulls++
+	case bool:
+		s.Booleans++
+	case string:
+		s.Strings++
+	case json.Number, float64, float32, int, int64, int32, uint64, uint32:
+		s.Numbers++
+	}
+}
+
+func joinKeyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// NewLineStats creates empty JSON Lines statistics ready to accumulate records.
+func NewLineStats() *LineStats {
+	return &LineStats{
+		KeyFrequency: make(map[string]int),
+		Structure:    NewStats(),
+	}
+}
+
+// AnalyzeLines computes per-record statistics for a JSON Lines document.
+// Blank lines are ignored; lines that fail to decode are counted as invalid.
+func AnalyzeLines(data string) *LineStats {
+	lineStats := NewLineStats()
+
+	for i, line := range strings.Split(data, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		value, err := DecodeString(line)
+		if err != nil {
+			lineStats.InvalidLines = append(lineStats.InvalidLines, i+1)
+			continue
+		}
+
+		lineStats.AddRecord(value)
+	}
+
+	return lineStats
+}
+
+// AddRecord merges one decoded record into the statistics.
+func (ls *LineStats) AddRecord(value any) {
+	ls.Records++
+	record := Analyze(value)
+	for path := range record.KeyPaths {
+		ls.KeyFrequency[path]++
+	}
+	ls.Structure.Add(value)
+}
+
+// SortedKeyCounts returns the entries of counts ordered by descending count,
+// then by path, so the most common keys come first.
+func SortedKeyCounts(counts map[string]int) []KeyCount {
+	result := make([]KeyCount, 0, len(counts))
+	for path, count := range counts {
+		result = append(result, KeyCount{Path: path, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
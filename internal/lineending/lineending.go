@@ -0,0 +1,209 @@
+// Package lineending detects and converts line-ending styles. Readers see
+// content with LF line endings only; the style the file used is recorded
+// so that writers can restore it.
+package lineending
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Style is a line-ending convention.
+type Style string
+
+const (
+	// None means the content has no line breaks at all
+	None  Style = ""
+	LF    Style = "lf"
+	CRLF  Style = "crlf"
+	CR    Style = "cr"
+	Mixed Style = "mixed"
+)
+
+// Parse returns the style for a name such as "lf" or "crlf".
+func Parse(name string) (Style, error) {
+	switch style := Style(strings.ToLower(strings.TrimSpace(name))); style {
+	case LF, CRLF, CR:
+		return style, nil
+	}
+	return None, fmt.Errorf("unsupported line ending '%s'. Supported line endings: lf, crlf, cr", name)
+}
+
+// Reader converts CRLF and CR line endings to LF while counting the
+// styles it has seen and recording the ending of every line.
+type Reader struct {
+	input *bufio.Reader
+	err   error
+
+	lf, crlf, cr int
+	endings      []Style
+	last         byte
+	empty        bool
+}
+
+// NewReader returns a Reader that normalizes the line endings of input.
+func NewReader(input io.Reader) *Reader {
+	return &Reader{input: bufio.NewReader(input), empty: true}
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	n := 0
+	for n < len(p) {
+		b, err := r.input.ReadByte()
+		if err != nil {
+			// Report the error on the next call so the bytes read so far are kept
+			r.err = err
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+
+		switch b {
+		case '\r':
+			if next, err := r.input.Peek(1); err == nil && next[0] == '\n' {
+				r.input.ReadByte()
+				r.crlf++
+				r.endings = append(r.endings, CRLF)
+			} else {
+				r.cr++
+				r.endings = append(r.endings, CR)
+			}
+			b = '\n'
+		case '\n':
+			r.lf++
+			r.endings = append(r.endings, LF)
+		}
+
+		r.last = b
+		r.empty = false
+		p[n] = b
+		n++
+	}
+	return n, nil
+}
+
+// Style returns the line-ending style of the content read so far.
+func (r *Reader) Style() Style {
+	styles := 0
+	style := None
+	for _, count := range []struct {
+		style Style
+		count int
+	}{{LF, r.lf}, {CRLF, r.crlf}, {CR, r.cr}} {
+		if count.count > 0 {
+			styles++
+			style = count.style
+		}
+	}
+	if styles > 1 {
+		return Mixed
+	}
+	return style
+}
+
+// Endings returns the ending of every line break read so far when the
+// style is Mixed, so that each line can get its ending back, and nil for
+// any other style.
+func (r *Reader) Endings() []Style {
+	if r.Style() != Mixed {
+		return nil
+	}
+	return r.endings
+}
+
+// FinalNewline reports whether the content read so far ends with a line
+// break. Empty content counts as ending with one.
+func (r *Reader) FinalNewline() bool {
+	return r.empty || r.last == '\n'
+}
+
+// Detect returns the line-ending style of data and whether it ends with a
+// line break.
+func Detect(data []byte) (Style, bool) {
+	reader := NewReader(bytes.NewReader(data))
+	io.Copy(io.Discard, reader)
+	return reader.Style(), reader.FinalNewline()
+}
+
+// Describe formats a line-ending style for display, e.g. "CRLF (no final
+// newline)".
+func Describe(style Style, finalNewline bool) string {
+	description := strings.ToUpper(string(style))
+	if description == "" {
+		description = "none"
+	}
+	if !finalNewline {
+		description += " (no final newline)"
+	}
+	return description
+}
+
+// Normalize converts CRLF and CR line endings in text to LF.
+func Normalize(text string) string {
+	if !strings.Contains(text, "\r") {
+		return text
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+// Restore returns text with its LF line endings replaced by endings, one
+// per line break, as recorded by Reader.Endings. When the number of line
+// breaks differs from the recorded one, lines were added or removed and no
+// longer match the recorded endings, so every line gets the most common
+// of them.
+func Restore(text string, endings []Style) string {
+	text = Normalize(text)
+	lines := strings.Split(text, "\n")
+	if len(lines)-1 != len(endings) {
+		return Convert(text, mostCommon(endings))
+	}
+
+	var restored strings.Builder
+	restored.Grow(len(text) + len(endings))
+	for i, line := range lines {
+		restored.WriteString(line)
+		if i < len(endings) {
+			restored.WriteString(Convert("\n", endings[i]))
+		}
+	}
+	return restored.String()
+}
+
+// mostCommon returns the style used by most of endings; ties go to LF,
+// then CRLF.
+func mostCommon(endings []Style) Style {
+	counts := make(map[Style]int)
+	for _, ending := range endings {
+		counts[ending]++
+	}
+	style := LF
+	for _, candidate := range []Style{CRLF, CR} {
+		if counts[candidate] > counts[style] {
+			style = candidate
+		}
+	}
+	return style
+}
+
+// Convert returns text with every line ending in the given style. Text in
+// None or Mixed style is returned unchanged.
+func Convert(text string, style Style) string {
+	switch style {
+	case LF:
+		return Normalize(text)
+	case CRLF:
+		return strings.ReplaceAll(Normalize(text), "\n", "\r\n")
+	case CR:
+		return strings.ReplaceAll(Normalize(text), "\n", "\r")
+	}
+	return text
+}
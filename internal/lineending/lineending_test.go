@@ -0,0 +1,137 @@
+package lineending_test
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/kcansari/optix/internal/lineending"
+)
+
+// TestReader tests that line endings are normalized and recorded.
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expected      string
+		expectedStyle lineending.Style
+		expectedFinal bool
+		expectedEnds  []lineending.Style
+	}{
+		{"empty", "", "", lineending.None, true, nil},
+		{"no line breaks", "abc", "abc", lineending.None, false, nil},
+		{"lf", "a\nb\n", "a\nb\n", lineending.LF, true, nil},
+		{"crlf", "a\r\nb\r\n", "a\nb\n", lineending.CRLF, true, nil},
+		{"cr", "a\rb\r", "a\nb\n", lineending.CR, true, nil},
+		{"crlf without final newline", "a\r\nb", "a\nb", lineending.CRLF, false, nil},
+		{"mixed", "a\r\nb\nc\r", "a\nb\nc\n", lineending.Mixed, true, []lineending.Style{lineending.CRLF, lineending.LF, lineending.CR}},
+		{"blank crlf lines", "\r\n\r\n", "\n\n", lineending.CRLF, true, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// One byte at a time, so a CRLF pair is split across reads
+			reader := lineending.NewReader(iotest.OneByteReader(strings.NewReader(test.input)))
+
+			output, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("Failed to read: %v", err)
+			}
+			if string(output) != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, output)
+			}
+			if style := reader.Style(); style != test.expectedStyle {
+				t.Errorf("Expected style '%s', got '%s'", test.expectedStyle, style)
+			}
+			if final := reader.FinalNewline(); final != test.expectedFinal {
+				t.Errorf("Expected final newline %t, got %t", test.expectedFinal, final)
+			}
+			if endings := reader.Endings(); !reflect.DeepEqual(endings, test.expectedEnds) {
+				t.Errorf("Expected endings %v, got %v", test.expectedEnds, endings)
+			}
+		})
+	}
+}
+
+// TestConvert tests converting text to each line-ending style.
+func TestConvert(t *testing.T) {
+	input := "a\r\nb\nc\r"
+
+	tests := []struct {
+		style    lineending.Style
+		expected string
+	}{
+		{lineending.LF, "a\nb\nc\n"},
+		{lineending.CRLF, "a\r\nb\r\nc\r\n"},
+		{lineending.CR, "a\rb\rc\r"},
+		{lineending.Mixed, input},
+		{lineending.None, input},
+	}
+
+	for _, test := range tests {
+		if output := lineending.Convert(input, test.style); output != test.expected {
+			t.Errorf("Convert to '%s': expected %q, got %q", test.style, test.expected, output)
+		}
+	}
+}
+
+// TestRestore tests giving lines their recorded endings back.
+func TestRestore(t *testing.T) {
+	endings := []lineending.Style{lineending.CRLF, lineending.LF, lineending.CRLF}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"same lines", "a\nb\nc\n", "a\r\nb\nc\r\n"},
+		{"final newline removed", "a\nb\nc", "a\r\nb\r\nc"},
+		{"fewer lines", "a\nc\n", "a\r\nc\r\n"},
+		{"more lines", "a\nb\nc\nd\n", "a\r\nb\r\nc\r\nd\r\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if output := lineending.Restore(test.input, endings); output != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, output)
+			}
+		})
+	}
+}
+
+// TestParse tests parsing line-ending names.
+func TestParse(t *testing.T) {
+	for name, expected := range map[string]lineending.Style{"LF": lineending.LF, "crlf": lineending.CRLF, " cr ": lineending.CR} {
+		style, err := lineending.Parse(name)
+		if err != nil || style != expected {
+			t.Errorf("Expected '%s' to parse as '%s', got '%s' (%v)", name, expected, style, err)
+		}
+	}
+
+	for _, name := range []string{"", "mixed", "windows"} {
+		if _, err := lineending.Parse(name); err == nil {
+			t.Errorf("Expected an error for '%s'", name)
+		}
+	}
+}
+
+// TestDescribe tests the display form of line-ending styles
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		style        lineending.Style
+		finalNewline bool
+		expected     string
+	}{
+		{lineending.CRLF, true, "CRLF"},
+		{lineending.Mixed, false, "MIXED (no final newline)"},
+		{lineending.None, false, "none (no final newline)"},
+	}
+
+	for _, test := range tests {
+		if description := lineending.Describe(test.style, test.finalNewline); description != test.expected {
+			t.Errorf("Describe(%q, %t) = %q, expected %q", test.style, test.finalNewline, description, test.expected)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+package logparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kcansari/optix/internal/jsonutil"
+)
+
+// FieldParser splits a log line written in one format into named fields.
+// This follows the Strategy pattern used for levels and timestamps.
+type FieldParser interface {
+	// ParseFields returns the fields of the line in the order they appear,
+	// or an error when the line is not written in the format
+	ParseFields(line string) (*jsonutil.Object, error)
+
+	// GetFormat returns the name of the format, e.g. "logfmt"
+	GetFormat() string
+}
+
+// FieldParserStrategy holds the parsers of the known log formats.
+type FieldParserStrategy struct {
+	parsers []FieldParser
+}
+
+// NewFieldParserStrategy creates a strategy without parsers.
+func NewFieldParserStrategy() *FieldParserStrategy {
+	return &FieldParserStrategy{}
+}
+
+// NewDefaultFieldParserStrategy creates a strategy for JSON, access log,
+// syslog and logfmt lines. Syslog timestamps without a time zone are read
+// in location. logfmt comes last, since it is the most lenient format.
+func NewDefaultFieldParserStrategy(location *time.Location) *FieldParserStrategy {
+	strategy := NewFieldParserStrategy()
+	strategy.AddParser(&JSONFieldParser{})
+	strategy.AddParser(&AccessLogFieldParser{})
+	strategy.AddParser(NewSyslogFieldParser(location))
+	strategy.AddParser(&LogfmtFieldParser{})
+	return strategy
+}
+
+// AddParser adds a parser, preferred by DetectFormat over later ones.
+func (s *FieldParserStrategy) AddParser(parser FieldParser) {
+	s.parsers = append(s.parsers, parser)
+}
+
+// GetParser returns the parser for a format name, or nil.
+func (s *FieldParserStrategy) GetParser(format string) FieldParser {
+	for _, parser := range s.parsers {
+		if strings.EqualFold(parser.GetFormat(), format) {
+			return parser
+		}
+	}
+	return nil
+}
+
+// GetFormats returns the names of the formats, in order.
+func (s *FieldParserStrategy) GetFormats() []string {
+	formats := make([]string, 0, len(s.parsers))
+	for _, parser := range s.parsers {
+		formats = append(formats, parser.GetFormat())
+	}
+	return formats
+}
+
+// detectSampleLines is the number of non-blank lines DetectFormat looks at.
+const detectSampleLines = 50
+
+// DetectFormat returns the parser that understands the most of the first
+// non-blank lines, or nil when none understands any of them.
+func (s *FieldParserStrategy) DetectFormat(lines []string) FieldParser {
+	var sample []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			sample = append(sample, line)
+			if len(sample) == detectSampleLines {
+				break
+			}
+		}
+	}
+
+	var best FieldParser
+	bestCount := 0
+	for _, parser := range s.parsers {
+		count := 0
+		for _, line := range sample {
+			if _, err := parser.ParseFields(line); err == nil {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = parser, count
+		}
+	}
+	return best
+}
+
+// formatTime writes parsed timestamps in one sortable layout.
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// JSONFieldParser reads JSON log lines. Nested objects are flattened into
+// dotted keys such as "http.status".
+type JSONFieldParser struct{}
+
+func (p *JSONFieldParser) ParseFields(line string) (*jsonutil.Object, error) {
+	value, err := jsonutil.DecodeString(line)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, ok := value.(*jsonutil.Object); !ok {
+		return nil, fmt.Errorf("not a JSON object")
+	}
+	return jsonutil.Flatten(value, jsonutil.DefaultSeparator).(*jsonutil.Object), nil
+}
+
+func (p *JSONFieldParser) GetFormat() string {
+	return "json"
+}
+
+// LogfmtFieldParser reads logfmt lines such as
+// time=2024-05-01T14:02:03Z level=warn msg="disk almost full" free=2%.
+// Keys without a value ("debug") are true.
+type LogfmtFieldParser struct{}
+
+func (p *LogfmtFieldParser) ParseFields(line string) (*jsonutil.Object, error) {
+	fields := &jsonutil.Object{}
+	pairs := 0
+
+	rest := line
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			break
+		}
+
+		end := strings.IndexAny(rest, " \t=")
+		if end < 0 {
+			end = len(rest)
+		}
+		key := rest[:end]
+		if key == "" || strings.ContainsRune(key, '"') {
+			return nil, fmt.Errorf("invalid logfmt key near '%s'", truncate(rest))
+		}
+		rest = rest[end:]
+
+		if !strings.HasPrefix(rest, "=") {
+			fields.Set(key, true)
+			continue
+		}
+		rest = rest[1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, remainder, err := cutQuoted(rest)
+			if err != nil {
+				return nil, fmt.Errorf("invalid logfmt value for '%s': %w", key, err)
+			}
+			value, rest = quoted, remainder
+		} else {
+			end := strings.IndexAny(rest, " \t")
+			if end < 0 {
+				end = len(rest)
+			}
+			value, rest = rest[:end], rest[end:]
+		}
+		fields.Set(key, value)
+		pairs++
+	}
+
+	if pairs == 0 {
+		return nil, fmt.Errorf("no key=value pairs")
+	}
+	return fields, nil
+}
+
+func (p *LogfmtFieldParser) GetFormat() string {
+	return "logfmt"
+}
+
+// cutQuoted unquotes the double-quoted string at the start of s and
+// returns it with the rest of s.
+func cutQuoted(s string) (string, string, error) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				// Accept escapes Go does not know by keeping them as they are
+				value = strings.ReplaceAll(s[1:i], `\"`, `"`)
+			}
+			return value, s[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quote")
+}
+
+// truncate shortens text quoted in error messages.
+func truncate(text string) string {
+	const maxLength = 20
+	if len(text) > maxLength {
+		return text[:maxLength] + "..."
+	}
+	return text
+}
+
+// accessLogPattern matches the Common and Combined Log Formats written by
+// Apache and Nginx; anything after the user agent is ignored.
+var accessLogPattern = regexp.MustCompile(`^(\S+) \S+ (\S+) \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}) (\d+|-)( "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)")?`)
+
+// AccessLogFieldParser reads Apache and Nginx access log lines in the
+// Common or Combined Log Format:
+//
+//	127.0.0.1 - frank [01/May/2024:14:02:03 +0200] "GET /a HTTP/1.1" 200 512 "-" "curl/8.0"
+//
+// The fields are remote_addr, remote_user, time, method, path, protocol,
+// status, bytes and, for the Combined format, referer and user_agent.
+type AccessLogFieldParser struct{}
+
+func (p *AccessLogFieldParser) ParseFields(line string) (*jsonutil.Object, error) {
+	match := accessLogPattern.FindStringSubmatch(line)
+	if match == nil {
+		return nil, fmt.Errorf("not an access log line")
+	}
+
+	timestamp, err := time.Parse("02/Jan/2006:15:04:05 -0700", match[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid access log time '%s'", match[3])
+	}
+
+	fields := &jsonutil.Object{}
+	fields.Set("remote_addr", match[1])
+	fields.Set("remote_user", dashEmpty(match[2]))
+	fields.Set("time", formatTime(timestamp))
+
+	// A malformed request line is kept whole as the path
+	request := unescapeQuoted(match[4])
+	if parts := strings.Fields(request); len(parts) == 3 {
+		fields.Set("method", parts[0])
+		fields.Set("path", parts[1])
+		fields.Set("protocol", parts[2])
+	} else {
+		fields.Set("method", "")
+		fields.Set("path", request)
+		fields.Set("protocol", "")
+	}
+
+	bytes := match[6]
+	if bytes == "-" {
+		bytes = "0"
+	}
+	fields.Set("status", json.Number(match[5]))
+	fields.Set("bytes", json.Number(bytes))
+
+	if match[7] != "" {
+		fields.Set("referer", dashEmpty(unescapeQuoted(match[8])))
+		fields.Set("user_agent", dashEmpty(unescapeQuoted(match[9])))
+	}
+	return fields, nil
+}
+
+func (p *AccessLogFieldParser) GetFormat() string {
+	return "access"
+}
+
+// dashEmpty turns the "-" access logs write for missing values into "".
+func dashEmpty(value string) string {
+	if value == "-" {
+		return ""
+	}
+	return value
+}
+
+// unescapeQuoted undoes the escaping of quotes inside quoted fields.
+func unescapeQuoted(value string) string {
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(value)
+}
+
+var (
+	// syslog5424Pattern matches RFC 5424 messages:
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+	syslog5424Pattern = regexp.MustCompile(`^<(\d{1,3})>(\d{1,2}) (\S+) (\S+) (\S+) (\S+) (\S+) (-|(?:\[(?:[^\]\\]|\\.)*\])+)(?: (.*))?$`)
+
+	// syslog3164Pattern matches RFC 3164 (BSD) messages, as written to
+	// /var/log/syslog, with an optional <PRI> and TAG[PID]:
+	syslog3164Pattern = regexp.MustCompile(`^(?:<(\d{1,3})>)?((?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) [ \d]\d \d{2}:\d{2}:\d{2}) (\S+) (?:([^\s:\[]+)(?:\[(\d+)\])?: ?)?(.*)$`)
+)
+
+// syslogFacilities and syslogSeverities name the parts of a syslog priority.
+var (
+	syslogFacilities = []string{
+		"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+		"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+		"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+	}
+	syslogSeverities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+)
+
+// SyslogFieldParser reads RFC 5424 and RFC 3164 (BSD) syslog lines. The
+// fields are facility and severity (when a priority is given), time, host,
+// app, pid, msgid and structured_data (RFC 5424) and message; fields the
+// line leaves out are not set.
+type SyslogFieldParser struct {
+	location *time.Location
+	now      time.Time
+}
+
+// NewSyslogFieldParser creates a parser that reads RFC 3164 timestamps,
+// which have no year or time zone, in location.
+func NewSyslogFieldParser(location *time.Location) *SyslogFieldParser {
+	return &SyslogFieldParser{location: location, now: time.Now()}
+}
+
+// SetNow sets the time used to infer the year of RFC 3164 timestamps.
+func (p *SyslogFieldParser) SetNow(now time.Time) {
+	p.now = now
+}
+
+func (p *SyslogFieldParser) ParseFields(line string) (*jsonutil.Object, error) {
+	fields := &jsonutil.Object{}
+
+	if match := syslog5424Pattern.FindStringSubmatch(line); match != nil {
+		if err := setPriority(fields, match[1]); err != nil {
+			return nil, err
+		}
+		if match[3] != "-" {
+			timestamp, err := time.Parse(time.RFC3339Nano, match[3])
+			if err != nil {
+				return nil, fmt.Errorf("invalid syslog time '%s'", match[3])
+			}
+			fields.Set("time", formatTime(timestamp))
+		}
+		for i, name := range []string{"host", "app", "pid", "msgid", "structured_data"} {
+			if value := match[4+i]; value != "-" {
+				fields.Set(name, value)
+			}
+		}
+		fields.Set("message", strings.TrimPrefix(match[9], "\ufeff"))
+		return fields, nil
+	}
+
+	match := syslog3164Pattern.FindStringSubmatch(line)
+	if match == nil {
+		return nil, fmt.Errorf("not a syslog line")
+	}
+	if match[1] != "" {
+		if err := setPriority(fields, match[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	location := p.location
+	if location == nil {
+		location = time.Local
+	}
+	timestamp, err := parseSyslogTime(match[2], location, p.now.In(location))
+	if err != nil {
+		return nil, fmt.Errorf("invalid syslog time '%s'", match[2])
+	}
+	fields.Set("time", formatTime(timestamp))
+	fields.Set("host", match[3])
+	if match[4] != "" {
+		fields.Set("app", match[4])
+	}
+	if match[5] != "" {
+		fields.Set("pid", match[5])
+	}
+	fields.Set("message", match[6])
+	return fields, nil
+}
+
+func (p *SyslogFieldParser) GetFormat() string {
+	return "syslog"
+}
+
+// setPriority sets the facility and severity encoded in a syslog priority.
+func setPriority(fields *jsonutil.Object, priority string) error {
+	value, err := strconv.Atoi(priority)
+	if err != nil || value > 191 {
+		return fmt.Errorf("invalid syslog priority <%s>", priority)
+	}
+	fields.Set("facility", syslogFacilities[value/8])
+	fields.Set("severity", syslogSeverities[value%8])
+	return nil
+}
@@ -0,0 +1,84 @@
+package logparser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RecordGrouping decides which lines start a new record when lines are
+// grouped into records, such as a log line and its stack trace.
+type RecordGrouping struct {
+	// start matches lines that start a record; nil when continuation is used
+	start *regexp.Regexp
+
+	// continuation matches lines that continue the record before them
+	continuation *regexp.Regexp
+}
+
+// recordPresets are the groupings for common stack trace layouts. They
+// describe the continuation lines, since the first line of a record can
+// look like anything.
+var recordPresets = map[string]string{
+	// Exception lines, "at ..." frames, "... 5 more", "Caused by:" and
+	// "Suppressed:" sections
+	"java": `^(?:\s+at\s|\s+\.\.\. \d+ (?:more|common frames omitted)|Caused by:|\s+Suppressed:|[\w$.]+(?:Exception|Error|Throwable)\b|\s*$)`,
+
+	// "Traceback ..." headers, indented frames and source lines, the
+	// exception line and chained exception notes
+	"python": `^(?:Traceback \(most recent call last\):|\s+\S|[\w.]+(?:Error|Exception|Warning|Interrupt|Exit)\b|During handling of the above exception|The above exception was the direct cause|\s*$)`,
+
+	// Goroutine headers, function calls, indented file:line frames,
+	// "created by" lines and the exit status
+	"go-panic": `^(?:\s|goroutine \d+ \[|[\w./*()\[\]-]+\(.*\)$|created by |\[signal |exit status \d+|$)`,
+}
+
+// RecordPresets returns the names of the record grouping presets.
+func RecordPresets() []string {
+	names := make([]string, 0, len(recordPresets))
+	for name := range recordPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseRecordGrouping parses the value of --record-start: the name of a
+// preset (java, python, go-panic) or a regular expression matching the
+// first line of every record.
+func ParseRecordGrouping(spec string) (*RecordGrouping, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("record start cannot be empty")
+	}
+	if continuation, ok := recordPresets[strings.ToLower(spec)]; ok {
+		return &RecordGrouping{continuation: regexp.MustCompile(continuation)}, nil
+	}
+
+	start, err := regexp.Compile(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid record start pattern '%s' (or use a preset: %s): %w", spec, strings.Join(RecordPresets(), ", "), err)
+	}
+	return &RecordGrouping{start: start}, nil
+}
+
+// StartsRecord reports whether a line starts a new record.
+func (g *RecordGrouping) StartsRecord(line string) bool {
+	if g.start != nil {
+		return g.start.MatchString(line)
+	}
+	return !g.continuation.MatchString(line)
+}
+
+// Group groups lines into records and returns the index of the first line
+// of each record. Lines before the first record start form a record of
+// their own.
+func (g *RecordGrouping) Group(lines []string) []int {
+	var starts []int
+	for i, line := range lines {
+		if i == 0 || g.StartsRecord(line) {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
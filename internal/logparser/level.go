@@ -0,0 +1,152 @@
+// Package logparser reads the structure of application log lines: their
+// severity level and, for structured layouts, their fields.
+package logparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is the severity of a log line. Levels are ordered, so that
+// "warn or worse" is Level >= Warn.
+type Level int
+
+const (
+	// Unknown is the level of lines without a recognised severity
+	Unknown Level = iota
+	Trace
+	Debug
+	Info
+	Warn
+	Error
+	Fatal
+)
+
+// Levels lists the known levels from the least to the most severe.
+var Levels = []Level{Trace, Debug, Info, Warn, Error, Fatal}
+
+// levelNames maps the spellings found in logs to levels. Names are
+// matched without regard to case.
+var levelNames = map[string]Level{
+	"trace":     Trace,
+	"trc":       Trace,
+	"finest":    Trace,
+	"debug":     Debug,
+	"dbg":       Debug,
+	"fine":      Debug,
+	"info":      Info,
+	"inf":       Info,
+	"notice":    Info,
+	"warn":      Warn,
+	"warning":   Warn,
+	"wrn":       Warn,
+	"error":     Error,
+	"err":       Error,
+	"erro":      Error,
+	"severe":    Error,
+	"fatal":     Fatal,
+	"ftl":       Fatal,
+	"crit":      Fatal,
+	"critical":  Fatal,
+	"alert":     Fatal,
+	"emerg":     Fatal,
+	"emergency": Fatal,
+	"panic":     Fatal,
+}
+
+// String returns the canonical name of the level.
+func (l Level) String() string {
+	switch l {
+	case Trace:
+		return "trace"
+	case Debug:
+		return "debug"
+	case Info:
+		return "info"
+	case Warn:
+		return "warn"
+	case Error:
+		return "error"
+	case Fatal:
+		return "fatal"
+	}
+	return "unknown"
+}
+
+// ParseLevel returns the level for a name such as "WARNING" or "err".
+func ParseLevel(name string) (Level, bool) {
+	level, ok := levelNames[strings.ToLower(strings.TrimSpace(name))]
+	return level, ok
+}
+
+// LevelFilter selects lines by level.
+type LevelFilter struct {
+	// levels holds the selected levels
+	levels map[Level]bool
+}
+
+// ParseLevelFilter parses a level selection: a comma separated list of
+// levels, each either a single level ("error") or a minimum level
+// ("warn+" selects warn, error and fatal). "unknown" selects lines
+// without a recognised level.
+func ParseLevelFilter(spec string) (*LevelFilter, error) {
+	filter := &LevelFilter{levels: make(map[Level]bool)}
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		minimum := strings.HasSuffix(item, "+")
+		name := strings.TrimSuffix(item, "+")
+		if strings.EqualFold(name, "unknown") && !minimum {
+			filter.levels[Unknown] = true
+			continue
+		}
+
+		level, ok := ParseLevel(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown level '%s' (expected %s, unknown; add + for a minimum level)", name, strings.Join(LevelNames(), ", "))
+		}
+		if !minimum {
+			filter.levels[level] = true
+			continue
+		}
+		for _, other := range Levels {
+			if other >= level {
+				filter.levels[other] = true
+			}
+		}
+	}
+
+	if len(filter.levels) == 0 {
+		return nil, fmt.Errorf("no levels selected")
+	}
+	return filter, nil
+}
+
+// Match reports whether a level is selected.
+func (f *LevelFilter) Match(level Level) bool {
+	return f.levels[level]
+}
+
+// String describes the selected levels, e.g. "warn, error, fatal".
+func (f *LevelFilter) String() string {
+	var names []string
+	for _, level := range append([]Level{Unknown}, Levels...) {
+		if f.levels[level] {
+			names = append(names, level.String())
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// LevelNames returns the canonical names of the known levels.
+func LevelNames() []string {
+	names := make([]string, len(Levels))
+	for i, level := range Levels {
+		names[i] = level.String()
+	}
+	return names
+}
@@ -0,0 +1,579 @@
+package logparser_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kcansari/optix/internal/jsonutil"
+	"github.com/kcansari/optix/internal/logparser"
+)
+
+// TestParseLevel tests reading level names in their common spellings.
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name          string
+		expectedLevel logparser.Level
+		expectFound   bool
+	}{
+		{"WARNING", logparser.Warn, true},
+		{"warn", logparser.Warn, true},
+		{"Err", logparser.Error, true},
+		{"CRITICAL", logparser.Fatal, true},
+		{"dbg", logparser.Debug, true},
+		{"notice", logparser.Info, true},
+		{"verbose", logparser.Unknown, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			level, found := logparser.ParseLevel(test.name)
+			if found != test.expectFound || level != test.expectedLevel {
+				t.Errorf("Expected %v (%v), got %v (%v)", test.expectedLevel, test.expectFound, level, found)
+			}
+		})
+	}
+}
+
+// TestParseLevelFilter tests minimum levels and level sets.
+func TestParseLevelFilter(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expected    string
+		expectError bool
+	}{
+		{"warn+", "warn, error, fatal", false},
+		{"error", "error", false},
+		{"ERROR,fatal", "error, fatal", false},
+		{"debug, unknown", "unknown, debug", false},
+		{"info,error+", "info, error, fatal", false},
+		{"trace+", "trace, debug, info, warn, error, fatal", false},
+		{"verbose", "", true},
+		{"unknown+", "", true},
+		{"", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			filter, err := logparser.ParseLevelFilter(test.spec)
+			if test.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if filter.String() != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, filter.String())
+			}
+		})
+	}
+}
+
+// TestLevelParserStrategy tests finding the level in the common layouts.
+func TestLevelParserStrategy(t *testing.T) {
+	tests := []struct {
+		name           string
+		line           string
+		expectedLevel  logparser.Level
+		expectedLayout string
+	}{
+		{"prefix", "ERROR: Database connection failed", logparser.Error, "prefix"},
+		{"prefix mixed case", "Warning: disk almost full", logparser.Warn, "prefix"},
+		{"prefix after timestamp", "2024-05-01 12:00:00,123 INFO Started", logparser.Info, "prefix"},
+		{"python logging", "2024-05-01 12:00:00 - app - WARNING - Slow query", logparser.Warn, "prefix"},
+		{"level in message only", "INFO: Retrying after ERROR", logparser.Info, "prefix"},
+		{"lower case word in message", "connection error while saving", logparser.Unknown, ""},
+		{"brackets", "2024-05-01 12:00:00 [main] [ERROR] Failed", logparser.Error, "bracket"},
+		{"logfmt", `ts=2024-05-01T12:00:00Z level=warn msg="ERROR budget low"`, logparser.Warn, "logfmt"},
+		{"logfmt quoted", `time=1 lvl="debug" msg=x`, logparser.Debug, "logfmt"},
+		{"json", `{"time":"2024-05-01","level":"error","msg":"INFO: x"}`, logparser.Error, "json"},
+		{"json numeric", `{"level":40,"msg":"x"}`, logparser.Warn, "json"},
+		{"json severity", `{"severity":"CRITICAL"}`, logparser.Fatal, "json"},
+		{"stack trace", "    at com.example.Main.run(Main.java:42)", logparser.Unknown, ""},
+		{"empty", "", logparser.Unknown, ""},
+	}
+
+	strategy := logparser.NewDefaultLevelParserStrategy()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			level, layout := strategy.ParseLevel(test.line)
+			if level != test.expectedLevel {
+				t.Errorf("Expected level %v, got %v", test.expectedLevel, level)
+			}
+			if layout != test.expectedLayout {
+				t.Errorf("Expected layout %q, got %q", test.expectedLayout, layout)
+			}
+		})
+	}
+}
+
+// TestParseTimestamp tests finding timestamps in the common formats.
+func TestParseTimestamp(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		line        string
+		expected    time.Time
+		expectFound bool
+	}{
+		{"rfc3339", "2024-05-01T14:02:03Z ERROR x", time.Date(2024, 5, 1, 14, 2, 3, 0, time.UTC), true},
+		{"rfc3339 offset", "2024-05-01T16:02:03.5+02:00 x", time.Date(2024, 5, 1, 14, 2, 3, 500000000, time.UTC), true},
+		{"iso with comma", "2024-05-01 14:02:03,250 INFO x", time.Date(2024, 5, 1, 14, 2, 3, 250000000, time.UTC), true},
+		{"offset after a space", "2024-05-01 14:02:03,123 +0200 INFO x", time.Date(2024, 5, 1, 12, 2, 3, 123000000, time.UTC), true},
+		{"offset after a space with T", "2024-05-01T14:02:03 +0200 INFO x", time.Date(2024, 5, 1, 12, 2, 3, 0, time.UTC), true},
+		{"negative offset after a space", "2024-05-01 14:02:03 -05:00 INFO x", time.Date(2024, 5, 1, 19, 2, 3, 0, time.UTC), true},
+		{"go log", "2024/05/01 14:02:03 started", time.Date(2024, 5, 1, 14, 2, 3, 0, time.UTC), true},
+		{"apache", `10.0.0.1 - - [01/May/2024:16:02:03 +0200] "GET / HTTP/1.1" 200`, time.Date(2024, 5, 1, 14, 2, 3, 0, time.UTC), true},
+		{"syslog", "May  1 14:02:03 host sshd[42]: accepted", time.Date(2024, 5, 1, 14, 2, 3, 0, time.UTC), true},
+		{"syslog last year", "Dec 31 23:00:00 host cron: x", time.Date(2023, 12, 31, 23, 0, 0, 0, time.UTC), true},
+		{"epoch millis", `{"ts":1714572123456,"msg":"x"}`, time.UnixMilli(1714572123456), true},
+		{"epoch seconds", "1714572123 started", time.Unix(1714572123, 0), true},
+		{"logfmt epoch", "ts=1714572123.5 level=info", time.Unix(1714572123, 500000000), true},
+		{"earliest wins", "May  1 14:02:03 host app: 2024-01-01T00:00:00Z", time.Date(2024, 5, 1, 14, 2, 3, 0, time.UTC), true},
+		{"no timestamp", "    at com.example.Main.run(Main.java:42)", time.Time{}, false},
+		{"plain number", "processed 1714572123 records", time.Time{}, false},
+	}
+
+	parser := logparser.NewTimestampParser(time.UTC)
+	parser.SetNow(now)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, found := parser.ParseTimestamp(test.line)
+			if found != test.expectFound {
+				t.Fatalf("Expected found %v, got %v", test.expectFound, found)
+			}
+			if found && !parsed.Equal(test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, parsed)
+			}
+		})
+	}
+}
+
+// TestLayoutTimestampParser tests timestamps in custom Go layouts.
+func TestLayoutTimestampParser(t *testing.T) {
+	tests := []struct {
+		layout      string
+		line        string
+		expected    time.Time
+		expectError bool
+	}{
+		{"02.01.2006 15:04:05", "[app] 01.05.2024 14:02:03 started", time.Date(2024, 5, 1, 14, 2, 3, 0, time.UTC), false},
+		{"Jan 2, 2006 at 3:04pm", "May 1, 2024 at 2:02pm x", time.Date(2024, 5, 1, 14, 2, 0, 0, time.UTC), false},
+		{"2006-01-02 15:04:05.000", "2024-05-01 14:02:03.123 x", time.Date(2024, 5, 1, 14, 2, 3, 123000000, time.UTC), false},
+		{"no elements", "", time.Time{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.layout, func(t *testing.T) {
+			parser, err := logparser.NewLayoutTimestampParser(test.layout, time.UTC)
+			if test.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			parsed, found := parser.ParseTimestamp(test.line)
+			if !found || !parsed.Equal(test.expected) {
+				t.Errorf("Expected %v, got %v (found %v)", test.expected, parsed, found)
+			}
+		})
+	}
+}
+
+// TestParseTimeBound tests the values accepted by --since and --until.
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC)
+	tests := []struct {
+		value       string
+		expected    time.Time
+		expectError bool
+	}{
+		{"-15m", time.Date(2024, 5, 1, 14, 15, 0, 0, time.UTC), false},
+		{"2h", time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC), false},
+		{"14:02", time.Date(2024, 5, 1, 14, 2, 0, 0, time.UTC), false},
+		{"14:02:30", time.Date(2024, 5, 1, 14, 2, 30, 0, time.UTC), false},
+		{"2024-04-30 09:00", time.Date(2024, 4, 30, 9, 0, 0, 0, time.UTC), false},
+		{"2024-04-30T09:00:00+02:00", time.Date(2024, 4, 30, 7, 0, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			parsed, err := logparser.ParseTimeBound(test.value, now)
+			if test.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !parsed.Equal(test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, parsed)
+			}
+		})
+	}
+}
+
+// TestRecordParser tests grouping continuation lines with their entry.
+func TestRecordParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected []int // number of lines of each record
+	}{
+		{"one line per entry", []string{"INFO: a", "ERROR: b"}, []int{1, 1}},
+		{"indented stack trace", []string{"ERROR: failed", "    at a.b(C.java:1)", "    at d.e(F.java:2)", "INFO: next"}, []int{3, 1}},
+		{"timestamped continuation", []string{"2024-05-01T14:02:00Z ERROR failed", "java.lang.RuntimeException: boom", "    at a.b(C.java:1)", "2024-05-01T14:03:00Z INFO ok"}, []int{3, 1}},
+		{"plain lines without timestamps", []string{"INFO: a", "no level here", "ERROR: b"}, []int{1, 1, 1}},
+		{"leading continuation", []string{"    orphan", "INFO: a"}, []int{1, 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parser := logparser.NewRecordParser(logparser.NewDefaultLevelParserStrategy(), logparser.NewTimestampParser(time.UTC))
+			records := parser.Records(test.lines)
+
+			var sizes []int
+			for _, record := range records {
+				sizes = append(sizes, len(record.Lines))
+			}
+			if !reflect.DeepEqual(sizes, test.expected) {
+				t.Errorf("Expected record sizes %v, got %v", test.expected, sizes)
+			}
+		})
+	}
+}
+
+// TestHistogram tests counting entries per time bucket.
+func TestHistogram(t *testing.T) {
+	at := func(minute, second int) *logparser.Record {
+		return &logparser.Record{Time: time.Date(2024, 5, 1, 14, minute, second, 0, time.UTC)}
+	}
+	records := []*logparser.Record{at(2, 10), at(2, 50), at(5, 0), {}, at(3, 0)}
+
+	buckets, err := logparser.Histogram(records, time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []int{2, 1, 0, 1}
+	var counts []int
+	for _, bucket := range buckets {
+		counts = append(counts, bucket.Count)
+	}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Expected counts %v, got %v", expected, counts)
+	}
+	if !buckets[0].Start.Equal(time.Date(2024, 5, 1, 14, 2, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected first bucket %v", buckets[0].Start)
+	}
+
+	if _, err := logparser.Histogram(records, 0); err == nil {
+		t.Errorf("Expected error for a zero bucket width")
+	}
+}
+
+// TestRecordGrouping tests grouping lines into records with --record-start.
+func TestRecordGrouping(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		lines    []string
+		expected []int // index of the first line of each record
+	}{
+		{
+			"java with cause", "java",
+			[]string{
+				"2024-05-01 12:00:00 ERROR request failed",
+				"java.lang.IllegalStateException: boom",
+				"\tat com.example.A.run(A.java:10)",
+				"\t... 3 more",
+				"Caused by: java.io.IOException: closed",
+				"\tat com.example.B.read(B.java:20)",
+				"2024-05-01 12:00:01 INFO recovered",
+			},
+			[]int{0, 6},
+		},
+		{
+			"python traceback", "python",
+			[]string{
+				"ERROR:root:failed",
+				"Traceback (most recent call last):",
+				`  File "app.py", line 3, in <module>`,
+				"    main()",
+				"ValueError: bad value",
+				"INFO:root:done",
+			},
+			[]int{0, 5},
+		},
+		{
+			"go panic", "go-panic",
+			[]string{
+				"2024/05/01 12:00:00 starting",
+				"panic: runtime error: index out of range",
+				"",
+				"goroutine 1 [running]:",
+				"main.main()",
+				"\t/app/main.go:12 +0x1d",
+				"exit status 2",
+				"2024/05/01 12:00:05 restarted",
+			},
+			[]int{0, 1, 7},
+		},
+		{
+			"custom regex", `^\d{4}-`,
+			[]string{"orphan", "2024-05-01 a", "continued", "2024-05-02 b"},
+			[]int{0, 1, 3},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grouping, err := logparser.ParseRecordGrouping(test.spec)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if starts := grouping.Group(test.lines); !reflect.DeepEqual(starts, test.expected) {
+				t.Errorf("Expected record starts %v, got %v", test.expected, starts)
+			}
+		})
+	}
+
+	for _, spec := range []string{"", "(unclosed"} {
+		if _, err := logparser.ParseRecordGrouping(spec); err == nil {
+			t.Errorf("Expected error for record start %q", spec)
+		}
+	}
+}
+
+// TestFieldParsers tests splitting log lines of each format into fields.
+func TestFieldParsers(t *testing.T) {
+	syslog := logparser.NewSyslogFieldParser(time.UTC)
+	syslog.SetNow(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name     string
+		parser   logparser.FieldParser
+		line     string
+		expected map[string]string // expected text of some of the fields
+		missing  []string          // fields that must not be set
+	}{
+		{
+			"logfmt", &logparser.LogfmtFieldParser{},
+			`time=2024-05-01T14:02:03Z level=warn msg="disk \"almost\" full" dry`,
+			map[string]string{"level": "warn", "msg": `disk "almost" full`, "dry": "true"}, nil,
+		},
+		{
+			"combined access log", &logparser.AccessLogFieldParser{},
+			`10.0.0.1 - frank [01/May/2024:14:02:03 +0200] "POST /api/orders HTTP/1.1" 503 - "-" "curl/8.0"`,
+			map[string]string{"remote_addr": "10.0.0.1", "remote_user": "frank", "time": "2024-05-01T14:02:03+02:00", "method": "POST", "path": "/api/orders", "status": "503", "bytes": "0", "referer": "", "user_agent": "curl/8.0"}, nil,
+		},
+		{
+			"common access log", &logparser.AccessLogFieldParser{},
+			`::1 - - [01/May/2024:14:02:03 +0000] "GET / HTTP/1.0" 200 42`,
+			map[string]string{"status": "200", "bytes": "42"}, []string{"referer", "user_agent"},
+		},
+		{
+			"rfc 5424 syslog", syslog,
+			`<34>1 2024-05-01T14:02:03.5Z host1 sshd 123 - [origin ip="10.0.0.1"] Failed password`,
+			map[string]string{"facility": "auth", "severity": "crit", "time": "2024-05-01T14:02:03.5Z", "host": "host1", "app": "sshd", "pid": "123", "structured_data": `[origin ip="10.0.0.1"]`, "message": "Failed password"},
+			[]string{"msgid"},
+		},
+		{
+			"rfc 3164 syslog", syslog,
+			`May  1 14:02:03 host2 cron[99]: job done`,
+			map[string]string{"time": "2024-05-01T14:02:03Z", "host": "host2", "app": "cron", "pid": "99", "message": "job done"},
+			[]string{"facility", "severity"},
+		},
+		{
+			"nested json", &logparser.JSONFieldParser{},
+			`{"level":"error","http":{"status":500}}`,
+			map[string]string{"level": "error", "http.status": "500"}, nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fields, err := test.parser.ParseFields(test.line)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for key, expected := range test.expected {
+				value, ok := fields.Get(key)
+				if !ok || jsonutil.Text(value) != expected {
+					t.Errorf("Expected %s=%q, got %v (set: %t)", key, expected, value, ok)
+				}
+			}
+			for _, key := range test.missing {
+				if _, ok := fields.Get(key); ok {
+					t.Errorf("Expected %s to be missing", key)
+				}
+			}
+		})
+	}
+
+	// Lines in another format are rejected
+	invalid := map[logparser.FieldParser]string{
+		&logparser.LogfmtFieldParser{}:    "just some words",
+		&logparser.AccessLogFieldParser{}: "level=info msg=hello",
+		syslog:                            "2024-05-01 INFO started",
+		&logparser.JSONFieldParser{}:      "[1, 2]",
+	}
+	for parser, line := range invalid {
+		if _, err := parser.ParseFields(line); err == nil {
+			t.Errorf("Expected %s parser to reject %q", parser.GetFormat(), line)
+		}
+	}
+}
+
+// TestDetectFormat tests choosing the log format from the first lines.
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		lines    []string
+		expected string
+	}{
+		{[]string{`{"level":"info"}`, `{"level":"warn"}`}, "json"},
+		{[]string{`1.2.3.4 - - [01/May/2024:14:02:03 +0000] "GET / HTTP/1.1" 200 1`}, "access"},
+		{[]string{"May  1 14:02:03 host app: a", "", "May  1 14:02:04 host app: b"}, "syslog"},
+		{[]string{"level=info msg=a", "level=warn msg=b"}, "logfmt"},
+		{[]string{"plain text", "more text"}, ""},
+	}
+
+	strategy := logparser.NewDefaultFieldParserStrategy(time.UTC)
+	for _, test := range tests {
+		format := ""
+		if parser := strategy.DetectFormat(test.lines); parser != nil {
+			format = parser.GetFormat()
+		}
+		if format != test.expected {
+			t.Errorf("DetectFormat(%q) = %q, expected %q", test.lines, format, test.expected)
+		}
+	}
+}
+
+// TestParseCondition tests parsing and evaluating --where conditions.
+func TestParseCondition(t *testing.T) {
+	fields := &jsonutil.Object{}
+	fields.Set("status", json.Number("503"))
+	fields.Set("path", "/api/orders")
+	fields.Set("bytes", "90")
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{"status>=500", true},
+		{"status < 500", false},
+		{"status=503", true},
+		{"status==503.0", true},
+		{"bytes>100", false}, // numeric, not text, comparison
+		{"path~^/api/", true},
+		{"path!~^/api/", false},
+		{"path!=/health", true},
+		{"missing=x", false},
+		{"missing!=x", true},
+	}
+
+	for _, test := range tests {
+		condition, err := logparser.ParseCondition(test.expression)
+		if err != nil {
+			t.Errorf("ParseCondition(%q) returned error: %v", test.expression, err)
+			continue
+		}
+		if matched := condition.Match(fields); matched != test.expected {
+			t.Errorf("%q matched %t, expected %t", test.expression, matched, test.expected)
+		}
+	}
+
+	for _, expression := range []string{"status", ">=500", "path~("} {
+		if _, err := logparser.ParseCondition(expression); err == nil {
+			t.Errorf("Expected error for condition %q", expression)
+		}
+	}
+}
+
+// TestMaskLine tests masking the variable parts of log lines
+func TestMaskLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{"request took 532ms", "request took <NUM>"},
+		{"user-42 logged in from 10.0.0.7:51234", "user-<NUM> logged in from <IP>"},
+		{"job 3f2b1c9e-1a2b-4c3d-8e9f-0a1b2c3d4e5f done", "job <UUID> done"},
+		{`cache miss for key "user:17"`, "cache miss for key <STR>"},
+		{"2024-05-01T14:02:03.120Z started at 14:02:03", "<TIME> started at <TIME>"},
+		{"commit a1b2c3d4 at 0x7ff3 on deface", "commit <HEX> at <HEX> on deface"},
+		{"http2 utf8 stays", "http2 utf8 stays"},
+	}
+
+	for _, test := range tests {
+		if masked := logparser.MaskLine(test.line); masked != test.expected {
+			t.Errorf("MaskLine(%q) = %q, expected %q", test.line, masked, test.expected)
+		}
+	}
+}
+
+// TestPatternMiner tests grouping lines into templates
+func TestPatternMiner(t *testing.T) {
+	lines := []string{
+		"user alice logged in from 10.0.0.1",
+		"cache miss for key 17",
+		"user bob logged in from 10.0.0.2",
+		"",
+		"disk full on /dev/sda1",
+		"user carol logged in from 10.0.0.3",
+		"cache miss for key 18",
+	}
+
+	miner := logparser.NewPatternMiner(logparser.DefaultSimilarity)
+	for i, line := range lines {
+		pattern := miner.Add(i+1, line)
+		if (pattern == nil) != (line == "") {
+			t.Errorf("Add(%q) returned %v", line, pattern)
+		}
+	}
+
+	expected := []struct {
+		template  string
+		count     int
+		firstLine int
+		lastLine  int
+		example   string
+	}{
+		{"user <*> logged in from <IP>", 3, 1, 6, "user alice logged in from 10.0.0.1"},
+		{"cache miss for key <NUM>", 2, 2, 7, "cache miss for key 17"},
+		{"disk full on /dev/sda1", 1, 5, 5, "disk full on /dev/sda1"},
+	}
+
+	patterns := miner.Patterns()
+	if len(patterns) != len(expected) {
+		t.Fatalf("Expected %d patterns, got %d", len(expected), len(patterns))
+	}
+	for i, pattern := range patterns {
+		want := expected[i]
+		if pattern.Template() != want.template || pattern.Count != want.count ||
+			pattern.FirstLine != want.firstLine || pattern.LastLine != want.lastLine || pattern.Example != want.example {
+			t.Errorf("Pattern %d = %q (%d lines, %d-%d, %q), expected %q (%d lines, %d-%d, %q)",
+				i, pattern.Template(), pattern.Count, pattern.FirstLine, pattern.LastLine, pattern.Example,
+				want.template, want.count, want.firstLine, want.lastLine, want.example)
+		}
+	}
+
+	// A stricter similarity keeps lines with fewer equal words apart
+	strict := logparser.NewPatternMiner(0.9)
+	strict.Add(1, "user alice logged in")
+	strict.Add(2, "user bob logged in")
+	if patterns := strict.Patterns(); len(patterns) != 2 {
+		t.Errorf("Expected 2 patterns with similarity 0.9, got %d", len(patterns))
+	}
+}
@@ -0,0 +1,204 @@
+package logparser
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// LevelParser finds the severity of a log line written in one layout.
+// This follows the Strategy pattern used for readers and processors.
+type LevelParser interface {
+	// ParseLevel returns the level of the line, if the line uses the layout
+	ParseLevel(line string) (Level, bool)
+
+	// GetLayout returns the name of the layout, e.g. "logfmt"
+	GetLayout() string
+}
+
+// LevelParserStrategy tries several layouts in turn.
+type LevelParserStrategy struct {
+	parsers []LevelParser
+}
+
+// NewLevelParserStrategy creates a strategy without parsers.
+func NewLevelParserStrategy() *LevelParserStrategy {
+	return &LevelParserStrategy{}
+}
+
+// NewDefaultLevelParserStrategy creates a strategy for the common layouts.
+// Structured layouts are tried first, since their level field is
+// unambiguous.
+func NewDefaultLevelParserStrategy() *LevelParserStrategy {
+	strategy := NewLevelParserStrategy()
+	strategy.AddParser(&JSONLevelParser{})
+	strategy.AddParser(&LogfmtLevelParser{})
+	strategy.AddParser(&BracketLevelParser{})
+	strategy.AddParser(&PrefixLevelParser{})
+	return strategy
+}
+
+// AddParser adds a parser, tried after the ones already added.
+func (s *LevelParserStrategy) AddParser(parser LevelParser) {
+	s.parsers = append(s.parsers, parser)
+}
+
+// ParseLevel returns the level of a line and the layout it was found in.
+// Lines without a recognised level are Unknown.
+func (s *LevelParserStrategy) ParseLevel(line string) (Level, string) {
+	for _, parser := range s.parsers {
+		if level, ok := parser.ParseLevel(line); ok {
+			return level, parser.GetLayout()
+		}
+	}
+	return Unknown, ""
+}
+
+// JSONLevelParser reads the level field of JSON log lines, such as
+// {"level":"warn","msg":"..."}. Numeric levels (pino, bunyan) are
+// supported too.
+type JSONLevelParser struct{}
+
+// jsonLevelKeys are the field names that hold the level, in order of preference.
+var jsonLevelKeys = []string{"level", "lvl", "severity", "log.level", "loglevel", "levelname", "log_level"}
+
+func (p *JSONLevelParser) ParseLevel(line string) (Level, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") {
+		return Unknown, false
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(trimmed), &fields); err != nil {
+		return Unknown, false
+	}
+	for _, key := range jsonLevelKeys {
+		switch value := fields[key].(type) {
+		case string:
+			if level, ok := ParseLevel(value); ok {
+				return level, true
+			}
+		case float64:
+			if level, ok := numericLevel(value); ok {
+				return level, true
+			}
+		}
+	}
+	return Unknown, false
+}
+
+func (p *JSONLevelParser) GetLayout() string {
+	return "json"
+}
+
+// numericLevel maps the numeric levels of pino and bunyan
+// (10 trace, 20 debug, 30 info, 40 warn, 50 error, 60 fatal).
+func numericLevel(value float64) (Level, bool) {
+	switch {
+	case value >= 60:
+		return Fatal, true
+	case value >= 50:
+		return Error, true
+	case value >= 40:
+		return Warn, true
+	case value >= 30:
+		return Info, true
+	case value >= 20:
+		return Debug, true
+	case value >= 10:
+		return Trace, true
+	}
+	return Unknown, false
+}
+
+// LogfmtLevelParser reads the level key of logfmt lines, such as
+// `time=... level=warn msg="disk almost full"`.
+type LogfmtLevelParser struct{}
+
+// logfmtLevelPattern matches a level key and its (optionally quoted) value.
+var logfmtLevelPattern = regexp.MustCompile(`(?:^|\s)(?:level|lvl|severity|loglevel)="?([A-Za-z]+)"?(?:\s|$)`)
+
+func (p *LogfmtLevelParser) ParseLevel(line string) (Level, bool) {
+	match := logfmtLevelPattern.FindStringSubmatch(line)
+	if match == nil {
+		return Unknown, false
+	}
+	return ParseLevel(match[1])
+}
+
+func (p *LogfmtLevelParser) GetLayout() string {
+	return "logfmt"
+}
+
+// BracketLevelParser reads levels written in brackets, such as
+// "2024-05-01 12:00:00 [main] [ERROR] message".
+type BracketLevelParser struct{}
+
+// bracketPattern matches a bracketed word.
+var bracketPattern = regexp.MustCompile(`\[\s*([A-Za-z]+)\s*\]`)
+
+// maxBracketGroups is how many bracketed words are looked at; later ones
+// belong to the message.
+const maxBracketGroups = 3
+
+func (p *BracketLevelParser) ParseLevel(line string) (Level, bool) {
+	for _, match := range bracketPattern.FindAllStringSubmatch(line, maxBracketGroups) {
+		if level, ok := ParseLevel(match[1]); ok {
+			return level, true
+		}
+	}
+	return Unknown, false
+}
+
+func (p *BracketLevelParser) GetLayout() string {
+	return "bracket"
+}
+
+// PrefixLevelParser reads levels written as a word near the start of the
+// line: "ERROR: message", "2024-05-01 12:00:00 WARN message" or
+// "12:00:00 - app - INFO - message". After the first word, only upper
+// case levels count, so that "info" or "error" in a message is not
+// taken for the level.
+type PrefixLevelParser struct{}
+
+// maxPrefixWords is how many words are looked at for the level.
+const maxPrefixWords = 6
+
+func (p *PrefixLevelParser) ParseLevel(line string) (Level, bool) {
+	words := strings.Fields(line)
+	for i, word := range words {
+		if i == maxPrefixWords {
+			break
+		}
+
+		// "ERROR:" and "Error:" are levels wherever they start the line
+		name := strings.TrimRight(word, ":")
+		if i > 0 || name == word {
+			if !isUpper(name) {
+				continue
+			}
+		}
+		name = strings.Trim(name, "-|<>")
+		if level, ok := ParseLevel(name); ok {
+			return level, true
+		}
+	}
+	return Unknown, false
+}
+
+func (p *PrefixLevelParser) GetLayout() string {
+	return "prefix"
+}
+
+// isUpper reports whether word has letters and they are all upper case.
+func isUpper(word string) bool {
+	letters := false
+	for _, r := range word {
+		if unicode.IsLower(r) {
+			return false
+		}
+		letters = letters || unicode.IsLetter(r)
+	}
+	return letters
+}
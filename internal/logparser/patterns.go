@@ -0,0 +1,209 @@
+package logparser
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Wildcard stands for the tokens in which the lines of a pattern differ.
+const Wildcard = "<*>"
+
+// tokenMasks replace the variable parts of a line with placeholders before
+// clustering, in order: quoted strings may contain anything, and numbers
+// are masked last since they occur inside the other values.
+var tokenMasks = []struct {
+	pattern     *regexp.Regexp
+	placeholder string
+	// mask, when set, decides whether a match is masked
+	mask func(match string) bool
+}{
+	{pattern: regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'`), placeholder: "<STR>"},
+	{pattern: regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`), placeholder: "<UUID>"},
+	{pattern: regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)?|\b\d{2}:\d{2}:\d{2}(?:[.,]\d+)?\b`), placeholder: "<TIME>"},
+	{pattern: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?::\d+)?\b|\b(?:[0-9a-fA-F]{1,4}:){7}[0-9a-fA-F]{1,4}\b`), placeholder: "<IP>"},
+	{pattern: regexp.MustCompile(`\b0x[0-9a-fA-F]+\b|\b[0-9a-fA-F]{6,}\b`), placeholder: "<HEX>", mask: isHexValue},
+	{pattern: regexp.MustCompile(`\b\d+(?:\.\d+)?(?:[a-zA-Z]{1,3})?\b`), placeholder: "<NUM>"},
+}
+
+// isHexValue reports whether a run of hex digits is a value such as a
+// commit or an address rather than a number or a word like "deface".
+func isHexValue(match string) bool {
+	if strings.HasPrefix(match, "0x") {
+		return true
+	}
+	return strings.ContainsAny(match, "0123456789") && strings.ContainsAny(match, "abcdefABCDEF")
+}
+
+// MaskLine replaces the variable parts of a log line (quoted strings,
+// UUIDs, timestamps, IP addresses, hex values and numbers) with
+// placeholders such as <NUM>.
+func MaskLine(line string) string {
+	for _, mask := range tokenMasks {
+		if mask.mask == nil {
+			line = mask.pattern.ReplaceAllString(line, mask.placeholder)
+			continue
+		}
+		line = mask.pattern.ReplaceAllStringFunc(line, func(match string) string {
+			if !mask.mask(match) {
+				return match
+			}
+			return mask.placeholder
+		})
+	}
+	return line
+}
+
+// Pattern is a message template shared by a group of log lines.
+type Pattern struct {
+	// Tokens are the words of the template; Wildcard marks variable words
+	Tokens []string
+
+	// Count is the number of lines that match the template
+	Count int
+
+	// FirstLine and LastLine are the line numbers of the first and last
+	// matching line
+	FirstLine int
+	LastLine  int
+
+	// Example is the first matching line as it appears in the log
+	Example string
+}
+
+// Template returns the template as text.
+func (p *Pattern) Template() string {
+	return strings.Join(p.Tokens, " ")
+}
+
+// patternNode is a node of the prefix tree that routes lines to the
+// patterns they may belong to.
+type patternNode struct {
+	children map[string]*patternNode
+	patterns []*Pattern
+}
+
+// PatternMiner groups log lines into templates with the Drain algorithm:
+// lines are routed by their number of words and their first word to a
+// small set of candidate patterns, joined to the most similar one, and the
+// words in which they differ become wildcards.
+type PatternMiner struct {
+	// similarity is the share of equal words needed to join a pattern
+	similarity float64
+
+	// prefixDepth is the number of leading words used for routing
+	prefixDepth int
+
+	// maxChildren limits the branches of a tree node; further words are
+	// routed to a wildcard branch
+	maxChildren int
+
+	roots    map[int]*patternNode
+	patterns []*Pattern
+}
+
+// DefaultSimilarity is the share of equal words at which a line joins a
+// pattern.
+const DefaultSimilarity = 0.5
+
+// NewPatternMiner creates a miner joining lines to a pattern when at least
+// the given share (0 to 1) of their words are equal.
+func NewPatternMiner(similarity float64) *PatternMiner {
+	return &PatternMiner{
+		similarity:  similarity,
+		prefixDepth: 1,
+		maxChildren: 100,
+		roots:       make(map[int]*patternNode),
+	}
+}
+
+// Add adds a line and returns the pattern it was grouped into. Blank lines
+// are ignored and return nil.
+func (m *PatternMiner) Add(lineNumber int, line string) *Pattern {
+	tokens := strings.Fields(MaskLine(line))
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	leaf := m.leaf(tokens)
+	if best := m.closest(leaf.patterns, tokens); best != nil {
+		for i, token := range tokens {
+			if best.Tokens[i] != token {
+				best.Tokens[i] = Wildcard
+			}
+		}
+		best.Count++
+		best.LastLine = lineNumber
+		return best
+	}
+
+	pattern := &Pattern{Tokens: tokens, Count: 1, FirstLine: lineNumber, LastLine: lineNumber, Example: line}
+	leaf.patterns = append(leaf.patterns, pattern)
+	m.patterns = append(m.patterns, pattern)
+	return pattern
+}
+
+// leaf returns the tree node for lines with these words, creating it.
+func (m *PatternMiner) leaf(tokens []string) *patternNode {
+	node, ok := m.roots[len(tokens)]
+	if !ok {
+		node = &patternNode{children: make(map[string]*patternNode)}
+		m.roots[len(tokens)] = node
+	}
+
+	for _, token := range tokens[:min(m.prefixDepth, len(tokens))] {
+		// Words with digits are likely variable and share one branch
+		key := token
+		if strings.IndexFunc(token, unicode.IsDigit) >= 0 {
+			key = Wildcard
+		}
+
+		child, ok := node.children[key]
+		if !ok {
+			if len(node.children) >= m.maxChildren {
+				key = Wildcard
+			}
+			if child, ok = node.children[key]; !ok {
+				child = &patternNode{children: make(map[string]*patternNode)}
+				node.children[key] = child
+			}
+		}
+		node = child
+	}
+	return node
+}
+
+// closest returns the most similar pattern if it is similar enough.
+func (m *PatternMiner) closest(patterns []*Pattern, tokens []string) *Pattern {
+	var best *Pattern
+	bestSimilarity, bestWildcards := -1.0, -1
+	for _, pattern := range patterns {
+		equal, wildcards := 0, 0
+		for i, token := range pattern.Tokens {
+			switch token {
+			case Wildcard:
+				wildcards++
+			case tokens[i]:
+				equal++
+			}
+		}
+		similarity := float64(equal) / float64(len(tokens))
+		if similarity > bestSimilarity || (similarity == bestSimilarity && wildcards > bestWildcards) {
+			best, bestSimilarity, bestWildcards = pattern, similarity, wildcards
+		}
+	}
+	if best == nil || bestSimilarity < m.similarity {
+		return nil
+	}
+	return best
+}
+
+// Patterns returns the patterns found, the most frequent first.
+func (m *PatternMiner) Patterns() []*Pattern {
+	patterns := append([]*Pattern(nil), m.patterns...)
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].Count > patterns[j].Count
+	})
+	return patterns
+}
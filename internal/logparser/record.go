@@ -0,0 +1,193 @@
+package logparser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Record is a log entry: a line with its continuation lines, such as the
+// lines of a stack trace.
+type Record struct {
+	// LineNumber is the 1-based line the record starts on
+	LineNumber int
+
+	// Lines holds the lines of the record
+	Lines []string
+
+	// Level is the level of the first line
+	Level Level
+
+	// Time is the timestamp of the first line; zero when it has none
+	Time time.Time
+}
+
+// Text returns the lines of the record, each ending with a newline.
+func (r *Record) Text() string {
+	return strings.Join(r.Lines, "\n") + "\n"
+}
+
+// HasTime reports whether the record has a timestamp.
+func (r *Record) HasTime() bool {
+	return !r.Time.IsZero()
+}
+
+// RecordParser groups log lines into records. A line without a level or
+// a timestamp continues the record before it when it is indented, or when
+// the log has timestamps; otherwise it is a record of its own.
+type RecordParser struct {
+	levels *LevelParserStrategy
+	times  *TimestampParser
+
+	// current is the record being collected
+	current *Record
+
+	// lineNumber counts the lines added so far
+	lineNumber int
+
+	// timed reports whether a record with a timestamp has been seen
+	timed bool
+
+	// grouping, when set, decides which lines start a record
+	grouping *RecordGrouping
+}
+
+// NewRecordParser creates a record parser. times may be nil to ignore
+// timestamps.
+func NewRecordParser(levels *LevelParserStrategy, times *TimestampParser) *RecordParser {
+	return &RecordParser{levels: levels, times: times}
+}
+
+// SetGrouping makes the parser start records only at the lines the
+// grouping selects, instead of at lines with a level or timestamp.
+func (p *RecordParser) SetGrouping(grouping *RecordGrouping) {
+	p.grouping = grouping
+}
+
+// Add adds a line and returns the record it completes, if any.
+func (p *RecordParser) Add(line string) *Record {
+	p.lineNumber++
+
+	level, _ := p.levels.ParseLevel(line)
+	var timestamp time.Time
+	hasTime := false
+	if p.times != nil {
+		timestamp, hasTime = p.times.ParseTimestamp(line)
+	}
+
+	continues := level == Unknown && !hasTime && p.continues(line)
+	if p.grouping != nil {
+		continues = !p.grouping.StartsRecord(line)
+	}
+	if p.current != nil && continues {
+		p.current.Lines = append(p.current.Lines, line)
+		return nil
+	}
+
+	completed := p.current
+	p.current = &Record{LineNumber: p.lineNumber, Lines: []string{line}, Level: level, Time: timestamp}
+	p.timed = p.timed || hasTime
+	return completed
+}
+
+// continues reports whether a line without a level or timestamp belongs
+// to the current record.
+func (p *RecordParser) continues(line string) bool {
+	if p.timed || line == "" {
+		return true
+	}
+	first, _ := firstRune(line)
+	return unicode.IsSpace(first)
+}
+
+// Flush returns the last record, if any.
+func (p *RecordParser) Flush() *Record {
+	completed := p.current
+	p.current = nil
+	return completed
+}
+
+// Records groups all lines into records.
+func (p *RecordParser) Records(lines []string) []*Record {
+	var records []*Record
+	for _, line := range lines {
+		if record := p.Add(line); record != nil {
+			records = append(records, record)
+		}
+	}
+	if record := p.Flush(); record != nil {
+		records = append(records, record)
+	}
+	return records
+}
+
+// firstRune returns the first character of s.
+func firstRune(s string) (rune, bool) {
+	for _, r := range s {
+		return r, true
+	}
+	return 0, false
+}
+
+// maxBuckets limits the number of histogram buckets.
+const maxBuckets = 100000
+
+// Bucket is a time interval of a histogram and the records in it.
+type Bucket struct {
+	// Start is the beginning of the interval
+	Start time.Time
+
+	// Count is the number of records in the interval
+	Count int
+}
+
+// Histogram counts records per interval of the given width, aligned to
+// the wall clock of the records' time zone. Every interval from the first
+// to the last record is returned, including empty ones. Records without a
+// timestamp are not counted.
+func Histogram(records []*Record, width time.Duration) ([]Bucket, error) {
+	if width <= 0 {
+		return nil, fmt.Errorf("bucket width must be positive")
+	}
+
+	counts := make(map[int64]int)
+	var first, last time.Time
+	var location *time.Location
+	for _, record := range records {
+		if !record.HasTime() {
+			continue
+		}
+		start := bucketStart(record.Time, width)
+		counts[start.UnixNano()]++
+		if location == nil || start.Before(first) {
+			first = start
+		}
+		if location == nil || start.After(last) {
+			last = start
+		}
+		location = record.Time.Location()
+	}
+	if location == nil {
+		return nil, nil
+	}
+
+	if last.Sub(first)/width >= maxBuckets {
+		return nil, fmt.Errorf("the records span more than %d buckets of %v; use a wider bucket", maxBuckets, width)
+	}
+
+	var buckets []Bucket
+	for start := first; !start.After(last); start = start.Add(width) {
+		buckets = append(buckets, Bucket{Start: start.In(location), Count: counts[start.UnixNano()]})
+	}
+	return buckets, nil
+}
+
+// bucketStart returns the start of the interval t falls in, counting
+// intervals from midnight UTC shifted by the zone offset of t, so that
+// hourly and daily buckets start on the local hour and day.
+func bucketStart(t time.Time, width time.Duration) time.Time {
+	_, offset := t.Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(width).Add(-shift)
+}
@@ -0,0 +1,235 @@
+package logparser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeFormat is a timestamp layout that can be found inside a line.
+type timeFormat struct {
+	// name describes the format, e.g. "rfc3339"
+	name string
+
+	// pattern finds the timestamp; its first group is the text to parse
+	pattern *regexp.Regexp
+
+	// parse converts the matched text into a time
+	parse func(text string, location *time.Location, now time.Time) (time.Time, error)
+}
+
+// layoutParser returns a parse function for Go time layouts, tried in order.
+func layoutParser(layouts ...string) func(string, *time.Location, time.Time) (time.Time, error) {
+	return func(text string, location *time.Location, now time.Time) (time.Time, error) {
+		var err error
+		for _, layout := range layouts {
+			var parsed time.Time
+			if parsed, err = time.ParseInLocation(layout, text, location); err == nil {
+				return parsed, nil
+			}
+		}
+		return time.Time{}, err
+	}
+}
+
+// offsetSpaceReplacer removes the space between a time and its offset.
+var offsetSpaceReplacer = strings.NewReplacer(" +", "+", " -", "-")
+
+// defaultTimeFormats are the timestamp formats recognised without
+// configuration.
+var defaultTimeFormats = []timeFormat{
+	{
+		// 2024-05-01T14:02:03.123Z, 2024-05-01 14:02:03,123 +0200
+		name:    "rfc3339",
+		pattern: regexp.MustCompile(`(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z| ?[+-]\d{2}:?\d{2})?)`),
+		parse: func(text string, location *time.Location, now time.Time) (time.Time, error) {
+			// The offset may follow the time after a space; drop that space
+			// before the one between the date and the time becomes a T
+			text = offsetSpaceReplacer.Replace(strings.Replace(text, ",", ".", 1))
+			text = strings.Replace(text, " ", "T", 1)
+			return layoutParser("2006-01-02T15:04:05.999999999Z07:00", "2006-01-02T15:04:05.999999999Z0700", "2006-01-02T15:04:05.999999999")(text, location, now)
+		},
+	},
+	{
+		// 2024/05/01 14:02:03 (Go's log package)
+		name:    "slash",
+		pattern: regexp.MustCompile(`(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?)`),
+		parse:   layoutParser("2006/01/02 15:04:05.999999999"),
+	},
+	{
+		// [01/May/2024:14:02:03 +0200] (Apache and Nginx access logs)
+		name:    "apache",
+		pattern: regexp.MustCompile(`\[(\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4})\]`),
+		parse:   layoutParser("02/Jan/2006:15:04:05 -0700"),
+	},
+	{
+		// May  1 14:02:03 (syslog, without a year)
+		name:    "syslog",
+		pattern: regexp.MustCompile(`((?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) [ \d]\d \d{2}:\d{2}:\d{2})`),
+		parse:   parseSyslogTime,
+	},
+	{
+		// 1714572123456 or 1714572123.456 at the start of the line or as
+		// the value of a time field (ts=, "time":, ...)
+		name:    "epoch",
+		pattern: regexp.MustCompile(`(?:^|(?:\b(?:ts|time|timestamp|t)"?\s*[=:]\s*"?))(\d{10}(?:\d{3})?(?:\.\d+)?)(?:[\s",}]|$)`),
+		parse: func(text string, location *time.Location, now time.Time) (time.Time, error) {
+			seconds, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			if integer, _, _ := strings.Cut(text, "."); len(integer) == 13 {
+				seconds /= 1000
+			}
+			return time.Unix(0, int64(seconds*float64(time.Second))).In(location), nil
+		},
+	},
+}
+
+// parseSyslogTime parses a syslog timestamp such as "May  1 14:02:03",
+// which has no year.
+func parseSyslogTime(text string, location *time.Location, now time.Time) (time.Time, error) {
+	parsed, err := time.ParseInLocation("Jan _2 15:04:05", text, location)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// Assume the most recent year in which the date is not in the future
+	parsed = parsed.AddDate(now.Year(), 0, 0)
+	if parsed.After(now.Add(24 * time.Hour)) {
+		parsed = parsed.AddDate(-1, 0, 0)
+	}
+	return parsed, nil
+}
+
+// TimestampParser finds the timestamp of a log line.
+type TimestampParser struct {
+	formats  []timeFormat
+	location *time.Location
+	now      time.Time
+}
+
+// NewTimestampParser creates a parser for the common formats: RFC 3339 and
+// ISO 8601, Go's log package, Apache, syslog and epoch seconds or
+// milliseconds. Timestamps without a time zone are read in location.
+func NewTimestampParser(location *time.Location) *TimestampParser {
+	return &TimestampParser{formats: defaultTimeFormats, location: location, now: time.Now()}
+}
+
+// NewLayoutTimestampParser creates a parser for a custom Go time layout
+// such as "02.01.2006 15:04:05".
+func NewLayoutTimestampParser(layout string, location *time.Location) (*TimestampParser, error) {
+	pattern, err := layoutPattern(layout)
+	if err != nil {
+		return nil, err
+	}
+	format := timeFormat{name: "layout", pattern: pattern, parse: layoutParser(layout)}
+	return &TimestampParser{formats: []timeFormat{format}, location: location, now: time.Now()}, nil
+}
+
+// SetNow sets the time used to complete timestamps without a year.
+func (p *TimestampParser) SetNow(now time.Time) {
+	p.now = now
+}
+
+// ParseTimestamp returns the first timestamp in the line.
+func (p *TimestampParser) ParseTimestamp(line string) (time.Time, bool) {
+	best := -1
+	var result time.Time
+	for _, format := range p.formats {
+		match := format.pattern.FindStringSubmatchIndex(line)
+		if match == nil || (best >= 0 && match[2] >= best) {
+			continue
+		}
+		parsed, err := format.parse(line[match[2]:match[3]], p.location, p.now)
+		if err != nil {
+			continue
+		}
+		best, result = match[2], parsed
+	}
+	return result, best >= 0
+}
+
+// layoutElements maps the elements of Go time layouts to patterns, longest
+// first so that "2006" is not read as "2" and "006".
+var layoutElements = []struct{ element, pattern string }{
+	{"January", `[A-Z][a-z]+`}, {"Monday", `[A-Z][a-z]+`},
+	{"Z07:00", `(?:Z|[+-]\d{2}:\d{2})`}, {"-07:00", `[+-]\d{2}:\d{2}`},
+	{"Z0700", `(?:Z|[+-]\d{4})`}, {"-0700", `[+-]\d{4}`},
+	{"2006", `\d{4}`}, {"Jan", `[A-Z][a-z]{2}`}, {"Mon", `[A-Z][a-z]{2}`},
+	{"MST", `[A-Z]{3,5}`}, {"-07", `[+-]\d{2}`},
+	{"01", `\d{2}`}, {"02", `\d{2}`}, {"_2", `[ \d]\d`}, {"15", `\d{2}`},
+	{"03", `\d{2}`}, {"04", `\d{2}`}, {"05", `\d{2}`}, {"06", `\d{2}`},
+	{"PM", `[AP]M`}, {"pm", `[ap]m`},
+	{"1", `\d{1,2}`}, {"2", `\d{1,2}`}, {"3", `\d{1,2}`}, {"4", `\d{1,2}`}, {"5", `\d{1,2}`},
+}
+
+// fractionPattern matches the fractional seconds elements of a layout.
+var fractionPattern = regexp.MustCompile(`^[.,](0+|9+)`)
+
+// layoutPattern converts a Go time layout into a pattern that finds
+// timestamps written in it.
+func layoutPattern(layout string) (*regexp.Regexp, error) {
+	if layout == "" {
+		return nil, fmt.Errorf("empty time layout")
+	}
+
+	var pattern strings.Builder
+	elements := 0
+	for rest := layout; rest != ""; {
+		if match := fractionPattern.FindString(rest); match != "" {
+			if match[1] == '9' {
+				pattern.WriteString(`(?:[.,]\d+)?`)
+			} else {
+				fmt.Fprintf(&pattern, `[.,]\d{%d}`, len(match)-1)
+			}
+			rest = rest[len(match):]
+			continue
+		}
+
+		found := false
+		for _, e := range layoutElements {
+			if strings.HasPrefix(rest, e.element) {
+				pattern.WriteString(e.pattern)
+				rest = rest[len(e.element):]
+				found, elements = true, elements+1
+				break
+			}
+		}
+		if !found {
+			pattern.WriteString(regexp.QuoteMeta(rest[:1]))
+			rest = rest[1:]
+		}
+	}
+	if elements == 0 {
+		return nil, fmt.Errorf("time layout '%s' has no date or time elements (use Go's reference time, e.g. 2006-01-02 15:04:05)", layout)
+	}
+	return regexp.MustCompile("(" + pattern.String() + ")"), nil
+}
+
+// ParseTimeBound parses the value of --since or --until: a duration
+// before now ("-15m", "2h"), a time of day today ("14:02", "14:02:30"),
+// or a date and time ("2024-05-01 14:02", RFC 3339).
+func ParseTimeBound(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if duration, err := time.ParseDuration(strings.TrimPrefix(value, "-")); err == nil {
+		return now.Add(-duration), nil
+	}
+
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if clock, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			year, month, day := now.Date()
+			return time.Date(year, month, day, clock.Hour(), clock.Minute(), clock.Second(), 0, now.Location()), nil
+		}
+	}
+
+	layouts := []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+	for _, layout := range layouts {
+		if parsed, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s' (use e.g. -15m, 14:02 or 2024-05-01T14:02:00Z)", value)
+}
@@ -0,0 +1,116 @@
+package logparser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kcansari/optix/internal/jsonutil"
+)
+
+// conditionOperators are the comparisons a condition may use. Longer
+// operators come first so that ">=" is not read as ">".
+var conditionOperators = []string{"!~", "!=", ">=", "<=", "==", "~", "=", ">", "<"}
+
+// Condition compares one field of a record with a value, such as
+// status>=500 or path~^/api/.
+type Condition struct {
+	Field    string
+	Operator string
+	Value    string
+
+	// pattern is the compiled value of ~ and !~ conditions
+	pattern *regexp.Regexp
+}
+
+// ParseCondition parses a FIELD OPERATOR VALUE expression. The operators
+// are = (or ==), !=, >, >=, <, <=, ~ (matches a regular expression) and
+// !~ (does not match).
+func ParseCondition(expression string) (*Condition, error) {
+	position, operator := -1, ""
+	for _, candidate := range conditionOperators {
+		if i := strings.Index(expression, candidate); i >= 0 && (position < 0 || i < position) {
+			position, operator = i, candidate
+		}
+	}
+	if position < 0 {
+		return nil, fmt.Errorf("invalid condition '%s': expected FIELD OPERATOR VALUE, e.g. status>=500", expression)
+	}
+
+	condition := &Condition{
+		Field:    strings.TrimSpace(expression[:position]),
+		Operator: operator,
+		Value:    strings.TrimSpace(expression[position+len(operator):]),
+	}
+	if condition.Field == "" {
+		return nil, fmt.Errorf("invalid condition '%s': missing field name", expression)
+	}
+	if operator == "==" {
+		condition.Operator = "="
+	}
+	if operator == "~" || operator == "!~" {
+		pattern, err := regexp.Compile(condition.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern in condition '%s': %w", expression, err)
+		}
+		condition.pattern = pattern
+	}
+	return condition, nil
+}
+
+// Match reports whether the fields satisfy the condition. Values that are
+// both numbers are compared as numbers, others as text. A missing field
+// only satisfies != and !~.
+func (c *Condition) Match(fields *jsonutil.Object) bool {
+	value, ok := fields.Get(c.Field)
+	if !ok {
+		return c.Operator == "!=" || c.Operator == "!~"
+	}
+	text := jsonutil.Text(value)
+
+	switch c.Operator {
+	case "~":
+		return c.pattern.MatchString(text)
+	case "!~":
+		return !c.pattern.MatchString(text)
+	}
+
+	comparison := compareValues(text, c.Value)
+	switch c.Operator {
+	case "=":
+		return comparison == 0
+	case "!=":
+		return comparison != 0
+	case ">":
+		return comparison > 0
+	case ">=":
+		return comparison >= 0
+	case "<":
+		return comparison < 0
+	default:
+		return comparison <= 0
+	}
+}
+
+// String returns the condition as it would be written.
+func (c *Condition) String() string {
+	return c.Field + c.Operator + c.Value
+}
+
+// compareValues compares two values numerically when both are numbers
+// and as text otherwise.
+func compareValues(a, b string) int {
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	if errX == nil && errY == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,51 @@
+package patterns
+
+import "sort"
+
+// Frequency is how often a value was found.
+type Frequency struct {
+	Kind *Kind
+	Text string
+
+	// Count is the number of times the value was found
+	Count int
+
+	// FirstLine is the line number where the value was first found
+	FirstLine int
+}
+
+// Counter counts the distinct values found, per kind.
+type Counter struct {
+	index       map[string]*Frequency
+	frequencies []*Frequency
+}
+
+// NewCounter creates a counter without values.
+func NewCounter() *Counter {
+	return &Counter{index: make(map[string]*Frequency)}
+}
+
+// Add counts a match found on a line and reports whether its value was
+// seen for the first time.
+func (c *Counter) Add(lineNumber int, match Match) bool {
+	key := match.Kind.Name + "\x00" + match.Text
+	if frequency, ok := c.index[key]; ok {
+		frequency.Count++
+		return false
+	}
+	frequency := &Frequency{Kind: match.Kind, Text: match.Text, Count: 1, FirstLine: lineNumber}
+	c.index[key] = frequency
+	c.frequencies = append(c.frequencies, frequency)
+	return true
+}
+
+// Frequencies returns the values, the most frequent first; values found
+// as often keep the order they were first found in.
+func (c *Counter) Frequencies() []*Frequency {
+	frequencies := make([]*Frequency, len(c.frequencies))
+	copy(frequencies, c.frequencies)
+	sort.SliceStable(frequencies, func(i, j int) bool {
+		return frequencies[i].Count > frequencies[j].Count
+	})
+	return frequencies
+}
@@ -0,0 +1,344 @@
+// Package patterns recognizes common kinds of values in text, such as
+// email addresses, URLs, IP addresses, phone numbers, card numbers, UUIDs,
+// dates and numbers. A kind is
+// a regular expression, optionally with a check that rejects lookalikes,
+// such as the Luhn checksum of card numbers.
+package patterns
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// PersonalKinds are the names of the built-in kinds that hold personal
+// data, redacted by default.
+var PersonalKinds = []string{"email", "card", "ipv4", "ipv6", "phone"}
+
+// Kind is a kind of value that can be found in text.
+type Kind struct {
+	// Name identifies the kind, e.g. "email"
+	Name string
+
+	// Description says what the kind matches
+	Description string
+
+	// Pattern finds candidates. When it has a group named "value", only
+	// that group is the value, so that the pattern can require the
+	// characters around it.
+	Pattern *regexp.Regexp
+
+	// Valid, when set, rejects candidates that only look like the kind
+	Valid func(value string) bool
+}
+
+// Match is a value found in a line.
+type Match struct {
+	Kind *Kind
+
+	// Start and End are the byte offsets of the value in the line
+	Start int
+	End   int
+
+	// Text is the value
+	Text string
+}
+
+// NewKind creates a kind from a regular expression.
+func NewKind(name, description, pattern string) (*Kind, error) {
+	if name == "" {
+		return nil, fmt.Errorf("kind name cannot be empty")
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("kind '%s': invalid pattern: %w", name, err)
+	}
+	if description == "" {
+		description = name
+	}
+	return &Kind{Name: name, Description: description, Pattern: compiled}, nil
+}
+
+// FindAll returns the values of the kind in a line, in order.
+func (k *Kind) FindAll(line string) []Match {
+	valueGroup := k.Pattern.SubexpIndex("value")
+
+	var matches []Match
+	for _, found := range k.Pattern.FindAllStringSubmatchIndex(line, -1) {
+		start, end := found[0], found[1]
+		if valueGroup > 0 {
+			if found[2*valueGroup] < 0 {
+				continue
+			}
+			start, end = found[2*valueGroup], found[2*valueGroup+1]
+		}
+		if start == end {
+			continue
+		}
+		text := line[start:end]
+		if k.Valid != nil && !k.Valid(text) {
+			continue
+		}
+		matches = append(matches, Match{Kind: k, Start: start, End: end, Text: text})
+	}
+	return matches
+}
+
+// Registry holds the known kinds.
+type Registry struct {
+	kinds []*Kind
+}
+
+// NewRegistry creates a registry without kinds.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// NewDefaultRegistry creates a registry with the built-in kinds.
+func NewDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	for _, kind := range builtinKinds() {
+		registry.Add(kind)
+	}
+	return registry
+}
+
+// Add adds a kind, replacing a kind with the same name.
+func (r *Registry) Add(kind *Kind) {
+	for i, existing := range r.kinds {
+		if existing.Name == kind.Name {
+			r.kinds[i] = kind
+			return
+		}
+	}
+	r.kinds = append(r.kinds, kind)
+}
+
+// Get returns the kind with a name, or nil.
+func (r *Registry) Get(name string) *Kind {
+	for _, kind := range r.kinds {
+		if strings.EqualFold(kind.Name, name) {
+			return kind
+		}
+	}
+	return nil
+}
+
+// Kinds returns the kinds in the order they were added.
+func (r *Registry) Kinds() []*Kind {
+	return r.kinds
+}
+
+// Names returns the names of the kinds, sorted.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.kinds))
+	for _, kind := range r.kinds {
+		names = append(names, kind.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select returns the kinds with the given names, in the order of the
+// registry, or all kinds when no names are given.
+func (r *Registry) Select(names []string) ([]*Kind, error) {
+	if len(names) == 0 {
+		return r.kinds, nil
+	}
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		kind := r.Get(strings.TrimSpace(name))
+		if kind == nil {
+			return nil, fmt.Errorf("unknown kind '%s'. Available kinds: %s", name, strings.Join(r.Names(), ", "))
+		}
+		wanted[kind.Name] = true
+	}
+
+	var selected []*Kind
+	for _, kind := range r.kinds {
+		if wanted[kind.Name] {
+			selected = append(selected, kind)
+		}
+	}
+	return selected, nil
+}
+
+// FindAll returns the values of all kinds in a line, ordered by position.
+// Where values overlap, the kind that comes first wins, so a card number
+// is not also reported as a phone number.
+func FindAll(kinds []*Kind, line string) []Match {
+	var matches []Match
+	for _, kind := range kinds {
+		for _, match := range kind.FindAll(line) {
+			if !overlaps(matches, match) {
+				matches = append(matches, match)
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+	return matches
+}
+
+// overlaps reports whether a match overlaps one of the others.
+func overlaps(matches []Match, match Match) bool {
+	for _, other := range matches {
+		if match.Start < other.End && other.Start < match.End {
+			return true
+		}
+	}
+	return false
+}
+
+// builtinKinds returns the built-in kinds. Kinds with more structure come
+// first, since FindAll gives them precedence on overlaps.
+func builtinKinds() []*Kind {
+	return []*Kind{
+		{
+			Name:        "url",
+			Description: "URL with an http, https or ftp scheme",
+			Pattern:     regexp.MustCompile(`\b(?:[Hh][Tt][Tt][Pp][Ss]?|[Ff][Tt][Pp])://[^\s<>"'` + "`" + `]*[^\s<>"'` + "`" + `.,;:!?)\]}]`),
+		},
+		{
+			Name:        "email",
+			Description: "Email address",
+			Pattern:     regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`),
+		},
+		{
+			Name:        "uuid",
+			Description: "UUID",
+			Pattern:     regexp.MustCompile(`\b[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}\b`),
+		},
+		{
+			Name:        "card",
+			Description: "Payment card number (Luhn checked)",
+			Pattern:     regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+			Valid:       validCard,
+		},
+		{
+			Name:        "date",
+			Description: "Date, optionally with a time: 2024-05-01, 2024-05-01T12:00:00Z, 01/05/2024",
+			Pattern:     regexp.MustCompile(`\b(?:\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}(?::\d{2}(?:[.,]\d+)?)?(?:Z|[+-]\d{2}:?\d{2}\b)?)?|\d{1,2}/\d{1,2}/\d{4})\b`),
+			Valid:       validDate,
+		},
+		{
+			Name:        "ipv4",
+			Description: "IPv4 address",
+			Pattern:     regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`),
+		},
+		{
+			Name:        "ipv6",
+			Description: "IPv6 address",
+			Pattern:     regexp.MustCompile(`(?:^|[^0-9A-Za-z:.])(?P<value>[0-9A-Fa-f]{0,4}(?::[0-9A-Fa-f]{0,4}){2,7})(?:$|[^0-9A-Za-z:.])`),
+			Valid:       validIPv6,
+		},
+		{
+			Name:        "phone",
+			Description: "Phone number",
+			Pattern:     regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{1,4}\)[ .-]?|\b\d{1,4}[ .-])(?:\d{2,4}[ .-]){0,3}\d{3,4}\b`),
+			Valid:       validPhone,
+		},
+		{
+			Name:        "number",
+			Description: "Integer or decimal number, optionally signed",
+			Pattern:     regexp.MustCompile(`(?:^|[^\w.-])(?P<value>[-+]?\d+(?:\.\d+)*(?:[eE][-+]?\d+)?)\b`),
+			Valid:       validNumber,
+		},
+	}
+}
+
+// digits returns the digits of text.
+func digits(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, text)
+}
+
+// validCard reports whether text has the length and checksum of a card.
+func validCard(text string) bool {
+	number := digits(text)
+	return len(number) >= 13 && len(number) <= 19 && Luhn(number)
+}
+
+// validDate reports whether text has a month and day in range. In
+// slashed dates either of the first two numbers may be the month.
+func validDate(text string) bool {
+	var first, second int
+	if strings.Contains(text, "/") {
+		fmt.Sscanf(text, "%d/%d", &first, &second)
+		return first >= 1 && second >= 1 && first <= 31 && second <= 31 && (first <= 12 || second <= 12)
+	}
+	var year int
+	fmt.Sscanf(text, "%d-%d-%d", &year, &first, &second)
+	return first >= 1 && first <= 12 && second >= 1 && second <= 31
+}
+
+// validNumber rejects dotted sequences such as versions, which are not
+// one number.
+func validNumber(text string) bool {
+	return strings.Count(text, ".") <= 1
+}
+
+// validIPv6 reports whether text is an IPv6 address with at least three
+// groups of digits, or two when one is a full group of four as in fe80::1,
+// so that "::", "a::" and "a::b" in code are not matched.
+func validIPv6(text string) bool {
+	address, err := netip.ParseAddr(text)
+	if err != nil || !address.Is6() {
+		return false
+	}
+	groups, full := 0, false
+	for _, group := range strings.Split(text, ":") {
+		if group != "" {
+			groups++
+		}
+		if len(group) == 4 {
+			full = true
+		}
+	}
+	return groups >= 3 || groups == 2 && full
+}
+
+// validPhone reports whether text looks like a phone number rather than
+// another number with separators: 7 to 15 digits, written with a country
+// code, an area code in parentheses or at least two separators.
+func validPhone(text string) bool {
+	count := len(digits(text))
+	if count < 7 || count > 15 {
+		return false
+	}
+	separators := strings.Count(text, " ") + strings.Count(text, "-") + strings.Count(text, ".")
+	return strings.HasPrefix(text, "+") || strings.Contains(text, "(") || separators >= 2
+}
+
+// Luhn reports whether a string of digits passes the Luhn checksum used by
+// card numbers.
+func Luhn(number string) bool {
+	if number == "" {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if digit < 0 || digit > 9 {
+			return false
+		}
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
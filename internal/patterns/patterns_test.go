@@ -0,0 +1,256 @@
+package patterns_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kcansari/optix/internal/patterns"
+)
+
+// TestPersonalKinds tests the kinds of personal data on values and lookalikes
+func TestPersonalKinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		kind     string // expected kind, or "" for no match
+		expected string
+	}{
+		{"email", "contact John.Doe+ops@mail.example.co.uk today", "email", "John.Doe+ops@mail.example.co.uk"},
+		{"card with spaces", "paid with 4111 1111 1111 1111.", "card", "4111 1111 1111 1111"},
+		{"card with dashes", "card=5500-0000-0000-0004", "card", "5500-0000-0000-0004"},
+		{"card failing luhn", "order 4111111111111112", "", ""},
+		{"ipv4", "from 192.168.1.20:8080", "ipv4", "192.168.1.20"},
+		{"invalid ipv4", "version 300.1.2.3", "", ""},
+		{"ipv6", "client [2001:db8:85a3::8a2e:370:7334]:443", "ipv6", "2001:db8:85a3::8a2e:370:7334"},
+		{"short ipv6", "via fe80::1 on eth0", "ipv6", "fe80::1"},
+		{"scope in code", "std::vector<int> a::b", "", ""},
+		{"time is not ipv6", "at 12:30:45", "", ""},
+		{"international phone", "call +44 20 7946 0958", "phone", "+44 20 7946 0958"},
+		{"phone with area code", "tel (555) 456-7890", "phone", "(555) 456-7890"},
+		{"date is not a phone", "on 2024-05-01", "", ""},
+		{"plain number", "processed 1234567 rows", "", ""},
+	}
+
+	kinds, err := patterns.NewDefaultRegistry().Select(patterns.PersonalKinds)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matches := patterns.FindAll(kinds, test.line)
+			if test.kind == "" {
+				if len(matches) != 0 {
+					t.Errorf("Expected no match, got %s %q", matches[0].Kind.Name, matches[0].Text)
+				}
+				return
+			}
+			if len(matches) != 1 {
+				t.Fatalf("Expected 1 match, got %d", len(matches))
+			}
+			if matches[0].Kind.Name != test.kind || matches[0].Text != test.expected {
+				t.Errorf("Expected %s %q, got %s %q", test.kind, test.expected, matches[0].Kind.Name, matches[0].Text)
+			}
+			if test.line[matches[0].Start:matches[0].End] != matches[0].Text {
+				t.Errorf("Offsets %d-%d do not match %q", matches[0].Start, matches[0].End, matches[0].Text)
+			}
+		})
+	}
+}
+
+// TestExtractionKinds tests the other built-in kinds, each on its own
+func TestExtractionKinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     string
+		line     string
+		expected []string
+	}{
+		{"url", "url", "see https://example.com/a?b=1, or (ftp://files.example.org/x).", []string{"https://example.com/a?b=1", "ftp://files.example.org/x"}},
+		{"no scheme", "url", "visit example.com or mailto:a@b.io", nil},
+		{"uuid", "uuid", "id=3F2504E0-4F89-11D3-9A0C-0305E82C3301 next", []string{"3F2504E0-4F89-11D3-9A0C-0305E82C3301"}},
+		{"dates", "date", "2024-05-01 and 2024-05-01T12:00:03.120+02:00 or 31/12/2024", []string{"2024-05-01", "2024-05-01T12:00:03.120+02:00", "31/12/2024"}},
+		{"invalid dates", "date", "2024-13-01 and 40/40/2024", nil},
+		{"numbers", "number", "took 12.5 ms, retries=-1, 1e6 ops", []string{"12.5", "-1", "1e6"}},
+		{"not numbers", "number", "version 1.2.3 on host1, id x-9", nil},
+	}
+
+	registry := patterns.NewDefaultRegistry()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var values []string
+			for _, match := range registry.Get(test.kind).FindAll(test.line) {
+				values = append(values, match.Text)
+			}
+			if !reflect.DeepEqual(values, test.expected) {
+				t.Errorf("Expected %q, got %q", test.expected, values)
+			}
+		})
+	}
+}
+
+// TestCounter tests counting distinct values
+func TestCounter(t *testing.T) {
+	registry := patterns.NewDefaultRegistry()
+	email := registry.Get("email")
+	lines := []string{"a@x.io b@x.io", "b@x.io", "c@x.io b@x.io a@x.io"}
+
+	counter := patterns.NewCounter()
+	var firsts []string
+	for i, line := range lines {
+		for _, match := range email.FindAll(line) {
+			if counter.Add(i+1, match) {
+				firsts = append(firsts, match.Text)
+			}
+		}
+	}
+	if !reflect.DeepEqual(firsts, []string{"a@x.io", "b@x.io", "c@x.io"}) {
+		t.Errorf("Unexpected first occurrences %v", firsts)
+	}
+
+	var counted []string
+	for _, frequency := range counter.Frequencies() {
+		counted = append(counted, fmt.Sprintf("%s=%d@%d", frequency.Text, frequency.Count, frequency.FirstLine))
+	}
+	if expected := []string{"b@x.io=3@1", "a@x.io=2@1", "c@x.io=1@3"}; !reflect.DeepEqual(counted, expected) {
+		t.Errorf("Expected %v, got %v", expected, counted)
+	}
+}
+
+// TestLuhn tests the Luhn checksum
+func TestLuhn(t *testing.T) {
+	tests := []struct {
+		number string
+		valid  bool
+	}{
+		{"4111111111111111", true},
+		{"79927398713", true},
+		{"79927398710", false},
+		{"0", true},
+		{"", false},
+		{"4111-1111", false},
+	}
+
+	for _, test := range tests {
+		if valid := patterns.Luhn(test.number); valid != test.valid {
+			t.Errorf("Luhn(%q) = %t, expected %t", test.number, valid, test.valid)
+		}
+	}
+}
+
+// TestRegistrySelect tests selecting kinds by name
+func TestRegistrySelect(t *testing.T) {
+	registry := patterns.NewDefaultRegistry()
+
+	kinds, err := registry.Select([]string{"PHONE", " email"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(kinds) != 2 || kinds[0].Name != "email" || kinds[1].Name != "phone" {
+		t.Errorf("Expected email and phone in registry order, got %d kinds", len(kinds))
+	}
+
+	all, err := registry.Select(nil)
+	if err != nil || len(all) != len(registry.Kinds()) {
+		t.Errorf("Expected all kinds without names, got %d (%v)", len(all), err)
+	}
+
+	if _, err := registry.Select([]string{"ssn"}); err == nil {
+		t.Errorf("Expected error for unknown kind")
+	}
+
+	custom, err := patterns.NewKind("ticket", "", `\bTCK-\d+\b`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	registry.Add(custom)
+	if registry.Get("ticket") != custom || custom.Description != "ticket" {
+		t.Errorf("Expected the custom kind to be registered")
+	}
+	if _, err := patterns.NewKind("bad", "", "("); err == nil {
+		t.Errorf("Expected error for invalid pattern")
+	}
+}
+
+// TestFindAllOverlaps tests that the first kind wins on overlapping values
+func TestFindAllOverlaps(t *testing.T) {
+	kinds := patterns.NewDefaultRegistry().Kinds()
+	line := "ip 10.0.0.1 card 4111 1111 1111 1111 mail a@b.io"
+
+	matches := patterns.FindAll(kinds, line)
+	expected := []string{"ipv4", "card", "email"}
+	if len(matches) != len(expected) {
+		t.Fatalf("Expected %d matches, got %d", len(expected), len(matches))
+	}
+	for i, match := range matches {
+		if match.Kind.Name != expected[i] {
+			t.Errorf("Match %d: expected %s, got %s %q", i, expected[i], match.Kind.Name, match.Text)
+		}
+	}
+}
+
+// TestRedactor tests the redaction modes
+func TestRedactor(t *testing.T) {
+	kinds := patterns.NewDefaultRegistry().Kinds()
+	line := "user Jane@Example.com from 10.1.2.3 paid with 4111 1111 1111 1111"
+
+	t.Run("mask", func(t *testing.T) {
+		redactor, err := patterns.NewRedactor(kinds, patterns.ModeMask, "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := "user ****@*******.*** from **.*.*.* paid with **** **** **** 1111"
+		if redacted := redactor.Redact(line); redacted != expected {
+			t.Errorf("Expected %q, got %q", expected, redacted)
+		}
+		if redactor.Counts["email"] != 1 || redactor.Counts["ipv4"] != 1 || redactor.Counts["card"] != 1 {
+			t.Errorf("Unexpected counts: %v", redactor.Counts)
+		}
+	})
+
+	t.Run("hash is consistent per key", func(t *testing.T) {
+		first, _ := patterns.NewRedactor(kinds, patterns.ModeHash, "key-1")
+		second, _ := patterns.NewRedactor(kinds, patterns.ModeHash, "key-1")
+		other, _ := patterns.NewRedactor(kinds, patterns.ModeHash, "key-2")
+
+		a := first.Redact("jane@example.com")
+		if !strings.HasPrefix(a, "[email:") || strings.Contains(a, "jane") {
+			t.Errorf("Expected an email token, got %q", a)
+		}
+		// Case does not matter for addresses, and the token is the same in another run
+		if b := second.Redact("JANE@example.com"); b != a {
+			t.Errorf("Expected %q for the same address, got %q", a, b)
+		}
+		if c := other.Redact("jane@example.com"); c == a {
+			t.Errorf("Expected a different token with another key, got %q", c)
+		}
+		if d := first.Redact("john@example.com"); d == a {
+			t.Errorf("Expected a different token for another address, got %q", d)
+		}
+	})
+
+	t.Run("fake", func(t *testing.T) {
+		redactor, _ := patterns.NewRedactor(kinds, patterns.ModeFake, "key-1")
+		redacted := redactor.Redact(line)
+		if redacted != redactor.Redact(line) {
+			t.Errorf("Expected the same fake values for the same line")
+		}
+
+		matches := patterns.FindAll(kinds, redacted)
+		if len(matches) != 3 {
+			t.Fatalf("Expected fake values of 3 kinds in %q, got %d", redacted, len(matches))
+		}
+		if !strings.HasSuffix(matches[0].Text, "@example.com") || !strings.HasPrefix(matches[1].Text, "198.") ||
+			!strings.HasPrefix(matches[2].Text, "4000 0000 0") {
+			t.Errorf("Unexpected fake values in %q", redacted)
+		}
+	})
+
+	if _, err := patterns.NewRedactor(kinds, patterns.ModeHash, ""); err == nil {
+		t.Errorf("Expected error for hash mode without key")
+	}
+	if _, err := patterns.NewRedactor(kinds, "shuffle", "key"); err == nil {
+		t.Errorf("Expected error for unknown mode")
+	}
+}
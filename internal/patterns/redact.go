@@ -0,0 +1,184 @@
+package patterns
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"strings"
+	"unicode"
+)
+
+// Redaction modes.
+const (
+	// ModeMask hides the characters of a value but keeps its shape,
+	// e.g. ****@*******.***; card numbers keep their last four digits
+	ModeMask = "mask"
+
+	// ModeHash replaces a value with a token derived from a keyed HMAC,
+	// e.g. [email:3f9a0c1d]; the same value always gets the same token
+	ModeHash = "hash"
+
+	// ModeFake replaces a value with a made-up one of the same kind from
+	// a reserved range, e.g. user123456@example.com; like ModeHash, the
+	// same value always gets the same replacement
+	ModeFake = "fake"
+)
+
+// RedactModes returns the redaction modes.
+func RedactModes() []string {
+	return []string{ModeMask, ModeHash, ModeFake}
+}
+
+// Redactor replaces the values of some kinds in lines of text.
+type Redactor struct {
+	kinds []*Kind
+	mode  string
+	key   []byte
+
+	// Counts holds the number of values replaced per kind
+	Counts map[string]int
+}
+
+// NewRedactor creates a redactor for the kinds. The hash and fake modes
+// need a key; with the same key a value gets the same replacement in every
+// file and run.
+func NewRedactor(kinds []*Kind, mode, key string) (*Redactor, error) {
+	switch mode {
+	case ModeMask:
+	case ModeHash, ModeFake:
+		if key == "" {
+			return nil, fmt.Errorf("the %s mode needs a key", mode)
+		}
+	default:
+		return nil, fmt.Errorf("invalid redaction mode '%s'. Valid modes: %s", mode, strings.Join(RedactModes(), ", "))
+	}
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("no kinds of values to redact")
+	}
+	return &Redactor{kinds: kinds, mode: mode, key: []byte(key), Counts: make(map[string]int)}, nil
+}
+
+// Redact returns the line with the values replaced.
+func (r *Redactor) Redact(line string) string {
+	matches := FindAll(r.kinds, line)
+	if len(matches) == 0 {
+		return line
+	}
+
+	var redacted strings.Builder
+	last := 0
+	for _, match := range matches {
+		redacted.WriteString(line[last:match.Start])
+		redacted.WriteString(r.replacement(match))
+		last = match.End
+		r.Counts[match.Kind.Name]++
+	}
+	redacted.WriteString(line[last:])
+	return redacted.String()
+}
+
+// replacement returns the text that replaces a value.
+func (r *Redactor) replacement(match Match) string {
+	name := match.Kind.Name
+	if r.mode == ModeMask {
+		return mask(name, match.Text)
+	}
+
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(name + ":" + normalize(name, match.Text)))
+	sum := mac.Sum(nil)
+
+	if r.mode == ModeFake {
+		if fake := fakeValue(name, match.Text, sum); fake != "" {
+			return fake
+		}
+	}
+	return fmt.Sprintf("[%s:%s]", name, hex.EncodeToString(sum[:4]))
+}
+
+// normalize returns the form of a value that is hashed, so that the same
+// address or number written differently gets the same replacement.
+func normalize(kind, value string) string {
+	switch kind {
+	case "email":
+		return strings.ToLower(value)
+	case "card", "phone":
+		return digits(value)
+	case "ipv4", "ipv6":
+		if address, err := netip.ParseAddr(value); err == nil {
+			return address.String()
+		}
+	}
+	return value
+}
+
+// mask hides the letters and digits of a value. Card numbers keep their
+// last four digits, as on receipts.
+func mask(kind, value string) string {
+	keep := 0
+	if kind == "card" {
+		keep = 4
+	}
+
+	runes := []rune(value)
+	for i := len(runes) - 1; i >= 0; i-- {
+		if !unicode.IsLetter(runes[i]) && !unicode.IsDigit(runes[i]) {
+			continue
+		}
+		if keep > 0 && unicode.IsDigit(runes[i]) {
+			keep--
+			continue
+		}
+		runes[i] = '*'
+	}
+	return string(runes)
+}
+
+// fakeValue returns a made-up value of a built-in kind, chosen by the hash
+// of the original, or "" for other kinds. Values come from ranges reserved
+// for examples and testing, so they never belong to anyone.
+func fakeValue(kind, original string, sum []byte) string {
+	n := binary.BigEndian.Uint32(sum)
+	switch kind {
+	case "email":
+		return fmt.Sprintf("user%06d@example.com", n%1000000)
+	case "ipv4":
+		// 198.18.0.0/15 is reserved for benchmarking
+		return fmt.Sprintf("198.%d.%d.%d", 18+sum[4]&1, sum[5], sum[6])
+	case "ipv6":
+		// 2001:db8::/32 is reserved for documentation
+		return fmt.Sprintf("2001:db8::%x:%x", binary.BigEndian.Uint16(sum[4:]), binary.BigEndian.Uint16(sum[6:]))
+	case "phone":
+		// 555-0100 to 555-0199 are reserved for fiction
+		return fmt.Sprintf("+1-555-01%02d", n%100)
+	case "card":
+		return fakeCard(original, n)
+	}
+	return ""
+}
+
+// fakeCard returns a 16 digit test card number with a valid checksum,
+// grouped by four with the separator of the original, if any.
+func fakeCard(original string, n uint32) string {
+	number := fmt.Sprintf("400000000%06d", n%1000000)
+	for check := '0'; check <= '9'; check++ {
+		if Luhn(number + string(check)) {
+			number += string(check)
+			break
+		}
+	}
+
+	separator := ""
+	if strings.Contains(original, " ") {
+		separator = " "
+	} else if strings.Contains(original, "-") {
+		separator = "-"
+	}
+	if separator == "" {
+		return number
+	}
+	return strings.Join([]string{number[0:4], number[4:8], number[8:12], number[12:16]}, separator)
+}
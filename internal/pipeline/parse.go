@@ -0,0 +1,464 @@
+// Package pipeline chains text processors so that a file is read once,
+// passed through several operations in memory and written once.
+//
+// A pipeline is written as stages separated by "|":
+//
+//	filter [-v] [-c] [-o] [-r] PATTERN   keep matching lines; /PATTERN/ is a regex
+//	replace [-c] [-w] [-r] FIND REPLACE  replace text, like the replace command
+//	replace s/PATTERN/REPLACE/[gi]       replace a regex ($1 refers to a group)
+//	transform TYPE                       upper, lower, title, trim, eol-lf, eol-crlf
+//	dedupe                               drop repeated lines, keeping the first
+//
+// Words may be quoted with single or double quotes. A "|" inside quotes,
+// or written as "\|", does not end a stage.
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kcansari/optix/internal/processor"
+	"gopkg.in/yaml.v3"
+)
+
+// Operations lists the processors that can be used as stages.
+var Operations = []string{"filter", "replace", "transform", "dedupe"}
+
+// Stage is a single step of a pipeline.
+type Stage struct {
+	// Operation is the processor that runs the stage, e.g. "replace"
+	Operation string
+
+	// Options configures the processor
+	Options processor.ProcessOptions
+
+	// Source is the stage as written, for display
+	Source string
+}
+
+// Parse parses a pipeline such as "filter ERROR | transform lower".
+func Parse(spec string) ([]Stage, error) {
+	var stages []Stage
+	for i, text := range splitStages(spec) {
+		stage, err := ParseStage(text)
+		if err != nil {
+			return nil, fmt.Errorf("stage %d: %w", i+1, err)
+		}
+		stages = append(stages, stage)
+	}
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("the pipeline has no stages")
+	}
+	return stages, nil
+}
+
+// ParseStage parses a single stage such as "replace s/a/b/".
+func ParseStage(text string) (Stage, error) {
+	text = strings.TrimSpace(text)
+	words, err := splitWords(text)
+	if err != nil {
+		return Stage{}, err
+	}
+	if len(words) == 0 {
+		return Stage{}, fmt.Errorf("empty stage")
+	}
+
+	stage := Stage{Operation: strings.ToLower(words[0]), Source: text}
+	args, flags, err := parseFlags(words[1:])
+	if err != nil {
+		return Stage{}, fmt.Errorf("%s: %w", stage.Operation, err)
+	}
+
+	options := &stage.Options
+	switch stage.Operation {
+	case "filter":
+		if err := allowFlags(stage.Operation, flags, "v", "c", "o", "r"); err != nil {
+			return Stage{}, err
+		}
+		if len(args) == 0 {
+			return Stage{}, fmt.Errorf("filter: a pattern is required")
+		}
+		options.Pattern = strings.Join(args, " ")
+		options.RegexMode = flags["r"]
+		if pattern, ok := slashed(options.Pattern); ok {
+			options.Pattern = pattern
+			options.RegexMode = true
+		}
+		options.InvertMatch = flags["v"]
+		options.CaseSensitive = flags["c"]
+		options.OnlyMatching = flags["o"]
+
+	case "replace":
+		if err := allowFlags(stage.Operation, flags, "c", "w", "r"); err != nil {
+			return Stage{}, err
+		}
+		if len(args) == 1 && len(args[0]) > 1 && args[0][0] == 's' && !isWordChar(args[0][1]) {
+			if err := parseSubstitution(args[0], options); err != nil {
+				return Stage{}, err
+			}
+			break
+		}
+		if len(args) != 2 {
+			return Stage{}, fmt.Errorf("replace: expected FIND REPLACE or s/PATTERN/REPLACE/")
+		}
+		options.Pattern, options.ReplaceWith = args[0], args[1]
+		options.CaseSensitive = flags["c"]
+		options.WholeWord = flags["w"]
+		options.RegexMode = flags["r"]
+
+	case "transform":
+		if err := allowFlags(stage.Operation, flags); err != nil {
+			return Stage{}, err
+		}
+		if len(args) != 1 {
+			return Stage{}, fmt.Errorf("transform: expected a single type (upper, lower, title, trim, eol-lf, eol-crlf)")
+		}
+		options.TransformType = strings.ToLower(args[0])
+
+	case "dedupe":
+		if err := allowFlags(stage.Operation, flags); err != nil {
+			return Stage{}, err
+		}
+		if len(args) != 0 {
+			return Stage{}, fmt.Errorf("dedupe: takes no arguments")
+		}
+
+	default:
+		return Stage{}, fmt.Errorf("unknown operation '%s'. Valid operations: %s", words[0], strings.Join(Operations, ", "))
+	}
+
+	return stage, nil
+}
+
+// parseSubstitution parses a sed-style s/PATTERN/REPLACE/FLAGS expression.
+// The pattern is a regular expression and is case-sensitive unless the i
+// flag is given; g is accepted for familiarity, as all matches are replaced.
+func parseSubstitution(expression string, options *processor.ProcessOptions) error {
+	delimiter := expression[1]
+	parts := splitUnescaped(expression[2:], delimiter)
+	if len(parts) != 3 {
+		return fmt.Errorf("replace: malformed substitution '%s' (expected s%cPATTERN%cREPLACE%c)", expression, delimiter, delimiter, delimiter)
+	}
+	if parts[0] == "" {
+		return fmt.Errorf("replace: the pattern of '%s' is empty", expression)
+	}
+
+	options.Pattern, options.ReplaceWith = parts[0], parts[1]
+	options.RegexMode = true
+	options.CaseSensitive = true
+	for _, flag := range parts[2] {
+		switch flag {
+		case 'g':
+		case 'i':
+			options.CaseSensitive = false
+		default:
+			return fmt.Errorf("replace: unknown substitution flag '%c' (valid flags: g, i)", flag)
+		}
+	}
+	return nil
+}
+
+// splitUnescaped splits text at delimiter, except where it is escaped with
+// a backslash; the escaping backslash is removed.
+func splitUnescaped(text string, delimiter byte) []string {
+	var parts []string
+	var current strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '\\' && i+1 < len(text) && text[i+1] == delimiter:
+			current.WriteByte(delimiter)
+			i++
+		case text[i] == delimiter:
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(text[i])
+		}
+	}
+	return append(parts, current.String())
+}
+
+// slashed returns the pattern between slashes of "/pattern/".
+func slashed(text string) (string, bool) {
+	if len(text) > 2 && text[0] == '/' && text[len(text)-1] == '/' {
+		return text[1 : len(text)-1], true
+	}
+	return text, false
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// longFlags maps long flag names to their short form.
+var longFlags = map[string]string{
+	"invert":         "v",
+	"case-sensitive": "c",
+	"only-matching":  "o",
+	"regex":          "r",
+	"whole-word":     "w",
+}
+
+// parseFlags separates leading flags (-v, --invert, combined -vc) from the
+// arguments. "--" ends the flags, so patterns may start with a dash.
+func parseFlags(words []string) ([]string, map[string]bool, error) {
+	flags := make(map[string]bool)
+	for i, word := range words {
+		switch {
+		case word == "--":
+			return words[i+1:], flags, nil
+		case strings.HasPrefix(word, "--"):
+			short, ok := longFlags[word[2:]]
+			if !ok {
+				return nil, nil, fmt.Errorf("unknown flag '%s'", word)
+			}
+			flags[short] = true
+		case len(word) > 1 && word[0] == '-':
+			for _, flag := range word[1:] {
+				flags[string(flag)] = true
+			}
+		default:
+			return words[i:], flags, nil
+		}
+	}
+	return nil, flags, nil
+}
+
+// allowFlags reports flags the operation does not support.
+func allowFlags(operation string, flags map[string]bool, allowed ...string) error {
+	for flag := range flags {
+		found := false
+		for _, name := range allowed {
+			found = found || flag == name
+		}
+		if !found {
+			return fmt.Errorf("%s: unknown flag '-%s'", operation, flag)
+		}
+	}
+	return nil
+}
+
+// splitStages splits a pipeline at the "|" characters outside quotes.
+func splitStages(spec string) []string {
+	var stages []string
+	var current strings.Builder
+	var quote rune
+
+	for _, r := range spec {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			current.WriteRune(r)
+		case r == '|' && strings.HasSuffix(current.String(), `\`):
+			// An escaped "|" belongs to the stage
+			text := current.String()
+			current.Reset()
+			current.WriteString(text[:len(text)-1])
+			current.WriteRune(r)
+		case r == '|':
+			stages = append(stages, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	stages = append(stages, current.String())
+
+	// Ignore empty stages around the separators, e.g. a trailing "|"
+	var nonEmpty []string
+	for _, stage := range stages {
+		if strings.TrimSpace(stage) != "" {
+			nonEmpty = append(nonEmpty, stage)
+		}
+	}
+	return nonEmpty
+}
+
+// splitWords splits a stage into words at whitespace outside quotes. Quotes
+// are removed; backslashes are kept so regular expressions need no double
+// escaping, except before a quote character.
+func splitWords(text string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	var quote rune
+	inWord := false
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes) && (runes[i+1] == '\'' || runes[i+1] == '"') && quote != '\'':
+			current.WriteRune(runes[i+1])
+			inWord = true
+			i++
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in '%s'", quote, text)
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// stageFields are the keys of a stage written as a YAML mapping.
+var stageFields = map[string]bool{
+	"op": true, "pattern": true, "replace": true, "regex": true, "case_sensitive": true,
+	"whole_word": true, "invert": true, "only_matching": true, "type": true,
+}
+
+// stageSpec is a stage written as a YAML mapping.
+type stageSpec struct {
+	Op            string `yaml:"op"`
+	Pattern       string `yaml:"pattern"`
+	Replace       string `yaml:"replace"`
+	Regex         bool   `yaml:"regex"`
+	CaseSensitive bool   `yaml:"case_sensitive"`
+	WholeWord     bool   `yaml:"whole_word"`
+	Invert        bool   `yaml:"invert"`
+	OnlyMatching  bool   `yaml:"only_matching"`
+	Type          string `yaml:"type"`
+}
+
+// Load reads a pipeline file. The file holds a list of stages, either at
+// the top level or under a "stages" key. Each stage is a string in the
+// pipeline syntax or a mapping:
+//
+//	stages:
+//	  - filter ERROR
+//	  - op: replace
+//	    pattern: 'host\d+'
+//	    replace: HOST
+//	    regex: true
+//	  - transform lower
+//	  - dedupe
+func Load(filename string) ([]Stage, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline file: %w", err)
+	}
+
+	stages, err := ParseYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("pipeline file '%s': %w", filename, err)
+	}
+	return stages, nil
+}
+
+// ParseYAML parses the content of a pipeline file; see Load.
+func ParseYAML(data []byte) ([]Stage, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		return nil, fmt.Errorf("the pipeline has no stages")
+	}
+
+	list := document.Content[0]
+	if list.Kind == yaml.MappingNode {
+		var stagesNode *yaml.Node
+		for i := 0; i+1 < len(list.Content); i += 2 {
+			if key := list.Content[i].Value; key == "stages" {
+				stagesNode = list.Content[i+1]
+			} else {
+				return nil, fmt.Errorf("line %d: unknown key '%s' (expected 'stages')", list.Content[i].Line, key)
+			}
+		}
+		if stagesNode == nil {
+			return nil, fmt.Errorf("missing 'stages' list")
+		}
+		list = stagesNode
+	}
+	if list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("line %d: expected a list of stages", list.Line)
+	}
+
+	var stages []Stage
+	for i, node := range list.Content {
+		stage, err := parseStageNode(node)
+		if err != nil {
+			return nil, fmt.Errorf("stage %d (line %d): %w", i+1, node.Line, err)
+		}
+		stages = append(stages, stage)
+	}
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("the pipeline has no stages")
+	}
+	return stages, nil
+}
+
+func parseStageNode(node *yaml.Node) (Stage, error) {
+	if node.Kind == yaml.ScalarNode {
+		return ParseStage(node.Value)
+	}
+	if node.Kind != yaml.MappingNode {
+		return Stage{}, fmt.Errorf("expected a stage string or mapping")
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		if key := node.Content[i].Value; !stageFields[key] {
+			return Stage{}, fmt.Errorf("unknown field '%s'", key)
+		}
+	}
+
+	var spec stageSpec
+	if err := node.Decode(&spec); err != nil {
+		return Stage{}, err
+	}
+
+	stage := Stage{
+		Operation: strings.ToLower(spec.Op),
+		Options: processor.ProcessOptions{
+			Pattern:       spec.Pattern,
+			ReplaceWith:   spec.Replace,
+			RegexMode:     spec.Regex,
+			CaseSensitive: spec.CaseSensitive,
+			WholeWord:     spec.WholeWord,
+			InvertMatch:   spec.Invert,
+			OnlyMatching:  spec.OnlyMatching,
+			TransformType: strings.ToLower(spec.Type),
+		},
+	}
+
+	switch stage.Operation {
+	case "filter":
+		stage.Source = "filter " + spec.Pattern
+	case "replace":
+		stage.Source = fmt.Sprintf("replace %s → %s", spec.Pattern, spec.Replace)
+	case "transform":
+		stage.Source = "transform " + spec.Type
+	case "dedupe":
+		stage.Source = "dedupe"
+	case "":
+		return Stage{}, fmt.Errorf("missing 'op' (valid operations: %s)", strings.Join(Operations, ", "))
+	default:
+		return Stage{}, fmt.Errorf("unknown operation '%s'. Valid operations: %s", spec.Op, strings.Join(Operations, ", "))
+	}
+	return stage, nil
+}
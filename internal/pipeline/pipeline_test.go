@@ -0,0 +1,183 @@
+package pipeline_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kcansari/optix/internal/pipeline"
+	"github.com/kcansari/optix/internal/processor"
+	"github.com/kcansari/optix/internal/processor/strategies"
+	readerstrategies "github.com/kcansari/optix/internal/reader/strategies"
+)
+
+// TestParse tests parsing pipeline stages and their options.
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       string
+		operations []string
+		options    []processor.ProcessOptions
+	}{
+		{
+			name:       "literal filter and dedupe",
+			spec:       "filter ERROR | dedupe",
+			operations: []string{"filter", "dedupe"},
+			options:    []processor.ProcessOptions{{Pattern: "ERROR"}, {}},
+		},
+		{
+			name:       "filter flags and regex",
+			spec:       "filter -vc /time(out)?/",
+			operations: []string{"filter"},
+			options:    []processor.ProcessOptions{{Pattern: "time(out)?", RegexMode: true, InvertMatch: true, CaseSensitive: true}},
+		},
+		{
+			name:       "quoted pipe and words",
+			spec:       `filter "a|b" | filter connection refused | filter a\|b`,
+			operations: []string{"filter", "filter", "filter"},
+			options:    []processor.ProcessOptions{{Pattern: "a|b"}, {Pattern: "connection refused"}, {Pattern: "a|b"}},
+		},
+		{
+			name:       "substitution",
+			spec:       `replace s/host\d+/HOST/g | replace s#a/b#c#i`,
+			operations: []string{"replace", "replace"},
+			options: []processor.ProcessOptions{
+				{Pattern: `host\d+`, ReplaceWith: "HOST", RegexMode: true, CaseSensitive: true},
+				{Pattern: "a/b", ReplaceWith: "c", RegexMode: true},
+			},
+		},
+		{
+			name:       "escaped delimiter",
+			spec:       `replace 's/a\/b/c/'`,
+			operations: []string{"replace"},
+			options:    []processor.ProcessOptions{{Pattern: "a/b", ReplaceWith: "c", RegexMode: true, CaseSensitive: true}},
+		},
+		{
+			name:       "literal replace and transform",
+			spec:       `replace --whole-word 'old name' new | transform LOWER`,
+			operations: []string{"replace", "transform"},
+			options:    []processor.ProcessOptions{{Pattern: "old name", ReplaceWith: "new", WholeWord: true}, {TransformType: "lower"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stages, err := pipeline.Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(stages) != len(tt.operations) {
+				t.Fatalf("Expected %d stages, got %d", len(tt.operations), len(stages))
+			}
+			for i, stage := range stages {
+				if stage.Operation != tt.operations[i] {
+					t.Errorf("Stage %d: expected operation '%s', got '%s'", i+1, tt.operations[i], stage.Operation)
+				}
+				if !reflect.DeepEqual(stage.Options, tt.options[i]) {
+					t.Errorf("Stage %d: expected options %+v, got %+v", i+1, tt.options[i], stage.Options)
+				}
+			}
+		})
+	}
+}
+
+// TestParseErrors tests that malformed pipelines are rejected.
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		" | ",
+		"search ERROR",
+		"filter",
+		"filter -x ERROR",
+		"replace only-one",
+		"replace s/a/b",
+		"replace s/a/b/x",
+		"transform",
+		"dedupe now",
+		`filter "unterminated`,
+	} {
+		if _, err := pipeline.Parse(spec); err == nil {
+			t.Errorf("Expected an error for '%s'", spec)
+		}
+	}
+}
+
+// TestParseYAML tests pipeline files with string and mapping stages.
+func TestParseYAML(t *testing.T) {
+	data := []byte(`stages:
+  - filter -v DEBUG
+  - op: replace
+    pattern: 'host(\d+)'
+    replace: 'node-$1'
+    regex: true
+  - transform lower
+  - op: dedupe
+`)
+
+	stages, err := pipeline.ParseYAML(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var operations []string
+	for _, stage := range stages {
+		operations = append(operations, stage.Operation)
+	}
+	if strings.Join(operations, ",") != "filter,replace,transform,dedupe" {
+		t.Errorf("Unexpected operations: %v", operations)
+	}
+	if options := stages[1].Options; options.Pattern != `host(\d+)` || options.ReplaceWith != "node-$1" || !options.RegexMode {
+		t.Errorf("Unexpected replace options: %+v", options)
+	}
+
+	// A top-level list works too
+	if stages, err := pipeline.ParseYAML([]byte("- dedupe\n")); err != nil || len(stages) != 1 {
+		t.Errorf("Expected one stage from a top-level list, got %d (%v)", len(stages), err)
+	}
+
+	for _, invalid := range []string{"", "stages: []\n", "steps:\n  - dedupe\n", "- op: dedupe\n  typo: 1\n", "- op: sort\n", "- {}\n"} {
+		if _, err := pipeline.ParseYAML([]byte(invalid)); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+// TestRun tests that each stage processes the output of the previous one.
+func TestRun(t *testing.T) {
+	strategy := strategies.NewDefaultTextProcessorStrategy()
+
+	input := "INFO host1 ok\r\nERROR host12 Timeout\r\nERROR host3 timeout\r\nDEBUG x\r\nERROR host7 TIMEOUT"
+	content, err := readerstrategies.NewDefaultFileReaderStrategy().ReadData("app.log", []byte(input))
+	if err != nil {
+		t.Fatalf("Failed to read input: %v", err)
+	}
+
+	stages, err := pipeline.Parse(`filter ERROR | replace s/host\d+/HOST/ | transform lower | dedupe | transform eol-lf`)
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	result, err := pipeline.Run(strategy, "app.log", content, stages)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.Content != "error host timeout\n" {
+		t.Errorf("Unexpected content %q", result.Content)
+	}
+	if len(result.Stages) != 5 {
+		t.Fatalf("Expected 5 stage results, got %d", len(result.Stages))
+	}
+	if result.Stages[0].MatchesFound != 3 || result.Stages[1].MatchesFound != 3 || result.Stages[3].MatchesFound != 2 {
+		t.Errorf("Unexpected stage counts: filter %d, replace %d, dedupe %d",
+			result.Stages[0].MatchesFound, result.Stages[1].MatchesFound, result.Stages[3].MatchesFound)
+	}
+	if result.LineEnding != "lf" {
+		t.Errorf("Expected the eol-lf stage to set the line ending, got '%s'", result.LineEnding)
+	}
+
+	// Invalid stages fail before anything runs
+	if _, err := pipeline.Run(strategy, "app.log", content, []pipeline.Stage{{Operation: "filter", Source: "filter"}}); err == nil {
+		t.Error("Expected an error for a filter stage without a pattern")
+	}
+}
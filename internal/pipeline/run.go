@@ -0,0 +1,107 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kcansari/optix/internal/processor"
+	"github.com/kcansari/optix/internal/reader"
+)
+
+// Result is the outcome of running a pipeline.
+type Result struct {
+	// Stages holds the result of each stage, in order
+	Stages []*processor.ProcessingResult
+
+	// Content is the output of the last stage
+	Content string
+
+	// LineEnding is the line-ending style requested by a stage such as
+	// "transform eol-crlf"; empty keeps the style of the input
+	LineEnding string
+
+	// ExecutionTime is the time taken by all stages
+	ExecutionTime time.Duration
+}
+
+// Validate checks every stage before anything runs, so a mistake in the
+// last stage is reported before the first one does any work.
+func Validate(strategy *processor.TextProcessorStrategy, stages []Stage) error {
+	for i, stage := range stages {
+		textProcessor := strategy.GetProcessor(stage.Operation)
+		if textProcessor == nil {
+			return fmt.Errorf("stage %d: unsupported operation '%s'", i+1, stage.Operation)
+		}
+		if err := textProcessor.ValidateOptions(stage.Options); err != nil {
+			return fmt.Errorf("stage %d (%s): %w", i+1, stage.Source, err)
+		}
+	}
+	return nil
+}
+
+// Run feeds content through the stages, each stage processing the
+// ModifiedContent of the one before. Stages run as dry runs; writing the
+// final content is up to the caller, so a file is written (and backed up)
+// at most once.
+func Run(strategy *processor.TextProcessorStrategy, fileName string, content *reader.FileContent, stages []Stage) (*Result, error) {
+	if err := Validate(strategy, stages); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	result := &Result{}
+	current := content
+
+	for i, stage := range stages {
+		options := stage.Options
+		options.FileName = fileName
+		options.OutputFile = ""
+		options.DryRun = true
+		options.CreateBackup = false
+
+		stageResult, err := strategy.ProcessText(stage.Operation, current, options)
+		if err != nil {
+			return nil, fmt.Errorf("stage %d (%s): %w", i+1, stage.Source, err)
+		}
+		result.Stages = append(result.Stages, stageResult)
+		if stageResult.LineEnding != "" {
+			result.LineEnding = stageResult.LineEnding
+		}
+
+		current = textContent(content, stageResult.ModifiedContent)
+	}
+
+	result.Content = current.Content
+	result.ExecutionTime = time.Since(startTime)
+	return result, nil
+}
+
+// textContent wraps the output of a stage as input for the next one. The
+// properties the writer needs (encoding, line endings, compression) are
+// taken from the original input.
+func textContent(original *reader.FileContent, text string) *reader.FileContent {
+	var lines []string
+	if text != "" {
+		lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	}
+
+	wordCount := 0
+	for _, line := range lines {
+		wordCount += len(strings.Fields(line))
+	}
+
+	return &reader.FileContent{
+		Content:        text,
+		Lines:          lines,
+		FileType:       original.FileType,
+		Size:           int64(len(text)),
+		LineCount:      len(lines),
+		WordCount:      wordCount,
+		Compression:    original.Compression,
+		Encoding:       original.Encoding,
+		BOM:            original.BOM,
+		LineEnding:     original.LineEnding,
+		NoFinalNewline: original.NoFinalNewline,
+	}
+}
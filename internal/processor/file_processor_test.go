@@ -1,13 +1,18 @@
 package processor_test
 
 import (
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/kcansari/optix/internal/compression"
+	"github.com/kcansari/optix/internal/patterns"
 	"github.com/kcansari/optix/internal/processor/strategies"
 	"github.com/kcansari/optix/internal/reader"
+	readerstrategies "github.com/kcansari/optix/internal/reader/strategies"
 	"github.com/kcansari/optix/internal/types"
 )
 
@@ -274,6 +279,19 @@ INFO: Application stopped`
 			expectedLines:   2,
 			expectError:     false,
 		},
+		{
+			name: "Only matching returns every match per line",
+			options: types.ProcessOptions{
+				Pattern:       `\b[A-Z]\w*`,
+				RegexMode:     true,
+				CaseSensitive: true,
+				OnlyMatching:  true,
+				FileName:      "test.txt",
+			},
+			expectedMatches: 10, // The level and the capitalized word of each line
+			expectedLines:   10,
+			expectError:     false,
+		},
 		{
 			name: "Empty pattern",
 			options: types.ProcessOptions{
@@ -428,6 +446,39 @@ lower case text
 	}
 }
 
+// TestDedupeProcessor tests that repeated lines are removed in order.
+func TestDedupeProcessor(t *testing.T) {
+	processor := &strategies.DedupeProcessorStrategy{}
+
+	tests := []struct {
+		name               string
+		input              string
+		expectedContent    string
+		expectedDuplicates int
+	}{
+		{"no duplicates", "a\nb\nc\n", "a\nb\nc\n", 0},
+		{"keeps first occurrence", "b\na\nb\nc\na\n", "b\na\nc\n", 2},
+		{"case matters", "Error\nerror\n", "Error\nerror\n", 0},
+		{"blank lines", "a\n\n\nb\n", "a\n\nb\n", 1},
+		{"empty", "", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := processor.Process(createTestFileContent(tt.input), types.ProcessOptions{FileName: "test.txt", DryRun: true})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.ModifiedContent != tt.expectedContent {
+				t.Errorf("Expected content %q, got %q", tt.expectedContent, result.ModifiedContent)
+			}
+			if result.MatchesFound != tt.expectedDuplicates {
+				t.Errorf("Expected %d duplicates, got %d", tt.expectedDuplicates, result.MatchesFound)
+			}
+		})
+	}
+}
+
 func TestTextProcessorStrategy(t *testing.T) {
 	strategy := strategies.NewDefaultTextProcessorStrategy()
 
@@ -459,6 +510,11 @@ func TestTextProcessorStrategy(t *testing.T) {
 			operationType: "transform",
 			expectError:   false,
 		},
+		{
+			name:          "Valid dedupe operation",
+			operationType: "dedupe",
+			expectError:   false,
+		},
 		{
 			name:          "Invalid operation",
 			operationType: "invalid",
@@ -490,7 +546,7 @@ func TestTextProcessorStrategy(t *testing.T) {
 
 	// Test supported operations
 	supportedOps := strategy.GetSupportedOperations()
-	expectedOps := []string{"search", "replace", "filter", "transform"}
+	expectedOps := []string{"search", "replace", "filter", "transform", "dedupe", "redact"}
 
 	if len(supportedOps) != len(expectedOps) {
 		t.Errorf("Expected %d supported operations, got %d", len(expectedOps), len(supportedOps))
@@ -531,3 +587,299 @@ func TestProcessingResultTiming(t *testing.T) {
 		t.Errorf("Execution time %v is greater than actual duration %v", result.ExecutionTime, duration)
 	}
 }
+
+// TestProcessorsOperateOnRecords tests that line-oriented processors use decoded records when present.
+func TestProcessorsOperateOnRecords(t *testing.T) {
+	content := createTestFileContent("{\"level\":\"error\"}\n{bad error\n{\"level\":\"info\"}\n{\"level\":\"error\"}\n")
+	content.FileType = "jsonl"
+	content.Records = []types.Record{
+		{LineNumber: 1, Raw: `{"level":"error"}`},
+		{LineNumber: 3, Raw: `{"level":"info"}`},
+		{LineNumber: 4, Raw: `{"level":"error"}`},
+	}
+	content.InvalidLines = []types.LineError{{LineNumber: 2, Message: "invalid JSON"}}
+
+	filter := &strategies.FilterProcessorStrategy{}
+	result, err := filter.Process(content, types.ProcessOptions{Pattern: "error", FileName: "test.jsonl"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The skipped invalid line must not be matched
+	if result.MatchesFound != 2 {
+		t.Errorf("Expected 2 matching records, got %d", result.MatchesFound)
+	}
+
+	expected := "{\"level\":\"error\"}\n{\"level\":\"error\"}\n"
+	if result.ModifiedContent != expected {
+		t.Errorf("Expected content %q, got %q", expected, result.ModifiedContent)
+	}
+
+	search := &strategies.SearchProcessorStrategy{}
+	result, err = search.Process(content, types.ProcessOptions{Pattern: "info", FileName: "test.jsonl"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.MatchesFound != 1 {
+		t.Errorf("Expected 1 search match, got %d", result.MatchesFound)
+	}
+}
+
+// TestProcessorsGroupRecords tests that search and filter match and count
+// whole multi-line records when a record start is given.
+func TestProcessorsGroupRecords(t *testing.T) {
+	content := createTestFileContent("2024-05-01 INFO started\n2024-05-01 ERROR failed\njava.lang.NullPointerException: x\n\tat a.B.c(B.java:1)\n2024-05-01 INFO done\n")
+
+	filter := &strategies.FilterProcessorStrategy{}
+	result, err := filter.Process(content, types.ProcessOptions{Pattern: "NullPointer", RecordStart: "java", FileName: "test.log"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "2024-05-01 ERROR failed\njava.lang.NullPointerException: x\n\tat a.B.c(B.java:1)\n"
+	if result.MatchesFound != 1 || result.ModifiedContent != expected {
+		t.Errorf("Expected 1 record %q, got %d records %q", expected, result.MatchesFound, result.ModifiedContent)
+	}
+
+	// Inverting keeps the records without a match
+	result, err = filter.Process(content, types.ProcessOptions{Pattern: "NullPointer", RecordStart: `^\d{4}-`, InvertMatch: true, FileName: "test.log"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.MatchesFound != 2 {
+		t.Errorf("Expected 2 records without a match, got %d", result.MatchesFound)
+	}
+
+	search := &strategies.SearchProcessorStrategy{}
+	searchResult, err := search.Process(content, types.ProcessOptions{Pattern: "B.java", RecordStart: "java", FileName: "test.log"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if searchResult.MatchesFound != 1 || searchResult.SearchResults[0].LineNumber != 2 {
+		t.Fatalf("Expected one match on line 2, got %+v", searchResult.SearchResults)
+	}
+	if searchResult.LinesProcessed != 3 {
+		t.Errorf("Expected 3 records processed, got %d", searchResult.LinesProcessed)
+	}
+
+	if _, err := filter.Process(content, types.ProcessOptions{Pattern: "x", RecordStart: "(", FileName: "test.log"}); err == nil {
+		t.Errorf("Expected error for an invalid record start")
+	}
+}
+
+// TestReplaceKeepsCompression tests that rewriting a compressed file in
+// place re-compresses it in the same format.
+func TestReplaceKeepsCompression(t *testing.T) {
+	compressed, err := compression.Compress(compression.Gzip, []byte("level=error msg=boom\n"))
+	if err != nil {
+		t.Fatalf("Failed to compress: %v", err)
+	}
+
+	fileName := filepath.Join(t.TempDir(), "app.log.gz")
+	if err := os.WriteFile(fileName, compressed, 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	content := createTestFileContent("level=error msg=boom\n")
+	content.Compression = string(compression.Gzip)
+
+	processor := &strategies.ReplaceProcessorStrategy{}
+	_, err = processor.Process(content, types.ProcessOptions{
+		Pattern:     "error",
+		ReplaceWith: "warn",
+		FileName:    fileName,
+	})
+	if err != nil {
+		t.Fatalf("Failed to replace: %v", err)
+	}
+
+	input, format, err := compression.Open(fileName)
+	if err != nil {
+		t.Fatalf("Failed to open result: %v", err)
+	}
+	defer input.Close()
+
+	if format != compression.Gzip {
+		t.Errorf("Expected gzip output, got '%s'", format)
+	}
+	data, err := io.ReadAll(input)
+	if err != nil {
+		t.Fatalf("Failed to decompress result: %v", err)
+	}
+	if string(data) != "level=warn msg=boom\n" {
+		t.Errorf("Unexpected content: %q", data)
+	}
+}
+
+// TestTransformPreservesLineEndings tests that transforms read through the
+// reader strategy keep CRLF line endings and a missing final newline, and
+// that the eol transforms convert explicitly.
+func TestTransformPreservesLineEndings(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		transformType string
+		expected      string
+	}{
+		{"trim keeps crlf", "  a  \r\n b\r\n", "trim", "a\r\nb\r\n"},
+		{"trim keeps missing final newline", "  a\n b ", "trim", "a\nb"},
+		{"upper keeps cr", "a\rb\r", "upper", "A\rB\r"},
+		{"eol-lf converts crlf", "a\r\nb\r\nc", "eol-lf", "a\nb\nc"},
+		{"eol-crlf converts lf", "a\nb\n", "eol-crlf", "a\r\nb\r\n"},
+		{"eol-crlf converts mixed", "a\r\nb\nc\r", "eol-crlf", "a\r\nb\r\nc\r\n"},
+	}
+
+	readerStrategy := readerstrategies.NewDefaultFileReaderStrategy()
+	processor := &strategies.TransformProcessorStrategy{}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "notes.txt")
+			if err := os.WriteFile(fileName, []byte(test.input), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			content, err := readerStrategy.ReadFile(fileName)
+			if err != nil {
+				t.Fatalf("Failed to read test file: %v", err)
+			}
+
+			_, err = processor.Process(content, types.ProcessOptions{
+				TransformType: test.transformType,
+				FileName:      fileName,
+			})
+			if err != nil {
+				t.Fatalf("Failed to transform: %v", err)
+			}
+
+			data, _ := os.ReadFile(fileName)
+			if string(data) != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, data)
+			}
+		})
+	}
+}
+
+// TestReplaceKeepsMixedLineEndings tests that a replace in a file with
+// mixed line endings changes only the replaced text.
+func TestReplaceKeepsMixedLineEndings(t *testing.T) {
+	input := "level=error a\r\nlevel=info b\nlevel=error c\r\nlevel=warn d"
+	fileName := filepath.Join(t.TempDir(), "mixed.log")
+	if err := os.WriteFile(fileName, []byte(input), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	content, err := readerstrategies.NewDefaultFileReaderStrategy().ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	processor := &strategies.ReplaceProcessorStrategy{}
+	_, err = processor.Process(content, types.ProcessOptions{
+		Pattern:     "error",
+		ReplaceWith: "fatal",
+		FileName:    fileName,
+	})
+	if err != nil {
+		t.Fatalf("Failed to replace: %v", err)
+	}
+
+	expected := "level=fatal a\r\nlevel=info b\nlevel=fatal c\r\nlevel=warn d"
+	if data, _ := os.ReadFile(fileName); string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, data)
+	}
+}
+
+// TestRedactProcessor tests redacting personal data and counting the
+// replacements per kind.
+func TestRedactProcessor(t *testing.T) {
+	processor := &strategies.RedactProcessorStrategy{}
+	content := createTestFileContent("login ann@example.com from 10.0.0.7\nno personal data\nann@example.com paid with 4111111111111111\n")
+	registry := patterns.NewDefaultRegistry()
+	customerMail, err := patterns.NewKind("customer_mail", "", `\b\w+@example\.com\b`)
+	if err != nil {
+		t.Fatalf("Failed to create kind: %v", err)
+	}
+	registry.Add(customerMail)
+
+	tests := []struct {
+		name           string
+		options        types.ProcessOptions
+		expectedCounts map[string]int
+		expectError    bool
+	}{
+		{
+			name:           "All kinds masked",
+			options:        types.ProcessOptions{FileName: "test.txt", DryRun: true},
+			expectedCounts: map[string]int{"email": 2, "ipv4": 1, "card": 1},
+		},
+		{
+			name:           "Selected kinds hashed",
+			options:        types.ProcessOptions{RedactKinds: []string{"email"}, RedactMode: "hash", RedactKey: "k", FileName: "test.txt", DryRun: true},
+			expectedCounts: map[string]int{"email": 2},
+		},
+		{
+			name:           "Configured kind",
+			options:        types.ProcessOptions{RedactKinds: []string{"customer_mail"}, RedactRegistry: registry, FileName: "test.txt", DryRun: true},
+			expectedCounts: map[string]int{"customer_mail": 2},
+		},
+		{
+			name:        "Fake mode without key",
+			options:     types.ProcessOptions{RedactMode: "fake", FileName: "test.txt", DryRun: true},
+			expectError: true,
+		},
+		{
+			name:        "Unknown kind",
+			options:     types.ProcessOptions{RedactKinds: []string{"ssn"}, FileName: "test.txt", DryRun: true},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := processor.Process(content, tt.options)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if strings.Contains(result.ModifiedContent, "ann@example.com") {
+				t.Errorf("Expected addresses to be redacted, got %q", result.ModifiedContent)
+			}
+			if !strings.Contains(result.ModifiedContent, "\nno personal data\n") {
+				t.Errorf("Expected other lines to be kept, got %q", result.ModifiedContent)
+			}
+			total := 0
+			for kind, count := range tt.expectedCounts {
+				total += count
+				if result.RedactionCounts[kind] != count {
+					t.Errorf("Expected %d %s values, got %d", count, kind, result.RedactionCounts[kind])
+				}
+			}
+			if result.MatchesFound != total || len(result.RedactionCounts) != len(tt.expectedCounts) {
+				t.Errorf("Expected %d values in total, got %d (%v)", total, result.MatchesFound, result.RedactionCounts)
+			}
+		})
+	}
+
+	// Rewriting the file keeps the original in a backup
+	fileName := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(fileName, []byte(content.Content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	result, err := processor.Process(content, types.ProcessOptions{FileName: fileName, CreateBackup: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if backup, err := os.ReadFile(result.BackupPath); err != nil || string(backup) != content.Content {
+		t.Errorf("Expected the original content in the backup, got %q (%v)", backup, err)
+	}
+	if data, _ := os.ReadFile(fileName); string(data) != result.ModifiedContent {
+		t.Errorf("Expected the redacted content in the file, got %q", data)
+	}
+}
@@ -0,0 +1,73 @@
+package strategies
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kcansari/optix/internal/reader"
+	"github.com/kcansari/optix/internal/types"
+	"github.com/kcansari/optix/internal/writer"
+)
+
+// DedupeProcessorStrategy removes repeated lines, keeping the first
+// occurrence of each line in its original position.
+type DedupeProcessorStrategy struct{}
+
+func (dp *DedupeProcessorStrategy) Process(content *reader.FileContent, options types.ProcessOptions) (*types.ProcessingResult, error) {
+	startTime := time.Now()
+
+	if err := dp.ValidateOptions(options); err != nil {
+		return nil, fmt.Errorf("invalid dedupe options: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var keptLines []string
+	duplicates := 0
+
+	for _, input := range inputLines(content) {
+		if seen[input.text] {
+			duplicates++
+			continue
+		}
+		seen[input.text] = true
+		keptLines = append(keptLines, input.text)
+	}
+
+	dedupedContent := strings.Join(keptLines, "\n")
+	if len(keptLines) > 0 {
+		dedupedContent += "\n"
+	}
+
+	result := &types.ProcessingResult{
+		FileName:        options.FileName,
+		Operation:       "dedupe",
+		MatchesFound:    duplicates, // Number of lines removed
+		LinesProcessed:  content.LineCount,
+		Success:         true,
+		ExecutionTime:   time.Since(startTime),
+		ModifiedContent: dedupedContent,
+	}
+
+	if !options.DryRun {
+		outputFile := options.OutputFile
+		if outputFile == "" {
+			outputFile = options.FileName
+		}
+
+		err := writer.WriteFile(outputFile, dedupedContent, writer.OptionsFor(content, options.FileName, outputFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to write deduplicated content: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+func (dp *DedupeProcessorStrategy) GetOperationType() string {
+	return "dedupe"
+}
+
+func (dp *DedupeProcessorStrategy) ValidateOptions(options types.ProcessOptions) error {
+	return nil
+}
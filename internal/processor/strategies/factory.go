@@ -8,10 +8,12 @@ func NewDefaultTextProcessorStrategy() *processor.TextProcessorStrategy {
 	strategy := processor.NewTextProcessorStrategy()
 
 	// Register all available text processors
-	strategy.AddProcessor(&FilterProcessorStrategy{})
+	strategy.AddProcessor(&SearchProcessorStrategy{})
 	strategy.AddProcessor(&ReplaceProcessorStrategy{})
 	strategy.AddProcessor(&FilterProcessorStrategy{})
 	strategy.AddProcessor(&TransformProcessorStrategy{})
+	strategy.AddProcessor(&DedupeProcessorStrategy{})
+	strategy.AddProcessor(&RedactProcessorStrategy{})
 
 	return strategy
 }
@@ -2,13 +2,14 @@ package strategies
 
 import (
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/kcansari/optix/internal/logparser"
 	"github.com/kcansari/optix/internal/reader"
 	"github.com/kcansari/optix/internal/types"
+	"github.com/kcansari/optix/internal/writer"
 )
 
 type FilterProcessorStrategy struct{}
@@ -45,10 +46,16 @@ func (fp *FilterProcessorStrategy) Process(content *reader.FileContent, options
 		}
 	}
 
+	records, err := inputRecords(content, options.RecordStart)
+	if err != nil {
+		return nil, err
+	}
+
 	var filteredLines []string
 	matchCount := 0
 
-	for _, line := range content.Lines {
+	for _, input := range records {
+		line := input.text
 		matches := pattern.MatchString(line)
 
 		// Apply invert match logic
@@ -58,11 +65,12 @@ func (fp *FilterProcessorStrategy) Process(content *reader.FileContent, options
 
 		if matches {
 			if options.OnlyMatching {
-				// Extract only the matching part
-				match := pattern.FindString(line)
-				if match != "" {
-					filteredLines = append(filteredLines, match)
-					matchCount++
+				// Extract every matching part, one per output line
+				for _, match := range pattern.FindAllString(line, -1) {
+					if match != "" {
+						filteredLines = append(filteredLines, match)
+						matchCount++
+					}
 				}
 			} else {
 				// Include the entire line
@@ -89,7 +97,7 @@ func (fp *FilterProcessorStrategy) Process(content *reader.FileContent, options
 
 	// Write filtered content if output file is specified and not in dry run mode
 	if options.OutputFile != "" && !options.DryRun {
-		err = os.WriteFile(options.OutputFile, []byte(filteredContent), 0644)
+		err = writer.WriteFile(options.OutputFile, filteredContent, writer.OptionsFor(content, options.FileName, options.OutputFile))
 		if err != nil {
 			return nil, fmt.Errorf("failed to write filtered content: %w", err)
 		}
@@ -106,5 +114,10 @@ func (fp *FilterProcessorStrategy) ValidateOptions(options types.ProcessOptions)
 	if options.Pattern == "" {
 		return fmt.Errorf("filter pattern cannot be empty")
 	}
+	if options.RecordStart != "" {
+		if _, err := logparser.ParseRecordGrouping(options.RecordStart); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,66 @@
+package strategies
+
+import (
+	"strings"
+
+	"github.com/kcansari/optix/internal/logparser"
+	"github.com/kcansari/optix/internal/reader"
+)
+
+// numberedLine is a unit of input together with its 1-based line number.
+type numberedLine struct {
+	number int
+	text   string
+}
+
+// inputLines returns the lines a line-oriented processor should examine.
+// Record-oriented content (such as JSON Lines) yields its decoded records,
+// so blank and skipped invalid lines never reach the processor while the
+// reported line numbers still refer to the original file.
+func inputLines(content *reader.FileContent) []numberedLine {
+	if content.Records != nil {
+		lines := make([]numberedLine, 0, len(content.Records))
+		for _, record := range content.Records {
+			lines = append(lines, numberedLine{number: record.LineNumber, text: record.Raw})
+		}
+		return lines
+	}
+
+	lines := make([]numberedLine, 0, len(content.Lines))
+	for i, line := range content.Lines {
+		lines = append(lines, numberedLine{number: i + 1, text: line})
+	}
+	return lines
+}
+
+// inputRecords returns the units a search or filter examines. With a
+// record start (see ProcessOptions.RecordStart) the lines are grouped
+// into records, such as a log line and its stack trace, and each record
+// is one unit whose text holds its lines joined by newlines; the number
+// is the line the record starts on. Without one, it returns inputLines.
+func inputRecords(content *reader.FileContent, recordStart string) ([]numberedLine, error) {
+	lines := inputLines(content)
+	if recordStart == "" || len(lines) == 0 {
+		return lines, nil
+	}
+
+	grouping, err := logparser.ParseRecordGrouping(recordStart)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.text
+	}
+	starts := append(grouping.Group(texts), len(lines))
+
+	records := make([]numberedLine, 0, len(starts)-1)
+	for i := 0; i+1 < len(starts); i++ {
+		records = append(records, numberedLine{
+			number: lines[starts[i]].number,
+			text:   strings.Join(texts[starts[i]:starts[i+1]], "\n"),
+		})
+	}
+	return records, nil
+}
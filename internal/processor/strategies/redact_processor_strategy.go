@@ -0,0 +1,126 @@
+package strategies
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kcansari/optix/internal/backup"
+	"github.com/kcansari/optix/internal/patterns"
+	"github.com/kcansari/optix/internal/reader"
+	"github.com/kcansari/optix/internal/types"
+	"github.com/kcansari/optix/internal/writer"
+)
+
+// RedactProcessorStrategy replaces personal data such as email addresses,
+// IP addresses, phone numbers and card numbers, counting the replacements
+// per kind.
+type RedactProcessorStrategy struct{}
+
+func (rp *RedactProcessorStrategy) Process(content *reader.FileContent, options types.ProcessOptions) (*types.ProcessingResult, error) {
+	startTime := time.Now()
+
+	if err := rp.ValidateOptions(options); err != nil {
+		return nil, fmt.Errorf("invalid redact options: %w", err)
+	}
+
+	registry := options.RedactRegistry
+	if registry == nil {
+		registry = patterns.NewDefaultRegistry()
+	}
+	kinds, err := registry.Select(redactKinds(options))
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := patterns.NewRedactor(kinds, redactMode(options), options.RedactKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var backupPath string
+	if options.CreateBackup && !options.DryRun {
+		backupPath, err = backup.Create(options.FileName, options.BackupDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create backup: %w", err)
+		}
+	}
+
+	redactedLines := make([]string, len(content.Lines))
+	for i, line := range content.Lines {
+		redactedLines[i] = redactor.Redact(line)
+	}
+
+	redactedContent := strings.Join(redactedLines, "\n")
+	if len(redactedLines) > 0 {
+		redactedContent += "\n"
+	}
+
+	redactions := 0
+	for _, count := range redactor.Counts {
+		redactions += count
+	}
+
+	result := &types.ProcessingResult{
+		FileName:        options.FileName,
+		Operation:       "redact",
+		MatchesFound:    redactions,
+		LinesProcessed:  content.LineCount,
+		Success:         true,
+		BackupPath:      backupPath,
+		ExecutionTime:   time.Since(startTime),
+		ModifiedContent: redactedContent,
+		RedactionCounts: redactor.Counts,
+	}
+
+	if !options.DryRun {
+		outputFile := options.OutputFile
+		if outputFile == "" {
+			outputFile = options.FileName
+		}
+
+		err := writer.WriteFile(outputFile, redactedContent, writer.OptionsFor(content, options.FileName, outputFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to write redacted content: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+func (rp *RedactProcessorStrategy) GetOperationType() string {
+	return "redact"
+}
+
+func (rp *RedactProcessorStrategy) ValidateOptions(options types.ProcessOptions) error {
+	mode := redactMode(options)
+	valid := false
+	for _, candidate := range patterns.RedactModes() {
+		if mode == candidate {
+			valid = true
+		}
+	}
+	if !valid {
+		return fmt.Errorf("invalid redaction mode '%s'. Valid modes: %s", options.RedactMode, strings.Join(patterns.RedactModes(), ", "))
+	}
+	if mode != patterns.ModeMask && options.RedactKey == "" {
+		return fmt.Errorf("the %s mode needs a key", mode)
+	}
+	return nil
+}
+
+// redactMode returns the redaction mode of the options; mask by default.
+func redactMode(options types.ProcessOptions) string {
+	if options.RedactMode == "" {
+		return patterns.ModeMask
+	}
+	return strings.ToLower(options.RedactMode)
+}
+
+// redactKinds returns the names of the kinds to redact; the kinds of
+// personal data by default.
+func redactKinds(options types.ProcessOptions) []string {
+	if len(options.RedactKinds) == 0 {
+		return patterns.PersonalKinds
+	}
+	return options.RedactKinds
+}
@@ -2,13 +2,13 @@ package strategies
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"regexp"
 	"time"
 
+	"github.com/kcansari/optix/internal/backup"
 	"github.com/kcansari/optix/internal/reader"
 	"github.com/kcansari/optix/internal/types"
+	"github.com/kcansari/optix/internal/writer"
 )
 
 type ReplaceProcessorStrategy struct{}
@@ -78,7 +78,7 @@ func (rp *ReplaceProcessorStrategy) Process(content *reader.FileContent, options
 			outputFile = options.FileName
 		}
 
-		err = os.WriteFile(outputFile, []byte(modifiedContent), 0644)
+		err = writer.WriteFile(outputFile, modifiedContent, writer.OptionsFor(content, options.FileName, outputFile))
 		if err != nil {
 			return nil, fmt.Errorf("failed to write modified content: %w", err)
 		}
@@ -88,32 +88,7 @@ func (rp *ReplaceProcessorStrategy) Process(content *reader.FileContent, options
 }
 
 func (rp *ReplaceProcessorStrategy) createBackup(fileName, backupDir string) (string, error) {
-	content, err := os.ReadFile(fileName)
-	if err != nil {
-		return "", fmt.Errorf("failed to read original file: %w", err)
-	}
-
-	timestamp := time.Now().Format("20060102_150405")
-	baseName := filepath.Base(fileName)
-	backupName := fmt.Sprintf("%s.backup_%s", baseName, timestamp)
-
-	var backupPath string
-	if backupDir != "" {
-		err = os.MkdirAll(backupDir, 0755)
-		if err != nil {
-			return "", fmt.Errorf("failed to create backup directory: %w", err)
-		}
-		backupPath = filepath.Join(backupDir, backupName)
-	} else {
-		backupPath = fileName + ".backup_" + timestamp
-	}
-
-	err = os.WriteFile(backupPath, content, 0644)
-	if err != nil {
-		return "", fmt.Errorf("failed to write backup file: %w", err)
-	}
-
-	return backupPath, nil
+	return backup.Create(fileName, backupDir)
 }
 
 func (rp *ReplaceProcessorStrategy) GetOperationType() string {
@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"time"
 
+	"github.com/kcansari/optix/internal/logparser"
 	"github.com/kcansari/optix/internal/reader"
 	"github.com/kcansari/optix/internal/types"
 )
@@ -50,9 +51,13 @@ func (sp *SearchProcessorStrategy) Process(content *reader.FileContent, options
 	}
 
 	var results []types.SearchResult
-	lines := content.Lines
+	lines, err := inputRecords(content, options.RecordStart)
+	if err != nil {
+		return nil, err
+	}
 
-	for i, line := range lines {
+	for i, input := range lines {
+		line := input.text
 		if pattern.MatchString(line) {
 			match := pattern.FindString(line)
 
@@ -61,12 +66,14 @@ func (sp *SearchProcessorStrategy) Process(content *reader.FileContent, options
 			if options.ContextLines > 0 {
 				start := max(0, i-options.ContextLines)
 				end := min(len(lines), i+options.ContextLines+1)
-				context = lines[start:end]
+				for _, contextLine := range lines[start:end] {
+					context = append(context, contextLine.text)
+				}
 			}
 
 			results = append(results, types.SearchResult{
 				FileName:   options.FileName,
-				LineNumber: i + 1,
+				LineNumber: input.number,
 				Line:       line,
 				Match:      match,
 				Context:    context,
@@ -81,6 +88,7 @@ func (sp *SearchProcessorStrategy) Process(content *reader.FileContent, options
 		LinesProcessed: len(lines),
 		Success:        true,
 		ExecutionTime:  time.Since(startTime),
+		SearchResults:  results,
 	}
 
 	return result, nil
@@ -97,5 +105,10 @@ func (sp *SearchProcessorStrategy) ValidateOptions(options types.ProcessOptions)
 	if options.ContextLines < 0 {
 		return fmt.Errorf("context lines cannot be negative")
 	}
+	if options.RecordStart != "" {
+		if _, err := logparser.ParseRecordGrouping(options.RecordStart); err != nil {
+			return err
+		}
+	}
 	return nil
 }
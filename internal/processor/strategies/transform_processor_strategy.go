@@ -2,12 +2,13 @@ package strategies
 
 import (
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
+	"github.com/kcansari/optix/internal/lineending"
 	"github.com/kcansari/optix/internal/reader"
 	"github.com/kcansari/optix/internal/types"
+	"github.com/kcansari/optix/internal/writer"
 )
 
 type TransformProcessorStrategy struct{}
@@ -20,6 +21,7 @@ func (tp *TransformProcessorStrategy) Process(content *reader.FileContent, optio
 	}
 
 	var transformedContent string
+	var lineEnding lineending.Style
 
 	switch strings.ToLower(options.TransformType) {
 	case "upper":
@@ -38,6 +40,13 @@ func (tp *TransformProcessorStrategy) Process(content *reader.FileContent, optio
 		if len(trimmedLines) > 0 {
 			transformedContent += "\n"
 		}
+	case "eol-lf":
+		// Content always uses LF; only the output line endings change
+		transformedContent = content.Content
+		lineEnding = lineending.LF
+	case "eol-crlf":
+		transformedContent = content.Content
+		lineEnding = lineending.CRLF
 	default:
 		return nil, fmt.Errorf("unsupported transform type: %s", options.TransformType)
 	}
@@ -50,6 +59,7 @@ func (tp *TransformProcessorStrategy) Process(content *reader.FileContent, optio
 		Success:         true,
 		ExecutionTime:   time.Since(startTime),
 		ModifiedContent: transformedContent,
+		LineEnding:      string(lineEnding),
 	}
 
 	if !options.DryRun {
@@ -58,7 +68,12 @@ func (tp *TransformProcessorStrategy) Process(content *reader.FileContent, optio
 			outputFile = options.FileName
 		}
 
-		err := os.WriteFile(outputFile, []byte(transformedContent), 0644)
+		writeOptions := writer.OptionsFor(content, options.FileName, outputFile)
+		if lineEnding != lineending.None {
+			writeOptions.LineEnding = lineEnding
+		}
+
+		err := writer.WriteFile(outputFile, transformedContent, writeOptions)
 		if err != nil {
 			return nil, fmt.Errorf("failed to write transformed content: %w", err)
 		}
@@ -76,7 +91,7 @@ func (tp *TransformProcessorStrategy) ValidateOptions(options types.ProcessOptio
 		return fmt.Errorf("transform type cannot be empty")
 	}
 
-	validTypes := []string{"upper", "lower", "title", "trim"}
+	validTypes := []string{"upper", "lower", "title", "trim", "eol-lf", "eol-crlf"}
 	for _, validType := range validTypes {
 		if strings.ToLower(options.TransformType) == validType {
 			return nil
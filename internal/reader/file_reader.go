@@ -1,10 +1,18 @@
 package reader
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/kcansari/optix/internal/charset"
+	"github.com/kcansari/optix/internal/compression"
+	"github.com/kcansari/optix/internal/detect"
+	"github.com/kcansari/optix/internal/lineending"
 	"github.com/kcansari/optix/internal/types"
 )
 
@@ -12,13 +20,30 @@ type FileContent = types.FileContent
 
 type FileReader = types.FileReader
 
+// genericExtensions say little about the content, so a strong content match
+// (e.g. a data.txt that holds JSON) takes precedence over them.
+var genericExtensions = map[string]bool{
+	".txt":  true,
+	".text": true,
+}
+
 type FileReaderStrategy struct {
 	readers []FileReader
+
+	// typeOverride forces every file to be read with the reader for this type
+	typeOverride string
+
+	// extensionTypes maps extensions to reader types, e.g. ".conf" -> "txt"
+	extensionTypes map[string]string
+
+	// defaultType is the reader type for files without a mapped extension
+	defaultType string
 }
 
 func NewFileReaderStrategy() *FileReaderStrategy {
 	return &FileReaderStrategy{
-		readers: []FileReader{},
+		readers:        []FileReader{},
+		extensionTypes: make(map[string]string),
 	}
 }
 
@@ -26,19 +51,359 @@ func (frs *FileReaderStrategy) AddReader(reader FileReader) {
 	frs.readers = append(frs.readers, reader)
 }
 
+// SetTypeOverride makes ReadFile use the reader for fileType (e.g. "json")
+// regardless of extension or content. An empty type restores detection.
+func (frs *FileReaderStrategy) SetTypeOverride(fileType string) {
+	frs.typeOverride = normalizeType(fileType)
+}
+
+// SetDefaultType makes ReadFile use the reader for fileType (e.g. "txt")
+// for files whose extension is not mapped, instead of choosing a reader
+// from the extension and content. An empty type restores detection.
+func (frs *FileReaderStrategy) SetDefaultType(fileType string) {
+	frs.defaultType = normalizeType(fileType)
+}
+
+// MapExtension makes files with extension be read as fileType.
+func (frs *FileReaderStrategy) MapExtension(extension, fileType string) {
+	extension = strings.ToLower(extension)
+	if !strings.HasPrefix(extension, ".") {
+		extension = "." + extension
+	}
+	frs.extensionTypes[extension] = normalizeType(fileType)
+}
+
+// ReadFile selects a reader and reads filename. Compressed files (gzip,
+// bzip2, zlib, zstd) are detected by their magic bytes and decompressed
+// first; their inner name (app.log.gz -> app.log) is used for selection.
+// Content in another character encoding than UTF-8 is decoded, and CRLF
+// and CR line endings are converted to LF, before it reaches the reader.
+// Selection order:
+//  1. the type override, if set
+//  2. an explicit extension mapping
+//  3. the default type, if set
+//  4. a reader supporting the extension, unless the extension is generic
+//     and the content strongly matches another type
+//  5. content detection for unknown extensions
 func (frs *FileReaderStrategy) ReadFile(filename string) (*FileContent, error) {
-	extension := filepath.Ext(filename)
+	format, err := compression.DetectFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open '%s': %w", filename, err)
+	}
+	if format != compression.None {
+		return frs.readCompressed(filename, format)
+	}
 
-	for _, reader := range frs.readers {
-		if reader.SupportsFileType(extension) {
+	sample, err := sampleFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if encoding, bom := charset.Detect(sample); encoding != charset.UTF8 || bom {
+		// Readers expect UTF-8, so other encodings are decoded in memory
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read '%s': %w", filename, err)
+		}
+		return frs.readBytes(filename, filename, data)
+	}
+
+	content, err := frs.readSource(source{
+		name:   filename,
+		sample: func() ([]byte, error) { return sample, nil },
+		read: func(reader FileReader) (*FileContent, error) {
+			streamReader, ok := reader.(types.StreamReader)
+			if !ok {
+				return reader.Read(filename)
+			}
+
+			file, err := os.Open(filename)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open '%s': %w", filename, err)
+			}
+			defer file.Close()
+
+			return readNormalized(filename, file, streamReader)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if info, err := os.Stat(filename); err == nil {
+		content.Size = info.Size()
+	}
+	content.Encoding = string(charset.UTF8)
+
+	return content, nil
+}
+
+// readNormalized reads input with reader, converting CRLF and CR line
+// endings to LF on the way and recording the style that was found.
+func readNormalized(name string, input io.Reader, reader types.StreamReader) (*FileContent, error) {
+	normalizer := lineending.NewReader(input)
+
+	content, err := reader.ReadStream(name, normalizer)
+	if err != nil {
+		return nil, err
+	}
+
+	content.LineEnding = string(normalizer.Style())
+	for _, ending := range normalizer.Endings() {
+		content.LineEndings = append(content.LineEndings, string(ending))
+	}
+	content.NoFinalNewline = !normalizer.FinalNewline()
+
+	return content, nil
+}
+
+// source describes where content comes from, so that plain and compressed
+// files share the reader selection logic.
+type source struct {
+	// name is used to select a reader by extension and for detection
+	name string
+
+	// sample returns the leading bytes of the content
+	sample func() ([]byte, error)
+
+	// read reads the content with the given reader
+	read func(reader FileReader) (*FileContent, error)
+}
+
+func (frs *FileReaderStrategy) readSource(src source) (*FileContent, error) {
+	if frs.typeOverride != "" {
+		reader := frs.GetReaderForType(frs.typeOverride)
+		if reader == nil {
+			return nil, fmt.Errorf("unsupported type '%s' for file '%s'. Supported types: %s",
+				frs.typeOverride, src.name, strings.Join(frs.GetSupportedTypeNames(), ", "))
+		}
+		return src.read(reader)
+	}
+
+	extension := strings.ToLower(filepath.Ext(src.name))
+
+	if fileType, ok := frs.extensionTypes[extension]; ok {
+		if reader := frs.GetReaderForType(fileType); reader != nil {
+			return src.read(reader)
+		}
+	}
 
-			return reader.Read(filename)
+	if frs.defaultType != "" {
+		reader := frs.GetReaderForType(frs.defaultType)
+		if reader == nil {
+			return nil, fmt.Errorf("unsupported type '%s' for file '%s'. Supported types: %s",
+				frs.defaultType, src.name, strings.Join(frs.GetSupportedTypeNames(), ", "))
 		}
+		return src.read(reader)
+	}
+
+	if reader := frs.GetReaderForExtension(extension); reader != nil {
+		if genericExtensions[extension] {
+			if content, ok := frs.readDetected(src); ok {
+				return content, nil
+			}
+		}
+		return src.read(reader)
+	}
+
+	result, err := detectSource(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Type == detect.Binary {
+		return nil, fmt.Errorf("file '%s' appears to be binary (%s); use --type to read it anyway",
+			src.name, result.Reason)
+	}
+	if reader := frs.GetReaderForType(result.Type); reader != nil {
+		return src.read(reader)
 	}
 
 	supportedTypes := frs.GetSupportedTypes()
-	return nil, fmt.Errorf("unsupported file type '%s' for file '%s'. Supported types: %s",
-		extension, filename, strings.Join(supportedTypes, ", "))
+	return nil, fmt.Errorf("unsupported file type '%s' for file '%s' (detected %s). Supported types: %s",
+		extension, src.name, result, strings.Join(supportedTypes, ", "))
+}
+
+// readDetected reads the source with the reader for its detected type when
+// detection is confident enough. Failures fall back to the extension reader.
+func (frs *FileReaderStrategy) readDetected(src source) (*FileContent, bool) {
+	result, err := detectSource(src)
+	if err != nil || result.Confidence < detect.StrongConfidence || result.Type == detect.Binary {
+		return nil, false
+	}
+
+	reader := frs.GetReaderForType(result.Type)
+	if reader == nil {
+		return nil, false
+	}
+
+	content, err := src.read(reader)
+	if err != nil {
+		return nil, false
+	}
+	return content, true
+}
+
+// readCompressed decompresses filename into memory and reads the result
+// with the reader selected for the inner file name.
+func (frs *FileReaderStrategy) readCompressed(filename string, format compression.Format) (*FileContent, error) {
+	input, _, err := compression.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+
+	// Readers keep the whole content in memory anyway; buffering it lets
+	// detection and a fallback reader see the same data
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s file '%s': %w", format, filename, err)
+	}
+
+	content, err := frs.readBytes(filename, compression.TrimExtension(filename), data)
+	if err != nil {
+		return nil, err
+	}
+
+	if info, err := os.Stat(filename); err == nil {
+		content.Size = info.Size()
+	}
+	content.Compression = string(format)
+
+	return content, nil
+}
+
+// ReadData reads in-memory content, such as an archive member, choosing
+// the reader from name and the content the same way ReadFile does.
+// Compressed data is decompressed first.
+func (frs *FileReaderStrategy) ReadData(name string, data []byte) (*FileContent, error) {
+	format := compression.DetectNamed(name, data[:min(len(data), compression.HeaderSize)])
+	if format == compression.None {
+		return frs.readBytes(name, name, data)
+	}
+
+	input, err := compression.NewReader(format, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s data '%s': %w", format, name, err)
+	}
+	defer input.Close()
+
+	decompressed, err := io.ReadAll(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s data '%s': %w", format, name, err)
+	}
+
+	content, err := frs.readBytes(name, compression.TrimExtension(name), decompressed)
+	if err != nil {
+		return nil, err
+	}
+	content.Size = int64(len(data))
+	content.Compression = string(format)
+
+	return content, nil
+}
+
+// RawContent wraps data that is processed without a reader, such as a
+// binary file searched as text. Lines are split at LF and the bytes are
+// kept as they are.
+func RawContent(data []byte) *FileContent {
+	content := string(data)
+
+	var lines []string
+	if content != "" {
+		lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	}
+
+	return &FileContent{
+		Content:   content,
+		Lines:     lines,
+		FileType:  detect.Binary,
+		Size:      int64(len(data)),
+		LineCount: len(lines),
+	}
+}
+
+// readBytes reads data with the reader selected for innerName, decoding
+// it to UTF-8 first if it is in another encoding. Readers must implement
+// types.StreamReader to read from memory.
+func (frs *FileReaderStrategy) readBytes(name, innerName string, data []byte) (*FileContent, error) {
+	size := int64(len(data))
+
+	encoding, bom := charset.Detect(data[:min(len(data), detect.SampleSize)])
+	if encoding != charset.UTF8 || bom {
+		decoded, err := charset.Decode(data, encoding)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode '%s' as %s: %w", name, encoding, err)
+		}
+		data = []byte(decoded)
+	}
+
+	content, err := frs.readSource(source{
+		name:   innerName,
+		sample: func() ([]byte, error) { return data[:min(len(data), detect.SampleSize)], nil },
+		read: func(reader FileReader) (*FileContent, error) {
+			streamReader, ok := reader.(types.StreamReader)
+			if !ok {
+				return nil, fmt.Errorf("the reader for '%s' cannot read from memory", name)
+			}
+			return readNormalized(name, bytes.NewReader(data), streamReader)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content.Size = size
+	content.Encoding = string(encoding)
+	content.BOM = bom
+
+	return content, nil
+}
+
+func detectSource(src source) (detect.Result, error) {
+	sample, err := src.sample()
+	if err != nil {
+		return detect.Result{}, err
+	}
+	return detect.Detect(src.name, sample), nil
+}
+
+func sampleFile(filename string) ([]byte, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open '%s': %w", filename, err)
+	}
+	defer file.Close()
+
+	sample, err := detect.Sample(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", filename, err)
+	}
+	return sample, nil
+}
+
+// DetectFile runs content detection on the beginning of filename,
+// decompressing it first if needed.
+func DetectFile(filename string) (detect.Result, error) {
+	input, _, err := compression.Open(filename)
+	if err != nil {
+		return detect.Result{}, fmt.Errorf("failed to open '%s': %w", filename, err)
+	}
+	defer input.Close()
+
+	sample, err := detect.Sample(input)
+	if err != nil {
+		return detect.Result{}, fmt.Errorf("failed to read '%s': %w", filename, err)
+	}
+
+	if encoding, bom := charset.Detect(sample); encoding != charset.UTF8 || bom {
+		decoded, err := charset.Decode(sample, encoding)
+		if err != nil {
+			return detect.Result{}, fmt.Errorf("failed to decode '%s' as %s: %w", filename, encoding, err)
+		}
+		sample = []byte(decoded)
+	}
+
+	return detect.Detect(compression.TrimExtension(filename), sample), nil
 }
 
 func (frs *FileReaderStrategy) GetSupportedTypes() []string {
@@ -57,6 +422,16 @@ func (frs *FileReaderStrategy) GetSupportedTypes() []string {
 	return types
 }
 
+// GetSupportedTypeNames returns the values accepted by SetTypeOverride.
+func (frs *FileReaderStrategy) GetSupportedTypeNames() []string {
+	var names []string
+	for _, ext := range frs.GetSupportedTypes() {
+		names = append(names, strings.TrimPrefix(ext, "."))
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (frs *FileReaderStrategy) GetReaderForExtension(extension string) FileReader {
 	for _, reader := range frs.readers {
 		if reader.SupportsFileType(extension) {
@@ -66,6 +441,15 @@ func (frs *FileReaderStrategy) GetReaderForExtension(extension string) FileReade
 	return nil
 }
 
+// GetReaderForType returns the reader for a type name such as "json".
+func (frs *FileReaderStrategy) GetReaderForType(fileType string) FileReader {
+	return frs.GetReaderForExtension("." + normalizeType(fileType))
+}
+
 func (frs *FileReaderStrategy) GetReaderCount() int {
 	return len(frs.readers)
 }
+
+func normalizeType(fileType string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(fileType)), ".")
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/kcansari/optix/internal/compression"
+	"github.com/kcansari/optix/internal/jsonutil"
 	"github.com/kcansari/optix/internal/reader"
 	"github.com/kcansari/optix/internal/reader/strategies"
 	"github.com/kcansari/optix/internal/types"
@@ -131,9 +133,9 @@ func TestJSONFileReader(t *testing.T) {
 
 	reader := &strategies.JSONFileReader{}
 
-	// Test multiple JSON format support
+	// JSON Lines files are handled by the dedicated JSONLinesFileReader
 	supportedExts := reader.SupportedExtensions()
-	expectedExts := []string{".json", ".jsonl", ".ndjson"}
+	expectedExts := []string{".json"}
 
 	if len(supportedExts) != len(expectedExts) {
 		t.Errorf("Expected %d supported extensions, got %d", len(expectedExts), len(supportedExts))
@@ -187,6 +189,254 @@ func TestJSONFileReaderInvalidJSON(t *testing.T) {
 	}
 }
 
+// TestJSONLinesFileReader tests record-wise decoding of JSON Lines files.
+func TestJSONLinesFileReader(t *testing.T) {
+	testContent := "{\"id\": 1}\n\n{\"id\": 2, \"tags\": [\"a\"]}\n{\"id\": 3}\n"
+	testFile := createTempFile(t, "test.jsonl", testContent)
+
+	reader := &strategies.JSONLinesFileReader{}
+
+	for _, ext := range []string{".jsonl", ".ndjson"} {
+		if !reader.SupportsFileType(ext) {
+			t.Errorf("JSONLinesFileReader should support %s files", ext)
+		}
+	}
+
+	content, err := reader.Read(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read JSON Lines file: %v", err)
+	}
+
+	if content.FileType != "jsonl" {
+		t.Errorf("Expected file type 'jsonl', got '%s'", content.FileType)
+	}
+
+	if len(content.Records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(content.Records))
+	}
+
+	// Blank lines are skipped but line numbers still refer to the file
+	expectedLineNumbers := []int{1, 3, 4}
+	for i, record := range content.Records {
+		if record.LineNumber != expectedLineNumbers[i] {
+			t.Errorf("Record %d: expected line %d, got %d", i, expectedLineNumbers[i], record.LineNumber)
+		}
+	}
+
+	if content.Content != testContent {
+		t.Errorf("Expected content %q, got %q", testContent, content.Content)
+	}
+}
+
+// TestJSONLinesFileReaderInvalidRecords tests the skip-invalid and max-errors policies.
+func TestJSONLinesFileReaderInvalidRecords(t *testing.T) {
+	testContent := "{\"id\": 1}\n{broken\n{\"id\": 3}\nnot json\n{\"id\": 5}\n"
+	testFile := createTempFile(t, "invalid.jsonl", testContent)
+
+	// Strict mode rejects the file and names the failing line
+	strict := &strategies.JSONLinesFileReader{}
+	_, err := strict.Read(testFile)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected error mentioning line 2, got: %v", err)
+	}
+
+	// Skipping invalid records keeps the valid ones and reports the bad lines
+	lenient := &strategies.JSONLinesFileReader{Options: types.ReadOptions{SkipInvalid: true}}
+	content, err := lenient.Read(testFile)
+	if err != nil {
+		t.Fatalf("Unexpected error with SkipInvalid: %v", err)
+	}
+
+	if len(content.Records) != 3 {
+		t.Errorf("Expected 3 valid records, got %d", len(content.Records))
+	}
+
+	if len(content.InvalidLines) != 2 || content.InvalidLines[0].LineNumber != 2 || content.InvalidLines[1].LineNumber != 4 {
+		t.Errorf("Expected invalid lines 2 and 4, got %+v", content.InvalidLines)
+	}
+
+	// A positive error limit tolerates up to that many invalid lines
+	limited := &strategies.JSONLinesFileReader{Options: types.ReadOptions{MaxErrors: 1}}
+	if _, err := limited.Read(testFile); err == nil {
+		t.Error("Expected error when invalid lines exceed --max-errors")
+	}
+
+	limited.Options.MaxErrors = 2
+	if _, err := limited.Read(testFile); err != nil {
+		t.Errorf("Unexpected error within --max-errors limit: %v", err)
+	}
+}
+
+// TestLogFileReader tests parsing structured log lines into field records.
+func TestLogFileReader(t *testing.T) {
+	testContent := "127.0.0.1 - - [01/May/2024:14:02:03 +0000] \"GET /a HTTP/1.1\" 200 512\n\nnot an access line\n"
+	testFile := createTempFile(t, "access.log", testContent)
+
+	var reader *strategies.LogFileReader
+	for _, candidate := range strategies.NewLogFileReaders(types.ReadOptions{}) {
+		if candidate.SupportsFileType(".access") {
+			reader = candidate
+		}
+	}
+	if reader == nil {
+		t.Fatal("Expected a reader for the access type")
+	}
+
+	// Strict mode rejects the file and names the failing line
+	if _, err := reader.Read(testFile); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected error mentioning line 3, got: %v", err)
+	}
+
+	reader.Options.SkipInvalid = true
+	content, err := reader.Read(testFile)
+	if err != nil {
+		t.Fatalf("Unexpected error with SkipInvalid: %v", err)
+	}
+	if content.FileType != "access" {
+		t.Errorf("Expected file type 'access', got '%s'", content.FileType)
+	}
+	if len(content.Records) != 1 || len(content.InvalidLines) != 1 {
+		t.Fatalf("Expected 1 record and 1 invalid line, got %d and %d", len(content.Records), len(content.InvalidLines))
+	}
+
+	fields := content.Records[0].Data.(*jsonutil.Object)
+	if path, _ := fields.Get("path"); path != "/a" {
+		t.Errorf("Expected path '/a', got %v", path)
+	}
+
+	// The log types can be forced on files with any extension
+	strategy := strategies.NewFileReaderStrategyWithOptions(types.ReadOptions{Type: "access", SkipInvalid: true})
+	if content, err := strategy.ReadFile(testFile); err != nil || content.FileType != "access" {
+		t.Errorf("Expected the access reader to be selected, got %v", err)
+	}
+}
+
+// TestYAMLFileReader tests YAML syntax validation and the decoded document tree.
+func TestYAMLFileReader(t *testing.T) {
+	testContent := "base: &base\n  host: localhost\n  port: 5432\nprod:\n  <<: *base\n  host: prod.example.com\nitems: [1, 2.5, null, true]\n"
+	testFile := createTempFile(t, "config.yaml", testContent)
+
+	reader := &strategies.YAMLFileReader{}
+
+	for _, ext := range []string{".yaml", ".yml"} {
+		if !reader.SupportsFileType(ext) {
+			t.Errorf("YAMLFileReader should support %s files", ext)
+		}
+	}
+
+	content, err := reader.Read(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read YAML file: %v", err)
+	}
+
+	if content.FileType != "yaml" {
+		t.Errorf("Expected file type 'yaml', got '%s'", content.FileType)
+	}
+
+	if content.Content != testContent {
+		t.Errorf("Expected content %q, got %q", testContent, content.Content)
+	}
+
+	// Key order is preserved and merge keys are resolved
+	data, err := jsonutil.Format(content.Data, jsonutil.FormatOptions{})
+	if err != nil {
+		t.Fatalf("Failed to format decoded tree: %v", err)
+	}
+
+	expected := `{"base":{"host":"localhost","port":5432},"prod":{"host":"prod.example.com","port":5432},"items":[1,2.5,null,true]}`
+	if string(data) != expected {
+		t.Errorf("Expected decoded tree %s, got %s", expected, data)
+	}
+
+	// A sequence of merged mappings is merged in order; earlier ones win
+	mergeFile := createTempFile(t, "merge.yaml", "a: &a\n  x: 1\n  y: 1\nb: &b\n  y: 2\n  z: 2\nc:\n  <<: [*a, *b]\n  z: 3\n")
+	merged, err := reader.Read(mergeFile)
+	if err != nil {
+		t.Fatalf("Failed to read YAML file: %v", err)
+	}
+	data, err = jsonutil.Format(merged.Data, jsonutil.FormatOptions{})
+	if err != nil {
+		t.Fatalf("Failed to format decoded tree: %v", err)
+	}
+	expected = `{"a":{"x":1,"y":1},"b":{"y":2,"z":2},"c":{"x":1,"y":1,"z":3}}`
+	if string(data) != expected {
+		t.Errorf("Expected decoded tree %s, got %s", expected, data)
+	}
+
+	// Syntax errors are reported
+	invalidFile := createTempFile(t, "invalid.yaml", "items: [1, 2\n")
+	if _, err := reader.Read(invalidFile); err == nil || !strings.Contains(err.Error(), "invalid YAML") {
+		t.Errorf("Expected error mentioning 'invalid YAML', got: %v", err)
+	}
+}
+
+// TestTOMLFileReader tests TOML syntax validation and the decoded document tree.
+func TestTOMLFileReader(t *testing.T) {
+	testContent := "title = \"Example\"\n\n[server]\nport = 8080\nenabled = true\n\n[[products]]\nname = \"Hammer\"\n\n[[products]]\nname = \"Nail\"\n"
+	testFile := createTempFile(t, "config.toml", testContent)
+
+	reader := &strategies.TOMLFileReader{}
+
+	if !reader.SupportsFileType(".toml") {
+		t.Error("TOMLFileReader should support .toml files")
+	}
+
+	content, err := reader.Read(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read TOML file: %v", err)
+	}
+
+	if content.FileType != "toml" {
+		t.Errorf("Expected file type 'toml', got '%s'", content.FileType)
+	}
+
+	data, err := jsonutil.Format(content.Data, jsonutil.FormatOptions{})
+	if err != nil {
+		t.Fatalf("Failed to format decoded tree: %v", err)
+	}
+
+	expected := `{"title":"Example","server":{"port":8080,"enabled":true},"products":[{"name":"Hammer"},{"name":"Nail"}]}`
+	if string(data) != expected {
+		t.Errorf("Expected decoded tree %s, got %s", expected, data)
+	}
+
+	invalidFile := createTempFile(t, "invalid.toml", "key = \n")
+	if _, err := reader.Read(invalidFile); err == nil || !strings.Contains(err.Error(), "invalid TOML") {
+		t.Errorf("Expected error mentioning 'invalid TOML', got: %v", err)
+	}
+}
+
+// TestXMLFileReader tests XML reading and well-formedness validation.
+func TestXMLFileReader(t *testing.T) {
+	testContent := "<?xml version=\"1.0\"?>\n<feed>\n  <entry id=\"1\">First</entry>\n</feed>\n"
+	testFile := createTempFile(t, "feed.xml", testContent)
+
+	reader := &strategies.XMLFileReader{}
+
+	for _, ext := range []string{".xml", ".rss", ".atom"} {
+		if !reader.SupportsFileType(ext) {
+			t.Errorf("XMLFileReader should support %s files", ext)
+		}
+	}
+
+	content, err := reader.Read(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read XML file: %v", err)
+	}
+
+	if content.FileType != "xml" {
+		t.Errorf("Expected file type 'xml', got '%s'", content.FileType)
+	}
+	if content.LineCount != 4 {
+		t.Errorf("Expected 4 lines, got %d", content.LineCount)
+	}
+
+	invalidFile := createTempFile(t, "invalid.xml", "<feed><entry></feed>\n")
+	if _, err := reader.Read(invalidFile); err == nil || !strings.Contains(err.Error(), "not well-formed XML") {
+		t.Errorf("Expected error mentioning 'not well-formed XML', got: %v", err)
+	}
+}
+
 // TestFileReaderStrategy tests the improved strategy pattern implementation.
 func TestFileReaderStrategy(t *testing.T) {
 	// Create test files
@@ -284,6 +534,208 @@ func TestFileReaderStrategyUnsupportedType(t *testing.T) {
 	}
 }
 
+// TestFileReaderStrategyDetection tests reader selection from content,
+// extension mappings, the type override and the default type.
+func TestFileReaderStrategyDetection(t *testing.T) {
+	tests := []struct {
+		name         string
+		filename     string
+		content      string
+		options      types.ReadOptions
+		expectedType string
+	}{
+		{"extensionless json", "payload", `{"a": 1}`, types.ReadOptions{}, "json"},
+		{"json in txt file", "data.txt", "{\"a\": 1}\n", types.ReadOptions{}, "json"},
+		{"plain txt file", "notes.txt", "{ not json\n", types.ReadOptions{}, "txt"},
+		{"dockerfile", "Dockerfile", "FROM alpine\n", types.ReadOptions{}, "txt"},
+		{"extensionless csv", "export", "a,b\n1,2\n3,4\n", types.ReadOptions{}, "csv"},
+		{"default mapping", "app.conf", "[server]\nport = 1\n", types.ReadOptions{}, "txt"},
+		{"custom mapping", "app.conf", "[server]\nport = 1\n", types.ReadOptions{ExtensionTypes: map[string]string{"conf": "toml"}}, "toml"},
+		{"type override", "data.json", "{\"a\": 1}\n", types.ReadOptions{Type: "txt"}, "txt"},
+		{"default type", "data.json", "{\"a\": 1}\n", types.ReadOptions{DefaultType: "txt"}, "txt"},
+		{"default type skips built-in mapping", "events.ndjson", "{\"a\": 1}\n", types.ReadOptions{DefaultType: "txt"}, "txt"},
+		{"mapping before default type", "app.conf", "[server]\nport = 1\n", types.ReadOptions{DefaultType: "txt", ExtensionTypes: map[string]string{"conf": "toml"}}, "toml"},
+		{"type override before default type", "app.log", "{\"a\": 1}\n", types.ReadOptions{Type: "jsonl", DefaultType: "txt"}, "jsonl"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testFile := createTempFile(t, test.filename, test.content)
+			strategy := strategies.NewFileReaderStrategyWithOptions(test.options)
+
+			content, err := strategy.ReadFile(testFile)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", test.filename, err)
+			}
+			if content.FileType != test.expectedType {
+				t.Errorf("Expected file type '%s', got '%s'", test.expectedType, content.FileType)
+			}
+		})
+	}
+
+	binaryFile := createTempFile(t, "blob", "\x00\x01\x02")
+	_, err := strategies.NewDefaultFileReaderStrategy().ReadFile(binaryFile)
+	if err == nil || !strings.Contains(err.Error(), "appears to be binary") {
+		t.Errorf("Expected binary file error, got: %v", err)
+	}
+
+	strategy := strategies.NewFileReaderStrategyWithOptions(types.ReadOptions{Type: "nope"})
+	if _, err := strategy.ReadFile(binaryFile); err == nil || !strings.Contains(err.Error(), "unsupported type 'nope'") {
+		t.Errorf("Expected unsupported type error, got: %v", err)
+	}
+}
+
+// TestFileReaderStrategyCompressed tests transparent decompression and
+// reader selection by the inner file name.
+func TestFileReaderStrategyCompressed(t *testing.T) {
+	tests := []struct {
+		name         string
+		filename     string
+		format       compression.Format
+		content      string
+		expectedType string
+	}{
+		{"gzip json lines", "events.jsonl.gz", compression.Gzip, "{\"id\": 1}\n{\"id\": 2}\n", "jsonl"},
+		{"bzip2 csv", "data.csv.bz2", compression.Bzip2, "a,b\n1,2\n", "csv"},
+		{"zstd json", "dump.json.zst", compression.Zstd, "{\"a\": [1, 2]}\n", "json"},
+		{"rotated log", "app.log.1", compression.Gzip, "INFO started\nERROR failed\n", "txt"},
+	}
+
+	strategy := strategies.NewDefaultFileReaderStrategy()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compressed, err := compression.Compress(test.format, []byte(test.content))
+			if err != nil {
+				t.Fatalf("Failed to compress: %v", err)
+			}
+			testFile := createTempFile(t, test.filename, string(compressed))
+
+			content, err := strategy.ReadFile(testFile)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", test.filename, err)
+			}
+
+			if content.FileType != test.expectedType {
+				t.Errorf("Expected file type '%s', got '%s'", test.expectedType, content.FileType)
+			}
+			if content.Compression != string(test.format) {
+				t.Errorf("Expected compression '%s', got '%s'", test.format, content.Compression)
+			}
+			if content.Content != test.content {
+				t.Errorf("Expected content %q, got %q", test.content, content.Content)
+			}
+			if content.Size != int64(len(compressed)) {
+				t.Errorf("Expected on-disk size %d, got %d", len(compressed), content.Size)
+			}
+		})
+	}
+
+	corrupt := createTempFile(t, "broken.log.gz", "\x1f\x8b\x08\x00garbage")
+	if _, err := strategy.ReadFile(corrupt); err == nil || !strings.Contains(err.Error(), "gzip") {
+		t.Errorf("Expected gzip decompression error, got: %v", err)
+	}
+}
+
+// TestFileReaderStrategyEncoding tests that files in other encodings are
+// decoded before they are read and that the encoding is recorded.
+func TestFileReaderStrategyEncoding(t *testing.T) {
+	tests := []struct {
+		name             string
+		filename         string
+		data             string
+		expectedType     string
+		expectedEncoding string
+		expectedBOM      bool
+		expectedContent  string
+		expectedWords    int
+	}{
+		{"utf-8", "notes.txt", "café au lait\n", "txt", "utf-8", false, "café au lait\n", 3},
+		{"utf-8 bom json", "data.json", "\xef\xbb\xbf{\"a\": 1}\n", "json", "utf-8", true, "{\"a\": 1}\n", 2},
+		{"utf-16le windows export", "export.csv", "\xff\xfei\x00d\x00,\x00n\x00\r\x00\n\x001\x00,\x00\xe9\x00\r\x00\n\x00", "csv", "utf-16le", true, "id,n\n1,é\n", 4},
+		{"utf-16be", "notes.txt", "\xfe\xff\x00h\x00i\x00\n", "txt", "utf-16be", true, "hi\n", 1},
+		{"latin-1 csv", "people.csv", "name,city\nJos\xe9,M\xe1laga\n", "csv", "iso-8859-1", false, "name,city\nJosé,Málaga\n", 4},
+		{"windows-1252", "quote.txt", "\x93smart\x94 quotes\n", "txt", "windows-1252", false, "“smart” quotes\n", 2},
+	}
+
+	strategy := strategies.NewDefaultFileReaderStrategy()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testFile := createTempFile(t, test.filename, test.data)
+
+			content, err := strategy.ReadFile(testFile)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", test.filename, err)
+			}
+
+			if content.FileType != test.expectedType {
+				t.Errorf("Expected file type '%s', got '%s'", test.expectedType, content.FileType)
+			}
+			if content.Encoding != test.expectedEncoding || content.BOM != test.expectedBOM {
+				t.Errorf("Expected encoding '%s' (bom %t), got '%s' (bom %t)",
+					test.expectedEncoding, test.expectedBOM, content.Encoding, content.BOM)
+			}
+			if content.Content != test.expectedContent {
+				t.Errorf("Expected content %q, got %q", test.expectedContent, content.Content)
+			}
+			if content.WordCount != test.expectedWords {
+				t.Errorf("Expected %d words, got %d", test.expectedWords, content.WordCount)
+			}
+			if content.Size != int64(len(test.data)) {
+				t.Errorf("Expected on-disk size %d, got %d", len(test.data), content.Size)
+			}
+		})
+	}
+}
+
+// TestFileReaderStrategyLineEndings tests that content is normalized to LF
+// and that the original line endings are recorded.
+func TestFileReaderStrategyLineEndings(t *testing.T) {
+	tests := []struct {
+		name                   string
+		filename               string
+		data                   string
+		expectedContent        string
+		expectedLineEnding     string
+		expectedNoFinalNewline bool
+	}{
+		{"lf text", "notes.txt", "a\nb\n", "a\nb\n", "lf", false},
+		{"crlf text", "notes.txt", "a\r\nb\r\n", "a\nb\n", "crlf", false},
+		{"cr text", "notes.txt", "a\rb\r", "a\nb\n", "cr", false},
+		{"no final newline", "notes.txt", "a\r\nb", "a\nb\n", "crlf", true},
+		{"crlf csv", "data.csv", "id,name\r\n1,x\r\n", "id,name\n1,x\n", "crlf", false},
+		{"crlf json", "data.json", "{\r\n  \"a\": 1\r\n}", "{\n  \"a\": 1\n}\n", "crlf", true},
+		{"utf-16 crlf", "export.txt", "\xff\xfea\x00\r\x00\n\x00", "a\n", "crlf", false},
+	}
+
+	strategy := strategies.NewDefaultFileReaderStrategy()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testFile := createTempFile(t, test.filename, test.data)
+
+			content, err := strategy.ReadFile(testFile)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", test.filename, err)
+			}
+
+			if content.Content != test.expectedContent {
+				t.Errorf("Expected content %q, got %q", test.expectedContent, content.Content)
+			}
+			if content.LineEnding != test.expectedLineEnding {
+				t.Errorf("Expected line ending '%s', got '%s'", test.expectedLineEnding, content.LineEnding)
+			}
+			if content.NoFinalNewline != test.expectedNoFinalNewline {
+				t.Errorf("Expected NoFinalNewline %t, got %t", test.expectedNoFinalNewline, content.NoFinalNewline)
+			}
+			if content.Size != int64(len(test.data)) {
+				t.Errorf("Expected on-disk size %d, got %d", len(test.data), content.Size)
+			}
+		})
+	}
+}
+
 // TestAddReader tests adding custom readers to the strategy.
 func TestAddReader(t *testing.T) {
 	strategy := reader.NewFileReaderStrategy()
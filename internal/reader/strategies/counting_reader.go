@@ -0,0 +1,16 @@
+package strategies
+
+import "io"
+
+// countingReader counts the bytes read through it, which gives readers the
+// content size for streams that have no file to stat.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.count += int64(n)
+	return n, err
+}
@@ -20,12 +20,14 @@ func (r *CSVFileReader) Read(filename string) (*types.FileContent, error) {
 	}
 	defer file.Close()
 
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get file info for '%s': %w", filename, err)
-	}
+	return r.ReadStream(filename, file)
+}
+
+// ReadStream reads CSV content from input; name is used in error messages.
+func (r *CSVFileReader) ReadStream(name string, input io.Reader) (*types.FileContent, error) {
+	counter := &countingReader{reader: input}
 
-	bufferedReader := bufio.NewReader(file)
+	bufferedReader := bufio.NewReader(counter)
 	csvReader := csv.NewReader(bufferedReader)
 
 	var contentBuilder strings.Builder
@@ -39,7 +41,7 @@ func (r *CSVFileReader) Read(filename string) (*types.FileContent, error) {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("error reading CSV record in file '%s': %w", filename, err)
+			return nil, fmt.Errorf("error reading CSV record in file '%s': %w", name, err)
 		}
 
 		line := strings.Join(record, ",")
@@ -60,7 +62,7 @@ func (r *CSVFileReader) Read(filename string) (*types.FileContent, error) {
 		Content:   content,
 		Lines:     lines,
 		FileType:  "csv",
-		Size:      fileInfo.Size(),
+		Size:      counter.count,
 		LineCount: recordCount,
 		WordCount: wordCount,
 	}, nil
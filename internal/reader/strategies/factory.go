@@ -1,15 +1,44 @@
 package strategies
 
 import (
+	"github.com/kcansari/optix/internal/detect"
 	"github.com/kcansari/optix/internal/reader"
+	"github.com/kcansari/optix/internal/types"
 )
 
 func NewDefaultFileReaderStrategy() *reader.FileReaderStrategy {
+	return NewFileReaderStrategyWithOptions(types.ReadOptions{})
+}
+
+// NewFileReaderStrategyWithOptions creates the default reader strategy with
+// readers configured by the given options (e.g. skipping invalid records)
+// and the reader selection overrides applied.
+func NewFileReaderStrategyWithOptions(options types.ReadOptions) *reader.FileReaderStrategy {
 	strategy := reader.NewFileReaderStrategy()
 
 	strategy.AddReader(&TextFileReader{})
 	strategy.AddReader(&CSVFileReader{})
 	strategy.AddReader(&JSONFileReader{})
+	strategy.AddReader(&JSONLinesFileReader{Options: options})
+	strategy.AddReader(&YAMLFileReader{})
+	strategy.AddReader(&TOMLFileReader{})
+	strategy.AddReader(&XMLFileReader{})
+	for _, logReader := range NewLogFileReaders(options) {
+		strategy.AddReader(logReader)
+	}
+
+	// Built-in aliases first so user mappings can replace them; with a
+	// default type only the user mappings apply
+	if options.DefaultType == "" {
+		for extension, fileType := range detect.DefaultExtensionTypes {
+			strategy.MapExtension(extension, fileType)
+		}
+	}
+	for extension, fileType := range options.ExtensionTypes {
+		strategy.MapExtension(extension, fileType)
+	}
+	strategy.SetTypeOverride(options.Type)
+	strategy.SetDefaultType(options.DefaultType)
 
 	return strategy
 }
@@ -2,11 +2,12 @@ package strategies
 
 import (
 	"bufio"
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
+	"github.com/kcansari/optix/internal/jsonutil"
 	"github.com/kcansari/optix/internal/types"
 )
 
@@ -19,12 +20,14 @@ func (r *JSONFileReader) Read(filename string) (*types.FileContent, error) {
 	}
 	defer file.Close()
 
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get file info for '%s': %w", filename, err)
-	}
+	return r.ReadStream(filename, file)
+}
 
-	bufferedReader := bufio.NewReader(file)
+// ReadStream reads JSON content from input; name is used in error messages.
+func (r *JSONFileReader) ReadStream(name string, input io.Reader) (*types.FileContent, error) {
+	counter := &countingReader{reader: input}
+
+	bufferedReader := bufio.NewReader(counter)
 
 	var lines []string
 	var contentBuilder strings.Builder
@@ -42,25 +45,24 @@ func (r *JSONFileReader) Read(filename string) (*types.FileContent, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading JSON file '%s': %w", filename, err)
+		return nil, fmt.Errorf("error reading JSON file '%s': %w", name, err)
 	}
 
 	contentStr := contentBuilder.String()
 
-	decoder := json.NewDecoder(strings.NewReader(contentStr))
-
-	var jsonData interface{}
-	if err := decoder.Decode(&jsonData); err != nil {
-		return nil, fmt.Errorf("file '%s' contains invalid JSON: %w", filename, err)
+	jsonData, err := jsonutil.DecodeString(contentStr)
+	if err != nil {
+		return nil, fmt.Errorf("file '%s' contains invalid JSON: %w", name, err)
 	}
 
 	return &types.FileContent{
 		Content:   contentStr,
 		Lines:     lines,
 		FileType:  "json",
-		Size:      fileInfo.Size(),
+		Size:      counter.count,
 		LineCount: len(lines),
 		WordCount: wordCount,
+		Data:      jsonData,
 	}, nil
 }
 
@@ -74,5 +76,5 @@ func (r *JSONFileReader) SupportsFileType(extension string) bool {
 }
 
 func (r *JSONFileReader) SupportedExtensions() []string {
-	return []string{".json", ".jsonl", ".ndjson"}
+	return []string{".json"}
 }
@@ -0,0 +1,106 @@
+package strategies
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kcansari/optix/internal/jsonutil"
+	"github.com/kcansari/optix/internal/types"
+)
+
+// maxRecordSize is the longest single JSON Lines record we accept.
+const maxRecordSize = 16 * 1024 * 1024
+
+// JSONLinesFileReader reads JSON Lines (NDJSON) files, decoding every
+// non-blank line as an independent JSON value.
+type JSONLinesFileReader struct {
+	Options types.ReadOptions
+}
+
+func (r *JSONLinesFileReader) Read(filename string) (*types.FileContent, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open JSON Lines file '%s': %w", filename, err)
+	}
+	defer file.Close()
+
+	return r.ReadStream(filename, file)
+}
+
+// ReadStream reads JSON Lines content from input; name is used in error messages.
+func (r *JSONLinesFileReader) ReadStream(name string, input io.Reader) (*types.FileContent, error) {
+	counter := &countingReader{reader: input}
+
+	scanner := bufio.NewScanner(counter)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+
+	tolerateErrors := r.Options.SkipInvalid || r.Options.MaxErrors > 0
+
+	var lines []string
+	var contentBuilder strings.Builder
+	var wordCount int
+	records := []types.Record{}
+	var invalidLines []types.LineError
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNumber := len(lines) + 1
+
+		lines = append(lines, line)
+		contentBuilder.WriteString(line)
+		contentBuilder.WriteString("\n")
+		wordCount += len(strings.Fields(line))
+
+		// Blank lines separate nothing in JSON Lines; ignore them
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		value, err := jsonutil.DecodeString(line)
+		if err != nil {
+			if !tolerateErrors {
+				return nil, fmt.Errorf("file '%s' contains invalid JSON on line %d: %w", name, lineNumber, err)
+			}
+
+			invalidLines = append(invalidLines, types.LineError{LineNumber: lineNumber, Message: err.Error()})
+			if r.Options.MaxErrors > 0 && len(invalidLines) > r.Options.MaxErrors {
+				return nil, fmt.Errorf("file '%s' has more than %d invalid lines (last on line %d)",
+					name, r.Options.MaxErrors, lineNumber)
+			}
+			continue
+		}
+
+		records = append(records, types.Record{LineNumber: lineNumber, Raw: line, Data: value})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading JSON Lines file '%s': %w", name, err)
+	}
+
+	return &types.FileContent{
+		Content:      contentBuilder.String(),
+		Lines:        lines,
+		FileType:     "jsonl",
+		Size:         counter.count,
+		LineCount:    len(lines),
+		WordCount:    wordCount,
+		Records:      records,
+		InvalidLines: invalidLines,
+	}, nil
+}
+
+func (r *JSONLinesFileReader) SupportsFileType(extension string) bool {
+	for _, ext := range r.SupportedExtensions() {
+		if strings.ToLower(extension) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *JSONLinesFileReader) SupportedExtensions() []string {
+	return []string{".jsonl", ".ndjson"}
+}
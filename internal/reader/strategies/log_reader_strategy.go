@@ -0,0 +1,119 @@
+package strategies
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kcansari/optix/internal/logparser"
+	"github.com/kcansari/optix/internal/types"
+)
+
+// LogFileReader reads structured log files, parsing every non-blank line
+// into a record of fields with a logparser.FieldParser. There is one
+// reader per log format (logfmt, access, syslog), selected with --type
+// or --ext-map, since log files of every format share the .log extension.
+type LogFileReader struct {
+	Parser  logparser.FieldParser
+	Options types.ReadOptions
+}
+
+// NewLogFileReaders creates a reader for each structured log format.
+// JSON logs are read by the JSON Lines reader.
+func NewLogFileReaders(options types.ReadOptions) []*LogFileReader {
+	return []*LogFileReader{
+		{Parser: &logparser.LogfmtFieldParser{}, Options: options},
+		{Parser: &logparser.AccessLogFieldParser{}, Options: options},
+		{Parser: logparser.NewSyslogFieldParser(time.Local), Options: options},
+	}
+}
+
+func (r *LogFileReader) Read(filename string) (*types.FileContent, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s log '%s': %w", r.Parser.GetFormat(), filename, err)
+	}
+	defer file.Close()
+
+	return r.ReadStream(filename, file)
+}
+
+// ReadStream reads log content from input; name is used in error messages.
+func (r *LogFileReader) ReadStream(name string, input io.Reader) (*types.FileContent, error) {
+	counter := &countingReader{reader: input}
+
+	scanner := bufio.NewScanner(counter)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+
+	format := r.Parser.GetFormat()
+	tolerateErrors := r.Options.SkipInvalid || r.Options.MaxErrors > 0
+
+	var lines []string
+	var contentBuilder strings.Builder
+	var wordCount int
+	records := []types.Record{}
+	var invalidLines []types.LineError
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNumber := len(lines) + 1
+
+		lines = append(lines, line)
+		contentBuilder.WriteString(line)
+		contentBuilder.WriteString("\n")
+		wordCount += len(strings.Fields(line))
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields, err := r.Parser.ParseFields(line)
+		if err != nil {
+			if !tolerateErrors {
+				return nil, fmt.Errorf("file '%s' contains an invalid %s line %d: %w", name, format, lineNumber, err)
+			}
+
+			invalidLines = append(invalidLines, types.LineError{LineNumber: lineNumber, Message: err.Error()})
+			if r.Options.MaxErrors > 0 && len(invalidLines) > r.Options.MaxErrors {
+				return nil, fmt.Errorf("file '%s' has more than %d invalid lines (last on line %d)",
+					name, r.Options.MaxErrors, lineNumber)
+			}
+			continue
+		}
+
+		records = append(records, types.Record{LineNumber: lineNumber, Raw: line, Data: fields})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s log '%s': %w", format, name, err)
+	}
+
+	return &types.FileContent{
+		Content:      contentBuilder.String(),
+		Lines:        lines,
+		FileType:     format,
+		Size:         counter.count,
+		LineCount:    len(lines),
+		WordCount:    wordCount,
+		Records:      records,
+		InvalidLines: invalidLines,
+	}, nil
+}
+
+func (r *LogFileReader) SupportsFileType(extension string) bool {
+	for _, ext := range r.SupportedExtensions() {
+		if strings.ToLower(extension) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportedExtensions returns the format name as the extension, e.g.
+// ".logfmt", which makes the format available as a --type.
+func (r *LogFileReader) SupportedExtensions() []string {
+	return []string{"." + r.Parser.GetFormat()}
+}
@@ -3,6 +3,7 @@ package strategies
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -18,12 +19,14 @@ func (r *TextFileReader) Read(filename string) (*types.FileContent, error) {
 	}
 	defer file.Close()
 
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get file info for '%s': %w", filename, err)
-	}
+	return r.ReadStream(filename, file)
+}
+
+// ReadStream reads text content from input; name is used in error messages.
+func (r *TextFileReader) ReadStream(name string, input io.Reader) (*types.FileContent, error) {
+	counter := &countingReader{reader: input}
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(counter)
 
 	var lines []string
 	var contentBuilder strings.Builder
@@ -41,7 +44,7 @@ func (r *TextFileReader) Read(filename string) (*types.FileContent, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading text file '%s': %w", filename, err)
+		return nil, fmt.Errorf("error reading text file '%s': %w", name, err)
 	}
 
 	content := contentBuilder.String()
@@ -50,7 +53,7 @@ func (r *TextFileReader) Read(filename string) (*types.FileContent, error) {
 		Content:   content,
 		Lines:     lines,
 		FileType:  "txt",
-		Size:      fileInfo.Size(),
+		Size:      counter.count,
 		LineCount: len(lines),
 		WordCount: wordCount,
 	}, nil
@@ -0,0 +1,151 @@
+package strategies
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/kcansari/optix/internal/jsonutil"
+	"github.com/kcansari/optix/internal/types"
+)
+
+type TOMLFileReader struct{}
+
+func (r *TOMLFileReader) Read(filename string) (*types.FileContent, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open TOML file '%s': %w", filename, err)
+	}
+	defer file.Close()
+
+	return r.ReadStream(filename, file)
+}
+
+// ReadStream reads TOML content from input; name is used in error messages.
+func (r *TOMLFileReader) ReadStream(name string, input io.Reader) (*types.FileContent, error) {
+	counter := &countingReader{reader: input}
+
+	scanner := bufio.NewScanner(counter)
+
+	var lines []string
+	var contentBuilder strings.Builder
+	var wordCount int
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		lines = append(lines, line)
+		contentBuilder.WriteString(line)
+		contentBuilder.WriteString("\n")
+
+		wordCount += len(strings.Fields(line))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading TOML file '%s': %w", name, err)
+	}
+
+	content := contentBuilder.String()
+
+	var document map[string]any
+	metadata, err := toml.Decode(content, &document)
+	if err != nil {
+		return nil, fmt.Errorf("file '%s' contains invalid TOML: %w", name, err)
+	}
+
+	// Go maps lose the key order, so rebuild it from the decoder metadata
+	keyOrder := make(map[string]int)
+	for i, key := range metadata.Keys() {
+		path := key.String()
+		if _, seen := keyOrder[path]; !seen {
+			keyOrder[path] = i
+		}
+	}
+
+	return &types.FileContent{
+		Content:   content,
+		Lines:     lines,
+		FileType:  "toml",
+		Size:      counter.count,
+		LineCount: len(lines),
+		WordCount: wordCount,
+		Data:      convertTOMLValue(document, "", keyOrder),
+	}, nil
+}
+
+func (r *TOMLFileReader) SupportsFileType(extension string) bool {
+	for _, ext := range r.SupportedExtensions() {
+		if strings.ToLower(extension) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *TOMLFileReader) SupportedExtensions() []string {
+	return []string{".toml"}
+}
+
+// convertTOMLValue turns decoded TOML into the same tree shape the JSON
+// reader produces, ordering table keys as they appear in the file.
+func convertTOMLValue(value any, path string, keyOrder map[string]int) any {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			orderI, okI := keyOrder[tomlKeyPath(path, keys[i])]
+			orderJ, okJ := keyOrder[tomlKeyPath(path, keys[j])]
+			if okI && okJ {
+				return orderI < orderJ
+			}
+			if okI != okJ {
+				return okI
+			}
+			return keys[i] < keys[j]
+		})
+
+		object := &jsonutil.Object{}
+		for _, key := range keys {
+			object.Members = append(object.Members, jsonutil.Member{
+				Key:   key,
+				Value: convertTOMLValue(v[key], tomlKeyPath(path, key), keyOrder),
+			})
+		}
+		return object
+	case []map[string]any:
+		items := make([]any, 0, len(v))
+		for _, item := range v {
+			items = append(items, convertTOMLValue(item, path, keyOrder))
+		}
+		return items
+	case []any:
+		items := make([]any, 0, len(v))
+		for _, item := range v {
+			items = append(items, convertTOMLValue(item, path, keyOrder))
+		}
+		return items
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		// Local dates and times (toml.LocalDate, ...) keep their TOML notation
+		return v.String()
+	default:
+		return value
+	}
+}
+
+// tomlKeyPath joins keys the same way toml.Key.String does for plain keys.
+func tomlKeyPath(path, key string) string {
+	quoted := toml.Key{key}.String()
+	if path == "" {
+		return quoted
+	}
+	return path + "." + quoted
+}
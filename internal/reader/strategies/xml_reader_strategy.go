@@ -0,0 +1,77 @@
+package strategies
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kcansari/optix/internal/types"
+	"github.com/kcansari/optix/internal/xmlquery"
+)
+
+type XMLFileReader struct{}
+
+func (r *XMLFileReader) Read(filename string) (*types.FileContent, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XML file '%s': %w", filename, err)
+	}
+	defer file.Close()
+
+	return r.ReadStream(filename, file)
+}
+
+// ReadStream reads XML content from input; name is used in error messages.
+func (r *XMLFileReader) ReadStream(name string, input io.Reader) (*types.FileContent, error) {
+	counter := &countingReader{reader: input}
+
+	scanner := bufio.NewScanner(counter)
+
+	var lines []string
+	var contentBuilder strings.Builder
+	var wordCount int
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		lines = append(lines, line)
+		contentBuilder.WriteString(line)
+		contentBuilder.WriteString("\n")
+
+		wordCount += len(strings.Fields(line))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading XML file '%s': %w", name, err)
+	}
+
+	content := contentBuilder.String()
+
+	// Stream the tokens once to make sure the document is well-formed
+	if _, err := xmlquery.Analyze(strings.NewReader(content)); err != nil {
+		return nil, fmt.Errorf("file '%s' is not well-formed XML: %w", name, err)
+	}
+
+	return &types.FileContent{
+		Content:   content,
+		Lines:     lines,
+		FileType:  "xml",
+		Size:      counter.count,
+		LineCount: len(lines),
+		WordCount: wordCount,
+	}, nil
+}
+
+func (r *XMLFileReader) SupportsFileType(extension string) bool {
+	for _, ext := range r.SupportedExtensions() {
+		if strings.ToLower(extension) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *XMLFileReader) SupportedExtensions() []string {
+	return []string{".xml", ".rss", ".atom"}
+}
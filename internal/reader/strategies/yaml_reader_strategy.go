@@ -0,0 +1,202 @@
+package strategies
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kcansari/optix/internal/jsonutil"
+	"github.com/kcansari/optix/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+type YAMLFileReader struct{}
+
+func (r *YAMLFileReader) Read(filename string) (*types.FileContent, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open YAML file '%s': %w", filename, err)
+	}
+	defer file.Close()
+
+	return r.ReadStream(filename, file)
+}
+
+// ReadStream reads YAML content from input; name is used in error messages.
+func (r *YAMLFileReader) ReadStream(name string, input io.Reader) (*types.FileContent, error) {
+	counter := &countingReader{reader: input}
+
+	scanner := bufio.NewScanner(counter)
+
+	var lines []string
+	var contentBuilder strings.Builder
+	var wordCount int
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		lines = append(lines, line)
+		contentBuilder.WriteString(line)
+		contentBuilder.WriteString("\n")
+
+		wordCount += len(strings.Fields(line))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading YAML file '%s': %w", name, err)
+	}
+
+	content := contentBuilder.String()
+
+	data, err := decodeYAMLDocuments(content)
+	if err != nil {
+		return nil, fmt.Errorf("file '%s' contains invalid YAML: %w", name, err)
+	}
+
+	return &types.FileContent{
+		Content:   content,
+		Lines:     lines,
+		FileType:  "yaml",
+		Size:      counter.count,
+		LineCount: len(lines),
+		WordCount: wordCount,
+		Data:      data,
+	}, nil
+}
+
+func (r *YAMLFileReader) SupportsFileType(extension string) bool {
+	for _, ext := range r.SupportedExtensions() {
+		if strings.ToLower(extension) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *YAMLFileReader) SupportedExtensions() []string {
+	return []string{".yaml", ".yml"}
+}
+
+// decodeYAMLDocuments decodes every document in a YAML stream into an
+// order-preserving tree. A single document is returned as is; a stream of
+// several documents is returned as a []any with one entry per document.
+func decodeYAMLDocuments(content string) (any, error) {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+
+	var documents []any
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := convertYAMLNode(&node)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, value)
+	}
+
+	switch len(documents) {
+	case 0:
+		return nil, nil
+	case 1:
+		return documents[0], nil
+	default:
+		return documents, nil
+	}
+}
+
+// mergeYAMLMappings copies the members of a merged mapping, or of a
+// sequence of mappings, into object. Keys already in object win, and
+// earlier mappings of a sequence win over later ones. It reports false,
+// leaving object unchanged, when value is not a mapping or a sequence of
+// mappings.
+func mergeYAMLMappings(object *jsonutil.Object, value any) bool {
+	var mappings []*jsonutil.Object
+	switch v := value.(type) {
+	case *jsonutil.Object:
+		mappings = []*jsonutil.Object{v}
+	case []any:
+		for _, item := range v {
+			mapping, ok := item.(*jsonutil.Object)
+			if !ok {
+				return false
+			}
+			mappings = append(mappings, mapping)
+		}
+	default:
+		return false
+	}
+
+	for _, mapping := range mappings {
+		for _, member := range mapping.Members {
+			if _, exists := object.Get(member.Key); !exists {
+				object.Set(member.Key, member.Value)
+			}
+		}
+	}
+	return true
+}
+
+// convertYAMLNode turns a yaml.Node into the same tree shape the JSON reader
+// produces: *jsonutil.Object for mappings, []any for sequences and Go scalars.
+func convertYAMLNode(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return convertYAMLNode(node.Content[0])
+	case yaml.AliasNode:
+		return convertYAMLNode(node.Alias)
+	case yaml.MappingNode:
+		object := &jsonutil.Object{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+
+			value, err := convertYAMLNode(valueNode)
+			if err != nil {
+				return nil, err
+			}
+
+			// Merge keys (<<: *anchor or <<: [*a, *b]) copy the members of
+			// the referenced mappings
+			if keyNode.Tag == "!!merge" {
+				if mergeYAMLMappings(object, value) {
+					continue
+				}
+			}
+
+			object.Set(keyNode.Value, value)
+		}
+		return object, nil
+	case yaml.SequenceNode:
+		items := make([]any, 0, len(node.Content))
+		for _, child := range node.Content {
+			value, err := convertYAMLNode(child)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	default:
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		// Timestamps are kept as written rather than re-formatted
+		if _, isTime := value.(time.Time); isTime {
+			return node.Value, nil
+		}
+		return value, nil
+	}
+}
@@ -0,0 +1,122 @@
+package secrets
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Allowlist holds the findings that are known and accepted, such as test
+// fixtures and documentation examples.
+type Allowlist struct {
+	fingerprints map[string]bool
+	rules        map[string]bool
+	paths        []string
+	secrets      []*regexp.Regexp
+}
+
+// NewAllowlist creates an empty allowlist.
+func NewAllowlist() *Allowlist {
+	return &Allowlist{fingerprints: make(map[string]bool), rules: make(map[string]bool)}
+}
+
+// LoadAllowlist reads an allowlist file; see ParseAllowlist.
+func LoadAllowlist(filename string) (*Allowlist, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowlist: %w", err)
+	}
+	defer file.Close()
+
+	allowlist, err := ParseAllowlist(file)
+	if err != nil {
+		return nil, fmt.Errorf("allowlist '%s': %w", filename, err)
+	}
+	return allowlist, nil
+}
+
+// ParseAllowlist parses an allowlist with one entry per line. Blank lines
+// and lines starting with # are ignored.
+//
+//	fingerprint:3f9a0c1d2e4b5a69   a finding, as printed with it
+//	path:testdata/                 files under a directory
+//	path:*.md                      files matching a glob (name or path)
+//	rule:jwt                       every finding of a rule
+//	secret:^AKIA.*EXAMPLE$         secrets matching a regular expression
+func ParseAllowlist(input io.Reader) (*Allowlist, error) {
+	allowlist := NewAllowlist()
+	scanner := bufio.NewScanner(input)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		kind, value, ok := strings.Cut(line, ":")
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("line %d: expected KIND:VALUE with kind fingerprint, path, rule or secret", lineNumber)
+		}
+		switch strings.TrimSpace(kind) {
+		case "fingerprint":
+			allowlist.fingerprints[strings.ToLower(value)] = true
+		case "rule":
+			allowlist.rules[value] = true
+		case "path":
+			if _, err := path.Match(value, ""); err != nil {
+				return nil, fmt.Errorf("line %d: invalid path pattern '%s': %w", lineNumber, value, err)
+			}
+			allowlist.paths = append(allowlist.paths, value)
+		case "secret":
+			pattern, err := regexp.Compile(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid secret pattern '%s': %w", lineNumber, value, err)
+			}
+			allowlist.secrets = append(allowlist.secrets, pattern)
+		default:
+			return nil, fmt.Errorf("line %d: unknown kind '%s' (use fingerprint, path, rule or secret)", lineNumber, kind)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return allowlist, nil
+}
+
+// AllowsPath reports whether the file is allowlisted. A pattern matches
+// the whole path, the file name, or a directory the file is in.
+func (a *Allowlist) AllowsPath(fileName string) bool {
+	fileName = filepath.ToSlash(filepath.Clean(fileName))
+	for _, pattern := range a.paths {
+		if matched, _ := path.Match(pattern, fileName); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, path.Base(fileName)); matched {
+			return true
+		}
+		if directory := strings.TrimSuffix(pattern, "/"); strings.HasPrefix(fileName, directory+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether a finding is allowlisted.
+func (a *Allowlist) Allows(finding Finding) bool {
+	if a.fingerprints[finding.Fingerprint()] || a.rules[finding.Rule.ID] || a.AllowsPath(finding.File) {
+		return true
+	}
+	for _, pattern := range a.secrets {
+		if pattern.MatchString(finding.Secret) {
+			return true
+		}
+	}
+	return false
+}
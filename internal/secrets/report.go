@@ -0,0 +1,265 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Reporter writes findings in one output format. Secrets are always
+// redacted, so that reports can be shared.
+type Reporter interface {
+	// Report writes the findings; rules are all the rules that were used
+	Report(w io.Writer, findings []Finding, rules []*Rule) error
+
+	// GetFormat returns the name of the format, e.g. "sarif"
+	GetFormat() string
+}
+
+// ReporterStrategy holds the available reporters.
+type ReporterStrategy struct {
+	reporters []Reporter
+}
+
+// NewReporterStrategy creates a strategy without reporters.
+func NewReporterStrategy() *ReporterStrategy {
+	return &ReporterStrategy{}
+}
+
+// NewDefaultReporterStrategy creates a strategy with the text, JSON and
+// SARIF reporters.
+func NewDefaultReporterStrategy(toolVersion string) *ReporterStrategy {
+	strategy := NewReporterStrategy()
+	strategy.AddReporter(&TextReporter{})
+	strategy.AddReporter(&JSONReporter{})
+	strategy.AddReporter(&SARIFReporter{ToolVersion: toolVersion})
+	return strategy
+}
+
+// AddReporter adds a reporter to the strategy.
+func (s *ReporterStrategy) AddReporter(reporter Reporter) {
+	s.reporters = append(s.reporters, reporter)
+}
+
+// GetReporter returns the reporter for a format name, or nil.
+func (s *ReporterStrategy) GetReporter(format string) Reporter {
+	for _, reporter := range s.reporters {
+		if strings.EqualFold(reporter.GetFormat(), format) {
+			return reporter
+		}
+	}
+	return nil
+}
+
+// GetFormatNames returns the names of the formats, sorted.
+func (s *ReporterStrategy) GetFormatNames() []string {
+	names := make([]string, 0, len(s.reporters))
+	for _, reporter := range s.reporters {
+		names = append(names, reporter.GetFormat())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FormatForFile returns the format implied by the extension of an output
+// file (report.sarif, findings.json), or "" when there is none.
+func (s *ReporterStrategy) FormatForFile(fileName string) string {
+	extension := strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	if extension == "txt" || s.GetReporter(extension) == nil {
+		return ""
+	}
+	return extension
+}
+
+// roundEntropy rounds an entropy to two decimals for reports.
+func roundEntropy(entropy float64) float64 {
+	return math.Round(entropy*100) / 100
+}
+
+// TextReporter writes one line per finding, like compiler messages:
+// file:line:column: severity rule description.
+type TextReporter struct{}
+
+func (r *TextReporter) Report(w io.Writer, findings []Finding, rules []*Rule) error {
+	for _, finding := range findings {
+		_, err := fmt.Fprintf(w, "%s:%d:%d: %s %s: %s %s (entropy %.2f, fingerprint %s)\n",
+			finding.File, finding.Line, finding.Column, finding.Rule.Severity, finding.Rule.ID,
+			finding.Rule.Description, finding.Redacted(), finding.Entropy, finding.Fingerprint())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TextReporter) GetFormat() string {
+	return "text"
+}
+
+// jsonFinding is the layout of a finding in JSON reports.
+type jsonFinding struct {
+	RuleID      string  `json:"rule_id"`
+	Description string  `json:"description"`
+	Severity    string  `json:"severity"`
+	File        string  `json:"file"`
+	Line        int     `json:"line"`
+	Column      int     `json:"column"`
+	EndColumn   int     `json:"end_column"`
+	Secret      string  `json:"secret"`
+	Entropy     float64 `json:"entropy"`
+	Fingerprint string  `json:"fingerprint"`
+}
+
+// JSONReporter writes the findings as an indented JSON array.
+type JSONReporter struct{}
+
+func (r *JSONReporter) Report(w io.Writer, findings []Finding, rules []*Rule) error {
+	items := make([]jsonFinding, len(findings))
+	for i, finding := range findings {
+		items[i] = jsonFinding{
+			RuleID:      finding.Rule.ID,
+			Description: finding.Rule.Description,
+			Severity:    finding.Rule.Severity,
+			File:        finding.File,
+			Line:        finding.Line,
+			Column:      finding.Column,
+			EndColumn:   finding.EndColumn,
+			Secret:      finding.Redacted(),
+			Entropy:     roundEntropy(finding.Entropy),
+			Fingerprint: finding.Fingerprint(),
+		}
+	}
+	return writeJSON(w, items)
+}
+
+func (r *JSONReporter) GetFormat() string {
+	return "json"
+}
+
+// writeJSON writes a value as indented JSON followed by a line break.
+func writeJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(value)
+}
+
+// SARIFReporter writes the findings as a SARIF 2.1.0 log, the format read
+// by code scanning services.
+type SARIFReporter struct {
+	// ToolVersion is reported as the version of Optix
+	ToolVersion string
+}
+
+// The SARIF 2.1.0 objects that are written.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool       sarifTool     `json:"tool"`
+		ColumnKind string        `json:"columnKind"`
+		Results    []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Version        string      `json:"version,omitempty"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID                   string             `json:"id"`
+		ShortDescription     sarifMessage       `json:"shortDescription"`
+		DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	}
+	sarifConfiguration struct {
+		Level string `json:"level"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID              string            `json:"ruleId"`
+		RuleIndex           int               `json:"ruleIndex"`
+		Level               string            `json:"level"`
+		Message             sarifMessage      `json:"message"`
+		Locations           []sarifLocation   `json:"locations"`
+		PartialFingerprints map[string]string `json:"partialFingerprints"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+		EndColumn   int `json:"endColumn"`
+	}
+)
+
+func (r *SARIFReporter) Report(w io.Writer, findings []Finding, rules []*Rule) error {
+	driver := sarifDriver{
+		Name:           "optix",
+		InformationURI: "https://github.com/kcansari/optix",
+		Version:        r.ToolVersion,
+		Rules:          make([]sarifRule, len(rules)),
+	}
+	ruleIndex := make(map[string]int, len(rules))
+	for i, rule := range rules {
+		ruleIndex[rule.ID] = i
+		driver.Rules[i] = sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: rule.Severity},
+		}
+	}
+
+	results := make([]sarifResult, len(findings))
+	for i, finding := range findings {
+		results[i] = sarifResult{
+			RuleID:    finding.Rule.ID,
+			RuleIndex: ruleIndex[finding.Rule.ID],
+			Level:     finding.Rule.Severity,
+			Message: sarifMessage{Text: fmt.Sprintf("%s: %s (entropy %.2f)",
+				finding.Rule.Description, finding.Redacted(), finding.Entropy)},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(finding.File)},
+				Region: sarifRegion{
+					StartLine:   finding.Line,
+					StartColumn: finding.Column,
+					EndColumn:   finding.EndColumn,
+				},
+			}}},
+			PartialFingerprints: map[string]string{"optixSecret/v1": finding.Fingerprint()},
+		}
+	}
+
+	return writeJSON(w, sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:       sarifTool{Driver: driver},
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	})
+}
+
+func (r *SARIFReporter) GetFormat() string {
+	return "sarif"
+}
@@ -0,0 +1,357 @@
+// Package secrets finds credentials such as API keys, tokens and private
+// keys in text. Rules are regular expressions, optionally with a minimum
+// Shannon entropy for the secret so that placeholders like "changeme" are
+// not reported. Findings can be suppressed with an allowlist file or an
+// "optix:ignore" comment on the line.
+package secrets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IgnoreComment marks a line whose findings are not reported.
+const IgnoreComment = "optix:ignore"
+
+// Severity levels of rules, named like the SARIF levels.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNote    = "note"
+)
+
+// Rule describes one kind of secret.
+type Rule struct {
+	// ID identifies the rule, e.g. "aws-access-key-id"
+	ID string
+
+	// Description says what was found, e.g. "AWS access key ID"
+	Description string
+
+	// Severity is SeverityError, SeverityWarning or SeverityNote
+	Severity string
+
+	// Pattern matches the secret. When it has a group named "secret", only
+	// that group is the secret, e.g. the value of password = "...". Of
+	// several groups named "secret", the first one that matched is used.
+	Pattern *regexp.Regexp
+
+	// MinEntropy is the lowest Shannon entropy, in bits per character, of
+	// a reported secret; 0 reports every match
+	MinEntropy float64
+
+	// Generic marks a rule for any value assigned to a secret-like name,
+	// which a scanner runs after the specific rules
+	Generic bool
+}
+
+// NewRule creates a rule, checking the pattern and the severity.
+func NewRule(id, description, severity, pattern string, minEntropy float64) (*Rule, error) {
+	if id == "" {
+		return nil, fmt.Errorf("rule id cannot be empty")
+	}
+	if severity == "" {
+		severity = SeverityWarning
+	}
+	switch severity {
+	case SeverityError, SeverityWarning, SeverityNote:
+	default:
+		return nil, fmt.Errorf("rule '%s': invalid severity '%s' (use error, warning or note)", id, severity)
+	}
+	if pattern == "" {
+		return nil, fmt.Errorf("rule '%s': pattern cannot be empty", id)
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("rule '%s': invalid pattern: %w", id, err)
+	}
+	if minEntropy < 0 {
+		return nil, fmt.Errorf("rule '%s': min entropy cannot be negative", id)
+	}
+	if description == "" {
+		description = id
+	}
+	return &Rule{ID: id, Description: description, Severity: severity, Pattern: compiled, MinEntropy: minEntropy}, nil
+}
+
+// mustRule creates a built-in rule.
+func mustRule(id, description, severity, pattern string, minEntropy float64) *Rule {
+	rule, err := NewRule(id, description, severity, pattern, minEntropy)
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+// DefaultRules returns the built-in rules. Specific rules come before the
+// generic assignment rule, which only reports what they did not.
+func DefaultRules() []*Rule {
+	generic := mustRule("generic-secret", "Secret assigned in code or configuration", SeverityWarning,
+		// A quoted value is the secret as a whole, unless it starts like a
+		// reference or placeholder (${VAR}, $VAR, %(name)s, {{ }}, <...>);
+		// an unquoted value ends at characters that start references
+		`(?i)[\w.-]*(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|credential)[\w.-]*["']?\s*(?::=|=>|[:=])\s*`+
+			`(?:"(?P<secret>[^\s"$%{<][^\s"]{7,})"|'(?P<secret>[^\s'$%{<][^\s']{7,})'|(?P<secret>[^\s"'$%{}<>,;]{8,}))`, 3.5)
+	generic.Generic = true
+
+	return []*Rule{
+		mustRule("private-key", "Private key", SeverityError,
+			`-----BEGIN (?:RSA |DSA |EC |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`, 0),
+		mustRule("aws-access-key-id", "AWS access key ID", SeverityError,
+			`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`, 0),
+		mustRule("aws-secret-access-key", "AWS secret access key", SeverityError,
+			`(?i)aws_?secret_?(?:access_?)?key["']?\s*(?::=|=>|[:=])\s*["']?(?P<secret>[A-Za-z0-9/+]{40})\b`, 3.5),
+		mustRule("github-token", "GitHub token", SeverityError,
+			`\b(?:gh[pousr]_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`, 0),
+		mustRule("slack-token", "Slack token", SeverityError,
+			`\bxox[baprs]-[0-9A-Za-z-]{10,}\b`, 0),
+		mustRule("stripe-secret-key", "Stripe secret key", SeverityError,
+			`\b[rs]k_live_[0-9A-Za-z]{24,}\b`, 0),
+		mustRule("google-api-key", "Google API key", SeverityError,
+			`\bAIza[0-9A-Za-z_-]{35}\b`, 0),
+		mustRule("jwt", "JSON Web Token", SeverityWarning,
+			`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`, 0),
+		generic,
+	}
+}
+
+// rulesFile is the layout of a rules file; see LoadRules.
+type rulesFile struct {
+	Rules []struct {
+		ID          string  `yaml:"id"`
+		Description string  `yaml:"description"`
+		Severity    string  `yaml:"severity"`
+		Pattern     string  `yaml:"pattern"`
+		MinEntropy  float64 `yaml:"min_entropy"`
+	} `yaml:"rules"`
+}
+
+// LoadRules reads rules from a YAML file:
+//
+//	rules:
+//	  - id: internal-api-key
+//	    description: Internal API key
+//	    severity: error
+//	    pattern: '\bik_(?P<secret>[a-z0-9]{32})\b'
+//	    min_entropy: 3
+func LoadRules(filename string) ([]*Rule, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var document rulesFile
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("rules file '%s': %w", filename, err)
+	}
+
+	rules := make([]*Rule, 0, len(document.Rules))
+	for _, entry := range document.Rules {
+		rule, err := NewRule(entry.ID, entry.Description, entry.Severity, entry.Pattern, entry.MinEntropy)
+		if err != nil {
+			return nil, fmt.Errorf("rules file '%s': %w", filename, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Finding is a secret found in a file.
+type Finding struct {
+	Rule *Rule
+
+	// File is the name of the file or archive member
+	File string
+
+	// Line is the line number; Column and EndColumn are the character
+	// positions of the secret, starting at 1, EndColumn past its end
+	Line      int
+	Column    int
+	EndColumn int
+
+	// Secret is the secret as found
+	Secret string
+
+	// Entropy is the Shannon entropy of the secret in bits per character
+	Entropy float64
+}
+
+// Fingerprint identifies the secret independently of where it was found,
+// so that it can be allowlisted.
+func (f Finding) Fingerprint() string {
+	sum := sha256.Sum256([]byte(f.Rule.ID + ":" + f.Secret))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Redacted returns the secret with all but its first characters hidden.
+func (f Finding) Redacted() string {
+	runes := []rune(f.Secret)
+	shown := min(4, len(runes)/4)
+	return string(runes[:shown]) + strings.Repeat("*", len(runes)-shown)
+}
+
+// Entropy returns the Shannon entropy of text in bits per character.
+func Entropy(text string) float64 {
+	if text == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range text {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// Scanner finds secrets with a set of rules.
+type Scanner struct {
+	rules []*Rule
+
+	// genericRules run after rules, so that any rule added later still
+	// reports its secrets before a generic rule claims them
+	genericRules []*Rule
+
+	allowlist *Allowlist
+}
+
+// NewScanner creates a scanner without rules.
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+// NewDefaultScanner creates a scanner with the built-in rules.
+func NewDefaultScanner() *Scanner {
+	scanner := NewScanner()
+	for _, rule := range DefaultRules() {
+		scanner.AddRule(rule)
+	}
+	return scanner
+}
+
+// AddRule adds a rule, replacing a rule with the same ID. Generic rules
+// are kept after all other rules.
+func (s *Scanner) AddRule(rule *Rule) {
+	for _, rules := range [][]*Rule{s.rules, s.genericRules} {
+		for i, existing := range rules {
+			if existing.ID == rule.ID {
+				rules[i] = rule
+				return
+			}
+		}
+	}
+	if rule.Generic {
+		s.genericRules = append(s.genericRules, rule)
+	} else {
+		s.rules = append(s.rules, rule)
+	}
+}
+
+// Rules returns the rules of the scanner in the order they run.
+func (s *Scanner) Rules() []*Rule {
+	rules := make([]*Rule, 0, len(s.rules)+len(s.genericRules))
+	return append(append(rules, s.rules...), s.genericRules...)
+}
+
+// SetAllowlist sets the allowlist of findings that are not reported.
+func (s *Scanner) SetAllowlist(allowlist *Allowlist) {
+	s.allowlist = allowlist
+}
+
+// AllowsFile reports whether a whole file is allowlisted.
+func (s *Scanner) AllowsFile(fileName string) bool {
+	return s.allowlist != nil && s.allowlist.AllowsPath(fileName)
+}
+
+// ScanLines returns the secrets found in the lines of a file. Where the
+// secrets of several rules overlap, only the first rule reports, even when
+// the allowlist accepts its finding.
+func (s *Scanner) ScanLines(fileName string, lines []string) []Finding {
+	if s.AllowsFile(fileName) {
+		return nil
+	}
+
+	rules := s.Rules()
+	var findings []Finding
+	for i, line := range lines {
+		if strings.Contains(line, IgnoreComment) {
+			continue
+		}
+
+		var taken [][2]int
+		for _, rule := range rules {
+			secretGroups := secretGroups(rule.Pattern)
+			for _, match := range rule.Pattern.FindAllStringSubmatchIndex(line, -1) {
+				start, end := match[0], match[1]
+				for _, group := range secretGroups {
+					if match[2*group] >= 0 {
+						start, end = match[2*group], match[2*group+1]
+						break
+					}
+				}
+				if overlaps(taken, start, end) {
+					continue
+				}
+
+				finding := Finding{
+					Rule:      rule,
+					File:      fileName,
+					Line:      i + 1,
+					Column:    utf8.RuneCountInString(line[:start]) + 1,
+					EndColumn: utf8.RuneCountInString(line[:end]) + 1,
+					Secret:    line[start:end],
+				}
+				finding.Entropy = Entropy(finding.Secret)
+				if finding.Entropy < rule.MinEntropy {
+					continue
+				}
+
+				// An allowed secret still takes its span, so that a later,
+				// more generic rule does not report it again
+				taken = append(taken, [2]int{start, end})
+				if s.allowlist != nil && s.allowlist.Allows(finding) {
+					continue
+				}
+				findings = append(findings, finding)
+			}
+		}
+	}
+	return findings
+}
+
+// secretGroups returns the indexes of the groups named "secret" in pattern.
+func secretGroups(pattern *regexp.Regexp) []int {
+	var groups []int
+	for i, name := range pattern.SubexpNames() {
+		if name == "secret" {
+			groups = append(groups, i)
+		}
+	}
+	return groups
+}
+
+// overlaps reports whether the range from start to end overlaps a taken one.
+func overlaps(taken [][2]int, start, end int) bool {
+	for _, span := range taken {
+		if start < span[1] && span[0] < end {
+			return true
+		}
+	}
+	return false
+}
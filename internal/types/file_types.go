@@ -2,6 +2,8 @@
 // This package helps avoid circular dependencies by providing common types.
 package types
 
+import "io"
+
 // FileContent represents the content and metadata of a file.
 // This struct holds all the information we extract from a file.
 type FileContent struct {
@@ -12,7 +14,7 @@ type FileContent struct {
 	// This is useful for line-by-line processing
 	Lines []string
 
-	// FileType indicates what type of file this is (txt, csv, json)
+	// FileType indicates what type of file this is (txt, csv, json, yaml, ...)
 	FileType string
 
 	// Size is the file size in bytes
@@ -23,6 +25,92 @@ type FileContent struct {
 
 	// WordCount is the total number of words in the file
 	WordCount int
+
+	// Data is the decoded document tree for structured formats (JSON, YAML,
+	// TOML). Objects are *jsonutil.Object values that keep key order. For
+	// multi-document YAML files Data is a []any with one entry per document.
+	// It is nil for unstructured formats.
+	Data any
+
+	// Records holds the decoded records of record-oriented formats such as
+	// JSON Lines. It is nil for formats that are not record-oriented.
+	Records []Record
+
+	// InvalidLines lists the lines that were skipped because they could not
+	// be decoded (only populated when the read options allow skipping)
+	InvalidLines []LineError
+
+	// Compression is the format the file was decompressed from ("gzip",
+	// "bzip2", "zlib", "zstd"), or empty for uncompressed files. Size is
+	// the compressed size on disk in that case.
+	Compression string
+
+	// Encoding is the character encoding the file was decoded from
+	// ("utf-8", "utf-16le", "iso-8859-1", ...). Content is always UTF-8;
+	// an empty Encoding means UTF-8.
+	Encoding string
+
+	// BOM reports whether the file started with a byte order mark, which
+	// is removed from Content
+	BOM bool
+
+	// LineEnding is the line-ending style of the file ("lf", "crlf", "cr"
+	// or "mixed"), or empty when it has no line breaks. Content always
+	// uses LF line endings.
+	LineEnding string
+
+	// LineEndings holds the ending of every line, in order, when
+	// LineEnding is "mixed", so that writers can restore each of them
+	LineEndings []string
+
+	// NoFinalNewline reports whether the last line of the file lacked a
+	// line break. Readers still end Content with one.
+	NoFinalNewline bool
+}
+
+// Record is a single decoded record together with its position in the file.
+type Record struct {
+	// LineNumber is the 1-based line the record was read from
+	LineNumber int
+
+	// Raw is the record exactly as it appears in the file
+	Raw string
+
+	// Data is the decoded value of the record
+	Data any
+}
+
+// LineError describes a line that could not be decoded.
+type LineError struct {
+	// LineNumber is the 1-based line that failed to decode
+	LineNumber int
+
+	// Message explains why the line was rejected
+	Message string
+}
+
+// ReadOptions controls how readers are selected and how they deal with
+// malformed input.
+type ReadOptions struct {
+	// SkipInvalid makes record-oriented readers skip lines that fail to
+	// decode instead of rejecting the whole file
+	SkipInvalid bool
+
+	// MaxErrors is the number of invalid lines tolerated before reading
+	// fails; a positive value implies SkipInvalid. Zero means no limit
+	// when SkipInvalid is set.
+	MaxErrors int
+
+	// Type forces the reader for this type (e.g. "json") instead of
+	// choosing one from the extension and content
+	Type string
+
+	// ExtensionTypes maps extra extensions to reader types, e.g. ".conf" -> "txt"
+	ExtensionTypes map[string]string
+
+	// DefaultType is the reader type for files that Type and ExtensionTypes
+	// leave open, instead of choosing one from the extension and content
+	DefaultType string
 }
 
 // FileReader defines the interface that all file readers must implement.
@@ -40,3 +128,10 @@ type FileReader interface {
 	// This removes hardcoding and allows dynamic discovery of supported types.
 	SupportedExtensions() []string
 }
+
+// StreamReader is implemented by readers that can decode content from an
+// io.Reader instead of a file, e.g. a decompressed stream. The name is
+// only used in error messages.
+type StreamReader interface {
+	ReadStream(name string, input io.Reader) (*FileContent, error)
+}
@@ -5,6 +5,8 @@ package types
 
 import (
 	"time"
+
+	"github.com/kcansari/optix/internal/patterns"
 )
 
 // SearchResult represents a single search match with context information.
@@ -27,6 +29,17 @@ type ProcessingResult struct {
 	BackupPath      string
 	ExecutionTime   time.Duration
 	ModifiedContent string
+
+	// SearchResults lists the individual matches of a search operation
+	SearchResults []SearchResult
+
+	// LineEnding is the line-ending style the output must be written with
+	// when the operation changes it (e.g. "crlf"); empty keeps the input's
+	LineEnding string
+
+	// RedactionCounts holds the number of values a redact operation
+	// replaced per kind, e.g. "email"
+	RedactionCounts map[string]int
 }
 
 // TextProcessor defines the strategy interface for text processing operations.
@@ -52,6 +65,12 @@ type ProcessOptions struct {
 	WholeWord     bool
 	ContextLines  int
 
+	// RecordStart groups lines into records before search and filter:
+	// a preset ("java", "python", "go-panic") or a regular expression
+	// matching the first line of each record. Empty treats every line as
+	// a record of its own.
+	RecordStart string
+
 	// Replace options
 	ReplaceWith  string
 	CreateBackup bool
@@ -62,7 +81,16 @@ type ProcessOptions struct {
 	OnlyMatching bool
 
 	// Transform options
-	TransformType string // "upper", "lower", "title", "trim"
+	TransformType string // "upper", "lower", "title", "trim", "eol-lf", "eol-crlf"
+
+	// Redact options
+	RedactKinds []string // kinds of values to redact, e.g. "email"; empty redacts all kinds
+	RedactMode  string   // "mask", "hash" or "fake"
+	RedactKey   string   // HMAC key of the hash and fake modes, so a value always gets the same replacement
+
+	// RedactRegistry holds the kinds RedactKinds are chosen from, e.g. with
+	// configured kinds; nil uses the built-in kinds
+	RedactRegistry *patterns.Registry
 
 	// General options
 	FileName   string
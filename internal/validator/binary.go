@@ -0,0 +1,123 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kcansari/optix/internal/charset"
+	"github.com/kcansari/optix/internal/compression"
+	"github.com/kcansari/optix/internal/detect"
+)
+
+// BinaryPolicy decides how commands treat binary input files, like grep's
+// --binary-files option.
+type BinaryPolicy string
+
+const (
+	// BinaryReport processes binary files but only reports whether they
+	// match ("Binary file X matches") instead of printing their content
+	BinaryReport BinaryPolicy = "binary"
+
+	// BinarySkip ignores binary files
+	BinarySkip BinaryPolicy = "skip"
+
+	// BinaryText processes binary files as if they were text
+	BinaryText BinaryPolicy = "text"
+
+	// BinaryError fails on the first binary file
+	BinaryError BinaryPolicy = "error"
+)
+
+// ParseBinaryPolicy returns the policy for a name such as "skip".
+func ParseBinaryPolicy(name string) (BinaryPolicy, error) {
+	switch policy := BinaryPolicy(strings.ToLower(strings.TrimSpace(name))); policy {
+	case BinaryReport, BinarySkip, BinaryText, BinaryError:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid binary policy '%s'. Valid policies: binary, skip, text, error", name)
+}
+
+// BinaryFileError reports a binary file that a command refused to process.
+type BinaryFileError struct {
+	Filename string
+	Reason   string
+}
+
+func (e *BinaryFileError) Error() string {
+	return fmt.Sprintf("'%s' is a binary file (%s)", e.Filename, e.Reason)
+}
+
+// BinaryDetector is implemented by validators that can recognise binary files.
+type BinaryDetector interface {
+	// DetectBinary reports whether filename is binary and why
+	DetectBinary(filename string) (bool, string, error)
+}
+
+// DetectBinary reports whether filename is a binary file, judging by its
+// first block: NUL bytes, a high share of control bytes or invalid UTF-8,
+// or the signature of a known binary format. Compressed files are judged
+// by their decompressed content and text in UTF-16 or another detected
+// encoding is not binary.
+func (v *BasicFileValidator) DetectBinary(filename string) (bool, string, error) {
+	input, _, err := compression.Open(filename)
+	if err != nil {
+		return false, "", fmt.Errorf("file '%s' is not readable: %v", filename, err)
+	}
+	defer input.Close()
+
+	sample, err := detect.Sample(input)
+	if err != nil {
+		return false, "", fmt.Errorf("file '%s' is not readable: %v", filename, err)
+	}
+
+	binary, reason := DetectBinarySample(compression.TrimExtension(filename), sample)
+	return binary, reason, nil
+}
+
+// DetectBinaryData is DetectBinary for in-memory content such as an
+// archive member.
+func DetectBinaryData(name string, data []byte) (bool, string) {
+	format := compression.DetectNamed(name, data[:min(len(data), compression.HeaderSize)])
+	if format == compression.None {
+		return DetectBinarySample(name, data[:min(len(data), detect.SampleSize)])
+	}
+
+	input, err := compression.NewReader(format, bytes.NewReader(data))
+	if err != nil {
+		return true, fmt.Sprintf("corrupt %s data", format)
+	}
+	defer input.Close()
+
+	sample, err := detect.Sample(input)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return true, fmt.Sprintf("corrupt %s data", format)
+	}
+	return DetectBinarySample(compression.TrimExtension(name), sample)
+}
+
+// DetectBinarySample reports whether the leading bytes of a file look binary.
+func DetectBinarySample(name string, sample []byte) (bool, string) {
+	if encoding, bom := charset.Detect(sample); encoding != charset.UTF8 || bom {
+		if decoded, err := charset.Decode(sample, encoding); err == nil {
+			sample = []byte(decoded)
+		}
+	}
+
+	result := detect.Detect(name, sample)
+	if result.Type == detect.Binary {
+		return true, result.Reason
+	}
+	return false, ""
+}
+
+// DetectBinary reports whether filename is binary when the validator can
+// tell; validators that cannot report every file as text.
+func (vs *ValidatorStrategy) DetectBinary(filename string) (bool, string, error) {
+	detector, ok := vs.validator.(BinaryDetector)
+	if !ok {
+		return false, "", nil
+	}
+	return detector.DetectBinary(filename)
+}
@@ -1,7 +1,10 @@
 package validator
 
 import (
+	"bytes"
+	"compress/gzip"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -55,3 +58,65 @@ func TestBasicFileValidator(t *testing.T) {
 		t.Error("Expected error for non-existent file")
 	}
 }
+
+func TestDetectBinary(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte("plain text inside gzip\n"))
+	gz.Close()
+
+	tests := []struct {
+		name     string
+		file     string
+		data     []byte
+		expected bool
+	}{
+		{"text", "notes.txt", []byte("hello\nworld\n"), false},
+		{"nul bytes", "data.bin", []byte("abc\x00\x00\x00def\x00"), true},
+		{"png", "image.png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), true},
+		{"utf-16 text", "export.txt", []byte("\xff\xfeh\x00i\x00\n\x00"), false},
+		{"gzip of text", "app.log.gz", compressed.Bytes(), false},
+	}
+
+	validator := NewBasicFileValidator()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), test.file)
+			if err := os.WriteFile(path, test.data, 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			binary, reason, err := validator.DetectBinary(path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if binary != test.expected {
+				t.Errorf("Expected binary %t, got %t (%s)", test.expected, binary, reason)
+			}
+			if binary && reason == "" {
+				t.Error("Expected a reason for a binary file")
+			}
+
+			if binary, _ := DetectBinaryData(test.file, test.data); binary != test.expected {
+				t.Errorf("DetectBinaryData: expected binary %t, got %t", test.expected, binary)
+			}
+		})
+	}
+
+	if _, _, err := validator.DetectBinary("non_existent_file.txt"); err == nil {
+		t.Error("Expected error for non-existent file")
+	}
+}
+
+func TestParseBinaryPolicy(t *testing.T) {
+	for name, expected := range map[string]BinaryPolicy{"binary": BinaryReport, "SKIP": BinarySkip, " text ": BinaryText, "error": BinaryError} {
+		policy, err := ParseBinaryPolicy(name)
+		if err != nil || policy != expected {
+			t.Errorf("Expected '%s' to parse as '%s', got '%s' (%v)", name, expected, policy, err)
+		}
+	}
+
+	if _, err := ParseBinaryPolicy("without-match"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
@@ -0,0 +1,123 @@
+// Package writer writes processed content back to disk in the same on-disk
+// format the input was read from, e.g. re-compressing a gzip log after a
+// replace so that app.log.gz stays a valid gzip file, or writing a UTF-16
+// export back as UTF-16.
+package writer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kcansari/optix/internal/charset"
+	"github.com/kcansari/optix/internal/compression"
+	"github.com/kcansari/optix/internal/lineending"
+	"github.com/kcansari/optix/internal/types"
+)
+
+// Options describes how content is encoded when it is written.
+type Options struct {
+	// Compression is the format the output is compressed with
+	Compression compression.Format
+
+	// Encoding is the character encoding of the output; empty means UTF-8
+	Encoding charset.Encoding
+
+	// BOM writes the byte order mark of Encoding before the content
+	BOM bool
+
+	// LineEnding converts the LF line endings of the content to this
+	// style; None leaves the content as it is, and Mixed restores
+	// LineEndings
+	LineEnding lineending.Style
+
+	// LineEndings holds the ending of every line for the Mixed style; see
+	// lineending.Restore
+	LineEndings []lineending.Style
+
+	// TrimFinalNewline removes the line break at the end of the content,
+	// for inputs whose last line had none
+	TrimFinalNewline bool
+}
+
+// OptionsFor returns the options for writing content, read from source, to
+// target. The output keeps the character encoding, byte order mark, line
+// endings and missing final newline of content.
+//
+// A compression extension on target (.gz, .bz2, ...) selects that format;
+// rewriting the source itself keeps the format it was read in; any other
+// target is written uncompressed.
+func OptionsFor(content *types.FileContent, source, target string) Options {
+	var options Options
+	if content != nil {
+		// Outputs keep the character encoding and line endings of their input
+		options.Encoding = charset.Encoding(content.Encoding)
+		options.BOM = content.BOM
+		options.LineEnding = lineending.Style(content.LineEnding)
+		for _, ending := range content.LineEndings {
+			options.LineEndings = append(options.LineEndings, lineending.Style(ending))
+		}
+		options.TrimFinalNewline = content.NoFinalNewline
+	}
+
+	if format := compression.FromExtension(target); format != compression.None {
+		options.Compression = format
+	} else if content != nil && sameFile(source, target) {
+		options.Compression = compression.Format(content.Compression)
+	}
+	return options
+}
+
+// Encode converts content to the bytes that WriteFile stores.
+func Encode(data string, options Options) ([]byte, error) {
+	if options.TrimFinalNewline {
+		data = strings.TrimSuffix(data, "\n")
+	}
+	if options.LineEnding == lineending.Mixed {
+		data = lineending.Restore(data, options.LineEndings)
+	} else {
+		data = lineending.Convert(data, options.LineEnding)
+	}
+
+	encoded, err := charset.Encode(data, options.Encoding, options.BOM)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Compression != compression.None {
+		compressed, err := compression.Compress(options.Compression, encoded)
+		if err != nil {
+			return nil, err
+		}
+		encoded = compressed
+	}
+
+	return encoded, nil
+}
+
+// WriteFile encodes data according to options and writes it to path.
+func WriteFile(path, data string, options Options) error {
+	encoded, err := Encode(data, options)
+	if err != nil {
+		return fmt.Errorf("failed to encode output for '%s': %w", path, err)
+	}
+
+	return os.WriteFile(path, encoded, 0644)
+}
+
+func sameFile(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA == nil && errB == nil {
+		return os.SameFile(infoA, infoB)
+	}
+
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
@@ -0,0 +1,162 @@
+package writer_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kcansari/optix/internal/charset"
+	"github.com/kcansari/optix/internal/compression"
+	"github.com/kcansari/optix/internal/types"
+	"github.com/kcansari/optix/internal/writer"
+)
+
+// TestOptionsFor tests how the output format is chosen.
+func TestOptionsFor(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "app.log.gz")
+	if err := os.WriteFile(source, nil, 0644); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	content := &types.FileContent{Compression: "gzip"}
+
+	tests := []struct {
+		name     string
+		target   string
+		expected compression.Format
+	}{
+		{"in place keeps format", source, compression.Gzip},
+		{"relative path to source", filepath.Join(dir, ".", "app.log.gz"), compression.Gzip},
+		{"plain output", filepath.Join(dir, "out.log"), compression.None},
+		{"extension selects format", filepath.Join(dir, "out.log.zst"), compression.Zstd},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := writer.OptionsFor(content, source, test.target)
+			if options.Compression != test.expected {
+				t.Errorf("Expected compression '%s', got '%s'", test.expected, options.Compression)
+			}
+		})
+	}
+}
+
+// TestWriteFile tests that written files are compressed as requested.
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt.gz")
+
+	if err := writer.WriteFile(path, "hello\n", writer.Options{Compression: compression.Gzip}); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+
+	format, err := compression.DetectFile(path)
+	if err != nil {
+		t.Fatalf("Failed to detect: %v", err)
+	}
+	if format != compression.Gzip {
+		t.Errorf("Expected gzip output, got '%s'", format)
+	}
+}
+
+// TestWriteFileEncoding tests that output keeps the encoding of its input.
+func TestWriteFileEncoding(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		content  *types.FileContent
+		data     string
+		expected []byte
+	}{
+		{"utf-8", &types.FileContent{Encoding: "utf-8"}, "né\n", []byte("né\n")},
+		{"utf-8 with bom", &types.FileContent{Encoding: "utf-8", BOM: true}, "a\n", []byte("\xef\xbb\xbfa\n")},
+		{"utf-16le with bom", &types.FileContent{Encoding: "utf-16le", BOM: true}, "hi", []byte("\xff\xfeh\x00i\x00")},
+		{"latin-1", &types.FileContent{Encoding: "iso-8859-1"}, "né", []byte("n\xe9")},
+		{"unknown encoding is utf-8", nil, "né", []byte("né")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, "out.txt")
+			if err := writer.WriteFile(path, test.data, writer.OptionsFor(test.content, "in.txt", path)); err != nil {
+				t.Fatalf("Failed to write: %v", err)
+			}
+
+			data, _ := os.ReadFile(path)
+			if string(data) != string(test.expected) {
+				t.Errorf("Expected %q, got %q", test.expected, data)
+			}
+		})
+	}
+
+	err := writer.WriteFile(filepath.Join(dir, "euro.txt"), "5 €", writer.Options{Encoding: charset.Latin1})
+	if err == nil {
+		t.Error("Expected an error for a character Latin-1 cannot represent")
+	}
+}
+
+// TestWriteFileLineEndings tests that output keeps the line endings of its input.
+func TestWriteFileLineEndings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+
+	tests := []struct {
+		name     string
+		content  *types.FileContent
+		expected string
+	}{
+		{"lf", &types.FileContent{LineEnding: "lf"}, "a\nb\n"},
+		{"crlf", &types.FileContent{LineEnding: "crlf"}, "a\r\nb\r\n"},
+		{"cr without final newline", &types.FileContent{LineEnding: "cr", NoFinalNewline: true}, "a\rb"},
+		{"crlf utf-16", &types.FileContent{LineEnding: "crlf", Encoding: "utf-16le"}, "a\x00\r\x00\n\x00b\x00\r\x00\n\x00"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := writer.WriteFile(path, "a\nb\n", writer.OptionsFor(test.content, "in.txt", path)); err != nil {
+				t.Fatalf("Failed to write: %v", err)
+			}
+
+			data, _ := os.ReadFile(path)
+			if string(data) != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, data)
+			}
+		})
+	}
+}
+
+// TestWriteFileMixedLineEndings tests that content with mixed line endings
+// gets the ending of each line back.
+func TestWriteFileMixedLineEndings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mixed.txt")
+	content := &types.FileContent{LineEnding: "mixed", LineEndings: []string{"crlf", "lf", "crlf"}}
+
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"same lines", "a\nB\nc\n", "a\r\nB\nc\r\n"},
+		{"lines removed use the most common ending", "a\nc\n", "a\r\nc\r\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := writer.WriteFile(path, test.data, writer.OptionsFor(content, path, path)); err != nil {
+				t.Fatalf("Failed to write: %v", err)
+			}
+			if data, _ := os.ReadFile(path); string(data) != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, data)
+			}
+		})
+	}
+
+	// An explicit style converts every line
+	options := writer.OptionsFor(content, path, path)
+	options.LineEnding = "lf"
+	if err := writer.WriteFile(path, "a\nB\nc\n", options); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "a\nB\nc\n" {
+		t.Errorf("Expected LF line endings, got %q", data)
+	}
+}
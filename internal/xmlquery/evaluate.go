@@ -0,0 +1,258 @@
+package xmlquery
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Match is a single query result.
+type Match struct {
+	// Line is the 1-based line on which the selected element starts
+	Line int
+
+	// Path locates the element, e.g. /feed/entry[3]/title[1]
+	Path string
+
+	// Attribute is the selected attribute name for attribute queries
+	Attribute string
+
+	// Value is the raw XML, text content or attribute value
+	Value string
+}
+
+// frame is the evaluation state of one open element.
+type frame struct {
+	path string
+
+	// states holds the step indexes reached at this element: state k means
+	// the first k steps matched along the path ending here
+	states []int
+
+	// positions counts siblings per (state, predicate) for [n] predicates
+	positions map[[2]int]int
+
+	// childNames counts children by name to build indexed paths
+	childNames map[string]int
+
+	// capture is set when this element is selected by the query
+	capture *capture
+}
+
+// capture accumulates the result for a selected element.
+type capture struct {
+	line  int
+	path  string
+	start int64
+	text  strings.Builder
+}
+
+// recordingReader keeps the bytes read by the decoder so that selected
+// elements can be returned exactly as written. Implementing io.ByteReader
+// stops encoding/xml from adding its own buffering, which keeps the decoder
+// offsets aligned with the recorded bytes.
+type recordingReader struct {
+	reader *bufio.Reader
+	buffer []byte
+	base   int64
+}
+
+func (r *recordingReader) ReadByte() (byte, error) {
+	b, err := r.reader.ReadByte()
+	if err == nil {
+		r.buffer = append(r.buffer, b)
+	}
+	return b, err
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.buffer = append(r.buffer, p[:n]...)
+	return n, err
+}
+
+// discard drops recorded bytes before offset.
+func (r *recordingReader) discard(offset int64) {
+	drop := offset - r.base
+	if drop <= 0 {
+		return
+	}
+	r.buffer = append(r.buffer[:0], r.buffer[drop:]...)
+	r.base = offset
+}
+
+func (r *recordingReader) slice(start, end int64) string {
+	return string(r.buffer[start-r.base : end-r.base])
+}
+
+// Evaluate streams the XML document from input and calls emit for every
+// match in document order. Evaluation stops at the first error returned by
+// emit or at the first well-formedness error.
+func (q *Query) Evaluate(input io.Reader, emit func(Match) error) error {
+	recorder := &recordingReader{reader: bufio.NewReader(input)}
+	decoder := xml.NewDecoder(recorder)
+
+	stack := []*frame{{states: []int{0}, positions: map[[2]int]int{}, childNames: map[string]int{}}}
+	activeCaptures := 0
+
+	for {
+		tokenStart := decoder.InputOffset()
+		line, _ := decoder.InputPos()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("malformed XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			parent := stack[len(stack)-1]
+			parent.childNames[t.Name.Local]++
+
+			current := &frame{
+				path:       parent.path + "/" + t.Name.Local + "[" + strconv.Itoa(parent.childNames[t.Name.Local]) + "]",
+				states:     q.advance(parent, t),
+				positions:  map[[2]int]int{},
+				childNames: map[string]int{},
+			}
+
+			if containsState(current.states, len(q.Steps)) {
+				if q.Result == AttributeResult {
+					for _, attr := range t.Attr {
+						if q.Attribute == "*" || attr.Name.Local == q.Attribute {
+							match := Match{Line: line, Path: current.path, Attribute: attr.Name.Local, Value: attr.Value}
+							if err := emit(match); err != nil {
+								return err
+							}
+						}
+					}
+				} else {
+					current.capture = &capture{line: line, path: current.path, start: tokenStart}
+					activeCaptures++
+				}
+			}
+
+			stack = append(stack, current)
+		case xml.EndElement:
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			if current.capture != nil {
+				activeCaptures--
+				match := Match{Line: current.capture.line, Path: current.capture.path}
+				if q.Result == TextResult {
+					match.Value = strings.TrimSpace(current.capture.text.String())
+				} else {
+					match.Value = recorder.slice(current.capture.start, decoder.InputOffset())
+				}
+				if err := emit(match); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			// text() selects the direct text children of the selected element
+			if top := stack[len(stack)-1]; top.capture != nil && q.Result == TextResult {
+				top.capture.text.Write(t)
+			}
+		}
+
+		// Only keep the raw bytes needed by elements still being captured
+		if activeCaptures == 0 {
+			recorder.discard(decoder.InputOffset())
+		}
+	}
+
+	if len(stack) != 1 {
+		return fmt.Errorf("malformed XML: unexpected end of document")
+	}
+
+	return nil
+}
+
+// advance computes the states reached at a child element from its parent's states.
+func (q *Query) advance(parent *frame, element xml.StartElement) []int {
+	var states []int
+
+	for _, state := range parent.states {
+		if state >= len(q.Steps) {
+			continue
+		}
+		step := q.Steps[state]
+
+		// A descendant step keeps waiting for a match deeper in the tree
+		if step.Axis == DescendantAxis {
+			states = appendState(states, state)
+		}
+
+		if step.Name != "*" && step.Name != element.Name.Local {
+			continue
+		}
+
+		if q.predicatesPass(parent, state, step, element) {
+			states = appendState(states, state+1)
+		}
+	}
+
+	return states
+}
+
+// predicatesPass applies the step predicates in order. Position predicates
+// count the siblings that passed the predicates before them, as in XPath.
+func (q *Query) predicatesPass(parent *frame, state int, step Step, element xml.StartElement) bool {
+	for i, predicate := range step.Predicates {
+		switch predicate.Kind {
+		case AttributeExists:
+			if _, ok := attributeValue(element, predicate.Attribute); !ok {
+				return false
+			}
+		case AttributeEquals:
+			if value, ok := attributeValue(element, predicate.Attribute); !ok || value != predicate.Value {
+				return false
+			}
+		case AttributeNotEquals:
+			if value, ok := attributeValue(element, predicate.Attribute); !ok || value == predicate.Value {
+				return false
+			}
+		case Position:
+			key := [2]int{state, i}
+			parent.positions[key]++
+			if parent.positions[key] != predicate.Position {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func attributeValue(element xml.StartElement, name string) (string, bool) {
+	if colon := strings.LastIndexByte(name, ':'); colon != -1 {
+		name = name[colon+1:]
+	}
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+func appendState(states []int, state int) []int {
+	if containsState(states, state) {
+		return states
+	}
+	return append(states, state)
+}
+
+func containsState(states []int, state int) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,291 @@
+// Package xmlquery evaluates a subset of XPath against XML documents while
+// streaming encoding/xml tokens, so large feeds never need to be loaded into
+// a DOM. Only the elements that match a query are buffered.
+//
+// Supported syntax:
+//
+//	/a/b          child axis from the document root
+//	//b, /a//b    descendant axis
+//	*             any element name
+//	[@id]         attribute exists
+//	[@id='x']     attribute equals (also != and double quotes)
+//	[2]           position among the matching siblings (1-based)
+//	/text()       text content of the selected elements (final step only)
+//	/@id, /@*     attribute values of the selected elements (final step only)
+//
+// Element names are matched on their local part; namespace prefixes in the
+// query are ignored.
+package xmlquery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Axis describes how a step relates to the previous one.
+type Axis int
+
+const (
+	// ChildAxis selects direct children ("/").
+	ChildAxis Axis = iota
+	// DescendantAxis selects descendants at any depth ("//").
+	DescendantAxis
+)
+
+// PredicateKind identifies the kind of test a predicate performs.
+type PredicateKind int
+
+const (
+	// AttributeExists tests that an attribute is present: [@name].
+	AttributeExists PredicateKind = iota
+	// AttributeEquals tests an attribute value: [@name='value'].
+	AttributeEquals
+	// AttributeNotEquals tests an attribute value: [@name!='value'].
+	AttributeNotEquals
+	// Position selects the n-th sibling that passed the earlier predicates: [n].
+	Position
+)
+
+// Predicate is a single bracketed test applied to a step.
+type Predicate struct {
+	Kind      PredicateKind
+	Attribute string
+	Value     string
+	Position  int
+}
+
+// Step is one location step of a query.
+type Step struct {
+	Axis       Axis
+	Name       string // local element name or "*"
+	Predicates []Predicate
+}
+
+// ResultKind identifies what a query returns for each selected element.
+type ResultKind int
+
+const (
+	// ElementResult returns the matching element as raw XML.
+	ElementResult ResultKind = iota
+	// TextResult returns the text content of the matching element.
+	TextResult
+	// AttributeResult returns attribute values of the matching element.
+	AttributeResult
+)
+
+// Query is a parsed XPath expression.
+type Query struct {
+	Expression string
+	Steps      []Step
+	Result     ResultKind
+	// Attribute is the attribute selected by AttributeResult ("*" for all).
+	Attribute string
+}
+
+// Parse compiles an XPath expression in the supported subset.
+func Parse(expression string) (*Query, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+
+	query := &Query{Expression: expression}
+	rest := expression
+
+	// A relative path is evaluated from the document root
+	if !strings.HasPrefix(rest, "/") {
+		rest = "/" + rest
+	}
+
+	for rest != "" {
+		axis := ChildAxis
+		switch {
+		case strings.HasPrefix(rest, "//"):
+			axis = DescendantAxis
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "/"):
+			rest = rest[1:]
+		default:
+			return nil, fmt.Errorf("expected '/' in query '%s' before '%s'", expression, rest)
+		}
+
+		token, remainder, err := nextStep(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query '%s': %w", expression, err)
+		}
+		rest = remainder
+
+		switch {
+		case token == "text()":
+			if rest != "" {
+				return nil, fmt.Errorf("invalid query '%s': text() must be the last step", expression)
+			}
+			if len(query.Steps) == 0 || axis == DescendantAxis {
+				return nil, fmt.Errorf("invalid query '%s': text() must follow an element step", expression)
+			}
+			query.Result = TextResult
+		case strings.HasPrefix(token, "@"):
+			if rest != "" {
+				return nil, fmt.Errorf("invalid query '%s': attribute selection must be the last step", expression)
+			}
+			// "//@id" selects the attribute on elements at any depth
+			if axis == DescendantAxis || len(query.Steps) == 0 {
+				query.Steps = append(query.Steps, Step{Axis: DescendantAxis, Name: "*"})
+			}
+			query.Result = AttributeResult
+			query.Attribute = token[1:]
+			if query.Attribute == "" {
+				return nil, fmt.Errorf("invalid query '%s': missing attribute name", expression)
+			}
+		default:
+			step, err := parseStep(token)
+			if err != nil {
+				return nil, fmt.Errorf("invalid query '%s': %w", expression, err)
+			}
+			step.Axis = axis
+			query.Steps = append(query.Steps, step)
+		}
+	}
+
+	if len(query.Steps) == 0 {
+		return nil, fmt.Errorf("invalid query '%s': no element steps", expression)
+	}
+
+	return query, nil
+}
+
+// nextStep splits off the next step, respecting quotes and brackets.
+func nextStep(input string) (string, string, error) {
+	depth := 0
+	var quote rune
+
+	for i, r := range input {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+			if depth < 0 {
+				return "", "", fmt.Errorf("unbalanced ']'")
+			}
+		case r == '/' && depth == 0:
+			if i == 0 {
+				return "", "", fmt.Errorf("empty step")
+			}
+			return input[:i], input[i:], nil
+		}
+	}
+
+	if quote != 0 {
+		return "", "", fmt.Errorf("unterminated string literal")
+	}
+	if depth != 0 {
+		return "", "", fmt.Errorf("unbalanced '['")
+	}
+	if input == "" {
+		return "", "", fmt.Errorf("empty step")
+	}
+	return input, "", nil
+}
+
+// parseStep parses "name[pred][pred]".
+func parseStep(token string) (Step, error) {
+	nameEnd := strings.IndexByte(token, '[')
+	if nameEnd == -1 {
+		nameEnd = len(token)
+	}
+
+	name := token[:nameEnd]
+	// Namespace prefixes are ignored; elements match on their local name
+	if colon := strings.LastIndexByte(name, ':'); colon != -1 {
+		name = name[colon+1:]
+	}
+	if name == "" {
+		return Step{}, fmt.Errorf("missing element name in step '%s'", token)
+	}
+	if name != "*" && strings.ContainsAny(name, "()@=' \"") {
+		return Step{}, fmt.Errorf("unsupported step '%s'", token)
+	}
+
+	step := Step{Name: name}
+	rest := token[nameEnd:]
+	for rest != "" {
+		if rest[0] != '[' {
+			return Step{}, fmt.Errorf("unexpected '%s' in step '%s'", rest, token)
+		}
+		end := predicateEnd(rest)
+		if end == -1 {
+			return Step{}, fmt.Errorf("unterminated predicate in step '%s'", token)
+		}
+
+		predicate, err := parsePredicate(strings.TrimSpace(rest[1:end]))
+		if err != nil {
+			return Step{}, err
+		}
+		step.Predicates = append(step.Predicates, predicate)
+		rest = rest[end+1:]
+	}
+
+	return step, nil
+}
+
+// predicateEnd returns the index of the ']' closing the predicate at input[0].
+func predicateEnd(input string) int {
+	var quote rune
+	for i, r := range input {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+func parsePredicate(body string) (Predicate, error) {
+	if position, err := strconv.Atoi(body); err == nil {
+		if position < 1 {
+			return Predicate{}, fmt.Errorf("position predicate must be at least 1, got %d", position)
+		}
+		return Predicate{Kind: Position, Position: position}, nil
+	}
+
+	if !strings.HasPrefix(body, "@") {
+		return Predicate{}, fmt.Errorf("unsupported predicate '[%s]'", body)
+	}
+
+	kind := AttributeExists
+	attribute, value := body[1:], ""
+	if name, literal, found := strings.Cut(body[1:], "!="); found {
+		kind, attribute, value = AttributeNotEquals, name, literal
+	} else if name, literal, found := strings.Cut(body[1:], "="); found {
+		kind, attribute, value = AttributeEquals, name, literal
+	}
+
+	attribute = strings.TrimSpace(attribute)
+	if attribute == "" || strings.ContainsAny(attribute, "'\" ") {
+		return Predicate{}, fmt.Errorf("invalid attribute predicate '[%s]'", body)
+	}
+
+	if kind != AttributeExists {
+		value = strings.TrimSpace(value)
+		if len(value) < 2 || (value[0] != '\'' && value[0] != '"') || value[len(value)-1] != value[0] {
+			return Predicate{}, fmt.Errorf("attribute value must be quoted in '[%s]'", body)
+		}
+		value = value[1 : len(value)-1]
+	}
+
+	return Predicate{Kind: kind, Attribute: attribute, Value: value}, nil
+}
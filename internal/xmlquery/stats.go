@@ -0,0 +1,67 @@
+package xmlquery
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Stats summarizes the structure of an XML document.
+type Stats struct {
+	RootElement      string
+	Elements         int
+	Attributes       int
+	TextNodes        int
+	Comments         int
+	MaxDepth         int
+	DistinctElements map[string]int
+}
+
+// Analyze streams an XML document and counts its elements and attributes.
+// It also serves as a well-formedness check: malformed input is an error.
+func Analyze(input io.Reader) (*Stats, error) {
+	decoder := xml.NewDecoder(input)
+	stats := &Stats{DistinctElements: make(map[string]int)}
+	depth := 0
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line, _ := decoder.InputPos()
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if stats.RootElement == "" {
+				stats.RootElement = t.Name.Local
+			}
+			depth++
+			stats.MaxDepth = max(stats.MaxDepth, depth)
+			stats.Elements++
+			stats.Attributes += len(t.Attr)
+			stats.DistinctElements[t.Name.Local]++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if strings.TrimSpace(string(t)) != "" {
+				stats.TextNodes++
+			}
+		case xml.Comment:
+			stats.Comments++
+		}
+	}
+
+	if stats.RootElement == "" {
+		return nil, fmt.Errorf("document has no root element")
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unexpected end of document")
+	}
+
+	return stats, nil
+}
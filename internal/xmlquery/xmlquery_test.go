@@ -0,0 +1,201 @@
+package xmlquery_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kcansari/optix/internal/xmlquery"
+)
+
+const testDocument = `<?xml version="1.0"?>
+<catalog xmlns:v="urn:vendor">
+  <!-- vendor feed -->
+  <product id="1" status="active"><name>Widget</name><price currency="EUR">9.99</price></product>
+  <product id="2" status="retired">
+    <name>Gadget</name>
+    <price currency="USD">19.50</price>
+  </product>
+  <group>
+    <v:product id="3" status="active"><name>Nested</name></v:product>
+  </group>
+</catalog>
+`
+
+// evaluate runs a query against testDocument and returns the matches.
+func evaluate(t *testing.T, expression string) []xmlquery.Match {
+	t.Helper()
+
+	query, err := xmlquery.Parse(expression)
+	if err != nil {
+		t.Fatalf("Failed to parse '%s': %v", expression, err)
+	}
+
+	var matches []xmlquery.Match
+	err = query.Evaluate(strings.NewReader(testDocument), func(match xmlquery.Match) error {
+		matches = append(matches, match)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to evaluate '%s': %v", expression, err)
+	}
+	return matches
+}
+
+// TestEvaluateValues tests the values selected by the supported syntax.
+func TestEvaluateValues(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   []string
+	}{
+		{"/catalog/product/name/text()", []string{"Widget", "Gadget"}},
+		{"//name/text()", []string{"Widget", "Gadget", "Nested"}},
+		{"catalog/product/name/text()", []string{"Widget", "Gadget"}},
+		{"/catalog//product/@id", []string{"1", "2", "3"}},
+		{"//product[@status='active']/@id", []string{"1", "3"}},
+		{`//product[@status!="active"]/@id`, []string{"2"}},
+		{"//product[@missing]/@id", nil},
+		{"/catalog/product[2]/@id", []string{"2"}},
+		{"/catalog/product[@status='retired'][1]/@id", []string{"2"}},
+		{"//product[@status='active'][2]/@id", nil},
+		{"/catalog/*/name/text()", []string{"Widget", "Gadget"}},
+		{"//@currency", []string{"EUR", "USD"}},
+		{"/catalog/product[1]/price", []string{`<price currency="EUR">9.99</price>`}},
+		{"/catalog/product[1]/@*", []string{"1", "active"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			matches := evaluate(t, test.expression)
+
+			var values []string
+			for _, match := range matches {
+				values = append(values, match.Value)
+			}
+
+			if strings.Join(values, "|") != strings.Join(test.expected, "|") {
+				t.Errorf("Expected %q, got %q", test.expected, values)
+			}
+		})
+	}
+}
+
+// TestEvaluateLocations tests the line numbers and paths reported for matches.
+func TestEvaluateLocations(t *testing.T) {
+	matches := evaluate(t, "//product/name")
+
+	expected := []struct {
+		line int
+		path string
+	}{
+		{4, "/catalog[1]/product[1]/name[1]"},
+		{6, "/catalog[1]/product[2]/name[1]"},
+		{10, "/catalog[1]/group[1]/product[1]/name[1]"},
+	}
+
+	if len(matches) != len(expected) {
+		t.Fatalf("Expected %d matches, got %d", len(expected), len(matches))
+	}
+	for i, match := range matches {
+		if match.Line != expected[i].line || match.Path != expected[i].path {
+			t.Errorf("Match %d: expected line %d path %s, got line %d path %s",
+				i, expected[i].line, expected[i].path, match.Line, match.Path)
+		}
+	}
+}
+
+// TestEvaluateNestedCaptures tests that nested selected elements are each returned whole.
+func TestEvaluateNestedCaptures(t *testing.T) {
+	query, err := xmlquery.Parse("//div")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	var values []string
+	err = query.Evaluate(strings.NewReader(`<div a="1"><div>inner</div></div>`), func(match xmlquery.Match) error {
+		values = append(values, match.Value)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to evaluate: %v", err)
+	}
+
+	// Matches are emitted when the element closes, so the inner one comes first
+	expected := []string{`<div>inner</div>`, `<div a="1"><div>inner</div></div>`}
+	if strings.Join(values, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %q, got %q", expected, values)
+	}
+}
+
+// TestParseErrors tests that unsupported or malformed expressions are rejected.
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"/a[",
+		"/a]",
+		"/a//",
+		"/a[0]",
+		"/a[@id=x]",
+		"/a[@id='x]",
+		"/a[name()]",
+		"/text()",
+		"/a//text()",
+		"/a/text()/b",
+		"/a/@id/b",
+		"/a/@",
+		"/a/count(b)",
+	}
+
+	for _, expression := range tests {
+		if _, err := xmlquery.Parse(expression); err == nil {
+			t.Errorf("Expected error for expression %q", expression)
+		}
+	}
+}
+
+// TestEvaluateMalformedXML tests that malformed documents are reported.
+func TestEvaluateMalformedXML(t *testing.T) {
+	query, err := xmlquery.Parse("//a")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	for _, input := range []string{"<a><b></a>", "<a>", "<a></a><b"} {
+		err := query.Evaluate(strings.NewReader(input), func(xmlquery.Match) error { return nil })
+		if err == nil || !strings.Contains(err.Error(), "malformed XML") {
+			t.Errorf("Expected malformed XML error for %q, got: %v", input, err)
+		}
+	}
+}
+
+// TestAnalyze tests the structural statistics of a document.
+func TestAnalyze(t *testing.T) {
+	stats, err := xmlquery.Analyze(strings.NewReader(testDocument))
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+
+	if stats.RootElement != "catalog" {
+		t.Errorf("Expected root element 'catalog', got '%s'", stats.RootElement)
+	}
+	if stats.Elements != 10 {
+		t.Errorf("Expected 10 elements, got %d", stats.Elements)
+	}
+	if stats.Attributes != 9 {
+		t.Errorf("Expected 9 attributes, got %d", stats.Attributes)
+	}
+	if stats.Comments != 1 {
+		t.Errorf("Expected 1 comment, got %d", stats.Comments)
+	}
+	if stats.MaxDepth != 4 {
+		t.Errorf("Expected max depth 4, got %d", stats.MaxDepth)
+	}
+	if stats.DistinctElements["product"] != 3 {
+		t.Errorf("Expected 3 product elements, got %d", stats.DistinctElements["product"])
+	}
+
+	for _, input := range []string{"", "<a>", "<a></b>", "just text"} {
+		if _, err := xmlquery.Analyze(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected error for input %q", input)
+		}
+	}
+}
@@ -1,6 +1,15 @@
 package main
 
-import "github.com/kcansari/optix/cmd"
+import (
+	"github.com/kcansari/optix/cmd"
+
+	// Command packages register themselves with the root command in init
+	_ "github.com/kcansari/optix/cmd/commands/data"
+	_ "github.com/kcansari/optix/cmd/commands/file"
+	_ "github.com/kcansari/optix/cmd/commands/logs"
+	_ "github.com/kcansari/optix/cmd/commands/process"
+	_ "github.com/kcansari/optix/cmd/commands/scan"
+)
 
 func main() {
 	cmd.Execute()